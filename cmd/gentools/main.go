@@ -0,0 +1,213 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bo0mer/gentools/pkg/output"
+	"github.com/Bo0mer/gentools/pkg/resolution"
+)
+
+func usage() {
+	var out io.Writer = os.Stdout
+
+	fmt.Fprintln(out, "A tool that manages code generated by mongen, logen and tracegen.")
+	fmt.Fprintf(out, "Usage: %s COMMAND [ARGUMENTS]\n", path.Base(os.Args[0]))
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "  Commands:")
+	fmt.Fprintln(out, "    regen [-check | -diff] [PACKAGES]")
+	fmt.Fprintln(out, "                     Regenerate all generated files in the specified packages, including")
+	fmt.Fprintln(out, "                     metric catalogs, from the arguments recorded in their headers")
+	fmt.Fprintln(out, "                     PACKAGES are directories, optionally ending with /...")
+	fmt.Fprintln(out, "                     and default to ./...")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "  Options:")
+	fmt.Fprintln(out, "    -h               Print this text and exit")
+	fmt.Fprintln(out, "    -check           Fail with a diff if a generated file is not up to date")
+	fmt.Fprintln(out, "    -diff            Print a diff for every generated file that is not up to date")
+	fmt.Fprintln(out, "")
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "regen":
+		if err := regen(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+	case "-h", "-help", "--help", "help":
+		usage()
+	default:
+		usage()
+		log.Fatalf("unknown command: %s", os.Args[1])
+	}
+}
+
+func regen(arguments []string) error {
+	fs := flag.NewFlagSet("regen", flag.ExitOnError)
+	fs.Usage = usage
+	var opts output.Options
+	fs.BoolVar(&opts.Check, "check", false, "Fail with a diff if a generated file is not up to date")
+	fs.BoolVar(&opts.Diff, "diff", false, "Print a diff for every generated file that is not up to date")
+	fs.Parse(arguments)
+
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	files, err := generatedFiles(patterns)
+	if err != nil {
+		return err
+	}
+
+	var modeArgs []string
+	if opts.Check {
+		modeArgs = append(modeArgs, "-check")
+	}
+	if opts.Diff {
+		modeArgs = append(modeArgs, "-diff")
+	}
+
+	locator := resolution.NewLocator()
+	var stale, failed int
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		header, _ := output.ParseHeader(src)
+		if len(header.Args) == 0 {
+			fmt.Printf("Skipping %q: the generator arguments are not recorded\n", file)
+			continue
+		}
+
+		hash, err := sourceHash(locator, header.Source)
+		if err != nil {
+			fmt.Printf("Cannot regenerate %q: %v\n", file, err)
+			failed++
+			continue
+		}
+		if hash != header.SourceHash {
+			fmt.Printf("Stale %q: the declaration of %s changed\n", file, header.Source)
+			stale++
+		}
+
+		cmd := exec.Command(header.Generator, append(modeArgs, header.Args...)...)
+		cmd.Dir = filepath.Dir(file)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			if !opts.Check {
+				fmt.Printf("Regenerating %q failed: %v\n", file, err)
+			}
+			failed++
+		}
+	}
+
+	switch {
+	case failed > 0 && opts.Check:
+		return fmt.Errorf("%d of %d generated file(s) are not up to date", failed, len(files))
+	case failed > 0:
+		return fmt.Errorf("failed to regenerate %d of %d file(s)", failed, len(files))
+	case opts.Check && stale > 0:
+		return fmt.Errorf("found %d stale file(s)", stale)
+	}
+	return nil
+}
+
+func sourceHash(locator *resolution.Locator, source string) (string, error) {
+	pkgPath, name := output.SplitSource(source)
+	context := resolution.NewSingleLocationContext(pkgPath)
+	d, err := locator.FindIdentType(context, ast.NewIdent(name))
+	if err != nil {
+		var notFound *resolution.TypeNotFoundError
+		if errors.As(err, &notFound) {
+			return "", fmt.Errorf("%s no longer exists", source)
+		}
+		return "", err
+	}
+	return locator.DeclarationHash(d)
+}
+
+// generatedFiles returns the files generated by any of the known generators
+// in the directories matched by the patterns.
+func generatedFiles(patterns []string) ([]string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		dirs := []string{pattern}
+		if strings.HasSuffix(pattern, "/...") {
+			var err error
+			dirs, err = subdirectories(strings.TrimSuffix(pattern, "/..."))
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, dir := range dirs {
+			found, err := generatedFilesInDir(dir)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, found...)
+		}
+	}
+	return files, nil
+}
+
+func subdirectories(root string) ([]string, error) {
+	if root == "" {
+		root = "."
+	}
+	var dirs []string
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		name := info.Name()
+		if p != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") ||
+			name == "testdata" || name == "vendor") {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, p)
+		return nil
+	})
+	return dirs, err
+}
+
+func generatedFilesInDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || !output.IsGeneratedFileName(entry.Name()) {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := output.ParseHeader(src); ok {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,35 @@
+package examplesmws_test
+
+import "sync"
+
+// logRecorder records the key-value pairs of the logged calls.
+type logRecorder struct {
+	mu      sync.Mutex
+	entries [][]interface{}
+}
+
+func (r *logRecorder) Log(keyvals ...interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, keyvals)
+	return nil
+}
+
+// take returns the entries logged since the last call.
+func (r *logRecorder) take() [][]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := r.entries
+	r.entries = nil
+	return entries
+}
+
+// value returns the value logged under the key in the entry, or nil.
+func value(entry []interface{}, key string) interface{} {
+	for i := 0; i+1 < len(entry); i += 2 {
+		if entry[i] == key {
+			return entry[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Code generated by logen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/logen/examples.PanickyService
+// Source-Hash: sha256:a73124b862ff563a093711fa2ba6b47edd29b1dfd7299f4d40cc82151da01ce7
+// Generator: logen v2.1.0
+// Args: -panics=true -output-dir . -o logging_panicky_service.go .. PanickyService
+package examplesmws
+
+import (
+	alias3 "context"
+	alias4 "fmt"
+	alias1 "github.com/Bo0mer/gentools/cmd/logen/examples"
+	alias2 "github.com/go-kit/kit/log"
+	alias5 "runtime/debug"
+)
+
+type errorLoggingPanickyService struct {
+	next   alias1.PanickyService
+	logger alias2.Logger
+	fields func(ctx alias3.Context, err error) []interface{}
+}
+
+// NewErrorLoggingPanickyService creates new error logging middleware.
+func NewErrorLoggingPanickyService(next alias1.PanickyService, logger alias2.Logger, fields ...func(ctx alias3.Context, err error) []interface{}) alias1.PanickyService {
+	f := func(ctx alias3.Context, err error) []interface{} { return nil }
+	if len(fields) > 0 {
+		f = fields[0]
+	}
+	return &errorLoggingPanickyService{next: next, logger: logger, fields: f}
+}
+func (m *errorLoggingPanickyService) DoWork(arg1 alias3.Context, arg2 string) (int, error) {
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.logger.Log("method", "DoWork", "panic", alias4.Sprint(_panic), "stack", string(alias5.Stack()))
+			panic(_panic)
+		}
+	}()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	if result2 != nil {
+		_fields := []interface{}{"method", "DoWork", "error", result2.Error()}
+		_more := m.fields(arg1, result2)
+		if len(_more) > 0 {
+			_fields = append(_fields, _more...)
+		}
+		m.logger.Log(_fields...)
+	}
+	return result1, result2
+}
+func (m *errorLoggingPanickyService) Close() {
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.logger.Log("method", "Close", "panic", alias4.Sprint(_panic), "stack", string(alias5.Stack()))
+			panic(_panic)
+		}
+	}()
+	m.next.Close()
+	return
+}
@@ -0,0 +1,56 @@
+package examplesmws_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/logen/examples"
+	"github.com/Bo0mer/gentools/cmd/logen/examples/examplesmws"
+)
+
+type panickyService struct{}
+
+func (panickyService) DoWork(context.Context, string) (int, error) {
+	panic("boom")
+}
+
+func (panickyService) Close() {
+	panic("boom")
+}
+
+func TestPanickyServiceLogsPanicsAndRaisesThemAgain(t *testing.T) {
+	for _, tc := range []struct {
+		method string
+		call   func(examples.PanickyService)
+	}{
+		{method: "DoWork", call: func(svc examples.PanickyService) { svc.DoWork(context.Background(), "work") }},
+		{method: "Close", call: func(svc examples.PanickyService) { svc.Close() }},
+	} {
+		t.Run(tc.method, func(t *testing.T) {
+			logger := new(logRecorder)
+			func() {
+				defer func() {
+					if got := recover(); got != "boom" {
+						t.Errorf("got panic %v, want boom", got)
+					}
+				}()
+				tc.call(examplesmws.NewErrorLoggingPanickyService(panickyService{}, logger))
+			}()
+
+			got := logger.take()
+			if len(got) != 1 {
+				t.Fatalf("got %d entries, want 1", len(got))
+			}
+			if method := value(got[0], "method"); method != tc.method {
+				t.Errorf("got method %v, want %s", method, tc.method)
+			}
+			if p := value(got[0], "panic"); p != "boom" {
+				t.Errorf("got panic %v, want boom", p)
+			}
+			if stack, _ := value(got[0], "stack").(string); !strings.Contains(stack, "panickyService") {
+				t.Errorf("got stack %q, want the stack of the panic", stack)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Code generated by logen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/logen/examples.PredicateService
+// Source-Hash: sha256:ef25d499f58a08de1dabed7b85c6b811cf0d5c03aea22058c5642422dbeadb16
+// Generator: logen v2.1.0
+// Args: -output-dir . -o logging_predicate_service.go .. PredicateService
+package examplesmws
+
+import (
+	alias3 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/logen/examples"
+	alias2 "github.com/go-kit/kit/log"
+)
+
+type errorLoggingPredicateService struct {
+	next   alias1.PredicateService
+	logger alias2.Logger
+	fields func(ctx alias3.Context, err error) []interface{}
+}
+
+// NewErrorLoggingPredicateService creates new error logging middleware.
+func NewErrorLoggingPredicateService(next alias1.PredicateService, logger alias2.Logger, fields ...func(ctx alias3.Context, err error) []interface{}) alias1.PredicateService {
+	f := func(ctx alias3.Context, err error) []interface{} { return nil }
+	if len(fields) > 0 {
+		f = fields[0]
+	}
+	return &errorLoggingPredicateService{next: next, logger: logger, fields: f}
+}
+func (m *errorLoggingPredicateService) Lookup(arg1 alias3.Context, arg2 string) (string, bool) {
+	result1, result2 := m.next.Lookup(arg1, arg2)
+	if !result2 {
+		_fields := []interface{}{"method", "Lookup", "error", "failed"}
+		_more := m.fields(arg1, nil)
+		if len(_more) > 0 {
+			_fields = append(_fields, _more...)
+		}
+		m.logger.Log(_fields...)
+	}
+	return result1, result2
+}
+func (m *errorLoggingPredicateService) Status(arg1 alias3.Context) (alias1.Status, error) {
+	result1, result2 := m.next.Status(arg1)
+	if result2 != nil || result1 != alias1.StatusOK {
+		_fields := []interface{}{"method", "Status", "error", "failed"}
+		if result2 != nil {
+			_fields[3] = result2.Error()
+		}
+		_more := m.fields(arg1, result2)
+		if len(_more) > 0 {
+			_fields = append(_fields, _more...)
+		}
+		m.logger.Log(_fields...)
+	}
+	return result1, result2
+}
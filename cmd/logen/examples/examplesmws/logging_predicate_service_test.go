@@ -0,0 +1,65 @@
+package examplesmws_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/logen/examples"
+	"github.com/Bo0mer/gentools/cmd/logen/examples/examplesmws"
+)
+
+type predicateService struct {
+	values map[string]string
+	status examples.Status
+	err    error
+}
+
+func (s predicateService) Lookup(_ context.Context, key string) (string, bool) {
+	value, ok := s.values[key]
+	return value, ok
+}
+
+func (s predicateService) Status(context.Context) (examples.Status, error) {
+	return s.status, s.err
+}
+
+func TestPredicateService(t *testing.T) {
+	ctx := context.Background()
+
+	lookup := func(key string) func(examples.PredicateService) {
+		return func(svc examples.PredicateService) { svc.Lookup(ctx, key) }
+	}
+	status := func(svc examples.PredicateService) { svc.Status(ctx) }
+	for _, tc := range []struct {
+		name      string
+		next      predicateService
+		call      func(examples.PredicateService)
+		wantError interface{}
+	}{
+		{name: "lookup of an existing key", next: predicateService{values: map[string]string{"key": "value"}}, call: lookup("key")},
+		{name: "lookup of a missing key", call: lookup("key"), wantError: "failed"},
+		{name: "healthy status", call: status},
+		{name: "degraded status", next: predicateService{status: examples.StatusDegraded}, call: status, wantError: "failed"},
+		// The predicate does not hold, but the call returned an error.
+		{name: "status error", next: predicateService{err: errors.New("unavailable")}, call: status, wantError: "unavailable"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := new(logRecorder)
+			tc.call(examplesmws.NewErrorLoggingPredicateService(tc.next, logger))
+			got := logger.take()
+			if tc.wantError == nil {
+				if len(got) != 0 {
+					t.Fatalf("got %v logged, want nothing", got)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("got %d entries, want 1", len(got))
+			}
+			if err := value(got[0], "error"); err != tc.wantError {
+				t.Errorf("got error %v, want %v", err, tc.wantError)
+			}
+		})
+	}
+}
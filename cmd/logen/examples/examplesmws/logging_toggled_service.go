@@ -0,0 +1,60 @@
+// Code generated by logen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/logen/examples.ToggledService
+// Source-Hash: sha256:20a37c6a99921698e7e2e1aec5768f03c10f522b003176f2829b066475e81de2
+// Generator: logen v2.1.0
+// Args: -toggles=true -output-dir . -o logging_toggled_service.go .. ToggledService
+package examplesmws
+
+import (
+	alias3 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/logen/examples"
+	alias4 "github.com/Bo0mer/gentools/pkg/toggle"
+	alias2 "github.com/go-kit/kit/log"
+)
+
+type errorLoggingToggledService struct {
+	next         alias1.ToggledService
+	logger       alias2.Logger
+	fields       func(ctx alias3.Context, err error) []interface{}
+	doWorkToggle *alias4.Method
+	notifyToggle *alias4.Method
+}
+
+// NewErrorLoggingToggledService creates new error logging middleware.
+func NewErrorLoggingToggledService(next alias1.ToggledService, logger alias2.Logger, toggles *alias4.Controls, fields ...func(ctx alias3.Context, err error) []interface{}) alias1.ToggledService {
+	f := func(ctx alias3.Context, err error) []interface{} { return nil }
+	if len(fields) > 0 {
+		f = fields[0]
+	}
+	return &errorLoggingToggledService{next: next, logger: logger, fields: f, doWorkToggle: toggles.Method("ToggledService.DoWork"), notifyToggle: toggles.Method("ToggledService.Notify")}
+}
+func (m *errorLoggingToggledService) DoWork(arg1 alias3.Context, arg2 string) (int, error) {
+	if !m.doWorkToggle.Sample() {
+		return m.next.DoWork(arg1, arg2)
+	}
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	if result2 != nil {
+		_fields := []interface{}{"method", "DoWork", "error", result2.Error()}
+		_more := m.fields(arg1, result2)
+		if len(_more) > 0 {
+			_fields = append(_fields, _more...)
+		}
+		m.logger.Log(_fields...)
+	}
+	return result1, result2
+}
+func (m *errorLoggingToggledService) Notify(arg1 alias3.Context, arg2 []string) error {
+	if !m.notifyToggle.Sample() {
+		return m.next.Notify(arg1, arg2)
+	}
+	result1 := m.next.Notify(arg1, arg2)
+	if result1 != nil {
+		_fields := []interface{}{"method", "Notify", "error", result1.Error()}
+		_more := m.fields(arg1, result1)
+		if len(_more) > 0 {
+			_fields = append(_fields, _more...)
+		}
+		m.logger.Log(_fields...)
+	}
+	return result1
+}
@@ -0,0 +1,55 @@
+package examplesmws_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/logen/examples/examplesmws"
+	"github.com/Bo0mer/gentools/pkg/toggle"
+)
+
+type toggledService struct {
+	err error
+}
+
+func (s toggledService) DoWork(context.Context, string) (int, error) {
+	return 1, s.err
+}
+
+func (s toggledService) Notify(context.Context, []string) error {
+	return s.err
+}
+
+func TestToggledService(t *testing.T) {
+	ctx := context.Background()
+	logger := new(logRecorder)
+	toggles := new(toggle.Controls)
+	svc := examplesmws.NewErrorLoggingToggledService(toggledService{err: errors.New("failure")}, logger, toggles)
+
+	toggles.Disable("ToggledService.DoWork")
+	if n, err := svc.DoWork(ctx, "work"); n != 1 || err == nil {
+		t.Errorf("got %d, %v from a disabled method, want the results of next", n, err)
+	}
+	svc.Notify(ctx, nil)
+	got := logger.take()
+	if len(got) != 1 || value(got[0], "method") != "Notify" {
+		t.Fatalf("got %v logged, want only the call of Notify", got)
+	}
+
+	toggles.Enable("ToggledService.DoWork")
+	svc.DoWork(ctx, "work")
+	if got := logger.take(); len(got) != 1 || value(got[0], "method") != "DoWork" {
+		t.Errorf("got %v logged, want the call of DoWork", got)
+	}
+}
+
+func TestToggledServiceWithoutControls(t *testing.T) {
+	logger := new(logRecorder)
+	svc := examplesmws.NewErrorLoggingToggledService(toggledService{err: errors.New("failure")}, logger, nil)
+
+	svc.DoWork(context.Background(), "work")
+	if got := logger.take(); len(got) != 1 {
+		t.Errorf("got %d entries, want 1", len(got))
+	}
+}
@@ -0,0 +1,70 @@
+// Code generated by logen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/logen/examples.TypedErrorService
+// Source-Hash: sha256:dfcc0a878225e23636d7fa52a4f3a6289a580008525fb93d621365456c88897e
+// Generator: logen v2.1.0
+// Args: -output-dir . -o logging_typed_error_service.go .. TypedErrorService
+package examplesmws
+
+import (
+	alias3 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/logen/examples"
+	alias2 "github.com/go-kit/kit/log"
+)
+
+type errorLoggingTypedErrorService struct {
+	next   alias1.TypedErrorService
+	logger alias2.Logger
+	fields func(ctx alias3.Context, err error) []interface{}
+}
+
+// NewErrorLoggingTypedErrorService creates new error logging middleware.
+func NewErrorLoggingTypedErrorService(next alias1.TypedErrorService, logger alias2.Logger, fields ...func(ctx alias3.Context, err error) []interface{}) alias1.TypedErrorService {
+	f := func(ctx alias3.Context, err error) []interface{} { return nil }
+	if len(fields) > 0 {
+		f = fields[0]
+	}
+	return &errorLoggingTypedErrorService{next: next, logger: logger, fields: f}
+}
+func (m *errorLoggingTypedErrorService) Get(arg1 alias3.Context, arg2 string) (string, *alias1.NotFoundError) {
+	result1, result2 := m.next.Get(arg1, arg2)
+	if result2 != nil {
+		_fields := []interface{}{"method", "Get", "error", result2.Error()}
+		_more := m.fields(arg1, result2)
+		if len(_more) > 0 {
+			_fields = append(_fields, _more...)
+		}
+		m.logger.Log(_fields...)
+	}
+	return result1, result2
+}
+func (m *errorLoggingTypedErrorService) Check(arg1 alias3.Context) (error, bool) {
+	result1, result2 := m.next.Check(arg1)
+	if result1 != nil {
+		_fields := []interface{}{"method", "Check", "error", result1.Error()}
+		_more := m.fields(arg1, result1)
+		if len(_more) > 0 {
+			_fields = append(_fields, _more...)
+		}
+		m.logger.Log(_fields...)
+	}
+	return result1, result2
+}
+func (m *errorLoggingTypedErrorService) Resolve(arg1 alias3.Context, arg2 string) (string, *alias1.NotFoundError) {
+	result1, result2 := m.next.Resolve(arg1, arg2)
+	if result2 != nil || result1 == "" {
+		_fields := []interface{}{"method", "Resolve", "error", "failed"}
+		if result2 != nil {
+			_fields[3] = result2.Error()
+		}
+		var _err error
+		if result2 != nil {
+			_err = result2
+		}
+		_more := m.fields(arg1, _err)
+		if len(_more) > 0 {
+			_fields = append(_fields, _more...)
+		}
+		m.logger.Log(_fields...)
+	}
+	return result1, result2
+}
@@ -0,0 +1,79 @@
+package examplesmws_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/logen/examples"
+	"github.com/Bo0mer/gentools/cmd/logen/examples/examplesmws"
+)
+
+type typedErrorService struct {
+	value string
+	err   *examples.NotFoundError
+	check error
+}
+
+func (s typedErrorService) Get(context.Context, string) (string, *examples.NotFoundError) {
+	return s.value, s.err
+}
+
+func (s typedErrorService) Check(context.Context) (error, bool) {
+	return s.check, s.check == nil
+}
+
+func (s typedErrorService) Resolve(context.Context, string) (string, *examples.NotFoundError) {
+	return s.value, s.err
+}
+
+func TestTypedErrorService(t *testing.T) {
+	ctx := context.Background()
+
+	get := func(svc examples.TypedErrorService) { svc.Get(ctx, "key") }
+	check := func(svc examples.TypedErrorService) { svc.Check(ctx) }
+	resolve := func(svc examples.TypedErrorService) { svc.Resolve(ctx, "key") }
+	for _, tc := range []struct {
+		name      string
+		next      typedErrorService
+		call      func(examples.TypedErrorService)
+		wantError interface{}
+	}{
+		// A nil *NotFoundError is not mistaken for a failure.
+		{name: "get of an existing key", next: typedErrorService{value: "value"}, call: get},
+		{name: "get of a missing key", next: typedErrorService{err: &examples.NotFoundError{Key: "key"}}, call: get, wantError: "not found: key"},
+		{name: "successful check", call: check},
+		{name: "failed check", next: typedErrorService{check: errors.New("unhealthy")}, call: check, wantError: "unhealthy"},
+		{name: "resolve of an existing key", next: typedErrorService{value: "value"}, call: resolve},
+		{name: "empty resolve", call: resolve, wantError: "failed"},
+		{name: "resolve of a missing key", next: typedErrorService{value: "value", err: &examples.NotFoundError{Key: "key"}}, call: resolve, wantError: "not found: key"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := new(logRecorder)
+			var fieldsErr error
+			fields := func(_ context.Context, err error) []interface{} {
+				fieldsErr = err
+				return nil
+			}
+			tc.call(examplesmws.NewErrorLoggingTypedErrorService(tc.next, logger, fields))
+			got := logger.take()
+			if tc.wantError == nil {
+				if len(got) != 0 {
+					t.Fatalf("got %v logged, want nothing", got)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("got %d entries, want 1", len(got))
+			}
+			if err := value(got[0], "error"); err != tc.wantError {
+				t.Errorf("got error %v, want %v", err, tc.wantError)
+			}
+			// The fields are created with a nil error, and not with a nil
+			// *NotFoundError, when the call failed without an error.
+			if wantNil := tc.wantError == "failed"; (fieldsErr == nil) != wantNil {
+				t.Errorf("got fields of error %#v, want nil: %t", fieldsErr, wantNil)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package examples
+
+import "context"
+
+//go:generate logen . PredicateService
+
+// Status is the status of a PredicateService.
+type Status int
+
+// Statuses of a PredicateService.
+const (
+	StatusOK Status = iota
+	StatusDegraded
+)
+
+// PredicateService has methods that fail without returning an error.
+type PredicateService interface {
+	//gentools:fail !result2
+	Lookup(context.Context, string) (string, bool)
+	//gentools:fail result1 != StatusOK
+	Status(context.Context) (Status, error)
+}
+
+//go:generate logen . TypedErrorService
+
+// NotFoundError is returned by a TypedErrorService for missing keys.
+type NotFoundError struct {
+	Key string
+}
+
+func (e *NotFoundError) Error() string {
+	return "not found: " + e.Key
+}
+
+// TypedErrorService returns errors of concrete types, and not only as the
+// last result.
+type TypedErrorService interface {
+	Get(context.Context, string) (string, *NotFoundError)
+	Check(context.Context) (error, bool)
+	//gentools:fail result1 == ""
+	Resolve(context.Context, string) (string, *NotFoundError)
+}
+
+//go:generate logen -toggles . ToggledService
+
+// ToggledService has methods whose logging is disabled or sampled at
+// runtime.
+type ToggledService interface {
+	DoWork(context.Context, string) (int, error)
+	Notify(context.Context, []string) error
+}
+
+//go:generate logen -panics . PanickyService
+
+// PanickyService has methods whose panics are recorded before they are raised
+// again.
+type PanickyService interface {
+	DoWork(context.Context, string) (int, error)
+	Close()
+}
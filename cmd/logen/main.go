@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"errors"
 	"flag"
 	"fmt"
@@ -15,16 +16,30 @@ import (
 	"unicode"
 
 	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/output"
 	"github.com/Bo0mer/gentools/pkg/resolution"
 	"github.com/Bo0mer/gentools/pkg/transformation"
+	"github.com/Bo0mer/gentools/pkg/typecheck"
+)
+
+var (
+	outputOptions output.Options
+	naming        transformation.Naming
+	toggles       bool
+	panics        bool
 )
 
 func init() {
+	outputOptions.RegisterFlags(flag.CommandLine)
+	naming.RegisterFlags(flag.CommandLine)
+	flag.BoolVar(&toggles, "toggles", false, "Make the constructor accept runtime controls that disable or sample the logging of every method")
+	flag.BoolVar(&panics, "panics", false, "Log a panic of the wrapped implementation with its stack, and raise it again")
+
 	flag.Usage = func() {
 		var out io.Writer = os.Stdout
 
 		fmt.Fprintln(out, "A tool that generates logging wrappers for interfaces.")
-		fmt.Fprintf(out, "Usage: %s [-h] SOURCE_DIR INTERFACE_NAME\n", path.Base(os.Args[0]))
+		fmt.Fprintf(out, "Usage: %s [-h] [-check | -diff] [OPTIONS] SOURCE_DIR INTERFACE_NAME\n", path.Base(os.Args[0]))
 		fmt.Fprintln(out, "")
 		fmt.Fprintln(out, "  Arguments:")
 		fmt.Fprintln(out, "    SOURCE_DIR       Path to the file containing the interface")
@@ -32,6 +47,24 @@ func init() {
 		fmt.Fprintln(out, "")
 		fmt.Fprintln(out, "  Options:")
 		fmt.Fprintln(out, "    -h               Print this text and exit")
+		fmt.Fprintln(out, "    -check           Fail with a diff if the output file is not up to date")
+		fmt.Fprintln(out, "    -diff            Print a diff against the output file instead of writing it")
+		fmt.Fprintln(out, "    -output-dir DIR  Directory of the generated package")
+		fmt.Fprintln(out, "                     Defaults to SOURCE_DIR/<package>mws")
+		fmt.Fprintln(out, "    -o FILE          Output file, relative to the output directory, or - for stdout")
+		fmt.Fprintln(out, "    -pkg NAME        Name of the generated package")
+		fmt.Fprintln(out, "    -type NAME       Name of the generated wrapper type")
+		fmt.Fprintln(out, "    -constructor NAME")
+		fmt.Fprintln(out, "                     Name of the generated constructor")
+		fmt.Fprintln(out, "    -name-case CASE  Case of the logged method names: snake, kebab, camel or dotted")
+		fmt.Fprintln(out, "    -name-template TEMPLATE")
+		fmt.Fprintln(out, "                     Go template of the logged method names")
+		fmt.Fprintln(out, "    -key-case CASE   Case of the log field names: snake, kebab, camel or dotted")
+		fmt.Fprintln(out, "    -toggles         Make the constructor accept runtime controls that disable or sample the")
+		fmt.Fprintln(out, "                     logging of every method")
+		fmt.Fprintln(out, "    -panics          Log a panic of the wrapped implementation with its stack, and raise it again")
+		fmt.Fprintln(out, "    -prune           Remove files generated for interfaces that no longer exist")
+		fmt.Fprintln(out, "    -typecheck=false Do not type-check the generated source before writing it")
 		fmt.Fprintln(out, "")
 	}
 }
@@ -48,6 +81,12 @@ func parseArgs() (sourceDir, interfaceName string, err error) {
 		return "", "", fmt.Errorf("error determining absolute path to source directory: %v", err)
 	}
 	interfaceName = flag.Arg(1)
+	if err := outputOptions.Validate(); err != nil {
+		return "", "", err
+	}
+	if err := naming.Parse(); err != nil {
+		return "", "", err
+	}
 
 	return sourceDir, interfaceName, nil
 }
@@ -62,7 +101,10 @@ func main() {
 	if err != nil {
 		log.Fatalf("error resolving import path of source directory: %v", err)
 	}
-	targetPkg := path.Base(sourcePkgPath) + "mws"
+	target := outputOptions.Target(
+		filepath.Join(sourceDir, path.Base(sourcePkgPath)+"mws"),
+		filename(interfaceName),
+	)
 
 	locator := resolution.NewLocator()
 
@@ -71,10 +113,21 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	sourceHash, err := locator.DeclarationHash(d)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	typeName := fmt.Sprintf("errorLogging%s", interfaceName)
+	if outputOptions.TypeName != "" {
+		typeName = outputOptions.TypeName
+	}
+	constructorName := fmt.Sprintf("NewErrorLogging%s", interfaceName)
+	if outputOptions.ConstructorName != "" {
+		constructorName = outputOptions.ConstructorName
+	}
 
-	model := newModel(sourcePkgPath, interfaceName, typeName, targetPkg)
+	model := newModel(sourcePkgPath, interfaceName, typeName, constructorName, target.Package, target.ImportPath, naming, toggles, panics)
 	generator := astgen.Generator{
 		Model:    model,
 		Locator:  locator,
@@ -86,28 +139,28 @@ func main() {
 		log.Fatal(err)
 	}
 
-	targetPkgPath := filepath.Join(sourceDir, targetPkg)
-	if err := os.MkdirAll(targetPkgPath, 0777); err != nil {
-		log.Fatalf("error creating target package directory: %v", err)
+	header := output.Header{
+		Generator:  "logen",
+		Version:    output.Version,
+		Source:     sourcePkgPath + "." + interfaceName,
+		SourceHash: sourceHash,
+		Args:       output.Args(flag.CommandLine, target, sourceDir, flag.Args()[1:]...),
 	}
-
-	fd, err := os.Create(filepath.Join(targetPkgPath, filename(interfaceName)))
-	if err != nil {
-		log.Fatalf("error creating output source file: %v", err)
+	var src bytes.Buffer
+	if err := model.WriteSource(header, &src); err != nil {
+		log.Fatal(err)
 	}
-	defer fd.Close()
 
-	err = model.WriteSource(fd)
-	if err != nil {
-		log.Fatal(err)
+	if outputOptions.TypeCheck {
+		if err := typecheck.Check("logen", target.Dir, target.Name, src.Bytes()); err != nil {
+			log.Fatal(err)
+		}
 	}
 
-	wd, _ := os.Getwd()
-	path, err := filepath.Rel(wd, fd.Name())
+	err = outputOptions.Save(target, src.Bytes(), "logging", header.Source)
 	if err != nil {
-		path = fd.Name()
+		log.Fatal(err)
 	}
-	fmt.Printf("Wrote logging implementation of %q to %q\n", sourcePkgPath+"."+interfaceName, path)
 }
 
 func filename(interfaceName string) string {
@@ -133,19 +186,53 @@ type constructorBuilder struct {
 	logPackageName       string
 	interfacePackageName string
 	interfaceName        string
+	structName           string
+	constructorName      string
 	contextPackageName   string
+	// toggles are the runtime controls of the methods, if enabled.
+	toggles *astgen.Toggles
 }
 
-func newConstructorBuilder(logPackageName, packageName, interfaceName, contextPackageName string) *constructorBuilder {
+func newConstructorBuilder(logPackageName, packageName, interfaceName, structName, constructorName, contextPackageName string) *constructorBuilder {
 	return &constructorBuilder{
 		logPackageName:       logPackageName,
 		interfacePackageName: packageName,
 		interfaceName:        interfaceName,
+		structName:           structName,
+		constructorName:      constructorName,
 		contextPackageName:   contextPackageName,
 	}
 }
 
 func (c *constructorBuilder) Build() ast.Decl {
+	elts := []ast.Expr{
+		&ast.KeyValueExpr{Key: ast.NewIdent("next"), Value: ast.NewIdent("next")},
+		&ast.KeyValueExpr{Key: ast.NewIdent("logger"), Value: ast.NewIdent("logger")},
+		&ast.KeyValueExpr{Key: ast.NewIdent("fields"), Value: ast.NewIdent("f")},
+	}
+	params := []*ast.Field{
+		&ast.Field{
+			Names: []*ast.Ident{ast.NewIdent("next")},
+			Type:  astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
+		},
+		&ast.Field{
+			Names: []*ast.Ident{ast.NewIdent("logger")},
+			Type: &ast.SelectorExpr{
+				X:   ast.NewIdent(c.logPackageName),
+				Sel: ast.NewIdent("Logger"),
+			},
+		},
+	}
+	// The controls precede the fields, which are variadic.
+	if c.toggles != nil {
+		elts = append(elts, c.toggles.Init()...)
+		params = append(params, c.toggles.Param())
+	}
+	params = append(params, &ast.Field{
+		Names: []*ast.Ident{ast.NewIdent("fields")},
+		Type:  &ast.Ellipsis{Elt: fieldsFuncType(c.contextPackageName)},
+	})
+
 	funcBody := &ast.BlockStmt{
 		List: []ast.Stmt{
 			&ast.AssignStmt{
@@ -175,14 +262,19 @@ func (c *constructorBuilder) Build() ast.Decl {
 			},
 			&ast.ReturnStmt{
 				Results: []ast.Expr{
-					// TODO(borshukov): Find a better way to do this.
-					ast.NewIdent(fmt.Sprintf("&errorLogging%s{next: next, logger: logger, fields: f}", c.interfaceName)),
+					&ast.UnaryExpr{
+						Op: token.AND,
+						X: &ast.CompositeLit{
+							Type: ast.NewIdent(c.structName),
+							Elts: elts,
+						},
+					},
 				},
 			},
 		},
 	}
 
-	funcName := fmt.Sprintf("NewErrorLogging%s", c.interfaceName)
+	funcName := c.constructorName
 	return &ast.FuncDecl{
 		Doc: &ast.CommentGroup{
 			List: []*ast.Comment{&ast.Comment{
@@ -192,35 +284,13 @@ func (c *constructorBuilder) Build() ast.Decl {
 		Name: ast.NewIdent(funcName),
 		Type: &ast.FuncType{
 			Params: &ast.FieldList{
-				List: []*ast.Field{
-					&ast.Field{
-						Names: []*ast.Ident{ast.NewIdent("next")},
-						Type: &ast.SelectorExpr{
-							X:   ast.NewIdent(c.interfacePackageName),
-							Sel: ast.NewIdent(c.interfaceName),
-						},
-					},
-					&ast.Field{
-						Names: []*ast.Ident{ast.NewIdent("logger")},
-						Type: &ast.SelectorExpr{
-							X:   ast.NewIdent(c.logPackageName),
-							Sel: ast.NewIdent("Logger"),
-						},
-					},
-					&ast.Field{
-						Names: []*ast.Ident{ast.NewIdent("fields")},
-						Type:  &ast.Ellipsis{Elt: fieldsFuncType(c.contextPackageName)},
-					},
-				},
+				List: params,
 			},
 			Results: &ast.FieldList{
 				List: []*ast.Field{
 					&ast.Field{
 						Names: []*ast.Ident{ast.NewIdent("")},
-						Type: &ast.SelectorExpr{
-							X:   ast.NewIdent(c.interfacePackageName),
-							Sel: ast.NewIdent(c.interfaceName),
-						},
+						Type:  astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
 					},
 				},
 			},
@@ -229,21 +299,44 @@ func (c *constructorBuilder) Build() ast.Decl {
 	}
 }
 
+// failedError is the logged error of the calls that failed by their failure
+// predicate, without an error.
+const failedError = "failed"
+
 type LoggingMethodBuilder struct {
 	methodConfig        *astgen.MethodConfig
 	method              *astgen.Method
 	contextPackageAlias string
+
+	// operation is the logged name of the method
+	operation string
+	naming    transformation.Naming
+
+	// fmtPackageAlias and debugPackageAlias are the aliases of the packages
+	// that format a panic, if panics are logged.
+	fmtPackageAlias   string
+	debugPackageAlias string
 }
 
-func NewLoggingMethodBuilder(structName string, methodConfig *astgen.MethodConfig, contextPackageAlias string) *LoggingMethodBuilder {
+func NewLoggingMethodBuilder(structName string, methodConfig *astgen.MethodConfig, contextPackageAlias, operation string, naming transformation.Naming) *LoggingMethodBuilder {
 	method := astgen.NewMethod(methodConfig.MethodName, "m", structName)
 
 	return &LoggingMethodBuilder{
 		methodConfig:        methodConfig,
 		method:              method,
 		contextPackageAlias: contextPackageAlias,
+		operation:           operation,
+		naming:              naming,
 	}
 }
+
+// LogPanics makes the method log a panic of the call with its stack, before
+// raising it again.
+func (b *LoggingMethodBuilder) LogPanics(fmtPackageAlias, debugPackageAlias string) {
+	b.fmtPackageAlias = fmtPackageAlias
+	b.debugPackageAlias = debugPackageAlias
+}
+
 func (b *LoggingMethodBuilder) Build() ast.Decl {
 	b.method.SetType(&ast.FuncType{
 		Params: &ast.FieldList{
@@ -254,6 +347,17 @@ func (b *LoggingMethodBuilder) Build() ast.Decl {
 		},
 	})
 
+	// Log a panic of the call, and raise it again:
+	//   defer func() {
+	//     if _panic := recover(); _panic != nil {
+	//       m.logger.Log("method", "DoWork", "panic", fmt.Sprint(_panic), "stack", string(debug.Stack()))
+	//       panic(_panic)
+	//     }
+	//   }()
+	if b.fmtPackageAlias != "" {
+		b.method.AddStatement(astgen.Recover(b.panicLogStatement(b.operation)))
+	}
+
 	// Add method invocation:
 	//   result1, result2 := m.next.Method(arg1, arg2)
 	methodInvocation := NewMethodInvocation(b.methodConfig)
@@ -263,14 +367,11 @@ func (b *LoggingMethodBuilder) Build() ast.Decl {
 	})
 	b.method.AddStatement(methodInvocation.Build())
 
-	// Log if an error has occurred.
-	n := len(b.methodConfig.MethodResults)
-	if n > 0 {
-		last := b.methodConfig.MethodResults[n-1]
-		if id, ok := last.Type.(*ast.Ident); ok && id.Name == "error" {
-			s := b.conditionalLogMessageStatement(b.methodConfig.MethodName, last.Names[0].Name)
-			b.method.AddStatement(s)
-		}
+	// Log if an error has occurred or the failure predicate holds.
+	errorResult := b.methodConfig.ErrorResult()
+	if errorResult != nil || b.methodConfig.FailurePredicate != nil {
+		s := b.conditionalLogMessageStatement(b.operation, errorResult)
+		b.method.AddStatement(s)
 	}
 
 	// Add return statement
@@ -298,18 +399,69 @@ func (b *LoggingMethodBuilder) contextArgName() (string, bool) {
 	return "", false
 }
 
-func (b *LoggingMethodBuilder) conditionalLogMessageStatement(methodName, errorResultName string) ast.Stmt {
+// conditionalLogMessageStatement builds the statement that logs a failed
+// call. If the method declares a failure predicate, calls for which it holds
+// are logged with the "failed" error, unless they return an error. The
+// errorResult is nil if the method returns no error.
+func (b *LoggingMethodBuilder) conditionalLogMessageStatement(methodName string, errorResult *astgen.ErrorResult) ast.Stmt {
+	var err, errText ast.Expr = ast.NewIdent("nil"), &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", failedError)}
+	var cond ast.Expr
+	var errTextStmt ast.Stmt = &ast.EmptyStmt{}
+	var asErrorStmts []ast.Stmt
+	if errorResult != nil {
+		err = ast.NewIdent(errorResult.Name)
+		cond = &ast.BinaryExpr{X: err, Op: token.NEQ, Y: ast.NewIdent("nil")}
+		errorText := &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   err,
+				Sel: ast.NewIdent("Error"),
+			},
+		}
+		if b.methodConfig.FailurePredicate == nil {
+			errText = errorText
+		} else {
+			// if err != nil {
+			//   _fields[3] = err.Error()
+			// }
+			errTextStmt = &ast.IfStmt{
+				Cond: cond,
+				Body: &ast.BlockStmt{
+					List: []ast.Stmt{
+						&ast.AssignStmt{
+							Lhs: []ast.Expr{&ast.IndexExpr{X: ast.NewIdent("_fields"), Index: &ast.BasicLit{Kind: token.INT, Value: "3"}}},
+							Tok: token.ASSIGN,
+							Rhs: []ast.Expr{errorText},
+						},
+					},
+				},
+			}
+		}
+	}
+	if predicate := b.methodConfig.FailurePredicate; predicate != nil {
+		if cond == nil {
+			cond = predicate
+		} else {
+			cond = &ast.BinaryExpr{X: cond, Op: token.LOR, Y: predicate}
+		}
+	}
+
 	// If the first parameter is context.Context, get additional log
 	// fields.
 	var additionalFieldsStmt ast.Stmt = &ast.EmptyStmt{}
 	var appendAdditionalFieldsStmt ast.Stmt = &ast.EmptyStmt{}
 	if ctxArgName, ok := b.contextArgName(); ok {
+		// The error may be nil if the failure predicate held, so an error
+		// of a concrete type is converted to error first, rather than
+		// passed as a typed nil.
+		if errorResult != nil && b.methodConfig.FailurePredicate != nil {
+			asErrorStmts, err = errorResult.AsError("_err")
+		}
 		callExpr := &ast.CallExpr{
 			Fun: &ast.SelectorExpr{
 				X:   ast.NewIdent("m"), // receiver name
 				Sel: ast.NewIdent("fields"),
 			},
-			Args: []ast.Expr{ast.NewIdent(ctxArgName), ast.NewIdent(errorResultName)},
+			Args: []ast.Expr{ast.NewIdent(ctxArgName), err},
 		}
 
 		additionalFieldsStmt = &ast.AssignStmt{
@@ -358,15 +510,10 @@ func (b *LoggingMethodBuilder) conditionalLogMessageStatement(methodName, errorR
 					Elt: ast.NewIdent("interface{}"),
 				},
 				Elts: []ast.Expr{
-					&ast.BasicLit{Kind: token.STRING, Value: `"method"`},
+					&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", b.naming.Key("method"))},
 					&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", methodName)},
-					&ast.BasicLit{Kind: token.STRING, Value: `"error"`},
-					&ast.CallExpr{
-						Fun: &ast.SelectorExpr{
-							X:   ast.NewIdent(errorResultName),
-							Sel: ast.NewIdent("Error"),
-						},
-					},
+					&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", b.naming.Key("error"))},
+					errText,
 				},
 			},
 		},
@@ -382,18 +529,41 @@ func (b *LoggingMethodBuilder) conditionalLogMessageStatement(methodName, errorR
 		},
 	}
 
+	body := []ast.Stmt{assignStmt, errTextStmt}
+	body = append(body, asErrorStmts...)
+	body = append(body, additionalFieldsStmt, appendAdditionalFieldsStmt, &ast.ExprStmt{X: callLogExpr})
 	return &ast.IfStmt{
-		Cond: &ast.BinaryExpr{
-			X:  ast.NewIdent(errorResultName),
-			Op: token.NEQ,
-			Y:  ast.NewIdent("nil"),
-		},
-		Body: &ast.BlockStmt{
-			List: []ast.Stmt{
-				assignStmt,
-				additionalFieldsStmt,
-				appendAdditionalFieldsStmt,
-				&ast.ExprStmt{X: callLogExpr}},
+		Cond: cond,
+		Body: &ast.BlockStmt{List: body},
+	}
+}
+
+// panicLogStatement builds the statement that logs the value and the stack of
+// a recovered panic.
+func (b *LoggingMethodBuilder) panicLogStatement(methodName string) ast.Stmt {
+	key := func(name string) ast.Expr {
+		return &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", b.naming.Key(name))}
+	}
+	return &ast.ExprStmt{
+		X: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   &ast.SelectorExpr{X: ast.NewIdent("m"), Sel: ast.NewIdent("logger")},
+				Sel: ast.NewIdent("Log"),
+			},
+			Args: []ast.Expr{
+				key("method"),
+				&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", methodName)},
+				key("panic"),
+				&ast.CallExpr{
+					Fun:  astgen.QualifiedName(b.fmtPackageAlias, "Sprint"),
+					Args: []ast.Expr{ast.NewIdent(astgen.PanicVarName)},
+				},
+				key("stack"),
+				&ast.CallExpr{
+					Fun:  ast.NewIdent("string"),
+					Args: []ast.Expr{&ast.CallExpr{Fun: astgen.QualifiedName(b.debugPackageAlias, "Stack")}},
+				},
+			},
 		},
 	}
 }
@@ -8,42 +8,58 @@ import (
 	"io"
 
 	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/output"
+	"github.com/Bo0mer/gentools/pkg/transformation"
 )
 
 type model struct {
-	fileBuilder *astgen.File
-	structName  string
-	strct       *astgen.Struct
+	fileBuilder   *astgen.File
+	structName    string
+	strct         *astgen.Struct
+	interfacePath string
+	interfaceName string
+	naming        transformation.Naming
+	toggles       *astgen.Toggles
+	panics        bool
 
 	contextPackageAlias string
 }
 
-func newModel(interfacePath, interfaceName, structName, targetPkg string) *model {
-	file := astgen.NewFile(targetPkg)
+func newModel(interfacePath, interfaceName, structName, constructorName, targetPkg, targetPath string, naming transformation.Naming, toggles, panics bool) *model {
+	file := astgen.NewFile(targetPkg, targetPath)
 	strct := astgen.NewStruct(structName)
 	file.AppendDeclaration(strct)
 
 	m := &model{
-		fileBuilder: file,
-		structName:  structName,
-		strct:       strct,
+		fileBuilder:   file,
+		structName:    structName,
+		strct:         strct,
+		interfacePath: interfacePath,
+		interfaceName: interfaceName,
+		naming:        naming,
+		panics:        panics,
 	}
 	sourcePackageAlias := m.AddImport("", interfacePath)
 	logPackageAlias := m.AddImport("", "github.com/go-kit/kit/log")
 	m.contextPackageAlias = m.AddImport("", "context")
 
-	constructorBuilder := newConstructorBuilder(logPackageAlias, sourcePackageAlias, interfaceName, m.contextPackageAlias)
+	constructorBuilder := newConstructorBuilder(logPackageAlias, sourcePackageAlias, interfaceName, structName, constructorName, m.contextPackageAlias)
 	file.AppendDeclaration(constructorBuilder)
 
 	strct.AddField("next", sourcePackageAlias, interfaceName)
 	strct.AddField("logger", logPackageAlias, "Logger")
 	strct.AddFieldWithType("fields", fieldsFuncType(m.contextPackageAlias))
 
+	if toggles {
+		m.toggles = astgen.NewToggles(m.AddImport("", astgen.TogglePackage), interfaceName)
+		constructorBuilder.toggles = m.toggles
+	}
+
 	return m
 }
 
-func (m *model) WriteSource(w io.Writer) error {
-	fmt.Fprintf(w, "// Code generated by logen. DO NOT EDIT.\n")
+func (m *model) WriteSource(header output.Header, w io.Writer) error {
+	fmt.Fprint(w, header.String())
 	astFile := m.fileBuilder.Build()
 
 	if err := format.Node(w, token.NewFileSet(), astFile); err != nil {
@@ -57,9 +73,25 @@ func (m *model) AddImport(pkgName, location string) string {
 }
 
 func (m *model) AddMethod(method *astgen.MethodConfig) error {
-	mmb := NewLoggingMethodBuilder(m.structName, method, m.contextPackageAlias)
+	operation, err := m.naming.Operation(transformation.Operation{
+		Package:   m.interfacePath,
+		Interface: m.interfaceName,
+		Method:    method.MethodName,
+	})
+	if err != nil {
+		return err
+	}
+	mmb := NewLoggingMethodBuilder(m.structName, method, m.contextPackageAlias, operation, m.naming)
+	if m.panics {
+		mmb.LogPanics(m.AddImport("", "fmt"), m.AddImport("", "runtime/debug"))
+	}
 
-	m.fileBuilder.AppendDeclaration(mmb)
+	var decl astgen.DeclarationBuilder = mmb
+	if m.toggles != nil {
+		m.toggles.AddMethod(m.strct, method.MethodName)
+		decl = m.toggles.Guard(method, mmb)
+	}
+	m.fileBuilder.AppendDeclaration(decl)
 	return nil
 }
 
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+
+	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/directive"
+)
+
+// capabilities are the options of the model config that a provider supports.
+type capabilities struct {
+	classifyErrors bool
+	inFlightOps    bool
+	recordOutcome  bool
+	labels         bool
+	buckets        bool
+	streams        bool
+	metrics        bool
+	labelsFunc     bool
+	// outcome reports whether the outcome label is recorded on the failed
+	// operations and the durations even without -outcome.
+	outcome bool
+	// bounds reports whether the generated code creates its histograms with
+	// the default bucket bounds, or those of the bucket groups. Otherwise
+	// the bounds are known only if -metrics generates the histograms.
+	bounds bool
+	// durationName is the name of the metric of the operation durations.
+	durationName string
+	// durationUnit is the unit of the operation durations.
+	durationUnit string
+}
+
+var providerCapabilities = map[string]capabilities{
+	goKitProvider:      {classifyErrors: true, inFlightOps: true, recordOutcome: true, labels: true, buckets: true, streams: true, metrics: true, labelsFunc: true},
+	opencensusProvider: {classifyErrors: true, inFlightOps: true, recordOutcome: true, labels: true, buckets: true, streams: true, metrics: true},
+	prometheusProvider: {buckets: true, bounds: true},
+	otelProvider:       {outcome: true, durationName: "ops_duration"},
+	expvarProvider:     {bounds: true},
+	statsdProvider:     {classifyErrors: true, labels: true, durationName: "ops_duration", durationUnit: "milliseconds"},
+}
+
+// checkFlags returns an error if a flag is set that the provider does not
+// support, rather than ignore it.
+func (c capabilities) checkFlags(provider string) error {
+	for _, f := range []struct {
+		name      string
+		set       bool
+		supported bool
+	}{
+		{"classify-errors", classifyErrors, c.classifyErrors},
+		{"in-flight", inFlightOps, c.inFlightOps},
+		{"outcome", recordOutcome, c.recordOutcome},
+		{"metrics", withMetrics, c.metrics},
+		{"labels-func", labelsFunc, c.labelsFunc},
+		{"buckets", buckets != "", c.buckets},
+		{"method-buckets", methodBuckets != "", c.buckets},
+		{"streams", streams, c.streams},
+	} {
+		if f.set && !f.supported {
+			return fmt.Errorf("the %s provider does not support -%s", provider, f.name)
+		}
+	}
+	return nil
+}
+
+// checkDirectives returns an error if the doc comment has a directive that
+// the provider does not support, rather than ignore it.
+func (c capabilities) checkDirectives(provider string, doc *ast.CommentGroup) error {
+	for _, d := range directive.Parse("mongen", doc) {
+		supported := true
+		switch d.Name {
+		case "label":
+			supported = c.labels
+		case "buckets":
+			supported = c.buckets
+		}
+		if !supported {
+			return fmt.Errorf("the %s provider does not support //mongen:%s directives", provider, d.Name)
+		}
+	}
+	return nil
+}
+
+// checkedModel rejects the directives of the methods that the provider of the
+// model does not support, before they are added to the model.
+type checkedModel struct {
+	model
+	provider string
+}
+
+func (m checkedModel) AddMethod(method *astgen.MethodConfig) error {
+	if err := providerCapabilities[m.provider].checkDirectives(m.provider, method.Doc); err != nil {
+		return fmt.Errorf("method %s: %v", method.MethodName, err)
+	}
+	return m.model.AddMethod(method)
+}
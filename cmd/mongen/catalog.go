@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
+	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/catalog"
+	"github.com/Bo0mer/gentools/pkg/output"
+	"github.com/Bo0mer/gentools/pkg/transformation"
+)
+
+// catalog formats
+const (
+	catalogJSON    = "json"
+	catalogGrafana = "grafana"
+	catalogRules   = "rules"
+)
+
+// catalogModel collects the metrics, labels and operations recorded by the
+// monitoring implementation of an interface, without generating it.
+type catalogModel struct {
+	cfg      commonbuilders.ModelConfig
+	provider string
+	prefix   string
+	caps     capabilities
+	file     *astgen.File
+	labels   *commonbuilders.Labels
+	buckets  *commonbuilders.BucketGroups
+
+	operations []catalog.Operation
+}
+
+func newCatalogModel(provider, prefix string, cfg commonbuilders.ModelConfig) *catalogModel {
+	caps := providerCapabilities[provider]
+	if caps.durationName == "" {
+		caps.durationName = commonbuilders.OpsDurationMetric.Name
+	}
+	if caps.durationUnit == "" {
+		caps.durationUnit = "seconds"
+	}
+	cfg.ClassifyErrors = cfg.ClassifyErrors && caps.classifyErrors
+	cfg.InFlightOps = cfg.InFlightOps && caps.inFlightOps
+	cfg.RecordOutcome = cfg.RecordOutcome && caps.recordOutcome
+	cfg.Streams = cfg.Streams && caps.streams
+	cfg.Metrics = cfg.Metrics && caps.metrics
+	caps.bounds = caps.bounds || cfg.Metrics
+
+	m := &catalogModel{
+		cfg:      cfg,
+		provider: provider,
+		prefix:   prefix,
+		caps:     caps,
+		file:     astgen.NewFile(cfg.TargetPkg, cfg.TargetPath),
+	}
+	m.labels = commonbuilders.NewLabels(m, cfg)
+	m.buckets = commonbuilders.NewBucketGroups(cfg)
+	return m
+}
+
+func (m *catalogModel) AddImport(pkgName, location string) string {
+	return m.file.AddImport(pkgName, location)
+}
+
+func (m *catalogModel) AddMethod(method *astgen.MethodConfig) error {
+	if err := m.caps.checkDirectives(m.provider, method.Doc); err != nil {
+		return fmt.Errorf("method %s: %v", method.MethodName, err)
+	}
+	operation, err := m.cfg.OperationName(method.MethodName)
+	if err != nil {
+		return err
+	}
+	op := catalog.Operation{
+		Method:   method.MethodName,
+		Name:     operation,
+		Duration: m.metricName(m.caps.durationName),
+	}
+	if m.caps.labels {
+		if err := m.labels.AddMethod(method); err != nil {
+			return err
+		}
+		op.Labels = m.labels.MethodNames(method.MethodName)
+	}
+	if m.caps.buckets {
+		group, _, err := m.buckets.AddMethod(method)
+		if err != nil {
+			return err
+		}
+		if group != "" {
+			op.Duration = m.metricName(commonbuilders.DurationMetric(group).Name)
+		}
+	}
+	if m.cfg.Streams {
+		_, op.Stream = commonbuilders.FindStreamResult(method, m.file.ImportAlias)
+	}
+	if m.cfg.ResultSize {
+		_, op.ResultSize, err = commonbuilders.FindSizeResult(method)
+		if err != nil {
+			return err
+		}
+	}
+	m.operations = append(m.operations, op)
+	return nil
+}
+
+// Catalog returns the catalog of the methods added so far.
+func (m *catalogModel) Catalog(source string) (*catalog.Catalog, error) {
+	keys := m.cfg.LabelKeys()
+	c := &catalog.Catalog{
+		Generator: "mongen " + output.Version,
+		Source:    source,
+		Provider:  m.provider,
+		Labels: catalog.Labels{
+			Operation: keys.Operation,
+			Extra:     m.labels.Names(),
+		},
+		Operations: m.operations,
+	}
+	if m.cfg.ClassifyErrors {
+		c.Labels.ErrorClass = keys.ErrorClass
+	}
+	recordOutcome := m.cfg.RecordOutcome || m.caps.outcome
+	if recordOutcome {
+		c.Labels.Outcome = keys.Outcome
+	}
+
+	duration := commonbuilders.OpsDurationMetric
+	duration.Name = m.caps.durationName
+	metrics := []catalogMetric{
+		{catalog.TotalOps, catalog.Counter, "", commonbuilders.TotalOpsMetric, nil, ""},
+		{catalog.FailedOps, catalog.Counter, "", commonbuilders.FailedOpsMetric, nil, ""},
+		{catalog.OpsDuration, catalog.Histogram, m.caps.durationUnit, duration, commonbuilders.DurationBuckets, ""},
+	}
+	for _, group := range m.buckets.Groups() {
+		metrics = append(metrics, catalogMetric{catalog.OpsDuration, catalog.Histogram, m.caps.durationUnit, commonbuilders.DurationMetric(group), m.buckets.Bounds(group), group})
+	}
+	if m.cfg.InFlightOps {
+		metrics = append(metrics, catalogMetric{catalog.InFlightOps, catalog.Gauge, "", commonbuilders.InFlightOpsMetric, nil, ""})
+	}
+	if m.cfg.Streams {
+		metrics = append(metrics, catalogMetric{catalog.StreamSize, catalog.Histogram, "", commonbuilders.StreamSizeMetric, commonbuilders.SizeBuckets, ""})
+	}
+	if m.cfg.ResultSize {
+		metrics = append(metrics, catalogMetric{catalog.ResultSize, catalog.Histogram, "", commonbuilders.ResultSizeMetric, commonbuilders.SizeBuckets, ""})
+	}
+
+	for _, metric := range metrics {
+		// The bounds of histograms the generated code does not create are
+		// chosen by the user, and left out.
+		var buckets []float64
+		if m.caps.bounds {
+			var err error
+			if buckets, err = parseBounds(metric.bounds); err != nil {
+				return nil, fmt.Errorf("metric %s: %v", metric.Name, err)
+			}
+		}
+		labels := metric.LabelNames(m.labels, m.cfg.ClassifyErrors, recordOutcome)
+		if metric.role == catalog.FailedOps && m.caps.outcome {
+			labels = append(labels, keys.Outcome)
+		}
+		c.Metrics = append(c.Metrics, catalog.Metric{
+			Name:    m.metricName(metric.Name),
+			Role:    metric.role,
+			Type:    metric.typ,
+			Help:    metric.Help,
+			Unit:    metric.unit,
+			Labels:  labels,
+			Buckets: buckets,
+			Group:   metric.group,
+		})
+	}
+	return c, nil
+}
+
+// catalogMetric is a metric recorded by the implementation, with its role,
+// type, unit, bucket bounds and bucket group.
+type catalogMetric struct {
+	role, typ, unit string
+	commonbuilders.Metric
+	bounds []string
+	group  string
+}
+
+// metricName returns the name of the metric with the prefix.
+func (m *catalogModel) metricName(name string) string {
+	if m.prefix == "" {
+		return name
+	}
+	return m.prefix + "_" + name
+}
+
+func parseBounds(bounds []string) ([]float64, error) {
+	var values []float64
+	for _, bound := range bounds {
+		v, err := strconv.ParseFloat(bound, 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// renderCatalog renders the catalog in the format.
+func renderCatalog(c *catalog.Catalog, format string, opts catalog.RuleOptions) ([]byte, error) {
+	switch format {
+	case catalogJSON:
+		return c.JSON()
+	case catalogGrafana:
+		return c.Dashboard()
+	case catalogRules:
+		return c.Rules(opts), nil
+	}
+	return nil, fmt.Errorf("unknown catalog format: %s", format)
+}
+
+// catalogFilename returns the default name of the file of the catalog of the
+// interface in the format.
+func catalogFilename(interfaceName, format string) string {
+	base := fmt.Sprintf("monitoring_%s", transformation.ToSnakeCase(interfaceName))
+	switch format {
+	case catalogGrafana:
+		return base + ".dashboard.json"
+	case catalogRules:
+		return base + ".rules.yaml"
+	}
+	return base + ".catalog.json"
+}
+
+// latencyFlag is the value of the -alert-latency flag: the latency threshold
+// of the operations with the default buckets, optionally followed by those of
+// bucket groups, e.g. "0.5,batch=600". The threshold of the default buckets
+// is kept unless it is given.
+type latencyFlag struct {
+	opts *catalog.RuleOptions
+}
+
+func (f latencyFlag) String() string {
+	if f.opts == nil {
+		return ""
+	}
+	thresholds := []string{strconv.FormatFloat(f.opts.Latency, 'g', -1, 64)}
+	var groups []string
+	for group := range f.opts.GroupLatency {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		thresholds = append(thresholds, group+"="+strconv.FormatFloat(f.opts.GroupLatency[group], 'g', -1, 64))
+	}
+	return strings.Join(thresholds, ",")
+}
+
+func (f latencyFlag) Set(s string) error {
+	f.opts.GroupLatency = nil
+	for _, threshold := range strings.Split(s, ",") {
+		group, value := "", threshold
+		if i := strings.Index(threshold, "="); i >= 0 {
+			group, value = threshold[:i], threshold[i+1:]
+			if group == "" {
+				return fmt.Errorf("missing bucket group in latency threshold %q", threshold)
+			}
+		}
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || seconds < 0 {
+			return fmt.Errorf("invalid latency threshold %q", threshold)
+		}
+		if group == "" {
+			f.opts.Latency = seconds
+			continue
+		}
+		if f.opts.GroupLatency == nil {
+			f.opts.GroupLatency = make(map[string]float64)
+		}
+		f.opts.GroupLatency[group] = seconds
+	}
+	return nil
+}
+
+// checkGroupLatency returns an error if a latency threshold is given for a
+// bucket group that no operation of the catalog records its duration in.
+func checkGroupLatency(c *catalog.Catalog, opts catalog.RuleOptions) error {
+	groups := make(map[string]bool)
+	for _, m := range c.Metrics {
+		groups[m.Group] = true
+	}
+	for group := range opts.GroupLatency {
+		if !groups[group] {
+			return fmt.Errorf("no operation records its duration in bucket group %q of -alert-latency", group)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
+	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/catalog"
+	"github.com/Bo0mer/gentools/pkg/transformation"
+)
+
+func TestCatalogDescribesWhatEveryProviderRecords(t *testing.T) {
+	defaultBounds := []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	for _, tc := range []struct {
+		provider string
+		metrics  bool
+		// outcome is the key of the outcome label, if it is recorded
+		outcome        string
+		failedLabels   []string
+		durationLabels []string
+		durationName   string
+		durationUnit   string
+		buckets        []float64
+	}{
+		{
+			provider:       goKitProvider,
+			failedLabels:   []string{"operation"},
+			durationLabels: []string{"operation"},
+			durationName:   "ops_duration_seconds",
+			durationUnit:   "seconds",
+		},
+		{
+			provider:       goKitProvider,
+			metrics:        true,
+			failedLabels:   []string{"operation"},
+			durationLabels: []string{"operation"},
+			durationName:   "ops_duration_seconds",
+			durationUnit:   "seconds",
+			buckets:        defaultBounds,
+		},
+		{
+			provider:       opencensusProvider,
+			failedLabels:   []string{"operation"},
+			durationLabels: []string{"operation"},
+			durationName:   "ops_duration_seconds",
+			durationUnit:   "seconds",
+		},
+		{
+			provider:       opencensusProvider,
+			metrics:        true,
+			failedLabels:   []string{"operation"},
+			durationLabels: []string{"operation"},
+			durationName:   "ops_duration_seconds",
+			durationUnit:   "seconds",
+			buckets:        defaultBounds,
+		},
+		{
+			provider:       prometheusProvider,
+			failedLabels:   []string{"operation"},
+			durationLabels: []string{"operation"},
+			durationName:   "ops_duration_seconds",
+			durationUnit:   "seconds",
+			buckets:        defaultBounds,
+		},
+		{
+			provider:       otelProvider,
+			outcome:        "outcome",
+			failedLabels:   []string{"operation", "outcome"},
+			durationLabels: []string{"operation", "outcome"},
+			durationName:   "ops_duration",
+			durationUnit:   "seconds",
+		},
+		{
+			provider:       expvarProvider,
+			failedLabels:   []string{"operation"},
+			durationLabels: []string{"operation"},
+			durationName:   "ops_duration_seconds",
+			durationUnit:   "seconds",
+			buckets:        defaultBounds,
+		},
+		{
+			provider:       statsdProvider,
+			failedLabels:   []string{"operation"},
+			durationLabels: []string{"operation"},
+			durationName:   "ops_duration",
+			durationUnit:   "milliseconds",
+		},
+	} {
+		name := tc.provider
+		if tc.metrics {
+			name += " with -metrics"
+		}
+		t.Run(name, func(t *testing.T) {
+			model := newCatalogModel(tc.provider, "", commonbuilders.ModelConfig{
+				InterfacePath: "example.com/service",
+				InterfaceName: "Service",
+				TargetPkg:     "servicemws",
+				Metrics:       tc.metrics,
+				Naming:        transformation.Naming{Case: transformation.SnakeCase},
+			})
+			if err := model.AddMethod(&astgen.MethodConfig{MethodName: "DoWork"}); err != nil {
+				t.Fatal(err)
+			}
+			c, err := model.Catalog("example.com/service.Service")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.Labels.Outcome != tc.outcome {
+				t.Errorf("got outcome label %q, want %q", c.Labels.Outcome, tc.outcome)
+			}
+			failed, _ := c.Metric(catalog.FailedOps)
+			if !reflect.DeepEqual(failed.Labels, tc.failedLabels) {
+				t.Errorf("got failed ops labels %q, want %q", failed.Labels, tc.failedLabels)
+			}
+			duration, _ := c.Metric(catalog.OpsDuration)
+			if duration.Name != tc.durationName || duration.Unit != tc.durationUnit {
+				t.Errorf("got duration %s in %s, want %s in %s", duration.Name, duration.Unit, tc.durationName, tc.durationUnit)
+			}
+			if !reflect.DeepEqual(duration.Labels, tc.durationLabels) {
+				t.Errorf("got duration labels %q, want %q", duration.Labels, tc.durationLabels)
+			}
+			if !reflect.DeepEqual(duration.Buckets, tc.buckets) {
+				t.Errorf("got duration buckets %v, want %v", duration.Buckets, tc.buckets)
+			}
+		})
+	}
+}
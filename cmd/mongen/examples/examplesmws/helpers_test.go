@@ -0,0 +1,54 @@
+package examplesmws_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// service implements the interfaces of the examples that share its methods,
+// doing nothing.
+type service struct{}
+
+func (service) DoWork(int, string) (string, error) { return "", nil }
+
+func (service) DoWorkCtx(context.Context, int, string) (string, error) { return "", nil }
+
+func (service) Handle(context.Context, examples.Request) error { return nil }
+
+func (service) Ping(context.Context) error { return nil }
+
+func (service) Version() string { return "v1" }
+
+func (service) Close() {}
+
+// counterValue returns the value of the counter with the specified name and
+// labels gathered from the gatherer.
+func counterValue(t *testing.T, gatherer prometheus.Gatherer, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := gatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			if len(metric.GetLabel()) != len(labels) {
+				continue
+			}
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metrics
+				}
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	t.Fatalf("no %s counter with labels %v", name, labels)
+	return 0
+}
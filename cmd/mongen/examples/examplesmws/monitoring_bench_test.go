@@ -0,0 +1,123 @@
+package examplesmws_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples"
+	"github.com/Bo0mer/gentools/cmd/mongen/examples/examplesmws"
+	"github.com/go-kit/kit/metrics"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opencensus.io/stats"
+)
+
+type inFlightService struct{}
+
+func (inFlightService) DoWork(context.Context, int) (string, error) { return "", nil }
+
+func (inFlightService) Notify(string) {}
+
+func BenchmarkOCService(b *testing.B) {
+	svc := examplesmws.NewMonitoringOCService(service{},
+		stats.Int64("bench/total_ops", "", stats.UnitDimensionless),
+		stats.Int64("bench/failed_ops", "", stats.UnitDimensionless),
+		stats.Float64("bench/ops_duration_seconds", "", stats.UnitSeconds),
+		nil)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		svc.DoWorkCtx(ctx, i, "")
+	}
+}
+
+func BenchmarkLabeledServiceOC(b *testing.B) {
+	svc := examplesmws.NewMonitoringLabeledServiceOC(service{},
+		stats.Int64("bench/labeled/total_ops", "", stats.UnitDimensionless),
+		stats.Int64("bench/labeled/failed_ops", "", stats.UnitDimensionless),
+		stats.Float64("bench/labeled/ops_duration_seconds", "", stats.UnitSeconds),
+		nil, 10)
+	ctx := context.Background()
+	req := examples.Request{Tenant: "tenant"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		svc.Handle(ctx, req)
+	}
+}
+
+// newGoKitMetrics creates the metrics in a private registry, as the benchmarks
+// run more than once in a process and the default registry rejects metrics
+// registered again.
+func newGoKitMetrics() (totalOps, failedOps metrics.Counter, opsDuration metrics.Histogram) {
+	labels := []string{"operation"}
+	totalOpsVec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "total_ops"}, labels)
+	failedOpsVec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failed_ops"}, labels)
+	opsDurationVec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "ops_duration_seconds"}, labels)
+	prometheus.NewRegistry().MustRegister(totalOpsVec, failedOpsVec, opsDurationVec)
+	return kitprometheus.NewCounter(totalOpsVec), kitprometheus.NewCounter(failedOpsVec), kitprometheus.NewHistogram(opsDurationVec)
+}
+
+// unboundGoKitService records its metrics the way the go-kit wrappers did
+// before the metrics were bound to the operation label by the constructor.
+type unboundGoKitService struct {
+	next        examples.GoKitService
+	totalOps    metrics.Counter
+	failedOps   metrics.Counter
+	opsDuration metrics.Histogram
+}
+
+func (m *unboundGoKitService) DoWorkCtx(arg1 context.Context, arg2 int, arg3 string) (string, error) {
+	m.totalOps.With("operation", "do_work_ctx").Add(1)
+	_start := time.Now()
+	result1, result2 := m.next.DoWorkCtx(arg1, arg2, arg3)
+	m.opsDuration.With("operation", "do_work_ctx").Observe(time.Since(_start).Seconds())
+	if result2 != nil {
+		m.failedOps.With("operation", "do_work_ctx").Add(1)
+	}
+	return result1, result2
+}
+
+func BenchmarkGoKitService(b *testing.B) {
+	totalOps, failedOps, opsDuration := newGoKitMetrics()
+	svc := examplesmws.NewMonitoringGoKitService(service{}, totalOps, failedOps, opsDuration)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		svc.DoWorkCtx(ctx, i, "")
+	}
+}
+
+func BenchmarkUnboundGoKitService(b *testing.B) {
+	totalOps, failedOps, opsDuration := newGoKitMetrics()
+	svc := &unboundGoKitService{next: service{}, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		svc.DoWorkCtx(ctx, i, "")
+	}
+}
+
+func BenchmarkGoKitOutcomeService(b *testing.B) {
+	labels := []string{"operation"}
+	totalOpsVec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "total_ops"}, labels)
+	failedOpsVec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failed_ops"}, labels)
+	opsDurationVec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "ops_duration_seconds"}, []string{"operation", "outcome"})
+	inFlightOpsVec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "in_flight_ops"}, labels)
+	prometheus.NewRegistry().MustRegister(totalOpsVec, failedOpsVec, opsDurationVec, inFlightOpsVec)
+	svc := examplesmws.NewMonitoringInFlightService(inFlightService{},
+		kitprometheus.NewCounter(totalOpsVec),
+		kitprometheus.NewCounter(failedOpsVec),
+		kitprometheus.NewHistogram(opsDurationVec),
+		kitprometheus.NewGauge(inFlightOpsVec))
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		svc.DoWork(ctx, i)
+	}
+}
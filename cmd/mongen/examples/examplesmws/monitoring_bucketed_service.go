@@ -0,0 +1,118 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.BucketedService
+// Source-Hash: sha256:8cc4a30a860c5b1abceb2e663f5e174ac96a42a1d57210b04183449523b450e5
+// Generator: mongen v2.1.0
+// Args: -method-buckets=Rebuild=batch -metrics=true -outcome=true -output-dir . -o monitoring_bucketed_service.go .. BucketedService go-kit
+package examplesmws
+
+import (
+	alias6 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/go-kit/kit/metrics"
+	alias4 "github.com/go-kit/kit/metrics/prometheus"
+	alias5 "github.com/prometheus/client_golang/prometheus"
+	alias3 "time"
+)
+
+type monitoringBucketedService struct {
+	next             alias1.BucketedService
+	getOperation     monitoringBucketedServiceOperation
+	putOperation     monitoringBucketedServiceOperation
+	rebuildOperation monitoringBucketedServiceOperation
+	pingOperation    monitoringBucketedServiceOperation
+}
+type monitoringBucketedServiceOperation struct {
+	totalOps           alias2.Counter
+	failedOps          alias2.Counter
+	opsDurationSuccess alias2.Histogram
+	opsDurationError   alias2.Histogram
+}
+
+// NewMonitoringBucketedService creates new monitoring middleware.
+func NewMonitoringBucketedService(next alias1.BucketedService, totalOps alias2.Counter, failedOps alias2.Counter, opsDuration alias2.Histogram, opsDurationCache alias2.Histogram, opsDurationBatch alias2.Histogram) alias1.BucketedService {
+	m := &monitoringBucketedService{next: next}
+	bind := func(operation string, duration alias2.Histogram) monitoringBucketedServiceOperation {
+		return monitoringBucketedServiceOperation{totalOps: totalOps.With("operation", operation), failedOps: failedOps.With("operation", operation), opsDurationSuccess: duration.With("operation", operation, "outcome", "success"), opsDurationError: duration.With("operation", operation, "outcome", "error")}
+	}
+	m.getOperation = bind("get", opsDurationCache)
+	m.putOperation = bind("put", opsDurationCache)
+	m.rebuildOperation = bind("rebuild", opsDurationBatch)
+	m.pingOperation = bind("ping", opsDuration)
+	return m
+}
+
+// MonitoringBucketedServiceMetrics holds the metrics recorded by the monitoring middleware.
+type MonitoringBucketedServiceMetrics struct {
+	TotalOps         alias2.Counter
+	FailedOps        alias2.Counter
+	OpsDuration      alias2.Histogram
+	OpsDurationCache alias2.Histogram
+	OpsDurationBatch alias2.Histogram
+}
+
+// NewMonitoringBucketedServiceMetrics creates Prometheus metrics with the specified namespace and registers
+// them with the default registerer.
+func NewMonitoringBucketedServiceMetrics(namespace string) *MonitoringBucketedServiceMetrics {
+	return &MonitoringBucketedServiceMetrics{TotalOps: alias4.NewCounterFrom(alias5.CounterOpts{Namespace: namespace, Name: "total_ops", Help: "Total number of operations."}, []string{"operation"}), FailedOps: alias4.NewCounterFrom(alias5.CounterOpts{Namespace: namespace, Name: "failed_ops", Help: "Number of failed operations."}, []string{"operation"}), OpsDuration: alias4.NewHistogramFrom(alias5.HistogramOpts{Namespace: namespace, Name: "ops_duration_seconds", Help: "Duration of operations in seconds.", Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}}, []string{"operation", "outcome"}), OpsDurationCache: alias4.NewHistogramFrom(alias5.HistogramOpts{Namespace: namespace, Name: "cache_ops_duration_seconds", Help: "Duration of cache operations in seconds.", Buckets: []float64{.00001, .0001, .001, .01}}, []string{"operation", "outcome"}), OpsDurationBatch: alias4.NewHistogramFrom(alias5.HistogramOpts{Namespace: namespace, Name: "batch_ops_duration_seconds", Help: "Duration of batch operations in seconds.", Buckets: []float64{1, 10, 60, 600, 3600}}, []string{"operation", "outcome"})}
+}
+
+// Wrap wraps next with monitoring middleware created by NewMonitoringBucketedService that records the metrics.
+func (ms *MonitoringBucketedServiceMetrics) Wrap(next alias1.BucketedService) alias1.BucketedService {
+	return NewMonitoringBucketedService(next, ms.TotalOps, ms.FailedOps, ms.OpsDuration, ms.OpsDurationCache, ms.OpsDurationBatch)
+}
+func (m *monitoringBucketedService) Get(arg1 alias6.Context, arg2 string) (string, error) {
+	m.getOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2 := m.next.Get(arg1, arg2)
+	_opsDuration := m.getOperation.opsDurationSuccess
+	if result2 != nil {
+		_opsDuration = m.getOperation.opsDurationError
+	}
+	_opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.getOperation.failedOps.Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringBucketedService) Put(arg1 alias6.Context, arg2 string, arg3 string) error {
+	m.putOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1 := m.next.Put(arg1, arg2, arg3)
+	_opsDuration := m.putOperation.opsDurationSuccess
+	if result1 != nil {
+		_opsDuration = m.putOperation.opsDurationError
+	}
+	_opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.putOperation.failedOps.Add(1)
+	}
+	return result1
+}
+func (m *monitoringBucketedService) Rebuild(arg1 alias6.Context) error {
+	m.rebuildOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1 := m.next.Rebuild(arg1)
+	_opsDuration := m.rebuildOperation.opsDurationSuccess
+	if result1 != nil {
+		_opsDuration = m.rebuildOperation.opsDurationError
+	}
+	_opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.rebuildOperation.failedOps.Add(1)
+	}
+	return result1
+}
+func (m *monitoringBucketedService) Ping(arg1 alias6.Context) error {
+	m.pingOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1 := m.next.Ping(arg1)
+	_opsDuration := m.pingOperation.opsDurationSuccess
+	if result1 != nil {
+		_opsDuration = m.pingOperation.opsDurationError
+	}
+	_opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.pingOperation.failedOps.Add(1)
+	}
+	return result1
+}
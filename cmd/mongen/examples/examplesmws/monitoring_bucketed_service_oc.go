@@ -0,0 +1,150 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.BucketedService
+// Source-Hash: sha256:8cc4a30a860c5b1abceb2e663f5e174ac96a42a1d57210b04183449523b450e5
+// Generator: mongen v2.1.0
+// Args: -buckets=batch=1,10,60,600 -constructor=NewMonitoringBucketedServiceOC -method-buckets=Rebuild=batch -metrics=true -type=monitoringBucketedServiceOC -output-dir . -o monitoring_bucketed_service_oc.go .. BucketedService opencensus
+package examplesmws
+
+import (
+	alias1 "context"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias3 "go.opencensus.io/stats"
+	alias6 "go.opencensus.io/stats/view"
+	alias4 "go.opencensus.io/tag"
+	alias2 "time"
+)
+
+type monitoringBucketedServiceOC struct {
+	next             alias5.BucketedService
+	totalOps         *alias3.Int64Measure
+	failedOps        *alias3.Int64Measure
+	opsDuration      *alias3.Float64Measure
+	ctxFunc          func(alias1.Context) alias1.Context
+	opsDurationCache *alias3.Float64Measure
+	getOperation     alias4.Mutator
+	getTags          *alias4.Map
+	putOperation     alias4.Mutator
+	putTags          *alias4.Map
+	opsDurationBatch *alias3.Float64Measure
+	rebuildOperation alias4.Mutator
+	rebuildTags      *alias4.Map
+	pingOperation    alias4.Mutator
+	pingTags         *alias4.Map
+}
+
+// NewMonitoringBucketedServiceOC creates new monitoring middleware.
+func NewMonitoringBucketedServiceOC(next alias5.BucketedService, totalOps *alias3.Int64Measure, failedOps *alias3.Int64Measure, opsDuration *alias3.Float64Measure, opsDurationCache *alias3.Float64Measure, opsDurationBatch *alias3.Float64Measure, ctxFunc func(alias1.Context) alias1.Context) alias5.BucketedService {
+	m := &monitoringBucketedServiceOC{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, ctxFunc: ctxFunc, opsDurationCache: opsDurationCache, opsDurationBatch: opsDurationBatch}
+	operationTagKey := alias4.MustNewKey("operation")
+	bind := func(operation string) (alias4.Mutator, *alias4.Map) {
+		mutator := alias4.Insert(operationTagKey, operation)
+		ctx, _ := alias4.New(alias1.Background(), mutator)
+		return mutator, alias4.FromContext(ctx)
+	}
+	m.getOperation, m.getTags = bind("get")
+	m.putOperation, m.putTags = bind("put")
+	m.rebuildOperation, m.rebuildTags = bind("rebuild")
+	m.pingOperation, m.pingTags = bind("ping")
+	return m
+}
+
+// tagContext returns the context tagged with the mutators, the first of which tags the operation.
+// A context without tags is given the operationTags when the operation is its only tag, which saves copying them.
+// The mutators that fail, e.g. because of invalid label values, are left out.
+func (m *monitoringBucketedServiceOC) tagContext(ctx alias1.Context, operationTags *alias4.Map, mutators ...alias4.Mutator) alias1.Context {
+	if len(mutators) == 1 && alias4.FromContext(ctx) == nil {
+		return alias4.NewContext(ctx, operationTags)
+	}
+	if taggedCtx, err := alias4.New(ctx, mutators...); err == nil {
+		return taggedCtx
+	}
+	for _, mutator := range mutators {
+		if taggedCtx, err := alias4.New(ctx, mutator); err == nil {
+			ctx = taggedCtx
+		}
+	}
+	return ctx
+}
+
+// MonitoringBucketedServiceOCMetrics holds the metrics recorded by the monitoring middleware.
+type MonitoringBucketedServiceOCMetrics struct {
+	TotalOps         *alias3.Int64Measure
+	FailedOps        *alias3.Int64Measure
+	OpsDuration      *alias3.Float64Measure
+	OpsDurationCache *alias3.Float64Measure
+	OpsDurationBatch *alias3.Float64Measure
+	Views            []*alias6.View
+}
+
+// NewMonitoringBucketedServiceOCMetrics creates measures with names prefixed by the specified namespace
+// and the views that aggregate them. The views must be registered with view.Register.
+func NewMonitoringBucketedServiceOCMetrics(namespace string) *MonitoringBucketedServiceOCMetrics {
+	ms := &MonitoringBucketedServiceOCMetrics{TotalOps: alias3.Int64(namespace+"/total_ops", "Total number of operations.", alias3.UnitDimensionless), FailedOps: alias3.Int64(namespace+"/failed_ops", "Number of failed operations.", alias3.UnitDimensionless), OpsDuration: alias3.Float64(namespace+"/ops_duration_seconds", "Duration of operations in seconds.", alias3.UnitSeconds), OpsDurationCache: alias3.Float64(namespace+"/cache_ops_duration_seconds", "Duration of cache operations in seconds.", alias3.UnitSeconds), OpsDurationBatch: alias3.Float64(namespace+"/batch_ops_duration_seconds", "Duration of batch operations in seconds.", alias3.UnitSeconds)}
+	ms.Views = []*alias6.View{{Name: namespace + "/total_ops", Description: "Total number of operations.", Measure: ms.TotalOps, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Count()}, {Name: namespace + "/failed_ops", Description: "Number of failed operations.", Measure: ms.FailedOps, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Count()}, {Name: namespace + "/ops_duration_seconds", Description: "Duration of operations in seconds.", Measure: ms.OpsDuration, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Distribution(.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10)}, {Name: namespace + "/cache_ops_duration_seconds", Description: "Duration of cache operations in seconds.", Measure: ms.OpsDurationCache, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Distribution(.00001, .0001, .001, .01)}, {Name: namespace + "/batch_ops_duration_seconds", Description: "Duration of batch operations in seconds.", Measure: ms.OpsDurationBatch, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Distribution(1, 10, 60, 600)}}
+	return ms
+}
+
+// Wrap wraps next with monitoring middleware created by NewMonitoringBucketedServiceOC that records the metrics.
+func (ms *MonitoringBucketedServiceOCMetrics) Wrap(next alias5.BucketedService, ctxFunc func(alias1.Context) alias1.Context) alias5.BucketedService {
+	return NewMonitoringBucketedServiceOC(next, ms.TotalOps, ms.FailedOps, ms.OpsDuration, ms.OpsDurationCache, ms.OpsDurationBatch, ctxFunc)
+}
+func (m *monitoringBucketedServiceOC) Get(arg1 alias1.Context, arg2 string) (string, error) {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.getTags, m.getOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1, result2 := m.next.Get(arg1, arg2)
+	alias3.Record(ctx, m.opsDurationCache.M(alias2.Since(_start).Seconds()))
+	if result2 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1, result2
+}
+func (m *monitoringBucketedServiceOC) Put(arg1 alias1.Context, arg2 string, arg3 string) error {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.putTags, m.putOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1 := m.next.Put(arg1, arg2, arg3)
+	alias3.Record(ctx, m.opsDurationCache.M(alias2.Since(_start).Seconds()))
+	if result1 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1
+}
+func (m *monitoringBucketedServiceOC) Rebuild(arg1 alias1.Context) error {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.rebuildTags, m.rebuildOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1 := m.next.Rebuild(arg1)
+	alias3.Record(ctx, m.opsDurationBatch.M(alias2.Since(_start).Seconds()))
+	if result1 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1
+}
+func (m *monitoringBucketedServiceOC) Ping(arg1 alias1.Context) error {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.pingTags, m.pingOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1 := m.next.Ping(arg1)
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result1 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1
+}
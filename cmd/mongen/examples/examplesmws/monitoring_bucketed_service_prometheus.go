@@ -0,0 +1,100 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.BucketedService
+// Source-Hash: sha256:8cc4a30a860c5b1abceb2e663f5e174ac96a42a1d57210b04183449523b450e5
+// Generator: mongen v2.1.0
+// Args: -buckets=batch=1,10,60,600 -constructor=NewMonitoringBucketedServicePrometheus -method-buckets=Rebuild=batch -type=monitoringBucketedServicePrometheus -output-dir . -o monitoring_bucketed_service_prometheus.go .. BucketedService prometheus
+package examplesmws
+
+import (
+	alias4 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/prometheus/client_golang/prometheus"
+	alias3 "time"
+)
+
+type monitoringBucketedServicePrometheus struct {
+	next               alias1.BucketedService
+	getTotalOps        alias2.Counter
+	getFailedOps       alias2.Counter
+	getOpsDuration     alias2.Observer
+	putTotalOps        alias2.Counter
+	putFailedOps       alias2.Counter
+	putOpsDuration     alias2.Observer
+	rebuildTotalOps    alias2.Counter
+	rebuildFailedOps   alias2.Counter
+	rebuildOpsDuration alias2.Observer
+	pingTotalOps       alias2.Counter
+	pingFailedOps      alias2.Counter
+	pingOpsDuration    alias2.Observer
+}
+
+// NewMonitoringBucketedServicePrometheus creates new monitoring middleware.
+func NewMonitoringBucketedServicePrometheus(next alias1.BucketedService, totalOps, failedOps *alias2.CounterVec, opsDuration, opsDurationCache, opsDurationBatch *alias2.HistogramVec) alias1.BucketedService {
+	return &monitoringBucketedServicePrometheus{next: next, getTotalOps: totalOps.WithLabelValues("get"), getFailedOps: failedOps.WithLabelValues("get"), getOpsDuration: opsDurationCache.WithLabelValues("get"), putTotalOps: totalOps.WithLabelValues("put"), putFailedOps: failedOps.WithLabelValues("put"), putOpsDuration: opsDurationCache.WithLabelValues("put"), rebuildTotalOps: totalOps.WithLabelValues("rebuild"), rebuildFailedOps: failedOps.WithLabelValues("rebuild"), rebuildOpsDuration: opsDurationBatch.WithLabelValues("rebuild"), pingTotalOps: totalOps.WithLabelValues("ping"), pingFailedOps: failedOps.WithLabelValues("ping"), pingOpsDuration: opsDuration.WithLabelValues("ping")}
+}
+
+// NewMonitoringBucketedServicePrometheusCollectors creates the collectors expected by NewMonitoringBucketedServicePrometheus.
+func NewMonitoringBucketedServicePrometheusCollectors(namespace, subsystem string) (totalOps, failedOps *alias2.CounterVec, opsDuration, opsDurationCache, opsDurationBatch *alias2.HistogramVec) {
+	totalOps = alias2.NewCounterVec(alias2.CounterOpts{Namespace: namespace, Subsystem: subsystem, Name: "total_ops", Help: "Total number of operations."}, []string{"operation"})
+	failedOps = alias2.NewCounterVec(alias2.CounterOpts{Namespace: namespace, Subsystem: subsystem, Name: "failed_ops", Help: "Number of failed operations."}, []string{"operation"})
+	opsDuration = alias2.NewHistogramVec(alias2.HistogramOpts{Namespace: namespace, Subsystem: subsystem, Name: "ops_duration_seconds", Help: "Duration of operations in seconds.", Buckets: alias2.DefBuckets}, []string{"operation"})
+	opsDurationCache = alias2.NewHistogramVec(alias2.HistogramOpts{Namespace: namespace, Subsystem: subsystem, Name: "cache_ops_duration_seconds", Help: "Duration of cache operations in seconds.", Buckets: []float64{.00001, .0001, .001, .01}}, []string{"operation"})
+	opsDurationBatch = alias2.NewHistogramVec(alias2.HistogramOpts{Namespace: namespace, Subsystem: subsystem, Name: "batch_ops_duration_seconds", Help: "Duration of batch operations in seconds.", Buckets: []float64{1, 10, 60, 600}}, []string{"operation"})
+	return totalOps, failedOps, opsDuration, opsDurationCache, opsDurationBatch
+}
+
+// RegisterMonitoringBucketedServicePrometheus creates new monitoring middleware and registers its collectors with reg.
+// If a collector cannot be registered, the collectors registered before it are unregistered.
+func RegisterMonitoringBucketedServicePrometheus(reg alias2.Registerer, next alias1.BucketedService, namespace, subsystem string) (alias1.BucketedService, error) {
+	totalOps, failedOps, opsDuration, opsDurationCache, opsDurationBatch := NewMonitoringBucketedServicePrometheusCollectors(namespace, subsystem)
+	collectors := []alias2.Collector{totalOps, failedOps, opsDuration, opsDurationCache, opsDurationBatch}
+	for i, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			for _, registered := range collectors[:i] {
+				reg.Unregister(registered)
+			}
+			return nil, err
+		}
+	}
+	return NewMonitoringBucketedServicePrometheus(next, totalOps, failedOps, opsDuration, opsDurationCache, opsDurationBatch), nil
+}
+func (m *monitoringBucketedServicePrometheus) Get(arg1 alias4.Context, arg2 string) (string, error) {
+	m.getTotalOps.Inc()
+	_start := alias3.Now()
+	result1, result2 := m.next.Get(arg1, arg2)
+	m.getOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.getFailedOps.Inc()
+	}
+	return result1, result2
+}
+func (m *monitoringBucketedServicePrometheus) Put(arg1 alias4.Context, arg2 string, arg3 string) error {
+	m.putTotalOps.Inc()
+	_start := alias3.Now()
+	result1 := m.next.Put(arg1, arg2, arg3)
+	m.putOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.putFailedOps.Inc()
+	}
+	return result1
+}
+func (m *monitoringBucketedServicePrometheus) Rebuild(arg1 alias4.Context) error {
+	m.rebuildTotalOps.Inc()
+	_start := alias3.Now()
+	result1 := m.next.Rebuild(arg1)
+	m.rebuildOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.rebuildFailedOps.Inc()
+	}
+	return result1
+}
+func (m *monitoringBucketedServicePrometheus) Ping(arg1 alias4.Context) error {
+	m.pingTotalOps.Inc()
+	_start := alias3.Now()
+	result1 := m.next.Ping(arg1)
+	m.pingOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.pingFailedOps.Inc()
+	}
+	return result1
+}
@@ -0,0 +1,85 @@
+package examplesmws_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples/examplesmws"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type bucketedService struct{}
+
+func (bucketedService) Get(context.Context, string) (string, error) { return "", nil }
+
+func (bucketedService) Put(context.Context, string, string) error { return nil }
+
+func (bucketedService) Rebuild(context.Context) error { return nil }
+
+func (bucketedService) Ping(context.Context) error { return nil }
+
+func TestRegisterBucketedServicePrometheusRecordsGroupsWithTheirBuckets(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	svc, err := examplesmws.RegisterMonitoringBucketedServicePrometheus(registry, bucketedService{}, "bucketed", "service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := svc.Get(ctx, "key"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Rebuild(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name      string
+		operation string
+		bounds    []float64
+	}{
+		{name: "bucketed_service_ops_duration_seconds", operation: "ping", bounds: prometheus.DefBuckets},
+		{name: "bucketed_service_cache_ops_duration_seconds", operation: "get", bounds: []float64{.00001, .0001, .001, .01}},
+		{name: "bucketed_service_batch_ops_duration_seconds", operation: "rebuild", bounds: []float64{1, 10, 60, 600}},
+	} {
+		var found bool
+		for _, family := range families {
+			if family.GetName() != tc.name {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				if metric.GetLabel()[0].GetValue() != tc.operation {
+					continue
+				}
+				found = true
+				histogram := metric.GetHistogram()
+				if got := histogram.GetSampleCount(); got != 1 {
+					t.Errorf("%s: got %d samples, want 1", tc.name, got)
+				}
+				var bounds []float64
+				for _, bucket := range histogram.GetBucket() {
+					bounds = append(bounds, bucket.GetUpperBound())
+				}
+				if len(bounds) != len(tc.bounds) {
+					t.Errorf("%s: got buckets %v, want %v", tc.name, bounds, tc.bounds)
+					continue
+				}
+				for i := range bounds {
+					if bounds[i] != tc.bounds[i] {
+						t.Errorf("%s: got buckets %v, want %v", tc.name, bounds, tc.bounds)
+						break
+					}
+				}
+			}
+		}
+		if !found {
+			t.Errorf("no %s histogram of operation %q", tc.name, tc.operation)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.ClassifiedService
+// Source-Hash: sha256:317763a13ea89642594d268173ed4526c02987fa951c962a0bfc6665261f0965
+// Generator: mongen v2.1.0
+// Args: -classify-errors=true -output-dir . -o monitoring_classified_service.go .. ClassifiedService go-kit
+package examplesmws
+
+import (
+	alias6 "context"
+	alias5 "errors"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/go-kit/kit/metrics"
+	alias4 "sync"
+	alias3 "time"
+)
+
+type monitoringClassifiedService struct {
+	next            alias1.ClassifiedService
+	classifyError   func(error) string
+	maxLabelValues  int
+	labelsMu        alias4.Mutex
+	labelValues     map[string]map[string]bool
+	doWorkOperation monitoringClassifiedServiceOperation
+}
+type monitoringClassifiedServiceOperation struct {
+	totalOps    alias2.Counter
+	failedOps   alias2.Counter
+	opsDuration alias2.Histogram
+}
+
+// NewMonitoringClassifiedService creates new monitoring middleware.
+func NewMonitoringClassifiedService(next alias1.ClassifiedService, totalOps alias2.Counter, failedOps alias2.Counter, opsDuration alias2.Histogram, maxLabelValues int, classifyError func(error) string) alias1.ClassifiedService {
+	m := &monitoringClassifiedService{next: next, maxLabelValues: maxLabelValues, labelValues: map[string]map[string]bool{}, classifyError: classifyError}
+	bind := func(operation string, duration alias2.Histogram) monitoringClassifiedServiceOperation {
+		return monitoringClassifiedServiceOperation{totalOps: totalOps.With("operation", operation), failedOps: failedOps.With("operation", operation), opsDuration: duration.With("operation", operation)}
+	}
+	m.doWorkOperation = bind("do_work", opsDuration)
+	return m
+}
+
+// errorClass returns the class of the error recorded in the error_class label.
+func (m *monitoringClassifiedService) errorClass(err error) string {
+	if err == nil {
+		return "failed"
+	}
+	if m.classifyError != nil {
+		return m.labelValue("error_class", m.classifyError(err))
+	}
+	switch {
+	case alias5.Is(err, alias6.Canceled):
+		return "canceled"
+	case alias5.Is(err, alias6.DeadlineExceeded):
+		return "timeout"
+	}
+	return "error"
+}
+
+// labelValue returns the value of the label, or "other" once the label has maxLabelValues distinct values.
+func (m *monitoringClassifiedService) labelValue(label, value string) string {
+	if m.maxLabelValues <= 0 {
+		return value
+	}
+	m.labelsMu.Lock()
+	defer m.labelsMu.Unlock()
+	values := m.labelValues[label]
+	if values[value] {
+		return value
+	}
+	if len(values) >= m.maxLabelValues {
+		return "other"
+	}
+	if values == nil {
+		values = make(map[string]bool)
+		m.labelValues[label] = values
+	}
+	values[value] = true
+	return value
+}
+func (m *monitoringClassifiedService) DoWork(arg1 alias6.Context, arg2 int) (string, error) {
+	m.doWorkOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	m.doWorkOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.doWorkOperation.failedOps.With("error_class", m.errorClass(result2)).Add(1)
+	}
+	return result1, result2
+}
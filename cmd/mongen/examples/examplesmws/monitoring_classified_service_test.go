@@ -0,0 +1,49 @@
+package examplesmws_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples/examplesmws"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type classifiedService struct{}
+
+func (classifiedService) DoWork(_ context.Context, code int) (string, error) {
+	return "", fmt.Errorf("code %d", code)
+}
+
+func TestClassifiedServiceCapsErrorClasses(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	totalOpsVec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "total_ops"}, []string{"operation"})
+	failedOpsVec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failed_ops"}, []string{"operation", "error_class"})
+	opsDurationVec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "ops_duration_seconds"}, []string{"operation"})
+	registry.MustRegister(totalOpsVec, failedOpsVec, opsDurationVec)
+	// the classifier returns a class per error, more than the cap allows
+	svc := examplesmws.NewMonitoringClassifiedService(classifiedService{},
+		kitprometheus.NewCounter(totalOpsVec),
+		kitprometheus.NewCounter(failedOpsVec),
+		kitprometheus.NewHistogram(opsDurationVec),
+		2, func(err error) string { return err.Error() })
+
+	for code := 1; code <= 4; code++ {
+		svc.DoWork(context.Background(), code)
+	}
+
+	for _, tc := range []struct {
+		class string
+		want  float64
+	}{
+		{class: "code 1", want: 1},
+		{class: "code 2", want: 1},
+		{class: "other", want: 2},
+	} {
+		labels := map[string]string{"operation": "do_work", "error_class": tc.class}
+		if got := counterValue(t, registry, "failed_ops", labels); got != tc.want {
+			t.Errorf("got %v failures of class %q, want %v", got, tc.class, tc.want)
+		}
+	}
+}
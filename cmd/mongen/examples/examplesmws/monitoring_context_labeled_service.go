@@ -0,0 +1,139 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.ContextLabeledService
+// Source-Hash: sha256:38c4d9dda48760503d1987f1acb516d9e16d9562c7aa5c1ecbfc5b01c409974d
+// Generator: mongen v2.1.0
+// Args: -labels-func=true -metrics=true -output-dir . -o monitoring_context_labeled_service.go .. ContextLabeledService go-kit
+package examplesmws
+
+import (
+	alias4 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/go-kit/kit/metrics"
+	alias5 "github.com/go-kit/kit/metrics/prometheus"
+	alias6 "github.com/prometheus/client_golang/prometheus"
+	alias7 "sync"
+	alias3 "time"
+)
+
+type monitoringContextLabeledService struct {
+	next             alias1.ContextLabeledService
+	labelsFunc       func(alias4.Context) []string
+	maxLabelValues   int
+	labelsMu         alias7.Mutex
+	labelValues      map[string]map[string]bool
+	handleOperation  monitoringContextLabeledServiceOperation
+	pingOperation    monitoringContextLabeledServiceOperation
+	versionOperation monitoringContextLabeledServiceOperation
+}
+type monitoringContextLabeledServiceOperation struct {
+	totalOps    alias2.Counter
+	failedOps   alias2.Counter
+	opsDuration alias2.Histogram
+}
+
+// NewMonitoringContextLabeledService creates new monitoring middleware.
+func NewMonitoringContextLabeledService(next alias1.ContextLabeledService, totalOps alias2.Counter, failedOps alias2.Counter, opsDuration alias2.Histogram, maxLabelValues int, labelsFunc func(alias4.Context) []string) alias1.ContextLabeledService {
+	m := &monitoringContextLabeledService{next: next, maxLabelValues: maxLabelValues, labelValues: map[string]map[string]bool{}, labelsFunc: labelsFunc}
+	bind := func(operation string, duration alias2.Histogram) monitoringContextLabeledServiceOperation {
+		return monitoringContextLabeledServiceOperation{totalOps: totalOps.With("operation", operation), failedOps: failedOps.With("operation", operation), opsDuration: duration.With("operation", operation)}
+	}
+	m.handleOperation = bind("handle", opsDuration)
+	m.pingOperation = bind("ping", opsDuration)
+	m.versionOperation = bind("version", opsDuration)
+	return m
+}
+
+// MonitoringContextLabeledServiceMetrics holds the metrics recorded by the monitoring middleware.
+type MonitoringContextLabeledServiceMetrics struct {
+	TotalOps    alias2.Counter
+	FailedOps   alias2.Counter
+	OpsDuration alias2.Histogram
+}
+
+// NewMonitoringContextLabeledServiceMetrics creates Prometheus metrics with the specified namespace and registers
+// them with the default registerer.
+//
+// The metrics have the labels named by contextLabels as well, which must be the
+// names of the label pairs returned by labelsFunc.
+func NewMonitoringContextLabeledServiceMetrics(namespace string, contextLabels ...string) *MonitoringContextLabeledServiceMetrics {
+	return &MonitoringContextLabeledServiceMetrics{TotalOps: alias5.NewCounterFrom(alias6.CounterOpts{Namespace: namespace, Name: "total_ops", Help: "Total number of operations."}, append([]string{"operation", "tenant"}, contextLabels...)), FailedOps: alias5.NewCounterFrom(alias6.CounterOpts{Namespace: namespace, Name: "failed_ops", Help: "Number of failed operations."}, append([]string{"operation", "tenant"}, contextLabels...)), OpsDuration: alias5.NewHistogramFrom(alias6.HistogramOpts{Namespace: namespace, Name: "ops_duration_seconds", Help: "Duration of operations in seconds.", Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}}, append([]string{"operation", "tenant"}, contextLabels...))}
+}
+
+// Wrap wraps next with monitoring middleware created by NewMonitoringContextLabeledService that records the metrics.
+func (ms *MonitoringContextLabeledServiceMetrics) Wrap(next alias1.ContextLabeledService, maxLabelValues int, labelsFunc func(alias4.Context) []string) alias1.ContextLabeledService {
+	return NewMonitoringContextLabeledService(next, ms.TotalOps, ms.FailedOps, ms.OpsDuration, maxLabelValues, labelsFunc)
+}
+
+// labelValue returns the value of the label, or "other" once the label has maxLabelValues distinct values.
+func (m *monitoringContextLabeledService) labelValue(label, value string) string {
+	if m.maxLabelValues <= 0 {
+		return value
+	}
+	m.labelsMu.Lock()
+	defer m.labelsMu.Unlock()
+	values := m.labelValues[label]
+	if values[value] {
+		return value
+	}
+	if len(values) >= m.maxLabelValues {
+		return "other"
+	}
+	if values == nil {
+		values = make(map[string]bool)
+		m.labelValues[label] = values
+	}
+	values[value] = true
+	return value
+}
+
+// appendContextLabels appends the label pairs returned by labelsFunc to labels, with their values capped like those of the other labels.
+func (m *monitoringContextLabeledService) appendContextLabels(labels []string, ctx alias4.Context) []string {
+	if m.labelsFunc == nil {
+		return labels
+	}
+	pairs := m.labelsFunc(ctx)
+	for i := 1; i < len(pairs); i += 2 {
+		labels = append(labels, pairs[i-1], m.labelValue(pairs[i-1], pairs[i]))
+	}
+	if len(pairs)%2 == 1 {
+		labels = append(labels, pairs[len(pairs)-1])
+	}
+	return labels
+}
+
+// MonitoringContextLabeledServiceLabels are the names of the labels recorded by the monitoring middleware.
+var MonitoringContextLabeledServiceLabels = []string{"operation", "tenant"}
+
+func (m *monitoringContextLabeledService) Handle(arg1 alias4.Context, arg2 alias1.Request) error {
+	_labels := []string{"tenant", m.labelValue("tenant", arg2.Tenant)}
+	_labels = m.appendContextLabels(_labels, arg1)
+	m.handleOperation.totalOps.With(_labels...).Add(1)
+	_start := alias3.Now()
+	result1 := m.next.Handle(arg1, arg2)
+	m.handleOperation.opsDuration.With(_labels...).Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.handleOperation.failedOps.With(_labels...).Add(1)
+	}
+	return result1
+}
+func (m *monitoringContextLabeledService) Ping(arg1 alias4.Context) error {
+	_labels := []string{"tenant", ""}
+	_labels = m.appendContextLabels(_labels, arg1)
+	m.pingOperation.totalOps.With(_labels...).Add(1)
+	_start := alias3.Now()
+	result1 := m.next.Ping(arg1)
+	m.pingOperation.opsDuration.With(_labels...).Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.pingOperation.failedOps.With(_labels...).Add(1)
+	}
+	return result1
+}
+func (m *monitoringContextLabeledService) Version() string {
+	_labels := []string{"tenant", ""}
+	_labels = m.appendContextLabels(_labels, alias4.Background())
+	m.versionOperation.totalOps.With(_labels...).Add(1)
+	_start := alias3.Now()
+	result1 := m.next.Version()
+	m.versionOperation.opsDuration.With(_labels...).Observe(alias3.Since(_start).Seconds())
+	return result1
+}
@@ -0,0 +1,70 @@
+package examplesmws_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples"
+	"github.com/Bo0mer/gentools/cmd/mongen/examples/examplesmws"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type clientKey struct{}
+
+func TestContextLabeledService(t *testing.T) {
+	ms := examplesmws.NewMonitoringContextLabeledServiceMetrics("context_labeled", "client")
+	svc := ms.Wrap(service{}, 0, func(ctx context.Context) []string {
+		client, _ := ctx.Value(clientKey{}).(string)
+		return []string{"client", client}
+	})
+
+	ctx := context.WithValue(context.Background(), clientKey{}, "billing")
+	if err := svc.Handle(ctx, examples.Request{Tenant: "acme"}); err != nil {
+		t.Fatal(err)
+	}
+	// Version takes no context, and records the labels of the background
+	// context instead of panicking on a missing label value.
+	if got, want := svc.Version(), "v1"; got != want {
+		t.Fatalf("got version %q, want %q", got, want)
+	}
+
+	for _, tc := range []struct {
+		labels map[string]string
+		want   float64
+	}{
+		{labels: map[string]string{"operation": "handle", "tenant": "acme", "client": "billing"}, want: 1},
+		{labels: map[string]string{"operation": "version", "tenant": "", "client": ""}, want: 1},
+	} {
+		if got := counterValue(t, prometheus.DefaultGatherer, "context_labeled_total_ops", tc.labels); got != tc.want {
+			t.Errorf("got %v operations with labels %v, want %v", got, tc.labels, tc.want)
+		}
+	}
+}
+
+func TestContextLabeledServiceCapsContextLabelValues(t *testing.T) {
+	ms := examplesmws.NewMonitoringContextLabeledServiceMetrics("context_labeled_capped", "client")
+	svc := ms.Wrap(service{}, 1, func(ctx context.Context) []string {
+		client, _ := ctx.Value(clientKey{}).(string)
+		return []string{"client", client}
+	})
+
+	for _, client := range []string{"billing", "payments", "billing"} {
+		ctx := context.WithValue(context.Background(), clientKey{}, client)
+		if err := svc.Ping(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		client string
+		want   float64
+	}{
+		{client: "billing", want: 2},
+		{client: "other", want: 1},
+	} {
+		labels := map[string]string{"operation": "ping", "tenant": "", "client": tc.client}
+		if got := counterValue(t, prometheus.DefaultGatherer, "context_labeled_capped_total_ops", labels); got != tc.want {
+			t.Errorf("got %v operations with labels %v, want %v", got, labels, tc.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.ExpvarService
+// Source-Hash: sha256:2be545efc85577c074d84a3a61eb43e97a854372587e8fc4a43f2ac2b5fb2b00
+// Generator: mongen v2.1.0
+// Args: -output-dir . -o monitoring_expvar_service.go .. ExpvarService expvar
+package examplesmws
+
+import (
+	alias2 "expvar"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias4 "sync"
+	alias3 "time"
+)
+
+type monitoringExpvarService struct {
+	next           alias1.ExpvarService
+	totalOps       *alias2.Map
+	failedOps      *alias2.Map
+	doWorkDuration *alias2.Map
+	closeDuration  *alias2.Map
+}
+
+// NewMonitoringExpvarService creates new monitoring middleware that publishes its variables with names starting with prefix.
+func NewMonitoringExpvarService(next alias1.ExpvarService, prefix string) alias1.ExpvarService {
+	totalOps := monitoringExpvarServicePublish(prefix + "total_ops")
+	failedOps := monitoringExpvarServicePublish(prefix + "failed_ops")
+	opsDuration := monitoringExpvarServicePublish(prefix + "ops_duration_seconds")
+	totalOps.Add("do_work", 0)
+	failedOps.Add("do_work", 0)
+	totalOps.Add("close", 0)
+	failedOps.Add("close", 0)
+	return &monitoringExpvarService{next: next, totalOps: totalOps, failedOps: failedOps, doWorkDuration: monitoringExpvarServiceGet(opsDuration, "do_work"), closeDuration: monitoringExpvarServiceGet(opsDuration, "close")}
+}
+
+// monitoringExpvarServiceMu guards the lookup and publishing of the maps of monitoringExpvarService.
+var monitoringExpvarServiceMu alias4.Mutex
+
+// monitoringExpvarServicePublish returns the map published under the name, publishing a new map if no variable is published under it yet.
+// It panics if a variable that is not a map is published under the name.
+func monitoringExpvarServicePublish(name string) *alias2.Map {
+	monitoringExpvarServiceMu.Lock()
+	defer monitoringExpvarServiceMu.Unlock()
+	v := alias2.Get(name)
+	if v == nil {
+		return alias2.NewMap(name)
+	}
+	if m, ok := v.(*alias2.Map); ok {
+		return m
+	}
+	panic("expvar: " + name + " is published and is not an *expvar.Map")
+}
+
+// monitoringExpvarServiceGet returns the map stored under the key of m, storing a new map under it if there is none.
+func monitoringExpvarServiceGet(m *alias2.Map, key string) *alias2.Map {
+	monitoringExpvarServiceMu.Lock()
+	defer monitoringExpvarServiceMu.Unlock()
+	if sub, ok := m.Get(key).(*alias2.Map); ok {
+		return sub
+	}
+	sub := new(alias2.Map)
+	m.Set(key, sub)
+	return sub
+}
+
+// observe records the duration of an operation, in seconds, in its duration map.
+func (m *monitoringExpvarService) observe(duration *alias2.Map, seconds float64) {
+	duration.AddFloat("sum", seconds)
+	duration.Add("count", 1)
+	switch {
+	case seconds <= 0.005:
+		duration.Add("le_0.005", 1)
+		fallthrough
+	case seconds <= 0.01:
+		duration.Add("le_0.01", 1)
+		fallthrough
+	case seconds <= 0.025:
+		duration.Add("le_0.025", 1)
+		fallthrough
+	case seconds <= 0.05:
+		duration.Add("le_0.05", 1)
+		fallthrough
+	case seconds <= 0.1:
+		duration.Add("le_0.1", 1)
+		fallthrough
+	case seconds <= 0.25:
+		duration.Add("le_0.25", 1)
+		fallthrough
+	case seconds <= 0.5:
+		duration.Add("le_0.5", 1)
+		fallthrough
+	case seconds <= 1:
+		duration.Add("le_1", 1)
+		fallthrough
+	case seconds <= 2.5:
+		duration.Add("le_2.5", 1)
+		fallthrough
+	case seconds <= 5:
+		duration.Add("le_5", 1)
+		fallthrough
+	case seconds <= 10:
+		duration.Add("le_10", 1)
+		fallthrough
+	default:
+		duration.Add("le_+Inf", 1)
+	}
+}
+func (m *monitoringExpvarService) DoWork(arg1 int, arg2 string) (string, error) {
+	_start := alias3.Now()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	m.totalOps.Add("do_work", 1)
+	if result2 != nil {
+		m.failedOps.Add("do_work", 1)
+	}
+	m.observe(m.doWorkDuration, alias3.Since(_start).Seconds())
+	return result1, result2
+}
+func (m *monitoringExpvarService) Close() {
+	_start := alias3.Now()
+	m.next.Close()
+	m.totalOps.Add("close", 1)
+	m.observe(m.closeDuration, alias3.Since(_start).Seconds())
+}
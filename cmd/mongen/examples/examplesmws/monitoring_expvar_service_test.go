@@ -0,0 +1,92 @@
+package examplesmws_test
+
+import (
+	"expvar"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples"
+	"github.com/Bo0mer/gentools/cmd/mongen/examples/examplesmws"
+)
+
+func TestExpvarServiceSharesVariablesAcrossConcurrentConstructors(t *testing.T) {
+	const wrappers = 8
+	svcs := make([]examples.ExpvarService, wrappers)
+	var wg sync.WaitGroup
+	for i := range svcs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			svcs[i] = examplesmws.NewMonitoringExpvarService(service{}, "expvar_test.")
+		}(i)
+	}
+	wg.Wait()
+	for _, svc := range svcs {
+		svc.Close()
+	}
+
+	totalOps := expvar.Get("expvar_test.total_ops").(*expvar.Map)
+	if got := totalOps.Get("close").String(); got != "8" {
+		t.Errorf("got %s close operations, want %d", got, wrappers)
+	}
+
+	// The buckets are cumulative, so every bucket counts all the operations,
+	// which took less than the smallest bound.
+	duration := expvar.Get("expvar_test.ops_duration_seconds").(*expvar.Map).Get("close").(*expvar.Map)
+	for _, key := range []string{"count", "le_0.005", "le_1", "le_10", "le_+Inf"} {
+		if got := duration.Get(key).String(); got != "8" {
+			t.Errorf("got %s for %s, want %d", got, key, wrappers)
+		}
+	}
+}
+
+func TestExpvarServiceSharesVariablesWithWrappersOfOtherInterfaces(t *testing.T) {
+	// The maps are guarded by a mutex of the generated file, so the wrappers
+	// of the two interfaces are created one after the other.
+	const wrappers = 8
+	for i := 0; i < wrappers; i++ {
+		examplesmws.NewMonitoringExpvarService(service{}, "expvar_shared_test.").Close()
+		examplesmws.NewMonitoringPanickyServiceExpvar(panickyService{}, "expvar_shared_test.").Close()
+	}
+
+	totalOps := expvar.Get("expvar_shared_test.total_ops").(*expvar.Map)
+	if got := totalOps.Get("close").String(); got != "16" {
+		t.Errorf("got %s close operations, want %d", got, 2*wrappers)
+	}
+	duration := expvar.Get("expvar_shared_test.ops_duration_seconds").(*expvar.Map).Get("close").(*expvar.Map)
+	if got := duration.Get("count").String(); got != "16" {
+		t.Errorf("got %s close durations, want %d", got, 2*wrappers)
+	}
+}
+
+func TestExpvarServiceImportsOnlyTheStandardLibrary(t *testing.T) {
+	files := []string{
+		"monitoring_expvar_service.go",
+		"monitoring_sized_service_expvar.go",
+		"monitoring_panicky_service_expvar.go",
+	}
+	for _, name := range files {
+		f, err := parser.ParseFile(token.NewFileSet(), name, nil, parser.ImportsOnly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, spec := range f.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The monitored interface is the only package that is not
+			// part of the standard library.
+			if path == "github.com/Bo0mer/gentools/cmd/mongen/examples" {
+				continue
+			}
+			if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+				t.Errorf("%s imports %s, which is not part of the standard library", name, path)
+			}
+		}
+	}
+}
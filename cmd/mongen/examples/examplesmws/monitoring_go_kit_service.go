@@ -1,4 +1,8 @@
 // Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.GoKitService
+// Source-Hash: sha256:1eaaa6934b08370af07e893e052bd903f803078f34cd637c71f91d24b48a204d
+// Generator: mongen v2.1.0
+// Args: -output-dir . -o monitoring_go_kit_service.go .. GoKitService go-kit
 package examplesmws
 
 import (
@@ -9,7 +13,11 @@ import (
 )
 
 type monitoringGoKitService struct {
-	next        alias1.GoKitService
+	next               alias1.GoKitService
+	doWorkOperation    monitoringGoKitServiceOperation
+	doWorkCtxOperation monitoringGoKitServiceOperation
+}
+type monitoringGoKitServiceOperation struct {
 	totalOps    alias2.Counter
 	failedOps   alias2.Counter
 	opsDuration alias2.Histogram
@@ -17,25 +25,31 @@ type monitoringGoKitService struct {
 
 // NewMonitoringGoKitService creates new monitoring middleware.
 func NewMonitoringGoKitService(next alias1.GoKitService, totalOps alias2.Counter, failedOps alias2.Counter, opsDuration alias2.Histogram) alias1.GoKitService {
-	return &monitoringGoKitService{next, totalOps, failedOps, opsDuration}
+	m := &monitoringGoKitService{next: next}
+	bind := func(operation string, duration alias2.Histogram) monitoringGoKitServiceOperation {
+		return monitoringGoKitServiceOperation{totalOps: totalOps.With("operation", operation), failedOps: failedOps.With("operation", operation), opsDuration: duration.With("operation", operation)}
+	}
+	m.doWorkOperation = bind("do_work", opsDuration)
+	m.doWorkCtxOperation = bind("do_work_ctx", opsDuration)
+	return m
 }
 func (m *monitoringGoKitService) DoWork(arg1 int, arg2 string) (string, error) {
-	m.totalOps.With("operation", "do_work").Add(1)
+	m.doWorkOperation.totalOps.Add(1)
 	_start := alias3.Now()
 	result1, result2 := m.next.DoWork(arg1, arg2)
-	m.opsDuration.With("operation", "do_work").Observe(alias3.Since(_start).Seconds())
+	m.doWorkOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
 	if result2 != nil {
-		m.failedOps.With("operation", "do_work").Add(1)
+		m.doWorkOperation.failedOps.Add(1)
 	}
 	return result1, result2
 }
 func (m *monitoringGoKitService) DoWorkCtx(arg1 alias4.Context, arg2 int, arg3 string) (string, error) {
-	m.totalOps.With("operation", "do_work_ctx").Add(1)
+	m.doWorkCtxOperation.totalOps.Add(1)
 	_start := alias3.Now()
 	result1, result2 := m.next.DoWorkCtx(arg1, arg2, arg3)
-	m.opsDuration.With("operation", "do_work_ctx").Observe(alias3.Since(_start).Seconds())
+	m.doWorkCtxOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
 	if result2 != nil {
-		m.failedOps.With("operation", "do_work_ctx").Add(1)
+		m.doWorkCtxOperation.failedOps.Add(1)
 	}
 	return result1, result2
 }
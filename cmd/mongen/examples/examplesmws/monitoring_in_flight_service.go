@@ -0,0 +1,61 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.InFlightService
+// Source-Hash: sha256:50e231e1925f9abf7cc4bef0a9921b0a317f79c3d16c72d920669954733440db
+// Generator: mongen v2.1.0
+// Args: -in-flight=true -outcome=true -output-dir . -o monitoring_in_flight_service.go .. InFlightService go-kit
+package examplesmws
+
+import (
+	alias4 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/go-kit/kit/metrics"
+	alias3 "time"
+)
+
+type monitoringInFlightService struct {
+	next            alias1.InFlightService
+	doWorkOperation monitoringInFlightServiceOperation
+	notifyOperation monitoringInFlightServiceOperation
+}
+type monitoringInFlightServiceOperation struct {
+	totalOps           alias2.Counter
+	failedOps          alias2.Counter
+	opsDurationSuccess alias2.Histogram
+	opsDurationError   alias2.Histogram
+	inFlightOps        alias2.Gauge
+}
+
+// NewMonitoringInFlightService creates new monitoring middleware.
+func NewMonitoringInFlightService(next alias1.InFlightService, totalOps alias2.Counter, failedOps alias2.Counter, opsDuration alias2.Histogram, inFlightOps alias2.Gauge) alias1.InFlightService {
+	m := &monitoringInFlightService{next: next}
+	bind := func(operation string, duration alias2.Histogram) monitoringInFlightServiceOperation {
+		return monitoringInFlightServiceOperation{totalOps: totalOps.With("operation", operation), failedOps: failedOps.With("operation", operation), opsDurationSuccess: duration.With("operation", operation, "outcome", "success"), opsDurationError: duration.With("operation", operation, "outcome", "error"), inFlightOps: inFlightOps.With("operation", operation)}
+	}
+	m.doWorkOperation = bind("do_work", opsDuration)
+	m.notifyOperation = bind("notify", opsDuration)
+	return m
+}
+func (m *monitoringInFlightService) DoWork(arg1 alias4.Context, arg2 int) (string, error) {
+	m.doWorkOperation.totalOps.Add(1)
+	m.doWorkOperation.inFlightOps.Add(1)
+	defer m.doWorkOperation.inFlightOps.Add(-1)
+	_start := alias3.Now()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	_opsDuration := m.doWorkOperation.opsDurationSuccess
+	if result2 != nil {
+		_opsDuration = m.doWorkOperation.opsDurationError
+	}
+	_opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.doWorkOperation.failedOps.Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringInFlightService) Notify(arg1 string) {
+	m.notifyOperation.totalOps.Add(1)
+	m.notifyOperation.inFlightOps.Add(1)
+	defer m.notifyOperation.inFlightOps.Add(-1)
+	_start := alias3.Now()
+	m.next.Notify(arg1)
+	m.notifyOperation.opsDurationSuccess.Observe(alias3.Since(_start).Seconds())
+}
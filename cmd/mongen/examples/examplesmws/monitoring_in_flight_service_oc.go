@@ -0,0 +1,98 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.InFlightService
+// Source-Hash: sha256:50e231e1925f9abf7cc4bef0a9921b0a317f79c3d16c72d920669954733440db
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringInFlightServiceOC -in-flight=true -outcome=true -type=monitoringInFlightServiceOC -output-dir . -o monitoring_in_flight_service_oc.go .. InFlightService opencensus
+package examplesmws
+
+import (
+	alias1 "context"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias3 "go.opencensus.io/stats"
+	alias4 "go.opencensus.io/tag"
+	alias2 "time"
+)
+
+type monitoringInFlightServiceOC struct {
+	next            alias5.InFlightService
+	totalOps        *alias3.Int64Measure
+	failedOps       *alias3.Int64Measure
+	opsDuration     *alias3.Float64Measure
+	ctxFunc         func(alias1.Context) alias1.Context
+	inFlightOps     *alias3.Int64Measure
+	outcomeTagKey   alias4.Key
+	doWorkOperation alias4.Mutator
+	doWorkTags      *alias4.Map
+	notifyOperation alias4.Mutator
+	notifyTags      *alias4.Map
+}
+
+// NewMonitoringInFlightServiceOC creates new monitoring middleware.
+func NewMonitoringInFlightServiceOC(next alias5.InFlightService, totalOps *alias3.Int64Measure, failedOps *alias3.Int64Measure, opsDuration *alias3.Float64Measure, ctxFunc func(alias1.Context) alias1.Context, inFlightOps *alias3.Int64Measure) alias5.InFlightService {
+	m := &monitoringInFlightServiceOC{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, ctxFunc: ctxFunc, inFlightOps: inFlightOps, outcomeTagKey: alias4.MustNewKey("outcome")}
+	operationTagKey := alias4.MustNewKey("operation")
+	bind := func(operation string) (alias4.Mutator, *alias4.Map) {
+		mutator := alias4.Insert(operationTagKey, operation)
+		ctx, _ := alias4.New(alias1.Background(), mutator)
+		return mutator, alias4.FromContext(ctx)
+	}
+	m.doWorkOperation, m.doWorkTags = bind("do_work")
+	m.notifyOperation, m.notifyTags = bind("notify")
+	return m
+}
+
+// tagContext returns the context tagged with the mutators, the first of which tags the operation.
+// A context without tags is given the operationTags when the operation is its only tag, which saves copying them.
+// The mutators that fail, e.g. because of invalid label values, are left out.
+func (m *monitoringInFlightServiceOC) tagContext(ctx alias1.Context, operationTags *alias4.Map, mutators ...alias4.Mutator) alias1.Context {
+	if len(mutators) == 1 && alias4.FromContext(ctx) == nil {
+		return alias4.NewContext(ctx, operationTags)
+	}
+	if taggedCtx, err := alias4.New(ctx, mutators...); err == nil {
+		return taggedCtx
+	}
+	for _, mutator := range mutators {
+		if taggedCtx, err := alias4.New(ctx, mutator); err == nil {
+			ctx = taggedCtx
+		}
+	}
+	return ctx
+}
+func (m *monitoringInFlightServiceOC) DoWork(arg1 alias1.Context, arg2 int) (string, error) {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.doWorkTags, m.doWorkOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	alias3.Record(ctx, m.inFlightOps.M(1))
+	defer alias3.Record(ctx, m.inFlightOps.M(-1))
+	_start := alias2.Now()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	_outcome := "success"
+	if result2 != nil {
+		_outcome = "error"
+	}
+	if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.outcomeTagKey, _outcome)}, m.opsDuration.M(alias2.Since(_start).Seconds())); err != nil {
+		alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	}
+	if result2 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1, result2
+}
+func (m *monitoringInFlightServiceOC) Notify(arg1 string) {
+	ctx := alias1.Background()
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.notifyTags, m.notifyOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	alias3.Record(ctx, m.inFlightOps.M(1))
+	defer alias3.Record(ctx, m.inFlightOps.M(-1))
+	_start := alias2.Now()
+	m.next.Notify(arg1)
+	if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.outcomeTagKey, "success")}, m.opsDuration.M(alias2.Since(_start).Seconds())); err != nil {
+		alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	}
+}
@@ -0,0 +1,86 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.LabeledService
+// Source-Hash: sha256:02a6f7cc0cace5364fc6f4b137980217f39e942ce1166cb1c0558608b9888f7a
+// Generator: mongen v2.1.0
+// Args: -output-dir . -o monitoring_labeled_service.go .. LabeledService go-kit
+package examplesmws
+
+import (
+	alias4 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/go-kit/kit/metrics"
+	alias5 "sync"
+	alias3 "time"
+)
+
+type monitoringLabeledService struct {
+	next            alias1.LabeledService
+	maxLabelValues  int
+	labelsMu        alias5.Mutex
+	labelValues     map[string]map[string]bool
+	handleOperation monitoringLabeledServiceOperation
+	pingOperation   monitoringLabeledServiceOperation
+}
+type monitoringLabeledServiceOperation struct {
+	totalOps    alias2.Counter
+	failedOps   alias2.Counter
+	opsDuration alias2.Histogram
+}
+
+// NewMonitoringLabeledService creates new monitoring middleware.
+func NewMonitoringLabeledService(next alias1.LabeledService, totalOps alias2.Counter, failedOps alias2.Counter, opsDuration alias2.Histogram, maxLabelValues int) alias1.LabeledService {
+	m := &monitoringLabeledService{next: next, maxLabelValues: maxLabelValues, labelValues: map[string]map[string]bool{}}
+	bind := func(operation string, duration alias2.Histogram) monitoringLabeledServiceOperation {
+		return monitoringLabeledServiceOperation{totalOps: totalOps.With("operation", operation), failedOps: failedOps.With("operation", operation), opsDuration: duration.With("operation", operation)}
+	}
+	m.handleOperation = bind("handle", opsDuration)
+	m.pingOperation = bind("ping", opsDuration)
+	return m
+}
+
+// MonitoringLabeledServiceLabels are the names of the labels recorded by the monitoring middleware.
+var MonitoringLabeledServiceLabels = []string{"operation", "region", "tenant"}
+
+// labelValue returns the value of the label, or "other" once the label has maxLabelValues distinct values.
+func (m *monitoringLabeledService) labelValue(label, value string) string {
+	if m.maxLabelValues <= 0 {
+		return value
+	}
+	m.labelsMu.Lock()
+	defer m.labelsMu.Unlock()
+	values := m.labelValues[label]
+	if values[value] {
+		return value
+	}
+	if len(values) >= m.maxLabelValues {
+		return "other"
+	}
+	if values == nil {
+		values = make(map[string]bool)
+		m.labelValues[label] = values
+	}
+	values[value] = true
+	return value
+}
+func (m *monitoringLabeledService) Handle(arg1 alias4.Context, arg2 alias1.Request) error {
+	_labels := []string{"region", m.labelValue("region", alias1.Region(arg1)), "tenant", m.labelValue("tenant", arg2.Tenant)}
+	m.handleOperation.totalOps.With(_labels...).Add(1)
+	_start := alias3.Now()
+	result1 := m.next.Handle(arg1, arg2)
+	m.handleOperation.opsDuration.With(_labels...).Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.handleOperation.failedOps.With(_labels...).Add(1)
+	}
+	return result1
+}
+func (m *monitoringLabeledService) Ping(arg1 alias4.Context) error {
+	_labels := []string{"region", m.labelValue("region", alias1.Region(arg1)), "tenant", ""}
+	m.pingOperation.totalOps.With(_labels...).Add(1)
+	_start := alias3.Now()
+	result1 := m.next.Ping(arg1)
+	m.pingOperation.opsDuration.With(_labels...).Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.pingOperation.failedOps.With(_labels...).Add(1)
+	}
+	return result1
+}
@@ -0,0 +1,118 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.LabeledService
+// Source-Hash: sha256:02a6f7cc0cace5364fc6f4b137980217f39e942ce1166cb1c0558608b9888f7a
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringLabeledServiceOC -type=monitoringLabeledServiceOC -output-dir . -o monitoring_labeled_service_oc.go .. LabeledService opencensus
+package examplesmws
+
+import (
+	alias1 "context"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias3 "go.opencensus.io/stats"
+	alias4 "go.opencensus.io/tag"
+	alias6 "sync"
+	alias2 "time"
+)
+
+type monitoringLabeledServiceOC struct {
+	next            alias5.LabeledService
+	totalOps        *alias3.Int64Measure
+	failedOps       *alias3.Int64Measure
+	opsDuration     *alias3.Float64Measure
+	ctxFunc         func(alias1.Context) alias1.Context
+	maxLabelValues  int
+	labelsMu        alias6.Mutex
+	labelValues     map[string]map[string]bool
+	labelTagKeys    []alias4.Key
+	handleOperation alias4.Mutator
+	handleTags      *alias4.Map
+	pingOperation   alias4.Mutator
+	pingTags        *alias4.Map
+}
+
+// NewMonitoringLabeledServiceOC creates new monitoring middleware.
+func NewMonitoringLabeledServiceOC(next alias5.LabeledService, totalOps *alias3.Int64Measure, failedOps *alias3.Int64Measure, opsDuration *alias3.Float64Measure, ctxFunc func(alias1.Context) alias1.Context, maxLabelValues int) alias5.LabeledService {
+	m := &monitoringLabeledServiceOC{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, ctxFunc: ctxFunc, maxLabelValues: maxLabelValues, labelValues: map[string]map[string]bool{}, labelTagKeys: []alias4.Key{alias4.MustNewKey("region"), alias4.MustNewKey("tenant")}}
+	operationTagKey := alias4.MustNewKey("operation")
+	bind := func(operation string) (alias4.Mutator, *alias4.Map) {
+		mutator := alias4.Insert(operationTagKey, operation)
+		ctx, _ := alias4.New(alias1.Background(), mutator)
+		return mutator, alias4.FromContext(ctx)
+	}
+	m.handleOperation, m.handleTags = bind("handle")
+	m.pingOperation, m.pingTags = bind("ping")
+	return m
+}
+
+// tagContext returns the context tagged with the mutators, the first of which tags the operation.
+// A context without tags is given the operationTags when the operation is its only tag, which saves copying them.
+// The mutators that fail, e.g. because of invalid label values, are left out.
+func (m *monitoringLabeledServiceOC) tagContext(ctx alias1.Context, operationTags *alias4.Map, mutators ...alias4.Mutator) alias1.Context {
+	if len(mutators) == 1 && alias4.FromContext(ctx) == nil {
+		return alias4.NewContext(ctx, operationTags)
+	}
+	if taggedCtx, err := alias4.New(ctx, mutators...); err == nil {
+		return taggedCtx
+	}
+	for _, mutator := range mutators {
+		if taggedCtx, err := alias4.New(ctx, mutator); err == nil {
+			ctx = taggedCtx
+		}
+	}
+	return ctx
+}
+
+// MonitoringLabeledServiceOCLabels are the names of the labels recorded by the monitoring middleware.
+var MonitoringLabeledServiceOCLabels = []string{"operation", "region", "tenant"}
+
+// labelValue returns the value of the label, or "other" once the label has maxLabelValues distinct values.
+func (m *monitoringLabeledServiceOC) labelValue(label, value string) string {
+	if m.maxLabelValues <= 0 {
+		return value
+	}
+	m.labelsMu.Lock()
+	defer m.labelsMu.Unlock()
+	values := m.labelValues[label]
+	if values[value] {
+		return value
+	}
+	if len(values) >= m.maxLabelValues {
+		return "other"
+	}
+	if values == nil {
+		values = make(map[string]bool)
+		m.labelValues[label] = values
+	}
+	values[value] = true
+	return value
+}
+func (m *monitoringLabeledServiceOC) Handle(arg1 alias1.Context, arg2 alias5.Request) error {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.handleTags, m.handleOperation, alias4.Insert(m.labelTagKeys[0], m.labelValue("region", alias5.Region(arg1))), alias4.Insert(m.labelTagKeys[1], m.labelValue("tenant", arg2.Tenant)))
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1 := m.next.Handle(arg1, arg2)
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result1 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1
+}
+func (m *monitoringLabeledServiceOC) Ping(arg1 alias1.Context) error {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.pingTags, m.pingOperation, alias4.Insert(m.labelTagKeys[0], m.labelValue("region", alias5.Region(arg1))), alias4.Insert(m.labelTagKeys[1], ""))
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1 := m.next.Ping(arg1)
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result1 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1
+}
@@ -0,0 +1,60 @@
+package examplesmws_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples"
+	"github.com/Bo0mer/gentools/cmd/mongen/examples/examplesmws"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+func TestLabeledServiceOCLeavesOutInvalidLabels(t *testing.T) {
+	totalOps := stats.Int64("labeled_oc_test/total_ops", "", stats.UnitDimensionless)
+	var keys []tag.Key
+	for _, label := range examplesmws.MonitoringLabeledServiceOCLabels {
+		keys = append(keys, tag.MustNewKey(label))
+	}
+	totalOpsView := &view.View{Measure: totalOps, Aggregation: view.Count(), TagKeys: keys}
+	if err := view.Register(totalOpsView); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { view.Unregister(totalOpsView) })
+	svc := examplesmws.NewMonitoringLabeledServiceOC(service{},
+		totalOps,
+		stats.Int64("labeled_oc_test/failed_ops", "", stats.UnitDimensionless),
+		stats.Float64("labeled_oc_test/ops_duration_seconds", "", stats.UnitSeconds),
+		nil, 0)
+
+	svc.Handle(context.Background(), examples.Request{Tenant: "tenant"})
+	// The tenant is not a valid tag value.
+	svc.Handle(context.Background(), examples.Request{Tenant: "tenant\n"})
+
+	rows, err := view.RetrieveData(totalOpsView.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The region is empty, and empty tags are not recorded.
+	want := map[string]bool{
+		"operation=handle tenant=tenant": true,
+		"operation=handle":               true,
+	}
+	got := make(map[string]bool)
+	for _, row := range rows {
+		var tags []string
+		for _, rowTag := range row.Tags {
+			tags = append(tags, rowTag.Key.Name()+"="+rowTag.Value)
+		}
+		got[strings.Join(tags, " ")] = true
+		if count := row.Data.(*view.CountData).Value; count != 1 {
+			t.Errorf("got %d calls with tags %v, want 1", count, tags)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got tags %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.MetricsService
+// Source-Hash: sha256:87d9fc7780eb14542a33a420c881d7bbaedadf412995852b630a3d4be5ced229
+// Generator: mongen v2.1.0
+// Args: -in-flight=true -metrics=true -outcome=true -output-dir . -o monitoring_metrics_service.go .. MetricsService go-kit
+package examplesmws
+
+import (
+	alias6 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/go-kit/kit/metrics"
+	alias4 "github.com/go-kit/kit/metrics/prometheus"
+	alias5 "github.com/prometheus/client_golang/prometheus"
+	alias3 "time"
+)
+
+type monitoringMetricsService struct {
+	next            alias1.MetricsService
+	doWorkOperation monitoringMetricsServiceOperation
+}
+type monitoringMetricsServiceOperation struct {
+	totalOps           alias2.Counter
+	failedOps          alias2.Counter
+	opsDurationSuccess alias2.Histogram
+	opsDurationError   alias2.Histogram
+	inFlightOps        alias2.Gauge
+}
+
+// NewMonitoringMetricsService creates new monitoring middleware.
+func NewMonitoringMetricsService(next alias1.MetricsService, totalOps alias2.Counter, failedOps alias2.Counter, opsDuration alias2.Histogram, inFlightOps alias2.Gauge) alias1.MetricsService {
+	m := &monitoringMetricsService{next: next}
+	bind := func(operation string, duration alias2.Histogram) monitoringMetricsServiceOperation {
+		return monitoringMetricsServiceOperation{totalOps: totalOps.With("operation", operation), failedOps: failedOps.With("operation", operation), opsDurationSuccess: duration.With("operation", operation, "outcome", "success"), opsDurationError: duration.With("operation", operation, "outcome", "error"), inFlightOps: inFlightOps.With("operation", operation)}
+	}
+	m.doWorkOperation = bind("do_work", opsDuration)
+	return m
+}
+
+// MonitoringMetricsServiceMetrics holds the metrics recorded by the monitoring middleware.
+type MonitoringMetricsServiceMetrics struct {
+	TotalOps    alias2.Counter
+	FailedOps   alias2.Counter
+	OpsDuration alias2.Histogram
+	InFlightOps alias2.Gauge
+}
+
+// NewMonitoringMetricsServiceMetrics creates Prometheus metrics with the specified namespace and registers
+// them with the default registerer.
+func NewMonitoringMetricsServiceMetrics(namespace string) *MonitoringMetricsServiceMetrics {
+	return &MonitoringMetricsServiceMetrics{TotalOps: alias4.NewCounterFrom(alias5.CounterOpts{Namespace: namespace, Name: "total_ops", Help: "Total number of operations."}, []string{"operation"}), FailedOps: alias4.NewCounterFrom(alias5.CounterOpts{Namespace: namespace, Name: "failed_ops", Help: "Number of failed operations."}, []string{"operation"}), OpsDuration: alias4.NewHistogramFrom(alias5.HistogramOpts{Namespace: namespace, Name: "ops_duration_seconds", Help: "Duration of operations in seconds.", Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}}, []string{"operation", "outcome"}), InFlightOps: alias4.NewGaugeFrom(alias5.GaugeOpts{Namespace: namespace, Name: "in_flight_ops", Help: "Number of operations in progress."}, []string{"operation"})}
+}
+
+// Wrap wraps next with monitoring middleware created by NewMonitoringMetricsService that records the metrics.
+func (ms *MonitoringMetricsServiceMetrics) Wrap(next alias1.MetricsService) alias1.MetricsService {
+	return NewMonitoringMetricsService(next, ms.TotalOps, ms.FailedOps, ms.OpsDuration, ms.InFlightOps)
+}
+func (m *monitoringMetricsService) DoWork(arg1 alias6.Context, arg2 int) (string, error) {
+	m.doWorkOperation.totalOps.Add(1)
+	m.doWorkOperation.inFlightOps.Add(1)
+	defer m.doWorkOperation.inFlightOps.Add(-1)
+	_start := alias3.Now()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	_opsDuration := m.doWorkOperation.opsDurationSuccess
+	if result2 != nil {
+		_opsDuration = m.doWorkOperation.opsDurationError
+	}
+	_opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.doWorkOperation.failedOps.Add(1)
+	}
+	return result1, result2
+}
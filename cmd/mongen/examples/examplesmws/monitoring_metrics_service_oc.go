@@ -0,0 +1,103 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.MetricsService
+// Source-Hash: sha256:87d9fc7780eb14542a33a420c881d7bbaedadf412995852b630a3d4be5ced229
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringMetricsServiceOC -in-flight=true -metrics=true -outcome=true -type=monitoringMetricsServiceOC -output-dir . -o monitoring_metrics_service_oc.go .. MetricsService opencensus
+package examplesmws
+
+import (
+	alias1 "context"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias3 "go.opencensus.io/stats"
+	alias6 "go.opencensus.io/stats/view"
+	alias4 "go.opencensus.io/tag"
+	alias2 "time"
+)
+
+type monitoringMetricsServiceOC struct {
+	next            alias5.MetricsService
+	totalOps        *alias3.Int64Measure
+	failedOps       *alias3.Int64Measure
+	opsDuration     *alias3.Float64Measure
+	ctxFunc         func(alias1.Context) alias1.Context
+	inFlightOps     *alias3.Int64Measure
+	outcomeTagKey   alias4.Key
+	doWorkOperation alias4.Mutator
+	doWorkTags      *alias4.Map
+}
+
+// NewMonitoringMetricsServiceOC creates new monitoring middleware.
+func NewMonitoringMetricsServiceOC(next alias5.MetricsService, totalOps *alias3.Int64Measure, failedOps *alias3.Int64Measure, opsDuration *alias3.Float64Measure, ctxFunc func(alias1.Context) alias1.Context, inFlightOps *alias3.Int64Measure) alias5.MetricsService {
+	m := &monitoringMetricsServiceOC{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, ctxFunc: ctxFunc, inFlightOps: inFlightOps, outcomeTagKey: alias4.MustNewKey("outcome")}
+	operationTagKey := alias4.MustNewKey("operation")
+	bind := func(operation string) (alias4.Mutator, *alias4.Map) {
+		mutator := alias4.Insert(operationTagKey, operation)
+		ctx, _ := alias4.New(alias1.Background(), mutator)
+		return mutator, alias4.FromContext(ctx)
+	}
+	m.doWorkOperation, m.doWorkTags = bind("do_work")
+	return m
+}
+
+// tagContext returns the context tagged with the mutators, the first of which tags the operation.
+// A context without tags is given the operationTags when the operation is its only tag, which saves copying them.
+// The mutators that fail, e.g. because of invalid label values, are left out.
+func (m *monitoringMetricsServiceOC) tagContext(ctx alias1.Context, operationTags *alias4.Map, mutators ...alias4.Mutator) alias1.Context {
+	if len(mutators) == 1 && alias4.FromContext(ctx) == nil {
+		return alias4.NewContext(ctx, operationTags)
+	}
+	if taggedCtx, err := alias4.New(ctx, mutators...); err == nil {
+		return taggedCtx
+	}
+	for _, mutator := range mutators {
+		if taggedCtx, err := alias4.New(ctx, mutator); err == nil {
+			ctx = taggedCtx
+		}
+	}
+	return ctx
+}
+
+// MonitoringMetricsServiceOCMetrics holds the metrics recorded by the monitoring middleware.
+type MonitoringMetricsServiceOCMetrics struct {
+	TotalOps    *alias3.Int64Measure
+	FailedOps   *alias3.Int64Measure
+	OpsDuration *alias3.Float64Measure
+	InFlightOps *alias3.Int64Measure
+	Views       []*alias6.View
+}
+
+// NewMonitoringMetricsServiceOCMetrics creates measures with names prefixed by the specified namespace
+// and the views that aggregate them. The views must be registered with view.Register.
+func NewMonitoringMetricsServiceOCMetrics(namespace string) *MonitoringMetricsServiceOCMetrics {
+	ms := &MonitoringMetricsServiceOCMetrics{TotalOps: alias3.Int64(namespace+"/total_ops", "Total number of operations.", alias3.UnitDimensionless), FailedOps: alias3.Int64(namespace+"/failed_ops", "Number of failed operations.", alias3.UnitDimensionless), OpsDuration: alias3.Float64(namespace+"/ops_duration_seconds", "Duration of operations in seconds.", alias3.UnitSeconds), InFlightOps: alias3.Int64(namespace+"/in_flight_ops", "Number of operations in progress.", alias3.UnitDimensionless)}
+	ms.Views = []*alias6.View{{Name: namespace + "/total_ops", Description: "Total number of operations.", Measure: ms.TotalOps, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Count()}, {Name: namespace + "/failed_ops", Description: "Number of failed operations.", Measure: ms.FailedOps, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Count()}, {Name: namespace + "/ops_duration_seconds", Description: "Duration of operations in seconds.", Measure: ms.OpsDuration, TagKeys: []alias4.Key{alias4.MustNewKey("operation"), alias4.MustNewKey("outcome")}, Aggregation: alias6.Distribution(.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10)}, {Name: namespace + "/in_flight_ops", Description: "Number of operations in progress.", Measure: ms.InFlightOps, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Sum()}}
+	return ms
+}
+
+// Wrap wraps next with monitoring middleware created by NewMonitoringMetricsServiceOC that records the metrics.
+func (ms *MonitoringMetricsServiceOCMetrics) Wrap(next alias5.MetricsService, ctxFunc func(alias1.Context) alias1.Context) alias5.MetricsService {
+	return NewMonitoringMetricsServiceOC(next, ms.TotalOps, ms.FailedOps, ms.OpsDuration, ctxFunc, ms.InFlightOps)
+}
+func (m *monitoringMetricsServiceOC) DoWork(arg1 alias1.Context, arg2 int) (string, error) {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.doWorkTags, m.doWorkOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	alias3.Record(ctx, m.inFlightOps.M(1))
+	defer alias3.Record(ctx, m.inFlightOps.M(-1))
+	_start := alias2.Now()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	_outcome := "success"
+	if result2 != nil {
+		_outcome = "error"
+	}
+	if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.outcomeTagKey, _outcome)}, m.opsDuration.M(alias2.Since(_start).Seconds())); err != nil {
+		alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	}
+	if result2 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1, result2
+}
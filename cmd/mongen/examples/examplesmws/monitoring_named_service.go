@@ -0,0 +1,118 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.NamedService
+// Source-Hash: sha256:df19c7af4047ebaac7c78236fa5ddf94ab768239412d42823365aa5d4cd337b3
+// Generator: mongen v2.1.0
+// Args: -classify-errors=true -key-case=camel -name-template={{.PackageName}}.{{.Name}} -output-dir . -o monitoring_named_service.go .. NamedService opencensus
+package examplesmws
+
+import (
+	alias1 "context"
+	alias7 "errors"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias3 "go.opencensus.io/stats"
+	alias4 "go.opencensus.io/tag"
+	alias6 "sync"
+	alias2 "time"
+)
+
+type monitoringNamedService struct {
+	next                alias5.NamedService
+	totalOps            *alias3.Int64Measure
+	failedOps           *alias3.Int64Measure
+	opsDuration         *alias3.Float64Measure
+	ctxFunc             func(alias1.Context) alias1.Context
+	errorClassTagKey    alias4.Key
+	classifyError       func(error) string
+	maxLabelValues      int
+	labelsMu            alias6.Mutex
+	labelValues         map[string]map[string]bool
+	chargeCardOperation alias4.Mutator
+	chargeCardTags      *alias4.Map
+}
+
+// NewMonitoringNamedService creates new monitoring middleware.
+func NewMonitoringNamedService(next alias5.NamedService, totalOps *alias3.Int64Measure, failedOps *alias3.Int64Measure, opsDuration *alias3.Float64Measure, ctxFunc func(alias1.Context) alias1.Context, maxLabelValues int, classifyError func(error) string) alias5.NamedService {
+	m := &monitoringNamedService{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, ctxFunc: ctxFunc, maxLabelValues: maxLabelValues, labelValues: map[string]map[string]bool{}, classifyError: classifyError, errorClassTagKey: alias4.MustNewKey("errorClass")}
+	operationTagKey := alias4.MustNewKey("operation")
+	bind := func(operation string) (alias4.Mutator, *alias4.Map) {
+		mutator := alias4.Insert(operationTagKey, operation)
+		ctx, _ := alias4.New(alias1.Background(), mutator)
+		return mutator, alias4.FromContext(ctx)
+	}
+	m.chargeCardOperation, m.chargeCardTags = bind("examples.charge_card")
+	return m
+}
+
+// tagContext returns the context tagged with the mutators, the first of which tags the operation.
+// A context without tags is given the operationTags when the operation is its only tag, which saves copying them.
+// The mutators that fail, e.g. because of invalid label values, are left out.
+func (m *monitoringNamedService) tagContext(ctx alias1.Context, operationTags *alias4.Map, mutators ...alias4.Mutator) alias1.Context {
+	if len(mutators) == 1 && alias4.FromContext(ctx) == nil {
+		return alias4.NewContext(ctx, operationTags)
+	}
+	if taggedCtx, err := alias4.New(ctx, mutators...); err == nil {
+		return taggedCtx
+	}
+	for _, mutator := range mutators {
+		if taggedCtx, err := alias4.New(ctx, mutator); err == nil {
+			ctx = taggedCtx
+		}
+	}
+	return ctx
+}
+
+// errorClass returns the class of the error recorded in the errorClass label.
+func (m *monitoringNamedService) errorClass(err error) string {
+	if err == nil {
+		return "failed"
+	}
+	if m.classifyError != nil {
+		return m.labelValue("errorClass", m.classifyError(err))
+	}
+	switch {
+	case alias7.Is(err, alias1.Canceled):
+		return "canceled"
+	case alias7.Is(err, alias1.DeadlineExceeded):
+		return "timeout"
+	}
+	return "error"
+}
+
+// labelValue returns the value of the label, or "other" once the label has maxLabelValues distinct values.
+func (m *monitoringNamedService) labelValue(label, value string) string {
+	if m.maxLabelValues <= 0 {
+		return value
+	}
+	m.labelsMu.Lock()
+	defer m.labelsMu.Unlock()
+	values := m.labelValues[label]
+	if values[value] {
+		return value
+	}
+	if len(values) >= m.maxLabelValues {
+		return "other"
+	}
+	if values == nil {
+		values = make(map[string]bool)
+		m.labelValues[label] = values
+	}
+	values[value] = true
+	return value
+}
+func (m *monitoringNamedService) ChargeCard(arg1 alias1.Context, arg2 int) error {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.chargeCardTags, m.chargeCardOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1 := m.next.ChargeCard(arg1, arg2)
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result1 != nil {
+		if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.errorClassTagKey, m.errorClass(result1))}, m.failedOps.M(1)); err != nil {
+			alias3.Record(ctx, m.failedOps.M(1))
+		}
+	}
+	return result1
+}
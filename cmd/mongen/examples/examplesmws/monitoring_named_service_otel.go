@@ -0,0 +1,59 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.NamedService
+// Source-Hash: sha256:df19c7af4047ebaac7c78236fa5ddf94ab768239412d42823365aa5d4cd337b3
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringNamedServiceOtel -name-case=kebab -type=monitoringNamedServiceOtel -output-dir . -o monitoring_named_service_otel.go .. NamedService otel
+package examplesmws
+
+import (
+	alias1 "context"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias4 "go.opentelemetry.io/otel/attribute"
+	alias3 "go.opentelemetry.io/otel/metric"
+	alias2 "time"
+)
+
+type monitoringNamedServiceOtel struct {
+	next                   alias5.NamedService
+	totalOps               alias3.Int64Counter
+	failedOps              alias3.Int64Counter
+	opsDuration            alias3.Float64Histogram
+	chargeCardAttrs        alias3.MeasurementOption
+	chargeCardSuccessAttrs alias3.MeasurementOption
+	chargeCardErrorAttrs   alias3.MeasurementOption
+}
+
+// NewMonitoringNamedServiceOtel creates new monitoring middleware.
+func NewMonitoringNamedServiceOtel(next alias5.NamedService, totalOps, failedOps alias3.Int64Counter, opsDuration alias3.Float64Histogram) alias5.NamedService {
+	return &monitoringNamedServiceOtel{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, chargeCardAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "charge-card"))), chargeCardSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "charge-card"), alias4.String("outcome", "success"))), chargeCardErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "charge-card"), alias4.String("outcome", "error")))}
+}
+
+// NewMonitoringNamedServiceOtelFromMeter creates new monitoring middleware with instruments created by meter.
+func NewMonitoringNamedServiceOtelFromMeter(next alias5.NamedService, meter alias3.Meter) (alias5.NamedService, error) {
+	totalOps, err := meter.Int64Counter("total_ops", alias3.WithDescription("Total number of operations."))
+	if err != nil {
+		return nil, err
+	}
+	failedOps, err := meter.Int64Counter("failed_ops", alias3.WithDescription("Number of failed operations."))
+	if err != nil {
+		return nil, err
+	}
+	opsDuration, err := meter.Float64Histogram("ops_duration", alias3.WithDescription("Duration of operations."), alias3.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return NewMonitoringNamedServiceOtel(next, totalOps, failedOps, opsDuration), nil
+}
+func (m *monitoringNamedServiceOtel) ChargeCard(arg1 alias1.Context, arg2 int) error {
+	ctx := arg1
+	m.totalOps.Add(ctx, 1, m.chargeCardAttrs)
+	_start := alias2.Now()
+	result1 := m.next.ChargeCard(arg1, arg2)
+	_outcome := m.chargeCardSuccessAttrs
+	if result1 != nil {
+		_outcome = m.chargeCardErrorAttrs
+		m.failedOps.Add(ctx, 1, m.chargeCardErrorAttrs)
+	}
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), _outcome)
+	return result1
+}
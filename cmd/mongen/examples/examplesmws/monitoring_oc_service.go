@@ -1,42 +1,73 @@
 // Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.OCService
+// Source-Hash: sha256:593324d5edb6432999cc63f2241dfd8c874579975605f7baa97e7c8198666bdf
+// Generator: mongen v2.1.0
+// Args: -output-dir . -o monitoring_oc_service.go .. OCService opencensus
 package examplesmws
 
 import (
 	alias1 "context"
 	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
-	alias2 "go.opencensus.io/stats"
-	alias3 "go.opencensus.io/tag"
-	alias4 "time"
+	alias3 "go.opencensus.io/stats"
+	alias4 "go.opencensus.io/tag"
+	alias2 "time"
 )
 
 type monitoringOCService struct {
-	next        alias5.OCService
-	totalOps    *alias2.Int64Measure
-	failedOps   *alias2.Int64Measure
-	opsDuration *alias2.Float64Measure
-	ctxFunc     func(alias1.Context) alias1.Context
+	next               alias5.OCService
+	totalOps           *alias3.Int64Measure
+	failedOps          *alias3.Int64Measure
+	opsDuration        *alias3.Float64Measure
+	ctxFunc            func(alias1.Context) alias1.Context
+	doWorkOperation    alias4.Mutator
+	doWorkTags         *alias4.Map
+	doWorkCtxOperation alias4.Mutator
+	doWorkCtxTags      *alias4.Map
 }
 
 // NewMonitoringOCService creates new monitoring middleware.
-func NewMonitoringOCService(next alias5.OCService, totalOps *alias2.Int64Measure, failedOps *alias2.Int64Measure, opsDuration *alias2.Float64Measure, ctxFunc func(alias1.Context) alias1.Context) alias5.OCService {
-	return &monitoringOCService{next, totalOps, failedOps, opsDuration, ctxFunc}
+func NewMonitoringOCService(next alias5.OCService, totalOps *alias3.Int64Measure, failedOps *alias3.Int64Measure, opsDuration *alias3.Float64Measure, ctxFunc func(alias1.Context) alias1.Context) alias5.OCService {
+	m := &monitoringOCService{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, ctxFunc: ctxFunc}
+	operationTagKey := alias4.MustNewKey("operation")
+	bind := func(operation string) (alias4.Mutator, *alias4.Map) {
+		mutator := alias4.Insert(operationTagKey, operation)
+		ctx, _ := alias4.New(alias1.Background(), mutator)
+		return mutator, alias4.FromContext(ctx)
+	}
+	m.doWorkOperation, m.doWorkTags = bind("do_work")
+	m.doWorkCtxOperation, m.doWorkCtxTags = bind("do_work_ctx")
+	return m
+}
+
+// tagContext returns the context tagged with the mutators, the first of which tags the operation.
+// A context without tags is given the operationTags when the operation is its only tag, which saves copying them.
+// The mutators that fail, e.g. because of invalid label values, are left out.
+func (m *monitoringOCService) tagContext(ctx alias1.Context, operationTags *alias4.Map, mutators ...alias4.Mutator) alias1.Context {
+	if len(mutators) == 1 && alias4.FromContext(ctx) == nil {
+		return alias4.NewContext(ctx, operationTags)
+	}
+	if taggedCtx, err := alias4.New(ctx, mutators...); err == nil {
+		return taggedCtx
+	}
+	for _, mutator := range mutators {
+		if taggedCtx, err := alias4.New(ctx, mutator); err == nil {
+			ctx = taggedCtx
+		}
+	}
+	return ctx
 }
 func (m *monitoringOCService) DoWork(arg1 int, arg2 string) (string, error) {
 	ctx := alias1.Background()
 	if m.ctxFunc != nil {
 		ctx = m.ctxFunc(ctx)
 	}
-	tagKey := alias3.MustNewKey("operation")
-	var err error
-	if ctx, err = alias3.New(ctx, alias3.Insert(tagKey, "do_work")); err != nil {
-		panic(err)
-	}
-	alias2.Record(ctx, m.totalOps.M(1))
-	start := alias4.Now()
+	ctx = m.tagContext(ctx, m.doWorkTags, m.doWorkOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
 	result1, result2 := m.next.DoWork(arg1, arg2)
-	alias2.Record(ctx, m.opsDuration.M(alias4.Since(start).Seconds()))
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
 	if result2 != nil {
-		alias2.Record(ctx, m.failedOps.M(1))
+		alias3.Record(ctx, m.failedOps.M(1))
 	}
 	return result1, result2
 }
@@ -45,17 +76,13 @@ func (m *monitoringOCService) DoWorkCtx(arg1 alias1.Context, arg2 int, arg3 stri
 	if m.ctxFunc != nil {
 		ctx = m.ctxFunc(ctx)
 	}
-	tagKey := alias3.MustNewKey("operation")
-	var err error
-	if ctx, err = alias3.New(ctx, alias3.Insert(tagKey, "do_work_ctx")); err != nil {
-		panic(err)
-	}
-	alias2.Record(ctx, m.totalOps.M(1))
-	start := alias4.Now()
+	ctx = m.tagContext(ctx, m.doWorkCtxTags, m.doWorkCtxOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
 	result1, result2 := m.next.DoWorkCtx(arg1, arg2, arg3)
-	alias2.Record(ctx, m.opsDuration.M(alias4.Since(start).Seconds()))
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
 	if result2 != nil {
-		alias2.Record(ctx, m.failedOps.M(1))
+		alias3.Record(ctx, m.failedOps.M(1))
 	}
 	return result1, result2
 }
@@ -0,0 +1,71 @@
+package examplesmws_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples/examplesmws"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+func TestOCServiceTagsTheOperation(t *testing.T) {
+	serviceKey := tag.MustNewKey("service")
+	for _, tc := range []struct {
+		name    string
+		ctxFunc func(context.Context) context.Context
+		want    string
+	}{
+		// The operation is the only tag of the context, so its precomputed
+		// tags are used.
+		{name: "context without tags", want: "operation=do_work_ctx"},
+		{
+			name: "context with tags",
+			ctxFunc: func(ctx context.Context) context.Context {
+				ctx, _ = tag.New(ctx, tag.Insert(serviceKey, "payments"))
+				return ctx
+			},
+			want: "operation=do_work_ctx service=payments",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			totalOps := stats.Int64("oc_test/total_ops", "", stats.UnitDimensionless)
+			totalOpsView := &view.View{
+				Measure:     totalOps,
+				Aggregation: view.Count(),
+				TagKeys:     []tag.Key{tag.MustNewKey("operation"), serviceKey},
+			}
+			if err := view.Register(totalOpsView); err != nil {
+				t.Fatal(err)
+			}
+			defer view.Unregister(totalOpsView)
+			svc := examplesmws.NewMonitoringOCService(service{},
+				totalOps,
+				stats.Int64("oc_test/failed_ops", "", stats.UnitDimensionless),
+				stats.Float64("oc_test/ops_duration_seconds", "", stats.UnitSeconds),
+				tc.ctxFunc)
+
+			svc.DoWorkCtx(context.Background(), 1, "")
+			svc.DoWorkCtx(context.Background(), 2, "")
+
+			rows, err := view.RetrieveData(totalOpsView.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]int64)
+			for _, row := range rows {
+				var tags []string
+				for _, rowTag := range row.Tags {
+					tags = append(tags, rowTag.Key.Name()+"="+rowTag.Value)
+				}
+				got[strings.Join(tags, " ")] = row.Data.(*view.CountData).Value
+			}
+			if want := map[string]int64{tc.want: 2}; !reflect.DeepEqual(got, want) {
+				t.Errorf("got calls by tags %v, want %v", got, want)
+			}
+		})
+	}
+}
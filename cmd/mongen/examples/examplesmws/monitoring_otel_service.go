@@ -0,0 +1,75 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.OtelService
+// Source-Hash: sha256:832bae0601fbc85e79146f66a59d4387f6f94b4c9dfe7fd350e3fc1317ffb876
+// Generator: mongen v2.1.0
+// Args: -output-dir . -o monitoring_otel_service.go .. OtelService otel
+package examplesmws
+
+import (
+	alias1 "context"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias4 "go.opentelemetry.io/otel/attribute"
+	alias3 "go.opentelemetry.io/otel/metric"
+	alias2 "time"
+)
+
+type monitoringOtelService struct {
+	next                  alias5.OtelService
+	totalOps              alias3.Int64Counter
+	failedOps             alias3.Int64Counter
+	opsDuration           alias3.Float64Histogram
+	doWorkAttrs           alias3.MeasurementOption
+	doWorkSuccessAttrs    alias3.MeasurementOption
+	doWorkErrorAttrs      alias3.MeasurementOption
+	doWorkCtxAttrs        alias3.MeasurementOption
+	doWorkCtxSuccessAttrs alias3.MeasurementOption
+	doWorkCtxErrorAttrs   alias3.MeasurementOption
+}
+
+// NewMonitoringOtelService creates new monitoring middleware.
+func NewMonitoringOtelService(next alias5.OtelService, totalOps, failedOps alias3.Int64Counter, opsDuration alias3.Float64Histogram) alias5.OtelService {
+	return &monitoringOtelService{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, doWorkAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "do_work"))), doWorkSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "do_work"), alias4.String("outcome", "success"))), doWorkErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "do_work"), alias4.String("outcome", "error"))), doWorkCtxAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "do_work_ctx"))), doWorkCtxSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "do_work_ctx"), alias4.String("outcome", "success"))), doWorkCtxErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "do_work_ctx"), alias4.String("outcome", "error")))}
+}
+
+// NewMonitoringOtelServiceFromMeter creates new monitoring middleware with instruments created by meter.
+func NewMonitoringOtelServiceFromMeter(next alias5.OtelService, meter alias3.Meter) (alias5.OtelService, error) {
+	totalOps, err := meter.Int64Counter("total_ops", alias3.WithDescription("Total number of operations."))
+	if err != nil {
+		return nil, err
+	}
+	failedOps, err := meter.Int64Counter("failed_ops", alias3.WithDescription("Number of failed operations."))
+	if err != nil {
+		return nil, err
+	}
+	opsDuration, err := meter.Float64Histogram("ops_duration", alias3.WithDescription("Duration of operations."), alias3.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return NewMonitoringOtelService(next, totalOps, failedOps, opsDuration), nil
+}
+func (m *monitoringOtelService) DoWork(arg1 int, arg2 string) (string, error) {
+	ctx := alias1.Background()
+	m.totalOps.Add(ctx, 1, m.doWorkAttrs)
+	_start := alias2.Now()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	_outcome := m.doWorkSuccessAttrs
+	if result2 != nil {
+		_outcome = m.doWorkErrorAttrs
+		m.failedOps.Add(ctx, 1, m.doWorkErrorAttrs)
+	}
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), _outcome)
+	return result1, result2
+}
+func (m *monitoringOtelService) DoWorkCtx(arg1 alias1.Context, arg2 int, arg3 string) (string, error) {
+	ctx := arg1
+	m.totalOps.Add(ctx, 1, m.doWorkCtxAttrs)
+	_start := alias2.Now()
+	result1, result2 := m.next.DoWorkCtx(arg1, arg2, arg3)
+	_outcome := m.doWorkCtxSuccessAttrs
+	if result2 != nil {
+		_outcome = m.doWorkCtxErrorAttrs
+		m.failedOps.Add(ctx, 1, m.doWorkCtxErrorAttrs)
+	}
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), _outcome)
+	return result1, result2
+}
@@ -0,0 +1,67 @@
+package examplesmws_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples/examplesmws"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// failingService fails DoWorkCtx and succeeds DoWork.
+type failingService struct{ service }
+
+func (failingService) DoWorkCtx(context.Context, int, string) (string, error) {
+	return "", errors.New("failed")
+}
+
+// counterRecorder records the attributes of every addition.
+type counterRecorder struct {
+	noop.Int64Counter
+	attrs []string
+}
+
+func (c *counterRecorder) Add(_ context.Context, _ int64, options ...metric.AddOption) {
+	c.attrs = append(c.attrs, encode(metric.NewAddConfig(options).Attributes()))
+}
+
+// histogramRecorder records the attributes of every recording.
+type histogramRecorder struct {
+	noop.Float64Histogram
+	attrs []string
+}
+
+func (h *histogramRecorder) Record(_ context.Context, _ float64, options ...metric.RecordOption) {
+	h.attrs = append(h.attrs, encode(metric.NewRecordConfig(options).Attributes()))
+}
+
+func encode(set attribute.Set) string {
+	return set.Encoded(attribute.DefaultEncoder())
+}
+
+func TestOtelServiceRecordsTheAttributesOfTheOperationAndOutcome(t *testing.T) {
+	totalOps, failedOps := new(counterRecorder), new(counterRecorder)
+	opsDuration := new(histogramRecorder)
+	svc := examplesmws.NewMonitoringOtelService(failingService{}, totalOps, failedOps, opsDuration)
+
+	svc.DoWork(1, "a")
+	svc.DoWorkCtx(context.Background(), 1, "a")
+	svc.DoWork(2, "b")
+
+	want := []string{"operation=do_work", "operation=do_work_ctx", "operation=do_work"}
+	if !reflect.DeepEqual(totalOps.attrs, want) {
+		t.Errorf("got total ops with %q, want %q", totalOps.attrs, want)
+	}
+	want = []string{"operation=do_work_ctx,outcome=error"}
+	if !reflect.DeepEqual(failedOps.attrs, want) {
+		t.Errorf("got failed ops with %q, want %q", failedOps.attrs, want)
+	}
+	want = []string{"operation=do_work,outcome=success", "operation=do_work_ctx,outcome=error", "operation=do_work,outcome=success"}
+	if !reflect.DeepEqual(opsDuration.attrs, want) {
+		t.Errorf("got durations with %q, want %q", opsDuration.attrs, want)
+	}
+}
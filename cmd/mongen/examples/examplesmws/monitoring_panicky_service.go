@@ -0,0 +1,145 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.PanickyService
+// Source-Hash: sha256:994c340b1d47ccbff1663c73efffcbeacbcd9a1b03549657ef084fc55d2af0b6
+// Generator: mongen v2.1.0
+// Args: -classify-errors=true -in-flight=true -outcome=true -panics=true -output-dir . -o monitoring_panicky_service.go .. PanickyService go-kit
+package examplesmws
+
+import (
+	alias6 "context"
+	alias5 "errors"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/go-kit/kit/metrics"
+	alias4 "sync"
+	alias3 "time"
+)
+
+type monitoringPanickyService struct {
+	next            alias1.PanickyService
+	classifyError   func(error) string
+	maxLabelValues  int
+	labelsMu        alias4.Mutex
+	labelValues     map[string]map[string]bool
+	doWorkOperation monitoringPanickyServiceOperation
+	notifyOperation monitoringPanickyServiceOperation
+	closeOperation  monitoringPanickyServiceOperation
+}
+type monitoringPanickyServiceOperation struct {
+	totalOps           alias2.Counter
+	failedOps          alias2.Counter
+	opsDurationSuccess alias2.Histogram
+	opsDurationError   alias2.Histogram
+	inFlightOps        alias2.Gauge
+}
+
+// NewMonitoringPanickyService creates new monitoring middleware.
+func NewMonitoringPanickyService(next alias1.PanickyService, totalOps alias2.Counter, failedOps alias2.Counter, opsDuration alias2.Histogram, inFlightOps alias2.Gauge, maxLabelValues int, classifyError func(error) string) alias1.PanickyService {
+	m := &monitoringPanickyService{next: next, maxLabelValues: maxLabelValues, labelValues: map[string]map[string]bool{}, classifyError: classifyError}
+	bind := func(operation string, duration alias2.Histogram) monitoringPanickyServiceOperation {
+		return monitoringPanickyServiceOperation{totalOps: totalOps.With("operation", operation), failedOps: failedOps.With("operation", operation), opsDurationSuccess: duration.With("operation", operation, "outcome", "success"), opsDurationError: duration.With("operation", operation, "outcome", "error"), inFlightOps: inFlightOps.With("operation", operation)}
+	}
+	m.doWorkOperation = bind("do_work", opsDuration)
+	m.notifyOperation = bind("notify", opsDuration)
+	m.closeOperation = bind("close", opsDuration)
+	return m
+}
+
+// errorClass returns the class of the error recorded in the error_class label.
+func (m *monitoringPanickyService) errorClass(err error) string {
+	if err == nil {
+		return "failed"
+	}
+	if m.classifyError != nil {
+		return m.labelValue("error_class", m.classifyError(err))
+	}
+	switch {
+	case alias5.Is(err, alias6.Canceled):
+		return "canceled"
+	case alias5.Is(err, alias6.DeadlineExceeded):
+		return "timeout"
+	}
+	return "error"
+}
+
+// labelValue returns the value of the label, or "other" once the label has maxLabelValues distinct values.
+func (m *monitoringPanickyService) labelValue(label, value string) string {
+	if m.maxLabelValues <= 0 {
+		return value
+	}
+	m.labelsMu.Lock()
+	defer m.labelsMu.Unlock()
+	values := m.labelValues[label]
+	if values[value] {
+		return value
+	}
+	if len(values) >= m.maxLabelValues {
+		return "other"
+	}
+	if values == nil {
+		values = make(map[string]bool)
+		m.labelValues[label] = values
+	}
+	values[value] = true
+	return value
+}
+func (m *monitoringPanickyService) DoWork(arg1 alias6.Context, arg2 string) (int, error) {
+	m.doWorkOperation.totalOps.Add(1)
+	m.doWorkOperation.inFlightOps.Add(1)
+	defer m.doWorkOperation.inFlightOps.Add(-1)
+	_start := alias3.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.doWorkOperation.opsDurationError.Observe(alias3.Since(_start).Seconds())
+			m.doWorkOperation.failedOps.With("error_class", "panic").Add(1)
+			panic(_panic)
+		}
+	}()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	_opsDuration := m.doWorkOperation.opsDurationSuccess
+	if result2 != nil {
+		_opsDuration = m.doWorkOperation.opsDurationError
+	}
+	_opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.doWorkOperation.failedOps.With("error_class", m.errorClass(result2)).Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringPanickyService) Notify(arg1 alias6.Context, arg2 []string) error {
+	m.notifyOperation.totalOps.Add(1)
+	m.notifyOperation.inFlightOps.Add(1)
+	defer m.notifyOperation.inFlightOps.Add(-1)
+	_start := alias3.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.notifyOperation.opsDurationError.Observe(alias3.Since(_start).Seconds())
+			m.notifyOperation.failedOps.With("error_class", "panic").Add(1)
+			panic(_panic)
+		}
+	}()
+	result1 := m.next.Notify(arg1, arg2)
+	_opsDuration := m.notifyOperation.opsDurationSuccess
+	if result1 != nil {
+		_opsDuration = m.notifyOperation.opsDurationError
+	}
+	_opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.notifyOperation.failedOps.With("error_class", m.errorClass(result1)).Add(1)
+	}
+	return result1
+}
+func (m *monitoringPanickyService) Close() {
+	m.closeOperation.totalOps.Add(1)
+	m.closeOperation.inFlightOps.Add(1)
+	defer m.closeOperation.inFlightOps.Add(-1)
+	_start := alias3.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.closeOperation.opsDurationError.Observe(alias3.Since(_start).Seconds())
+			m.closeOperation.failedOps.With("error_class", "panic").Add(1)
+			panic(_panic)
+		}
+	}()
+	m.next.Close()
+	m.closeOperation.opsDurationSuccess.Observe(alias3.Since(_start).Seconds())
+}
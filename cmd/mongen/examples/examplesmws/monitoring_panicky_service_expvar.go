@@ -0,0 +1,160 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.PanickyService
+// Source-Hash: sha256:994c340b1d47ccbff1663c73efffcbeacbcd9a1b03549657ef084fc55d2af0b6
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringPanickyServiceExpvar -panics=true -type=monitoringPanickyServiceExpvar -output-dir . -o monitoring_panicky_service_expvar.go .. PanickyService expvar
+package examplesmws
+
+import (
+	alias5 "context"
+	alias2 "expvar"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias4 "sync"
+	alias3 "time"
+)
+
+type monitoringPanickyServiceExpvar struct {
+	next           alias1.PanickyService
+	totalOps       *alias2.Map
+	failedOps      *alias2.Map
+	doWorkDuration *alias2.Map
+	notifyDuration *alias2.Map
+	closeDuration  *alias2.Map
+}
+
+// NewMonitoringPanickyServiceExpvar creates new monitoring middleware that publishes its variables with names starting with prefix.
+func NewMonitoringPanickyServiceExpvar(next alias1.PanickyService, prefix string) alias1.PanickyService {
+	totalOps := monitoringPanickyServiceExpvarPublish(prefix + "total_ops")
+	failedOps := monitoringPanickyServiceExpvarPublish(prefix + "failed_ops")
+	opsDuration := monitoringPanickyServiceExpvarPublish(prefix + "ops_duration_seconds")
+	totalOps.Add("do_work", 0)
+	failedOps.Add("do_work", 0)
+	totalOps.Add("notify", 0)
+	failedOps.Add("notify", 0)
+	totalOps.Add("close", 0)
+	failedOps.Add("close", 0)
+	return &monitoringPanickyServiceExpvar{next: next, totalOps: totalOps, failedOps: failedOps, doWorkDuration: monitoringPanickyServiceExpvarGet(opsDuration, "do_work"), notifyDuration: monitoringPanickyServiceExpvarGet(opsDuration, "notify"), closeDuration: monitoringPanickyServiceExpvarGet(opsDuration, "close")}
+}
+
+// monitoringPanickyServiceExpvarMu guards the lookup and publishing of the maps of monitoringPanickyServiceExpvar.
+var monitoringPanickyServiceExpvarMu alias4.Mutex
+
+// monitoringPanickyServiceExpvarPublish returns the map published under the name, publishing a new map if no variable is published under it yet.
+// It panics if a variable that is not a map is published under the name.
+func monitoringPanickyServiceExpvarPublish(name string) *alias2.Map {
+	monitoringPanickyServiceExpvarMu.Lock()
+	defer monitoringPanickyServiceExpvarMu.Unlock()
+	v := alias2.Get(name)
+	if v == nil {
+		return alias2.NewMap(name)
+	}
+	if m, ok := v.(*alias2.Map); ok {
+		return m
+	}
+	panic("expvar: " + name + " is published and is not an *expvar.Map")
+}
+
+// monitoringPanickyServiceExpvarGet returns the map stored under the key of m, storing a new map under it if there is none.
+func monitoringPanickyServiceExpvarGet(m *alias2.Map, key string) *alias2.Map {
+	monitoringPanickyServiceExpvarMu.Lock()
+	defer monitoringPanickyServiceExpvarMu.Unlock()
+	if sub, ok := m.Get(key).(*alias2.Map); ok {
+		return sub
+	}
+	sub := new(alias2.Map)
+	m.Set(key, sub)
+	return sub
+}
+
+// observe records the duration of an operation, in seconds, in its duration map.
+func (m *monitoringPanickyServiceExpvar) observe(duration *alias2.Map, seconds float64) {
+	duration.AddFloat("sum", seconds)
+	duration.Add("count", 1)
+	switch {
+	case seconds <= 0.005:
+		duration.Add("le_0.005", 1)
+		fallthrough
+	case seconds <= 0.01:
+		duration.Add("le_0.01", 1)
+		fallthrough
+	case seconds <= 0.025:
+		duration.Add("le_0.025", 1)
+		fallthrough
+	case seconds <= 0.05:
+		duration.Add("le_0.05", 1)
+		fallthrough
+	case seconds <= 0.1:
+		duration.Add("le_0.1", 1)
+		fallthrough
+	case seconds <= 0.25:
+		duration.Add("le_0.25", 1)
+		fallthrough
+	case seconds <= 0.5:
+		duration.Add("le_0.5", 1)
+		fallthrough
+	case seconds <= 1:
+		duration.Add("le_1", 1)
+		fallthrough
+	case seconds <= 2.5:
+		duration.Add("le_2.5", 1)
+		fallthrough
+	case seconds <= 5:
+		duration.Add("le_5", 1)
+		fallthrough
+	case seconds <= 10:
+		duration.Add("le_10", 1)
+		fallthrough
+	default:
+		duration.Add("le_+Inf", 1)
+	}
+}
+func (m *monitoringPanickyServiceExpvar) DoWork(arg1 alias5.Context, arg2 string) (int, error) {
+	_start := alias3.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.totalOps.Add("do_work", 1)
+			m.failedOps.Add("do_work", 1)
+			m.observe(m.doWorkDuration, alias3.Since(_start).Seconds())
+			panic(_panic)
+		}
+	}()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	m.totalOps.Add("do_work", 1)
+	if result2 != nil {
+		m.failedOps.Add("do_work", 1)
+	}
+	m.observe(m.doWorkDuration, alias3.Since(_start).Seconds())
+	return result1, result2
+}
+func (m *monitoringPanickyServiceExpvar) Notify(arg1 alias5.Context, arg2 []string) error {
+	_start := alias3.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.totalOps.Add("notify", 1)
+			m.failedOps.Add("notify", 1)
+			m.observe(m.notifyDuration, alias3.Since(_start).Seconds())
+			panic(_panic)
+		}
+	}()
+	result1 := m.next.Notify(arg1, arg2)
+	m.totalOps.Add("notify", 1)
+	if result1 != nil {
+		m.failedOps.Add("notify", 1)
+	}
+	m.observe(m.notifyDuration, alias3.Since(_start).Seconds())
+	return result1
+}
+func (m *monitoringPanickyServiceExpvar) Close() {
+	_start := alias3.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.totalOps.Add("close", 1)
+			m.failedOps.Add("close", 1)
+			m.observe(m.closeDuration, alias3.Since(_start).Seconds())
+			panic(_panic)
+		}
+	}()
+	m.next.Close()
+	m.totalOps.Add("close", 1)
+	m.observe(m.closeDuration, alias3.Since(_start).Seconds())
+}
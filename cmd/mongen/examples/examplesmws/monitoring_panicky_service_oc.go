@@ -0,0 +1,200 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.PanickyService
+// Source-Hash: sha256:994c340b1d47ccbff1663c73efffcbeacbcd9a1b03549657ef084fc55d2af0b6
+// Generator: mongen v2.1.0
+// Args: -classify-errors=true -constructor=NewMonitoringPanickyServiceOC -outcome=true -panics=true -type=monitoringPanickyServiceOC -output-dir . -o monitoring_panicky_service_oc.go .. PanickyService opencensus
+package examplesmws
+
+import (
+	alias1 "context"
+	alias7 "errors"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias3 "go.opencensus.io/stats"
+	alias4 "go.opencensus.io/tag"
+	alias6 "sync"
+	alias2 "time"
+)
+
+type monitoringPanickyServiceOC struct {
+	next             alias5.PanickyService
+	totalOps         *alias3.Int64Measure
+	failedOps        *alias3.Int64Measure
+	opsDuration      *alias3.Float64Measure
+	ctxFunc          func(alias1.Context) alias1.Context
+	errorClassTagKey alias4.Key
+	outcomeTagKey    alias4.Key
+	classifyError    func(error) string
+	maxLabelValues   int
+	labelsMu         alias6.Mutex
+	labelValues      map[string]map[string]bool
+	doWorkOperation  alias4.Mutator
+	doWorkTags       *alias4.Map
+	notifyOperation  alias4.Mutator
+	notifyTags       *alias4.Map
+	closeOperation   alias4.Mutator
+	closeTags        *alias4.Map
+}
+
+// NewMonitoringPanickyServiceOC creates new monitoring middleware.
+func NewMonitoringPanickyServiceOC(next alias5.PanickyService, totalOps *alias3.Int64Measure, failedOps *alias3.Int64Measure, opsDuration *alias3.Float64Measure, ctxFunc func(alias1.Context) alias1.Context, maxLabelValues int, classifyError func(error) string) alias5.PanickyService {
+	m := &monitoringPanickyServiceOC{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, ctxFunc: ctxFunc, maxLabelValues: maxLabelValues, labelValues: map[string]map[string]bool{}, classifyError: classifyError, errorClassTagKey: alias4.MustNewKey("error_class"), outcomeTagKey: alias4.MustNewKey("outcome")}
+	operationTagKey := alias4.MustNewKey("operation")
+	bind := func(operation string) (alias4.Mutator, *alias4.Map) {
+		mutator := alias4.Insert(operationTagKey, operation)
+		ctx, _ := alias4.New(alias1.Background(), mutator)
+		return mutator, alias4.FromContext(ctx)
+	}
+	m.doWorkOperation, m.doWorkTags = bind("do_work")
+	m.notifyOperation, m.notifyTags = bind("notify")
+	m.closeOperation, m.closeTags = bind("close")
+	return m
+}
+
+// tagContext returns the context tagged with the mutators, the first of which tags the operation.
+// A context without tags is given the operationTags when the operation is its only tag, which saves copying them.
+// The mutators that fail, e.g. because of invalid label values, are left out.
+func (m *monitoringPanickyServiceOC) tagContext(ctx alias1.Context, operationTags *alias4.Map, mutators ...alias4.Mutator) alias1.Context {
+	if len(mutators) == 1 && alias4.FromContext(ctx) == nil {
+		return alias4.NewContext(ctx, operationTags)
+	}
+	if taggedCtx, err := alias4.New(ctx, mutators...); err == nil {
+		return taggedCtx
+	}
+	for _, mutator := range mutators {
+		if taggedCtx, err := alias4.New(ctx, mutator); err == nil {
+			ctx = taggedCtx
+		}
+	}
+	return ctx
+}
+
+// errorClass returns the class of the error recorded in the error_class label.
+func (m *monitoringPanickyServiceOC) errorClass(err error) string {
+	if err == nil {
+		return "failed"
+	}
+	if m.classifyError != nil {
+		return m.labelValue("error_class", m.classifyError(err))
+	}
+	switch {
+	case alias7.Is(err, alias1.Canceled):
+		return "canceled"
+	case alias7.Is(err, alias1.DeadlineExceeded):
+		return "timeout"
+	}
+	return "error"
+}
+
+// labelValue returns the value of the label, or "other" once the label has maxLabelValues distinct values.
+func (m *monitoringPanickyServiceOC) labelValue(label, value string) string {
+	if m.maxLabelValues <= 0 {
+		return value
+	}
+	m.labelsMu.Lock()
+	defer m.labelsMu.Unlock()
+	values := m.labelValues[label]
+	if values[value] {
+		return value
+	}
+	if len(values) >= m.maxLabelValues {
+		return "other"
+	}
+	if values == nil {
+		values = make(map[string]bool)
+		m.labelValues[label] = values
+	}
+	values[value] = true
+	return value
+}
+func (m *monitoringPanickyServiceOC) DoWork(arg1 alias1.Context, arg2 string) (int, error) {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.doWorkTags, m.doWorkOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.outcomeTagKey, "error")}, m.opsDuration.M(alias2.Since(_start).Seconds())); err != nil {
+				alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+			}
+			if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.errorClassTagKey, "panic")}, m.failedOps.M(1)); err != nil {
+				alias3.Record(ctx, m.failedOps.M(1))
+			}
+			panic(_panic)
+		}
+	}()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	_outcome := "success"
+	if result2 != nil {
+		_outcome = "error"
+	}
+	if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.outcomeTagKey, _outcome)}, m.opsDuration.M(alias2.Since(_start).Seconds())); err != nil {
+		alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	}
+	if result2 != nil {
+		if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.errorClassTagKey, m.errorClass(result2))}, m.failedOps.M(1)); err != nil {
+			alias3.Record(ctx, m.failedOps.M(1))
+		}
+	}
+	return result1, result2
+}
+func (m *monitoringPanickyServiceOC) Notify(arg1 alias1.Context, arg2 []string) error {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.notifyTags, m.notifyOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.outcomeTagKey, "error")}, m.opsDuration.M(alias2.Since(_start).Seconds())); err != nil {
+				alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+			}
+			if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.errorClassTagKey, "panic")}, m.failedOps.M(1)); err != nil {
+				alias3.Record(ctx, m.failedOps.M(1))
+			}
+			panic(_panic)
+		}
+	}()
+	result1 := m.next.Notify(arg1, arg2)
+	_outcome := "success"
+	if result1 != nil {
+		_outcome = "error"
+	}
+	if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.outcomeTagKey, _outcome)}, m.opsDuration.M(alias2.Since(_start).Seconds())); err != nil {
+		alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	}
+	if result1 != nil {
+		if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.errorClassTagKey, m.errorClass(result1))}, m.failedOps.M(1)); err != nil {
+			alias3.Record(ctx, m.failedOps.M(1))
+		}
+	}
+	return result1
+}
+func (m *monitoringPanickyServiceOC) Close() {
+	ctx := alias1.Background()
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.closeTags, m.closeOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.outcomeTagKey, "error")}, m.opsDuration.M(alias2.Since(_start).Seconds())); err != nil {
+				alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+			}
+			if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.errorClassTagKey, "panic")}, m.failedOps.M(1)); err != nil {
+				alias3.Record(ctx, m.failedOps.M(1))
+			}
+			panic(_panic)
+		}
+	}()
+	m.next.Close()
+	if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.outcomeTagKey, "success")}, m.opsDuration.M(alias2.Since(_start).Seconds())); err != nil {
+		alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	}
+}
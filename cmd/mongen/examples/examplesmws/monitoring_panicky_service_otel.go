@@ -0,0 +1,106 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.PanickyService
+// Source-Hash: sha256:994c340b1d47ccbff1663c73efffcbeacbcd9a1b03549657ef084fc55d2af0b6
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringPanickyServiceOtel -panics=true -type=monitoringPanickyServiceOtel -output-dir . -o monitoring_panicky_service_otel.go .. PanickyService otel
+package examplesmws
+
+import (
+	alias1 "context"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias4 "go.opentelemetry.io/otel/attribute"
+	alias3 "go.opentelemetry.io/otel/metric"
+	alias2 "time"
+)
+
+type monitoringPanickyServiceOtel struct {
+	next               alias5.PanickyService
+	totalOps           alias3.Int64Counter
+	failedOps          alias3.Int64Counter
+	opsDuration        alias3.Float64Histogram
+	doWorkAttrs        alias3.MeasurementOption
+	doWorkSuccessAttrs alias3.MeasurementOption
+	doWorkErrorAttrs   alias3.MeasurementOption
+	notifyAttrs        alias3.MeasurementOption
+	notifySuccessAttrs alias3.MeasurementOption
+	notifyErrorAttrs   alias3.MeasurementOption
+	closeAttrs         alias3.MeasurementOption
+	closeSuccessAttrs  alias3.MeasurementOption
+	closeErrorAttrs    alias3.MeasurementOption
+}
+
+// NewMonitoringPanickyServiceOtel creates new monitoring middleware.
+func NewMonitoringPanickyServiceOtel(next alias5.PanickyService, totalOps, failedOps alias3.Int64Counter, opsDuration alias3.Float64Histogram) alias5.PanickyService {
+	return &monitoringPanickyServiceOtel{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, doWorkAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "do_work"))), doWorkSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "do_work"), alias4.String("outcome", "success"))), doWorkErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "do_work"), alias4.String("outcome", "error"))), notifyAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "notify"))), notifySuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "notify"), alias4.String("outcome", "success"))), notifyErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "notify"), alias4.String("outcome", "error"))), closeAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "close"))), closeSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "close"), alias4.String("outcome", "success"))), closeErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "close"), alias4.String("outcome", "error")))}
+}
+
+// NewMonitoringPanickyServiceOtelFromMeter creates new monitoring middleware with instruments created by meter.
+func NewMonitoringPanickyServiceOtelFromMeter(next alias5.PanickyService, meter alias3.Meter) (alias5.PanickyService, error) {
+	totalOps, err := meter.Int64Counter("total_ops", alias3.WithDescription("Total number of operations."))
+	if err != nil {
+		return nil, err
+	}
+	failedOps, err := meter.Int64Counter("failed_ops", alias3.WithDescription("Number of failed operations."))
+	if err != nil {
+		return nil, err
+	}
+	opsDuration, err := meter.Float64Histogram("ops_duration", alias3.WithDescription("Duration of operations."), alias3.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return NewMonitoringPanickyServiceOtel(next, totalOps, failedOps, opsDuration), nil
+}
+func (m *monitoringPanickyServiceOtel) DoWork(arg1 alias1.Context, arg2 string) (int, error) {
+	ctx := arg1
+	m.totalOps.Add(ctx, 1, m.doWorkAttrs)
+	_start := alias2.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.failedOps.Add(ctx, 1, m.doWorkErrorAttrs)
+			m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), m.doWorkErrorAttrs)
+			panic(_panic)
+		}
+	}()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	_outcome := m.doWorkSuccessAttrs
+	if result2 != nil {
+		_outcome = m.doWorkErrorAttrs
+		m.failedOps.Add(ctx, 1, m.doWorkErrorAttrs)
+	}
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), _outcome)
+	return result1, result2
+}
+func (m *monitoringPanickyServiceOtel) Notify(arg1 alias1.Context, arg2 []string) error {
+	ctx := arg1
+	m.totalOps.Add(ctx, 1, m.notifyAttrs)
+	_start := alias2.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.failedOps.Add(ctx, 1, m.notifyErrorAttrs)
+			m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), m.notifyErrorAttrs)
+			panic(_panic)
+		}
+	}()
+	result1 := m.next.Notify(arg1, arg2)
+	_outcome := m.notifySuccessAttrs
+	if result1 != nil {
+		_outcome = m.notifyErrorAttrs
+		m.failedOps.Add(ctx, 1, m.notifyErrorAttrs)
+	}
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), _outcome)
+	return result1
+}
+func (m *monitoringPanickyServiceOtel) Close() {
+	ctx := alias1.Background()
+	m.totalOps.Add(ctx, 1, m.closeAttrs)
+	_start := alias2.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.failedOps.Add(ctx, 1, m.closeErrorAttrs)
+			m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), m.closeErrorAttrs)
+			panic(_panic)
+		}
+	}()
+	m.next.Close()
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), m.closeSuccessAttrs)
+}
@@ -0,0 +1,102 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.PanickyService
+// Source-Hash: sha256:994c340b1d47ccbff1663c73efffcbeacbcd9a1b03549657ef084fc55d2af0b6
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringPanickyServicePrometheus -panics=true -type=monitoringPanickyServicePrometheus -output-dir . -o monitoring_panicky_service_prometheus.go .. PanickyService prometheus
+package examplesmws
+
+import (
+	alias4 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/prometheus/client_golang/prometheus"
+	alias3 "time"
+)
+
+type monitoringPanickyServicePrometheus struct {
+	next              alias1.PanickyService
+	doWorkTotalOps    alias2.Counter
+	doWorkFailedOps   alias2.Counter
+	doWorkOpsDuration alias2.Observer
+	notifyTotalOps    alias2.Counter
+	notifyFailedOps   alias2.Counter
+	notifyOpsDuration alias2.Observer
+	closeTotalOps     alias2.Counter
+	closeFailedOps    alias2.Counter
+	closeOpsDuration  alias2.Observer
+}
+
+// NewMonitoringPanickyServicePrometheus creates new monitoring middleware.
+func NewMonitoringPanickyServicePrometheus(next alias1.PanickyService, totalOps, failedOps *alias2.CounterVec, opsDuration *alias2.HistogramVec) alias1.PanickyService {
+	return &monitoringPanickyServicePrometheus{next: next, doWorkTotalOps: totalOps.WithLabelValues("do_work"), doWorkFailedOps: failedOps.WithLabelValues("do_work"), doWorkOpsDuration: opsDuration.WithLabelValues("do_work"), notifyTotalOps: totalOps.WithLabelValues("notify"), notifyFailedOps: failedOps.WithLabelValues("notify"), notifyOpsDuration: opsDuration.WithLabelValues("notify"), closeTotalOps: totalOps.WithLabelValues("close"), closeFailedOps: failedOps.WithLabelValues("close"), closeOpsDuration: opsDuration.WithLabelValues("close")}
+}
+
+// NewMonitoringPanickyServicePrometheusCollectors creates the collectors expected by NewMonitoringPanickyServicePrometheus.
+func NewMonitoringPanickyServicePrometheusCollectors(namespace, subsystem string) (totalOps, failedOps *alias2.CounterVec, opsDuration *alias2.HistogramVec) {
+	totalOps = alias2.NewCounterVec(alias2.CounterOpts{Namespace: namespace, Subsystem: subsystem, Name: "total_ops", Help: "Total number of operations."}, []string{"operation"})
+	failedOps = alias2.NewCounterVec(alias2.CounterOpts{Namespace: namespace, Subsystem: subsystem, Name: "failed_ops", Help: "Number of failed operations."}, []string{"operation"})
+	opsDuration = alias2.NewHistogramVec(alias2.HistogramOpts{Namespace: namespace, Subsystem: subsystem, Name: "ops_duration_seconds", Help: "Duration of operations in seconds.", Buckets: alias2.DefBuckets}, []string{"operation"})
+	return totalOps, failedOps, opsDuration
+}
+
+// RegisterMonitoringPanickyServicePrometheus creates new monitoring middleware and registers its collectors with reg.
+// If a collector cannot be registered, the collectors registered before it are unregistered.
+func RegisterMonitoringPanickyServicePrometheus(reg alias2.Registerer, next alias1.PanickyService, namespace, subsystem string) (alias1.PanickyService, error) {
+	totalOps, failedOps, opsDuration := NewMonitoringPanickyServicePrometheusCollectors(namespace, subsystem)
+	collectors := []alias2.Collector{totalOps, failedOps, opsDuration}
+	for i, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			for _, registered := range collectors[:i] {
+				reg.Unregister(registered)
+			}
+			return nil, err
+		}
+	}
+	return NewMonitoringPanickyServicePrometheus(next, totalOps, failedOps, opsDuration), nil
+}
+func (m *monitoringPanickyServicePrometheus) DoWork(arg1 alias4.Context, arg2 string) (int, error) {
+	m.doWorkTotalOps.Inc()
+	_start := alias3.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.doWorkOpsDuration.Observe(alias3.Since(_start).Seconds())
+			m.doWorkFailedOps.Inc()
+			panic(_panic)
+		}
+	}()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	m.doWorkOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.doWorkFailedOps.Inc()
+	}
+	return result1, result2
+}
+func (m *monitoringPanickyServicePrometheus) Notify(arg1 alias4.Context, arg2 []string) error {
+	m.notifyTotalOps.Inc()
+	_start := alias3.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.notifyOpsDuration.Observe(alias3.Since(_start).Seconds())
+			m.notifyFailedOps.Inc()
+			panic(_panic)
+		}
+	}()
+	result1 := m.next.Notify(arg1, arg2)
+	m.notifyOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.notifyFailedOps.Inc()
+	}
+	return result1
+}
+func (m *monitoringPanickyServicePrometheus) Close() {
+	m.closeTotalOps.Inc()
+	_start := alias3.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.closeOpsDuration.Observe(alias3.Since(_start).Seconds())
+			m.closeFailedOps.Inc()
+			panic(_panic)
+		}
+	}()
+	m.next.Close()
+	m.closeOpsDuration.Observe(alias3.Since(_start).Seconds())
+}
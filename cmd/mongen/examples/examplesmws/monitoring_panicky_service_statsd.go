@@ -0,0 +1,132 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.PanickyService
+// Source-Hash: sha256:994c340b1d47ccbff1663c73efffcbeacbcd9a1b03549657ef084fc55d2af0b6
+// Generator: mongen v2.1.0
+// Args: -classify-errors=true -constructor=NewMonitoringPanickyServiceStatsd -panics=true -toggles=true -type=monitoringPanickyServiceStatsd -output-dir . -o monitoring_panicky_service_statsd.go .. PanickyService statsd
+package examplesmws
+
+import (
+	alias6 "context"
+	alias5 "errors"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/Bo0mer/gentools/pkg/statsd"
+	alias7 "github.com/Bo0mer/gentools/pkg/toggle"
+	alias4 "sync"
+	alias3 "time"
+)
+
+type monitoringPanickyServiceStatsd struct {
+	next           alias1.PanickyService
+	emitter        alias2.Emitter
+	classifyError  func(error) string
+	maxLabelValues int
+	labelsMu       alias4.Mutex
+	labelValues    map[string]map[string]bool
+	doWorkTags     []string
+	doWorkToggle   *alias7.Method
+	notifyTags     []string
+	notifyToggle   *alias7.Method
+	closeTags      []string
+	closeToggle    *alias7.Method
+}
+
+// NewMonitoringPanickyServiceStatsd creates new monitoring middleware.
+func NewMonitoringPanickyServiceStatsd(next alias1.PanickyService, emitter alias2.Emitter, maxLabelValues int, classifyError func(error) string, toggles *alias7.Controls) alias1.PanickyService {
+	return &monitoringPanickyServiceStatsd{next: next, emitter: emitter, doWorkTags: []string{"operation:do_work"}, notifyTags: []string{"operation:notify"}, closeTags: []string{"operation:close"}, maxLabelValues: maxLabelValues, labelValues: map[string]map[string]bool{}, classifyError: classifyError, doWorkToggle: toggles.Method("PanickyService.DoWork"), notifyToggle: toggles.Method("PanickyService.Notify"), closeToggle: toggles.Method("PanickyService.Close")}
+}
+
+// errorClass returns the class of the error recorded in the error_class label.
+func (m *monitoringPanickyServiceStatsd) errorClass(err error) string {
+	if err == nil {
+		return "failed"
+	}
+	if m.classifyError != nil {
+		return m.labelValue("error_class", m.classifyError(err))
+	}
+	switch {
+	case alias5.Is(err, alias6.Canceled):
+		return "canceled"
+	case alias5.Is(err, alias6.DeadlineExceeded):
+		return "timeout"
+	}
+	return "error"
+}
+
+// labelValue returns the value of the label, or "other" once the label has maxLabelValues distinct values.
+func (m *monitoringPanickyServiceStatsd) labelValue(label, value string) string {
+	if m.maxLabelValues <= 0 {
+		return value
+	}
+	m.labelsMu.Lock()
+	defer m.labelsMu.Unlock()
+	values := m.labelValues[label]
+	if values[value] {
+		return value
+	}
+	if len(values) >= m.maxLabelValues {
+		return "other"
+	}
+	if values == nil {
+		values = make(map[string]bool)
+		m.labelValues[label] = values
+	}
+	values[value] = true
+	return value
+}
+func (m *monitoringPanickyServiceStatsd) DoWork(arg1 alias6.Context, arg2 string) (int, error) {
+	if !m.doWorkToggle.Sample() {
+		return m.next.DoWork(arg1, arg2)
+	}
+	m.emitter.Count("total_ops", 1, m.doWorkTags...)
+	_start := alias3.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.emitter.Timing("ops_duration", alias3.Since(_start), m.doWorkTags...)
+			m.emitter.Count("failed_ops", 1, alias2.Tags(m.doWorkTags, "error_class", "panic")...)
+			panic(_panic)
+		}
+	}()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	m.emitter.Timing("ops_duration", alias3.Since(_start), m.doWorkTags...)
+	if result2 != nil {
+		m.emitter.Count("failed_ops", 1, alias2.Tags(m.doWorkTags, "error_class", m.errorClass(result2))...)
+	}
+	return result1, result2
+}
+func (m *monitoringPanickyServiceStatsd) Notify(arg1 alias6.Context, arg2 []string) error {
+	if !m.notifyToggle.Sample() {
+		return m.next.Notify(arg1, arg2)
+	}
+	m.emitter.Count("total_ops", 1, m.notifyTags...)
+	_start := alias3.Now()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			m.emitter.Timing("ops_duration", alias3.Since(_start), m.notifyTags...)
+			m.emitter.Count("failed_ops", 1, alias2.Tags(m.notifyTags, "error_class", "panic")...)
+			panic(_panic)
+		}
+	}()
+	result1 := m.next.Notify(arg1, arg2)
+	m.emitter.Timing("ops_duration", alias3.Since(_start), m.notifyTags...)
+	if result1 != nil {
+		m.emitter.Count("failed_ops", 1, alias2.Tags(m.notifyTags, "error_class", m.errorClass(result1))...)
+	}
+	return result1
+}
+func (m *monitoringPanickyServiceStatsd) Close() {
+	if !m.closeToggle.Sample() {
+		m.next.Close()
+	} else {
+		m.emitter.Count("total_ops", 1, m.closeTags...)
+		_start := alias3.Now()
+		defer func() {
+			if _panic := recover(); _panic != nil {
+				m.emitter.Timing("ops_duration", alias3.Since(_start), m.closeTags...)
+				m.emitter.Count("failed_ops", 1, alias2.Tags(m.closeTags, "error_class", "panic")...)
+				panic(_panic)
+			}
+		}()
+		m.next.Close()
+		m.emitter.Timing("ops_duration", alias3.Since(_start), m.closeTags...)
+	}
+}
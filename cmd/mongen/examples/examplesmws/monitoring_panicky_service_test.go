@@ -0,0 +1,105 @@
+package examplesmws_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples/examplesmws"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// panickyService panics with its value in every method, unless the value is
+// empty.
+type panickyService struct{ value string }
+
+func (s panickyService) DoWork(context.Context, string) (int, error) {
+	s.panic()
+	return 0, nil
+}
+
+func (s panickyService) Notify(context.Context, []string) error {
+	s.panic()
+	return nil
+}
+
+func (s panickyService) Close() { s.panic() }
+
+func (s panickyService) panic() {
+	if s.value != "" {
+		panic(s.value)
+	}
+}
+
+// recovered returns the value of the panic of the call, if it panicked.
+func recovered(call func()) (value interface{}) {
+	defer func() { value = recover() }()
+	call()
+	return nil
+}
+
+func TestPanickyServiceRecordsPanicsAndRaisesThemAgain(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	totalOpsVec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "total_ops"}, []string{"operation"})
+	failedOpsVec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failed_ops"}, []string{"operation", "error_class"})
+	opsDurationVec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "ops_duration_seconds"}, []string{"operation", "outcome"})
+	inFlightOpsVec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "in_flight_ops"}, []string{"operation"})
+	registry.MustRegister(totalOpsVec, failedOpsVec, opsDurationVec, inFlightOpsVec)
+	svc := examplesmws.NewMonitoringPanickyService(panickyService{value: "boom"},
+		kitprometheus.NewCounter(totalOpsVec),
+		kitprometheus.NewCounter(failedOpsVec),
+		kitprometheus.NewHistogram(opsDurationVec),
+		kitprometheus.NewGauge(inFlightOpsVec),
+		0, nil)
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		operation string
+		call      func()
+	}{
+		{operation: "do_work", call: func() { svc.DoWork(ctx, "a") }},
+		{operation: "notify", call: func() { svc.Notify(ctx, nil) }},
+		{operation: "close", call: svc.Close},
+	} {
+		t.Run(tc.operation, func(t *testing.T) {
+			if got := recovered(tc.call); got != "boom" {
+				t.Fatalf("got panic %v, want boom", got)
+			}
+
+			labels := map[string]string{"operation": tc.operation, "error_class": "panic"}
+			if got := counterValue(t, registry, "failed_ops", labels); got != 1 {
+				t.Errorf("got %v failures of class panic, want 1", got)
+			}
+			families, err := registry.Gather()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var durations uint64
+			for _, family := range families {
+				for _, metric := range family.GetMetric() {
+					labels := map[string]string{}
+					for _, label := range metric.GetLabel() {
+						labels[label.GetName()] = label.GetValue()
+					}
+					if labels["operation"] != tc.operation {
+						continue
+					}
+					switch family.GetName() {
+					case "ops_duration_seconds":
+						if labels["outcome"] != "error" {
+							t.Errorf("got duration with outcome %q, want error", labels["outcome"])
+						}
+						durations += metric.GetHistogram().GetSampleCount()
+					case "in_flight_ops":
+						if got := metric.GetGauge().GetValue(); got != 0 {
+							t.Errorf("got %v operations in flight, want 0", got)
+						}
+					}
+				}
+			}
+			if durations != 1 {
+				t.Errorf("got %d durations, want 1", durations)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.PredicateService
+// Source-Hash: sha256:224f7871df71f28e6a968982afc3001ea2a022170da870c8c27cb6f554477b22
+// Generator: mongen v2.1.0
+// Args: -classify-errors=true -outcome=true -output-dir . -o monitoring_predicate_service.go .. PredicateService go-kit
+package examplesmws
+
+import (
+	alias6 "context"
+	alias5 "errors"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/go-kit/kit/metrics"
+	alias4 "sync"
+	alias3 "time"
+)
+
+type monitoringPredicateService struct {
+	next            alias1.PredicateService
+	classifyError   func(error) string
+	maxLabelValues  int
+	labelsMu        alias4.Mutex
+	labelValues     map[string]map[string]bool
+	lookupOperation monitoringPredicateServiceOperation
+	statusOperation monitoringPredicateServiceOperation
+	findOperation   monitoringPredicateServiceOperation
+}
+type monitoringPredicateServiceOperation struct {
+	totalOps           alias2.Counter
+	failedOps          alias2.Counter
+	opsDurationSuccess alias2.Histogram
+	opsDurationError   alias2.Histogram
+}
+
+// NewMonitoringPredicateService creates new monitoring middleware.
+func NewMonitoringPredicateService(next alias1.PredicateService, totalOps alias2.Counter, failedOps alias2.Counter, opsDuration alias2.Histogram, maxLabelValues int, classifyError func(error) string) alias1.PredicateService {
+	m := &monitoringPredicateService{next: next, maxLabelValues: maxLabelValues, labelValues: map[string]map[string]bool{}, classifyError: classifyError}
+	bind := func(operation string, duration alias2.Histogram) monitoringPredicateServiceOperation {
+		return monitoringPredicateServiceOperation{totalOps: totalOps.With("operation", operation), failedOps: failedOps.With("operation", operation), opsDurationSuccess: duration.With("operation", operation, "outcome", "success"), opsDurationError: duration.With("operation", operation, "outcome", "error")}
+	}
+	m.lookupOperation = bind("lookup", opsDuration)
+	m.statusOperation = bind("status", opsDuration)
+	m.findOperation = bind("find", opsDuration)
+	return m
+}
+
+// errorClass returns the class of the error recorded in the error_class label.
+func (m *monitoringPredicateService) errorClass(err error) string {
+	if err == nil {
+		return "failed"
+	}
+	if m.classifyError != nil {
+		return m.labelValue("error_class", m.classifyError(err))
+	}
+	switch {
+	case alias5.Is(err, alias6.Canceled):
+		return "canceled"
+	case alias5.Is(err, alias6.DeadlineExceeded):
+		return "timeout"
+	}
+	return "error"
+}
+
+// labelValue returns the value of the label, or "other" once the label has maxLabelValues distinct values.
+func (m *monitoringPredicateService) labelValue(label, value string) string {
+	if m.maxLabelValues <= 0 {
+		return value
+	}
+	m.labelsMu.Lock()
+	defer m.labelsMu.Unlock()
+	values := m.labelValues[label]
+	if values[value] {
+		return value
+	}
+	if len(values) >= m.maxLabelValues {
+		return "other"
+	}
+	if values == nil {
+		values = make(map[string]bool)
+		m.labelValues[label] = values
+	}
+	values[value] = true
+	return value
+}
+func (m *monitoringPredicateService) Lookup(arg1 string) (string, bool) {
+	m.lookupOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2 := m.next.Lookup(arg1)
+	_opsDuration := m.lookupOperation.opsDurationSuccess
+	if !result2 {
+		_opsDuration = m.lookupOperation.opsDurationError
+	}
+	_opsDuration.Observe(alias3.Since(_start).Seconds())
+	if !result2 {
+		m.lookupOperation.failedOps.With("error_class", "failed").Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringPredicateService) Status(arg1 alias6.Context) (alias1.Status, error) {
+	m.statusOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2 := m.next.Status(arg1)
+	_opsDuration := m.statusOperation.opsDurationSuccess
+	if result2 != nil || result1 != alias1.StatusOK {
+		_opsDuration = m.statusOperation.opsDurationError
+	}
+	_opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil || result1 != alias1.StatusOK {
+		m.statusOperation.failedOps.With("error_class", m.errorClass(result2)).Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringPredicateService) Find(arg1 string, arg2 int) (string, bool) {
+	m.findOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2 := m.next.Find(arg1, arg2)
+	_opsDuration := m.findOperation.opsDurationSuccess
+	if !result2 || len(result1) > arg2 {
+		_opsDuration = m.findOperation.opsDurationError
+	}
+	_opsDuration.Observe(alias3.Since(_start).Seconds())
+	if !result2 || len(result1) > arg2 {
+		m.findOperation.failedOps.With("error_class", "failed").Add(1)
+	}
+	return result1, result2
+}
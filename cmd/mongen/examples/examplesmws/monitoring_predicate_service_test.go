@@ -0,0 +1,63 @@
+package examplesmws_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples"
+	"github.com/Bo0mer/gentools/cmd/mongen/examples/examplesmws"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type predicateService map[string]string
+
+func (s predicateService) Lookup(key string) (string, bool) {
+	value, ok := s[key]
+	return value, ok
+}
+
+func (predicateService) Status(context.Context) (examples.Status, error) {
+	return examples.StatusOK, nil
+}
+
+func (s predicateService) Find(key string, limit int) (string, bool) {
+	value, ok := s[key]
+	return value, ok
+}
+
+func TestPredicateService(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	totalOpsVec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "total_ops"}, []string{"operation"})
+	failedOpsVec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failed_ops"}, []string{"operation", "error_class"})
+	opsDurationVec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "ops_duration_seconds"}, []string{"operation", "outcome"})
+	registry.MustRegister(totalOpsVec, failedOpsVec, opsDurationVec)
+	svc := examplesmws.NewMonitoringPredicateService(predicateService{"key": "value"},
+		kitprometheus.NewCounter(totalOpsVec),
+		kitprometheus.NewCounter(failedOpsVec),
+		kitprometheus.NewHistogram(opsDurationVec),
+		0, nil)
+
+	// Lookup declares unnamed results, so its predicate, !result2, refers
+	// to them by position.
+	svc.Lookup("key")
+	svc.Lookup("missing")
+	// Find declares named results, so its predicate, !found || len(value) > limit,
+	// refers to them and to the parameters by name.
+	svc.Find("key", 10)
+	svc.Find("key", 1)
+	svc.Find("missing", 10)
+
+	for _, tc := range []struct {
+		operation string
+		want      float64
+	}{
+		{operation: "lookup", want: 1},
+		{operation: "find", want: 2},
+	} {
+		labels := map[string]string{"operation": tc.operation, "error_class": "failed"}
+		if got := counterValue(t, registry, "failed_ops", labels); got != tc.want {
+			t.Errorf("got %v failed %s operations, want %v", got, tc.operation, tc.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.PrometheusService
+// Source-Hash: sha256:79a878f7d202647f2231c64fea09584a6639c83881d78866c847cf0e17c62c53
+// Generator: mongen v2.1.0
+// Args: -output-dir . -o monitoring_prometheus_service.go .. PrometheusService prometheus
+package examplesmws
+
+import (
+	alias4 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/prometheus/client_golang/prometheus"
+	alias3 "time"
+)
+
+type monitoringPrometheusService struct {
+	next                 alias1.PrometheusService
+	doWorkTotalOps       alias2.Counter
+	doWorkFailedOps      alias2.Counter
+	doWorkOpsDuration    alias2.Observer
+	doWorkCtxTotalOps    alias2.Counter
+	doWorkCtxFailedOps   alias2.Counter
+	doWorkCtxOpsDuration alias2.Observer
+}
+
+// NewMonitoringPrometheusService creates new monitoring middleware.
+func NewMonitoringPrometheusService(next alias1.PrometheusService, totalOps, failedOps *alias2.CounterVec, opsDuration *alias2.HistogramVec) alias1.PrometheusService {
+	return &monitoringPrometheusService{next: next, doWorkTotalOps: totalOps.WithLabelValues("do_work"), doWorkFailedOps: failedOps.WithLabelValues("do_work"), doWorkOpsDuration: opsDuration.WithLabelValues("do_work"), doWorkCtxTotalOps: totalOps.WithLabelValues("do_work_ctx"), doWorkCtxFailedOps: failedOps.WithLabelValues("do_work_ctx"), doWorkCtxOpsDuration: opsDuration.WithLabelValues("do_work_ctx")}
+}
+
+// NewMonitoringPrometheusServiceCollectors creates the collectors expected by NewMonitoringPrometheusService.
+func NewMonitoringPrometheusServiceCollectors(namespace, subsystem string) (totalOps, failedOps *alias2.CounterVec, opsDuration *alias2.HistogramVec) {
+	totalOps = alias2.NewCounterVec(alias2.CounterOpts{Namespace: namespace, Subsystem: subsystem, Name: "total_ops", Help: "Total number of operations."}, []string{"operation"})
+	failedOps = alias2.NewCounterVec(alias2.CounterOpts{Namespace: namespace, Subsystem: subsystem, Name: "failed_ops", Help: "Number of failed operations."}, []string{"operation"})
+	opsDuration = alias2.NewHistogramVec(alias2.HistogramOpts{Namespace: namespace, Subsystem: subsystem, Name: "ops_duration_seconds", Help: "Duration of operations in seconds.", Buckets: alias2.DefBuckets}, []string{"operation"})
+	return totalOps, failedOps, opsDuration
+}
+
+// RegisterMonitoringPrometheusService creates new monitoring middleware and registers its collectors with reg.
+// If a collector cannot be registered, the collectors registered before it are unregistered.
+func RegisterMonitoringPrometheusService(reg alias2.Registerer, next alias1.PrometheusService, namespace, subsystem string) (alias1.PrometheusService, error) {
+	totalOps, failedOps, opsDuration := NewMonitoringPrometheusServiceCollectors(namespace, subsystem)
+	collectors := []alias2.Collector{totalOps, failedOps, opsDuration}
+	for i, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			for _, registered := range collectors[:i] {
+				reg.Unregister(registered)
+			}
+			return nil, err
+		}
+	}
+	return NewMonitoringPrometheusService(next, totalOps, failedOps, opsDuration), nil
+}
+func (m *monitoringPrometheusService) DoWork(arg1 int, arg2 string) (string, error) {
+	m.doWorkTotalOps.Inc()
+	_start := alias3.Now()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	m.doWorkOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.doWorkFailedOps.Inc()
+	}
+	return result1, result2
+}
+func (m *monitoringPrometheusService) DoWorkCtx(arg1 alias4.Context, arg2 int, arg3 string) (string, error) {
+	m.doWorkCtxTotalOps.Inc()
+	_start := alias3.Now()
+	result1, result2 := m.next.DoWorkCtx(arg1, arg2, arg3)
+	m.doWorkCtxOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.doWorkCtxFailedOps.Inc()
+	}
+	return result1, result2
+}
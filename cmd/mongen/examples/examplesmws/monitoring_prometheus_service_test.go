@@ -0,0 +1,34 @@
+package examplesmws_test
+
+import (
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples/examplesmws"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRegisterPrometheusServiceUnregistersOnFailure(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	// The duration histogram, which is registered after the counters, is
+	// already registered.
+	_, _, existing := examplesmws.NewMonitoringPrometheusServiceCollectors("registered", "service")
+	registry.MustRegister(existing)
+
+	if _, err := examplesmws.RegisterMonitoringPrometheusService(registry, service{}, "registered", "service"); err == nil {
+		t.Fatal("got no error registering a collector that is already registered")
+	}
+
+	// The counters registered before the failure are unregistered, so the
+	// registration succeeds once the histogram is unregistered.
+	registry.Unregister(existing)
+	svc, err := examplesmws.RegisterMonitoringPrometheusService(registry, service{}, "registered", "service")
+	if err != nil {
+		t.Fatalf("got error retrying the registration: %v", err)
+	}
+	if _, err := svc.DoWork(1, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if got := counterValue(t, registry, "registered_service_total_ops", map[string]string{"operation": "do_work"}); got != 1 {
+		t.Errorf("got %v operations, want 1", got)
+	}
+}
@@ -0,0 +1,167 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.SizedService
+// Source-Hash: sha256:1f2657f52a67efad3387ff686b72db66409045e62a90755ce723249266593bad
+// Generator: mongen v2.1.0
+// Args: -metrics=true -result-size=true -output-dir . -o monitoring_sized_service.go .. SizedService go-kit
+package examplesmws
+
+import (
+	alias6 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/go-kit/kit/metrics"
+	alias4 "github.com/go-kit/kit/metrics/prometheus"
+	alias5 "github.com/prometheus/client_golang/prometheus"
+	alias7 "reflect"
+	alias3 "time"
+)
+
+type monitoringSizedService struct {
+	next               alias1.SizedService
+	listOperation      monitoringSizedServiceOperation
+	indexOperation     monitoringSizedServiceOperation
+	nextOperation      monitoringSizedServiceOperation
+	currentOperation   monitoringSizedServiceOperation
+	partitionOperation monitoringSizedServiceOperation
+	lookupOperation    monitoringSizedServiceOperation
+	tagsOperation      monitoringSizedServiceOperation
+	pingOperation      monitoringSizedServiceOperation
+}
+type monitoringSizedServiceOperation struct {
+	totalOps    alias2.Counter
+	failedOps   alias2.Counter
+	opsDuration alias2.Histogram
+	resultSize  alias2.Histogram
+}
+
+// NewMonitoringSizedService creates new monitoring middleware.
+func NewMonitoringSizedService(next alias1.SizedService, totalOps alias2.Counter, failedOps alias2.Counter, opsDuration alias2.Histogram, resultSize alias2.Histogram) alias1.SizedService {
+	m := &monitoringSizedService{next: next}
+	bind := func(operation string, duration alias2.Histogram) monitoringSizedServiceOperation {
+		return monitoringSizedServiceOperation{totalOps: totalOps.With("operation", operation), failedOps: failedOps.With("operation", operation), opsDuration: duration.With("operation", operation), resultSize: resultSize.With("operation", operation)}
+	}
+	m.listOperation = bind("list", opsDuration)
+	m.indexOperation = bind("index", opsDuration)
+	m.nextOperation = bind("next", opsDuration)
+	m.currentOperation = bind("current", opsDuration)
+	m.partitionOperation = bind("partition", opsDuration)
+	m.lookupOperation = bind("lookup", opsDuration)
+	m.tagsOperation = bind("tags", opsDuration)
+	m.pingOperation = bind("ping", opsDuration)
+	return m
+}
+
+// MonitoringSizedServiceMetrics holds the metrics recorded by the monitoring middleware.
+type MonitoringSizedServiceMetrics struct {
+	TotalOps    alias2.Counter
+	FailedOps   alias2.Counter
+	OpsDuration alias2.Histogram
+	ResultSize  alias2.Histogram
+}
+
+// NewMonitoringSizedServiceMetrics creates Prometheus metrics with the specified namespace and registers
+// them with the default registerer.
+func NewMonitoringSizedServiceMetrics(namespace string) *MonitoringSizedServiceMetrics {
+	return &MonitoringSizedServiceMetrics{TotalOps: alias4.NewCounterFrom(alias5.CounterOpts{Namespace: namespace, Name: "total_ops", Help: "Total number of operations."}, []string{"operation"}), FailedOps: alias4.NewCounterFrom(alias5.CounterOpts{Namespace: namespace, Name: "failed_ops", Help: "Number of failed operations."}, []string{"operation"}), OpsDuration: alias4.NewHistogramFrom(alias5.HistogramOpts{Namespace: namespace, Name: "ops_duration_seconds", Help: "Duration of operations in seconds.", Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}}, []string{"operation"}), ResultSize: alias4.NewHistogramFrom(alias5.HistogramOpts{Namespace: namespace, Name: "result_size", Help: "Number of items in the results of operations.", Buckets: []float64{1, 4, 16, 64, 256, 1024, 4096, 16384, 65536, 262144}}, []string{"operation"})}
+}
+
+// Wrap wraps next with monitoring middleware created by NewMonitoringSizedService that records the metrics.
+func (ms *MonitoringSizedServiceMetrics) Wrap(next alias1.SizedService) alias1.SizedService {
+	return NewMonitoringSizedService(next, ms.TotalOps, ms.FailedOps, ms.OpsDuration, ms.ResultSize)
+}
+func (m *monitoringSizedService) List(arg1 alias6.Context) ([]alias1.Request, error) {
+	m.listOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2 := m.next.List(arg1)
+	if result2 == nil {
+		m.listOperation.resultSize.Observe(float64(len(result1)))
+	}
+	m.listOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.listOperation.failedOps.Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringSizedService) Index(arg1 alias6.Context) (map[string]alias1.Request, error) {
+	m.indexOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2 := m.next.Index(arg1)
+	if result2 == nil {
+		m.indexOperation.resultSize.Observe(float64(len(result1)))
+	}
+	m.indexOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.indexOperation.failedOps.Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringSizedService) Next(arg1 alias6.Context) (*alias1.Batch, error) {
+	m.nextOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2 := m.next.Next(arg1)
+	if result2 == nil && result1 != nil {
+		m.nextOperation.resultSize.Observe(float64(result1.Len()))
+	}
+	m.nextOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.nextOperation.failedOps.Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringSizedService) Current(arg1 alias6.Context) (alias1.Collection, error) {
+	m.currentOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2 := m.next.Current(arg1)
+	if result2 == nil && result1 != nil {
+		if _value := alias7.ValueOf(result1); _value.Kind() != alias7.Pointer || !_value.IsNil() {
+			m.currentOperation.resultSize.Observe(float64(result1.Len()))
+		}
+	}
+	m.currentOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.currentOperation.failedOps.Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringSizedService) Partition(arg1 alias6.Context, arg2 []alias1.Request) ([]alias1.Request, []alias1.Request, error) {
+	m.partitionOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2, result3 := m.next.Partition(arg1, arg2)
+	if result3 == nil {
+		m.partitionOperation.resultSize.Observe(float64(len(result2)))
+	}
+	m.partitionOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result3 != nil {
+		m.partitionOperation.failedOps.Add(1)
+	}
+	return result1, result2, result3
+}
+func (m *monitoringSizedService) Lookup(arg1 string) ([]alias1.Request, bool) {
+	m.lookupOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2 := m.next.Lookup(arg1)
+	if result2 {
+		m.lookupOperation.resultSize.Observe(float64(len(result1)))
+	}
+	m.lookupOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if !result2 {
+		m.lookupOperation.failedOps.Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringSizedService) Tags() []string {
+	m.tagsOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1 := m.next.Tags()
+	m.tagsOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	return result1
+}
+func (m *monitoringSizedService) Ping(arg1 alias6.Context) error {
+	m.pingOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1 := m.next.Ping(arg1)
+	m.pingOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.pingOperation.failedOps.Add(1)
+	}
+	return result1
+}
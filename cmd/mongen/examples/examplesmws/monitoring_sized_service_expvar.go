@@ -0,0 +1,270 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.SizedService
+// Source-Hash: sha256:1f2657f52a67efad3387ff686b72db66409045e62a90755ce723249266593bad
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringSizedServiceExpvar -result-size=true -type=monitoringSizedServiceExpvar -output-dir . -o monitoring_sized_service_expvar.go .. SizedService expvar
+package examplesmws
+
+import (
+	alias5 "context"
+	alias2 "expvar"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias6 "reflect"
+	alias4 "sync"
+	alias3 "time"
+)
+
+type monitoringSizedServiceExpvar struct {
+	next                alias1.SizedService
+	totalOps            *alias2.Map
+	failedOps           *alias2.Map
+	listDuration        *alias2.Map
+	listResultSize      *alias2.Map
+	indexDuration       *alias2.Map
+	indexResultSize     *alias2.Map
+	nextDuration        *alias2.Map
+	nextResultSize      *alias2.Map
+	currentDuration     *alias2.Map
+	currentResultSize   *alias2.Map
+	partitionDuration   *alias2.Map
+	partitionResultSize *alias2.Map
+	lookupDuration      *alias2.Map
+	lookupResultSize    *alias2.Map
+	tagsDuration        *alias2.Map
+	pingDuration        *alias2.Map
+}
+
+// NewMonitoringSizedServiceExpvar creates new monitoring middleware that publishes its variables with names starting with prefix.
+func NewMonitoringSizedServiceExpvar(next alias1.SizedService, prefix string) alias1.SizedService {
+	totalOps := monitoringSizedServiceExpvarPublish(prefix + "total_ops")
+	failedOps := monitoringSizedServiceExpvarPublish(prefix + "failed_ops")
+	opsDuration := monitoringSizedServiceExpvarPublish(prefix + "ops_duration_seconds")
+	resultSize := monitoringSizedServiceExpvarPublish(prefix + "result_size")
+	totalOps.Add("list", 0)
+	failedOps.Add("list", 0)
+	totalOps.Add("index", 0)
+	failedOps.Add("index", 0)
+	totalOps.Add("next", 0)
+	failedOps.Add("next", 0)
+	totalOps.Add("current", 0)
+	failedOps.Add("current", 0)
+	totalOps.Add("partition", 0)
+	failedOps.Add("partition", 0)
+	totalOps.Add("lookup", 0)
+	failedOps.Add("lookup", 0)
+	totalOps.Add("tags", 0)
+	failedOps.Add("tags", 0)
+	totalOps.Add("ping", 0)
+	failedOps.Add("ping", 0)
+	return &monitoringSizedServiceExpvar{next: next, totalOps: totalOps, failedOps: failedOps, listDuration: monitoringSizedServiceExpvarGet(opsDuration, "list"), listResultSize: monitoringSizedServiceExpvarGet(resultSize, "list"), indexDuration: monitoringSizedServiceExpvarGet(opsDuration, "index"), indexResultSize: monitoringSizedServiceExpvarGet(resultSize, "index"), nextDuration: monitoringSizedServiceExpvarGet(opsDuration, "next"), nextResultSize: monitoringSizedServiceExpvarGet(resultSize, "next"), currentDuration: monitoringSizedServiceExpvarGet(opsDuration, "current"), currentResultSize: monitoringSizedServiceExpvarGet(resultSize, "current"), partitionDuration: monitoringSizedServiceExpvarGet(opsDuration, "partition"), partitionResultSize: monitoringSizedServiceExpvarGet(resultSize, "partition"), lookupDuration: monitoringSizedServiceExpvarGet(opsDuration, "lookup"), lookupResultSize: monitoringSizedServiceExpvarGet(resultSize, "lookup"), tagsDuration: monitoringSizedServiceExpvarGet(opsDuration, "tags"), pingDuration: monitoringSizedServiceExpvarGet(opsDuration, "ping")}
+}
+
+// monitoringSizedServiceExpvarMu guards the lookup and publishing of the maps of monitoringSizedServiceExpvar.
+var monitoringSizedServiceExpvarMu alias4.Mutex
+
+// monitoringSizedServiceExpvarPublish returns the map published under the name, publishing a new map if no variable is published under it yet.
+// It panics if a variable that is not a map is published under the name.
+func monitoringSizedServiceExpvarPublish(name string) *alias2.Map {
+	monitoringSizedServiceExpvarMu.Lock()
+	defer monitoringSizedServiceExpvarMu.Unlock()
+	v := alias2.Get(name)
+	if v == nil {
+		return alias2.NewMap(name)
+	}
+	if m, ok := v.(*alias2.Map); ok {
+		return m
+	}
+	panic("expvar: " + name + " is published and is not an *expvar.Map")
+}
+
+// monitoringSizedServiceExpvarGet returns the map stored under the key of m, storing a new map under it if there is none.
+func monitoringSizedServiceExpvarGet(m *alias2.Map, key string) *alias2.Map {
+	monitoringSizedServiceExpvarMu.Lock()
+	defer monitoringSizedServiceExpvarMu.Unlock()
+	if sub, ok := m.Get(key).(*alias2.Map); ok {
+		return sub
+	}
+	sub := new(alias2.Map)
+	m.Set(key, sub)
+	return sub
+}
+
+// observe records the duration of an operation, in seconds, in its duration map.
+func (m *monitoringSizedServiceExpvar) observe(duration *alias2.Map, seconds float64) {
+	duration.AddFloat("sum", seconds)
+	duration.Add("count", 1)
+	switch {
+	case seconds <= 0.005:
+		duration.Add("le_0.005", 1)
+		fallthrough
+	case seconds <= 0.01:
+		duration.Add("le_0.01", 1)
+		fallthrough
+	case seconds <= 0.025:
+		duration.Add("le_0.025", 1)
+		fallthrough
+	case seconds <= 0.05:
+		duration.Add("le_0.05", 1)
+		fallthrough
+	case seconds <= 0.1:
+		duration.Add("le_0.1", 1)
+		fallthrough
+	case seconds <= 0.25:
+		duration.Add("le_0.25", 1)
+		fallthrough
+	case seconds <= 0.5:
+		duration.Add("le_0.5", 1)
+		fallthrough
+	case seconds <= 1:
+		duration.Add("le_1", 1)
+		fallthrough
+	case seconds <= 2.5:
+		duration.Add("le_2.5", 1)
+		fallthrough
+	case seconds <= 5:
+		duration.Add("le_5", 1)
+		fallthrough
+	case seconds <= 10:
+		duration.Add("le_10", 1)
+		fallthrough
+	default:
+		duration.Add("le_+Inf", 1)
+	}
+}
+
+// observeSize records the number of items in the result of an operation in its size map.
+func (m *monitoringSizedServiceExpvar) observeSize(sizes *alias2.Map, size int64) {
+	sizes.Add("sum", size)
+	sizes.Add("count", 1)
+	switch {
+	case size <= 1:
+		sizes.Add("le_1", 1)
+		fallthrough
+	case size <= 4:
+		sizes.Add("le_4", 1)
+		fallthrough
+	case size <= 16:
+		sizes.Add("le_16", 1)
+		fallthrough
+	case size <= 64:
+		sizes.Add("le_64", 1)
+		fallthrough
+	case size <= 256:
+		sizes.Add("le_256", 1)
+		fallthrough
+	case size <= 1024:
+		sizes.Add("le_1024", 1)
+		fallthrough
+	case size <= 4096:
+		sizes.Add("le_4096", 1)
+		fallthrough
+	case size <= 16384:
+		sizes.Add("le_16384", 1)
+		fallthrough
+	case size <= 65536:
+		sizes.Add("le_65536", 1)
+		fallthrough
+	case size <= 262144:
+		sizes.Add("le_262144", 1)
+		fallthrough
+	default:
+		sizes.Add("le_+Inf", 1)
+	}
+}
+func (m *monitoringSizedServiceExpvar) List(arg1 alias5.Context) ([]alias1.Request, error) {
+	_start := alias3.Now()
+	result1, result2 := m.next.List(arg1)
+	m.totalOps.Add("list", 1)
+	if result2 != nil {
+		m.failedOps.Add("list", 1)
+	}
+	if result2 == nil {
+		m.observeSize(m.listResultSize, int64(len(result1)))
+	}
+	m.observe(m.listDuration, alias3.Since(_start).Seconds())
+	return result1, result2
+}
+func (m *monitoringSizedServiceExpvar) Index(arg1 alias5.Context) (map[string]alias1.Request, error) {
+	_start := alias3.Now()
+	result1, result2 := m.next.Index(arg1)
+	m.totalOps.Add("index", 1)
+	if result2 != nil {
+		m.failedOps.Add("index", 1)
+	}
+	if result2 == nil {
+		m.observeSize(m.indexResultSize, int64(len(result1)))
+	}
+	m.observe(m.indexDuration, alias3.Since(_start).Seconds())
+	return result1, result2
+}
+func (m *monitoringSizedServiceExpvar) Next(arg1 alias5.Context) (*alias1.Batch, error) {
+	_start := alias3.Now()
+	result1, result2 := m.next.Next(arg1)
+	m.totalOps.Add("next", 1)
+	if result2 != nil {
+		m.failedOps.Add("next", 1)
+	}
+	if result2 == nil && result1 != nil {
+		m.observeSize(m.nextResultSize, int64(result1.Len()))
+	}
+	m.observe(m.nextDuration, alias3.Since(_start).Seconds())
+	return result1, result2
+}
+func (m *monitoringSizedServiceExpvar) Current(arg1 alias5.Context) (alias1.Collection, error) {
+	_start := alias3.Now()
+	result1, result2 := m.next.Current(arg1)
+	m.totalOps.Add("current", 1)
+	if result2 != nil {
+		m.failedOps.Add("current", 1)
+	}
+	if result2 == nil && result1 != nil {
+		if _value := alias6.ValueOf(result1); _value.Kind() != alias6.Pointer || !_value.IsNil() {
+			m.observeSize(m.currentResultSize, int64(result1.Len()))
+		}
+	}
+	m.observe(m.currentDuration, alias3.Since(_start).Seconds())
+	return result1, result2
+}
+func (m *monitoringSizedServiceExpvar) Partition(arg1 alias5.Context, arg2 []alias1.Request) ([]alias1.Request, []alias1.Request, error) {
+	_start := alias3.Now()
+	result1, result2, result3 := m.next.Partition(arg1, arg2)
+	m.totalOps.Add("partition", 1)
+	if result3 != nil {
+		m.failedOps.Add("partition", 1)
+	}
+	if result3 == nil {
+		m.observeSize(m.partitionResultSize, int64(len(result2)))
+	}
+	m.observe(m.partitionDuration, alias3.Since(_start).Seconds())
+	return result1, result2, result3
+}
+func (m *monitoringSizedServiceExpvar) Lookup(arg1 string) ([]alias1.Request, bool) {
+	_start := alias3.Now()
+	result1, result2 := m.next.Lookup(arg1)
+	m.totalOps.Add("lookup", 1)
+	if !result2 {
+		m.failedOps.Add("lookup", 1)
+	}
+	if result2 {
+		m.observeSize(m.lookupResultSize, int64(len(result1)))
+	}
+	m.observe(m.lookupDuration, alias3.Since(_start).Seconds())
+	return result1, result2
+}
+func (m *monitoringSizedServiceExpvar) Tags() []string {
+	_start := alias3.Now()
+	result1 := m.next.Tags()
+	m.totalOps.Add("tags", 1)
+	m.observe(m.tagsDuration, alias3.Since(_start).Seconds())
+	return result1
+}
+func (m *monitoringSizedServiceExpvar) Ping(arg1 alias5.Context) error {
+	_start := alias3.Now()
+	result1 := m.next.Ping(arg1)
+	m.totalOps.Add("ping", 1)
+	if result1 != nil {
+		m.failedOps.Add("ping", 1)
+	}
+	m.observe(m.pingDuration, alias3.Since(_start).Seconds())
+	return result1
+}
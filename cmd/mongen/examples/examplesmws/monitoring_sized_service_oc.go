@@ -0,0 +1,238 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.SizedService
+// Source-Hash: sha256:1f2657f52a67efad3387ff686b72db66409045e62a90755ce723249266593bad
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringSizedServiceOC -metrics=true -result-size=true -type=monitoringSizedServiceOC -output-dir . -o monitoring_sized_service_oc.go .. SizedService opencensus
+package examplesmws
+
+import (
+	alias1 "context"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias3 "go.opencensus.io/stats"
+	alias6 "go.opencensus.io/stats/view"
+	alias4 "go.opencensus.io/tag"
+	alias7 "reflect"
+	alias2 "time"
+)
+
+type monitoringSizedServiceOC struct {
+	next               alias5.SizedService
+	totalOps           *alias3.Int64Measure
+	failedOps          *alias3.Int64Measure
+	opsDuration        *alias3.Float64Measure
+	ctxFunc            func(alias1.Context) alias1.Context
+	resultSize         *alias3.Int64Measure
+	listOperation      alias4.Mutator
+	listTags           *alias4.Map
+	indexOperation     alias4.Mutator
+	indexTags          *alias4.Map
+	nextOperation      alias4.Mutator
+	nextTags           *alias4.Map
+	currentOperation   alias4.Mutator
+	currentTags        *alias4.Map
+	partitionOperation alias4.Mutator
+	partitionTags      *alias4.Map
+	lookupOperation    alias4.Mutator
+	lookupTags         *alias4.Map
+	tagsOperation      alias4.Mutator
+	tagsTags           *alias4.Map
+	pingOperation      alias4.Mutator
+	pingTags           *alias4.Map
+}
+
+// NewMonitoringSizedServiceOC creates new monitoring middleware.
+func NewMonitoringSizedServiceOC(next alias5.SizedService, totalOps *alias3.Int64Measure, failedOps *alias3.Int64Measure, opsDuration *alias3.Float64Measure, ctxFunc func(alias1.Context) alias1.Context, resultSize *alias3.Int64Measure) alias5.SizedService {
+	m := &monitoringSizedServiceOC{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, ctxFunc: ctxFunc, resultSize: resultSize}
+	operationTagKey := alias4.MustNewKey("operation")
+	bind := func(operation string) (alias4.Mutator, *alias4.Map) {
+		mutator := alias4.Insert(operationTagKey, operation)
+		ctx, _ := alias4.New(alias1.Background(), mutator)
+		return mutator, alias4.FromContext(ctx)
+	}
+	m.listOperation, m.listTags = bind("list")
+	m.indexOperation, m.indexTags = bind("index")
+	m.nextOperation, m.nextTags = bind("next")
+	m.currentOperation, m.currentTags = bind("current")
+	m.partitionOperation, m.partitionTags = bind("partition")
+	m.lookupOperation, m.lookupTags = bind("lookup")
+	m.tagsOperation, m.tagsTags = bind("tags")
+	m.pingOperation, m.pingTags = bind("ping")
+	return m
+}
+
+// tagContext returns the context tagged with the mutators, the first of which tags the operation.
+// A context without tags is given the operationTags when the operation is its only tag, which saves copying them.
+// The mutators that fail, e.g. because of invalid label values, are left out.
+func (m *monitoringSizedServiceOC) tagContext(ctx alias1.Context, operationTags *alias4.Map, mutators ...alias4.Mutator) alias1.Context {
+	if len(mutators) == 1 && alias4.FromContext(ctx) == nil {
+		return alias4.NewContext(ctx, operationTags)
+	}
+	if taggedCtx, err := alias4.New(ctx, mutators...); err == nil {
+		return taggedCtx
+	}
+	for _, mutator := range mutators {
+		if taggedCtx, err := alias4.New(ctx, mutator); err == nil {
+			ctx = taggedCtx
+		}
+	}
+	return ctx
+}
+
+// MonitoringSizedServiceOCMetrics holds the metrics recorded by the monitoring middleware.
+type MonitoringSizedServiceOCMetrics struct {
+	TotalOps    *alias3.Int64Measure
+	FailedOps   *alias3.Int64Measure
+	OpsDuration *alias3.Float64Measure
+	ResultSize  *alias3.Int64Measure
+	Views       []*alias6.View
+}
+
+// NewMonitoringSizedServiceOCMetrics creates measures with names prefixed by the specified namespace
+// and the views that aggregate them. The views must be registered with view.Register.
+func NewMonitoringSizedServiceOCMetrics(namespace string) *MonitoringSizedServiceOCMetrics {
+	ms := &MonitoringSizedServiceOCMetrics{TotalOps: alias3.Int64(namespace+"/total_ops", "Total number of operations.", alias3.UnitDimensionless), FailedOps: alias3.Int64(namespace+"/failed_ops", "Number of failed operations.", alias3.UnitDimensionless), OpsDuration: alias3.Float64(namespace+"/ops_duration_seconds", "Duration of operations in seconds.", alias3.UnitSeconds), ResultSize: alias3.Int64(namespace+"/result_size", "Number of items in the results of operations.", alias3.UnitDimensionless)}
+	ms.Views = []*alias6.View{{Name: namespace + "/total_ops", Description: "Total number of operations.", Measure: ms.TotalOps, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Count()}, {Name: namespace + "/failed_ops", Description: "Number of failed operations.", Measure: ms.FailedOps, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Count()}, {Name: namespace + "/ops_duration_seconds", Description: "Duration of operations in seconds.", Measure: ms.OpsDuration, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Distribution(.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10)}, {Name: namespace + "/result_size", Description: "Number of items in the results of operations.", Measure: ms.ResultSize, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Distribution(1, 4, 16, 64, 256, 1024, 4096, 16384, 65536, 262144)}}
+	return ms
+}
+
+// Wrap wraps next with monitoring middleware created by NewMonitoringSizedServiceOC that records the metrics.
+func (ms *MonitoringSizedServiceOCMetrics) Wrap(next alias5.SizedService, ctxFunc func(alias1.Context) alias1.Context) alias5.SizedService {
+	return NewMonitoringSizedServiceOC(next, ms.TotalOps, ms.FailedOps, ms.OpsDuration, ctxFunc, ms.ResultSize)
+}
+func (m *monitoringSizedServiceOC) List(arg1 alias1.Context) ([]alias5.Request, error) {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.listTags, m.listOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1, result2 := m.next.List(arg1)
+	if result2 == nil {
+		alias3.Record(ctx, m.resultSize.M(int64(len(result1))))
+	}
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result2 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServiceOC) Index(arg1 alias1.Context) (map[string]alias5.Request, error) {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.indexTags, m.indexOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1, result2 := m.next.Index(arg1)
+	if result2 == nil {
+		alias3.Record(ctx, m.resultSize.M(int64(len(result1))))
+	}
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result2 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServiceOC) Next(arg1 alias1.Context) (*alias5.Batch, error) {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.nextTags, m.nextOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1, result2 := m.next.Next(arg1)
+	if result2 == nil && result1 != nil {
+		alias3.Record(ctx, m.resultSize.M(int64(result1.Len())))
+	}
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result2 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServiceOC) Current(arg1 alias1.Context) (alias5.Collection, error) {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.currentTags, m.currentOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1, result2 := m.next.Current(arg1)
+	if result2 == nil && result1 != nil {
+		if _value := alias7.ValueOf(result1); _value.Kind() != alias7.Pointer || !_value.IsNil() {
+			alias3.Record(ctx, m.resultSize.M(int64(result1.Len())))
+		}
+	}
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result2 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServiceOC) Partition(arg1 alias1.Context, arg2 []alias5.Request) ([]alias5.Request, []alias5.Request, error) {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.partitionTags, m.partitionOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1, result2, result3 := m.next.Partition(arg1, arg2)
+	if result3 == nil {
+		alias3.Record(ctx, m.resultSize.M(int64(len(result2))))
+	}
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result3 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1, result2, result3
+}
+func (m *monitoringSizedServiceOC) Lookup(arg1 string) ([]alias5.Request, bool) {
+	ctx := alias1.Background()
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.lookupTags, m.lookupOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1, result2 := m.next.Lookup(arg1)
+	if result2 {
+		alias3.Record(ctx, m.resultSize.M(int64(len(result1))))
+	}
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if !result2 {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServiceOC) Tags() []string {
+	ctx := alias1.Background()
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.tagsTags, m.tagsOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1 := m.next.Tags()
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	return result1
+}
+func (m *monitoringSizedServiceOC) Ping(arg1 alias1.Context) error {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.pingTags, m.pingOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1 := m.next.Ping(arg1)
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result1 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1
+}
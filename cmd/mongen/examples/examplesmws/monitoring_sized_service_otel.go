@@ -0,0 +1,192 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.SizedService
+// Source-Hash: sha256:1f2657f52a67efad3387ff686b72db66409045e62a90755ce723249266593bad
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringSizedServiceOtel -result-size=true -type=monitoringSizedServiceOtel -output-dir . -o monitoring_sized_service_otel.go .. SizedService otel
+package examplesmws
+
+import (
+	alias1 "context"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias4 "go.opentelemetry.io/otel/attribute"
+	alias3 "go.opentelemetry.io/otel/metric"
+	alias6 "reflect"
+	alias2 "time"
+)
+
+type monitoringSizedServiceOtel struct {
+	next                  alias5.SizedService
+	totalOps              alias3.Int64Counter
+	failedOps             alias3.Int64Counter
+	opsDuration           alias3.Float64Histogram
+	resultSize            alias3.Int64Histogram
+	listAttrs             alias3.MeasurementOption
+	listSuccessAttrs      alias3.MeasurementOption
+	listErrorAttrs        alias3.MeasurementOption
+	indexAttrs            alias3.MeasurementOption
+	indexSuccessAttrs     alias3.MeasurementOption
+	indexErrorAttrs       alias3.MeasurementOption
+	nextAttrs             alias3.MeasurementOption
+	nextSuccessAttrs      alias3.MeasurementOption
+	nextErrorAttrs        alias3.MeasurementOption
+	currentAttrs          alias3.MeasurementOption
+	currentSuccessAttrs   alias3.MeasurementOption
+	currentErrorAttrs     alias3.MeasurementOption
+	partitionAttrs        alias3.MeasurementOption
+	partitionSuccessAttrs alias3.MeasurementOption
+	partitionErrorAttrs   alias3.MeasurementOption
+	lookupAttrs           alias3.MeasurementOption
+	lookupSuccessAttrs    alias3.MeasurementOption
+	lookupErrorAttrs      alias3.MeasurementOption
+	tagsAttrs             alias3.MeasurementOption
+	tagsSuccessAttrs      alias3.MeasurementOption
+	tagsErrorAttrs        alias3.MeasurementOption
+	pingAttrs             alias3.MeasurementOption
+	pingSuccessAttrs      alias3.MeasurementOption
+	pingErrorAttrs        alias3.MeasurementOption
+}
+
+// NewMonitoringSizedServiceOtel creates new monitoring middleware.
+func NewMonitoringSizedServiceOtel(next alias5.SizedService, totalOps, failedOps alias3.Int64Counter, opsDuration alias3.Float64Histogram, resultSize alias3.Int64Histogram) alias5.SizedService {
+	return &monitoringSizedServiceOtel{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, resultSize: resultSize, listAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "list"))), listSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "list"), alias4.String("outcome", "success"))), listErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "list"), alias4.String("outcome", "error"))), indexAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "index"))), indexSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "index"), alias4.String("outcome", "success"))), indexErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "index"), alias4.String("outcome", "error"))), nextAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "next"))), nextSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "next"), alias4.String("outcome", "success"))), nextErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "next"), alias4.String("outcome", "error"))), currentAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "current"))), currentSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "current"), alias4.String("outcome", "success"))), currentErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "current"), alias4.String("outcome", "error"))), partitionAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "partition"))), partitionSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "partition"), alias4.String("outcome", "success"))), partitionErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "partition"), alias4.String("outcome", "error"))), lookupAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "lookup"))), lookupSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "lookup"), alias4.String("outcome", "success"))), lookupErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "lookup"), alias4.String("outcome", "error"))), tagsAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "tags"))), tagsSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "tags"), alias4.String("outcome", "success"))), tagsErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "tags"), alias4.String("outcome", "error"))), pingAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "ping"))), pingSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "ping"), alias4.String("outcome", "success"))), pingErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "ping"), alias4.String("outcome", "error")))}
+}
+
+// NewMonitoringSizedServiceOtelFromMeter creates new monitoring middleware with instruments created by meter.
+func NewMonitoringSizedServiceOtelFromMeter(next alias5.SizedService, meter alias3.Meter) (alias5.SizedService, error) {
+	totalOps, err := meter.Int64Counter("total_ops", alias3.WithDescription("Total number of operations."))
+	if err != nil {
+		return nil, err
+	}
+	failedOps, err := meter.Int64Counter("failed_ops", alias3.WithDescription("Number of failed operations."))
+	if err != nil {
+		return nil, err
+	}
+	opsDuration, err := meter.Float64Histogram("ops_duration", alias3.WithDescription("Duration of operations."), alias3.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	resultSize, err := meter.Int64Histogram("result_size", alias3.WithDescription("Number of items in the results of operations."))
+	if err != nil {
+		return nil, err
+	}
+	return NewMonitoringSizedServiceOtel(next, totalOps, failedOps, opsDuration, resultSize), nil
+}
+func (m *monitoringSizedServiceOtel) List(arg1 alias1.Context) ([]alias5.Request, error) {
+	ctx := arg1
+	m.totalOps.Add(ctx, 1, m.listAttrs)
+	_start := alias2.Now()
+	result1, result2 := m.next.List(arg1)
+	_outcome := m.listSuccessAttrs
+	if result2 != nil {
+		_outcome = m.listErrorAttrs
+		m.failedOps.Add(ctx, 1, m.listErrorAttrs)
+	}
+	if result2 == nil {
+		m.resultSize.Record(ctx, int64(len(result1)), m.listAttrs)
+	}
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), _outcome)
+	return result1, result2
+}
+func (m *monitoringSizedServiceOtel) Index(arg1 alias1.Context) (map[string]alias5.Request, error) {
+	ctx := arg1
+	m.totalOps.Add(ctx, 1, m.indexAttrs)
+	_start := alias2.Now()
+	result1, result2 := m.next.Index(arg1)
+	_outcome := m.indexSuccessAttrs
+	if result2 != nil {
+		_outcome = m.indexErrorAttrs
+		m.failedOps.Add(ctx, 1, m.indexErrorAttrs)
+	}
+	if result2 == nil {
+		m.resultSize.Record(ctx, int64(len(result1)), m.indexAttrs)
+	}
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), _outcome)
+	return result1, result2
+}
+func (m *monitoringSizedServiceOtel) Next(arg1 alias1.Context) (*alias5.Batch, error) {
+	ctx := arg1
+	m.totalOps.Add(ctx, 1, m.nextAttrs)
+	_start := alias2.Now()
+	result1, result2 := m.next.Next(arg1)
+	_outcome := m.nextSuccessAttrs
+	if result2 != nil {
+		_outcome = m.nextErrorAttrs
+		m.failedOps.Add(ctx, 1, m.nextErrorAttrs)
+	}
+	if result2 == nil && result1 != nil {
+		m.resultSize.Record(ctx, int64(result1.Len()), m.nextAttrs)
+	}
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), _outcome)
+	return result1, result2
+}
+func (m *monitoringSizedServiceOtel) Current(arg1 alias1.Context) (alias5.Collection, error) {
+	ctx := arg1
+	m.totalOps.Add(ctx, 1, m.currentAttrs)
+	_start := alias2.Now()
+	result1, result2 := m.next.Current(arg1)
+	_outcome := m.currentSuccessAttrs
+	if result2 != nil {
+		_outcome = m.currentErrorAttrs
+		m.failedOps.Add(ctx, 1, m.currentErrorAttrs)
+	}
+	if result2 == nil && result1 != nil {
+		if _value := alias6.ValueOf(result1); _value.Kind() != alias6.Pointer || !_value.IsNil() {
+			m.resultSize.Record(ctx, int64(result1.Len()), m.currentAttrs)
+		}
+	}
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), _outcome)
+	return result1, result2
+}
+func (m *monitoringSizedServiceOtel) Partition(arg1 alias1.Context, arg2 []alias5.Request) ([]alias5.Request, []alias5.Request, error) {
+	ctx := arg1
+	m.totalOps.Add(ctx, 1, m.partitionAttrs)
+	_start := alias2.Now()
+	result1, result2, result3 := m.next.Partition(arg1, arg2)
+	_outcome := m.partitionSuccessAttrs
+	if result3 != nil {
+		_outcome = m.partitionErrorAttrs
+		m.failedOps.Add(ctx, 1, m.partitionErrorAttrs)
+	}
+	if result3 == nil {
+		m.resultSize.Record(ctx, int64(len(result2)), m.partitionAttrs)
+	}
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), _outcome)
+	return result1, result2, result3
+}
+func (m *monitoringSizedServiceOtel) Lookup(arg1 string) ([]alias5.Request, bool) {
+	ctx := alias1.Background()
+	m.totalOps.Add(ctx, 1, m.lookupAttrs)
+	_start := alias2.Now()
+	result1, result2 := m.next.Lookup(arg1)
+	_outcome := m.lookupSuccessAttrs
+	if !result2 {
+		_outcome = m.lookupErrorAttrs
+		m.failedOps.Add(ctx, 1, m.lookupErrorAttrs)
+	}
+	if result2 {
+		m.resultSize.Record(ctx, int64(len(result1)), m.lookupAttrs)
+	}
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), _outcome)
+	return result1, result2
+}
+func (m *monitoringSizedServiceOtel) Tags() []string {
+	ctx := alias1.Background()
+	m.totalOps.Add(ctx, 1, m.tagsAttrs)
+	_start := alias2.Now()
+	result1 := m.next.Tags()
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), m.tagsSuccessAttrs)
+	return result1
+}
+func (m *monitoringSizedServiceOtel) Ping(arg1 alias1.Context) error {
+	ctx := arg1
+	m.totalOps.Add(ctx, 1, m.pingAttrs)
+	_start := alias2.Now()
+	result1 := m.next.Ping(arg1)
+	_outcome := m.pingSuccessAttrs
+	if result1 != nil {
+		_outcome = m.pingErrorAttrs
+		m.failedOps.Add(ctx, 1, m.pingErrorAttrs)
+	}
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), _outcome)
+	return result1
+}
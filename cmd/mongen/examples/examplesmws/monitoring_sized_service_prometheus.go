@@ -0,0 +1,175 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.SizedService
+// Source-Hash: sha256:1f2657f52a67efad3387ff686b72db66409045e62a90755ce723249266593bad
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringSizedServicePrometheus -result-size=true -type=monitoringSizedServicePrometheus -output-dir . -o monitoring_sized_service_prometheus.go .. SizedService prometheus
+package examplesmws
+
+import (
+	alias4 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/prometheus/client_golang/prometheus"
+	alias5 "reflect"
+	alias3 "time"
+)
+
+type monitoringSizedServicePrometheus struct {
+	next                 alias1.SizedService
+	listTotalOps         alias2.Counter
+	listFailedOps        alias2.Counter
+	listOpsDuration      alias2.Observer
+	listResultSize       alias2.Observer
+	indexTotalOps        alias2.Counter
+	indexFailedOps       alias2.Counter
+	indexOpsDuration     alias2.Observer
+	indexResultSize      alias2.Observer
+	nextTotalOps         alias2.Counter
+	nextFailedOps        alias2.Counter
+	nextOpsDuration      alias2.Observer
+	nextResultSize       alias2.Observer
+	currentTotalOps      alias2.Counter
+	currentFailedOps     alias2.Counter
+	currentOpsDuration   alias2.Observer
+	currentResultSize    alias2.Observer
+	partitionTotalOps    alias2.Counter
+	partitionFailedOps   alias2.Counter
+	partitionOpsDuration alias2.Observer
+	partitionResultSize  alias2.Observer
+	lookupTotalOps       alias2.Counter
+	lookupFailedOps      alias2.Counter
+	lookupOpsDuration    alias2.Observer
+	lookupResultSize     alias2.Observer
+	tagsTotalOps         alias2.Counter
+	tagsFailedOps        alias2.Counter
+	tagsOpsDuration      alias2.Observer
+	pingTotalOps         alias2.Counter
+	pingFailedOps        alias2.Counter
+	pingOpsDuration      alias2.Observer
+}
+
+// NewMonitoringSizedServicePrometheus creates new monitoring middleware.
+func NewMonitoringSizedServicePrometheus(next alias1.SizedService, totalOps, failedOps *alias2.CounterVec, opsDuration, resultSize *alias2.HistogramVec) alias1.SizedService {
+	return &monitoringSizedServicePrometheus{next: next, listTotalOps: totalOps.WithLabelValues("list"), listFailedOps: failedOps.WithLabelValues("list"), listOpsDuration: opsDuration.WithLabelValues("list"), listResultSize: resultSize.WithLabelValues("list"), indexTotalOps: totalOps.WithLabelValues("index"), indexFailedOps: failedOps.WithLabelValues("index"), indexOpsDuration: opsDuration.WithLabelValues("index"), indexResultSize: resultSize.WithLabelValues("index"), nextTotalOps: totalOps.WithLabelValues("next"), nextFailedOps: failedOps.WithLabelValues("next"), nextOpsDuration: opsDuration.WithLabelValues("next"), nextResultSize: resultSize.WithLabelValues("next"), currentTotalOps: totalOps.WithLabelValues("current"), currentFailedOps: failedOps.WithLabelValues("current"), currentOpsDuration: opsDuration.WithLabelValues("current"), currentResultSize: resultSize.WithLabelValues("current"), partitionTotalOps: totalOps.WithLabelValues("partition"), partitionFailedOps: failedOps.WithLabelValues("partition"), partitionOpsDuration: opsDuration.WithLabelValues("partition"), partitionResultSize: resultSize.WithLabelValues("partition"), lookupTotalOps: totalOps.WithLabelValues("lookup"), lookupFailedOps: failedOps.WithLabelValues("lookup"), lookupOpsDuration: opsDuration.WithLabelValues("lookup"), lookupResultSize: resultSize.WithLabelValues("lookup"), tagsTotalOps: totalOps.WithLabelValues("tags"), tagsFailedOps: failedOps.WithLabelValues("tags"), tagsOpsDuration: opsDuration.WithLabelValues("tags"), pingTotalOps: totalOps.WithLabelValues("ping"), pingFailedOps: failedOps.WithLabelValues("ping"), pingOpsDuration: opsDuration.WithLabelValues("ping")}
+}
+
+// NewMonitoringSizedServicePrometheusCollectors creates the collectors expected by NewMonitoringSizedServicePrometheus.
+func NewMonitoringSizedServicePrometheusCollectors(namespace, subsystem string) (totalOps, failedOps *alias2.CounterVec, opsDuration, resultSize *alias2.HistogramVec) {
+	totalOps = alias2.NewCounterVec(alias2.CounterOpts{Namespace: namespace, Subsystem: subsystem, Name: "total_ops", Help: "Total number of operations."}, []string{"operation"})
+	failedOps = alias2.NewCounterVec(alias2.CounterOpts{Namespace: namespace, Subsystem: subsystem, Name: "failed_ops", Help: "Number of failed operations."}, []string{"operation"})
+	opsDuration = alias2.NewHistogramVec(alias2.HistogramOpts{Namespace: namespace, Subsystem: subsystem, Name: "ops_duration_seconds", Help: "Duration of operations in seconds.", Buckets: alias2.DefBuckets}, []string{"operation"})
+	resultSize = alias2.NewHistogramVec(alias2.HistogramOpts{Namespace: namespace, Subsystem: subsystem, Name: "result_size", Help: "Number of items in the results of operations.", Buckets: []float64{1, 4, 16, 64, 256, 1024, 4096, 16384, 65536, 262144}}, []string{"operation"})
+	return totalOps, failedOps, opsDuration, resultSize
+}
+
+// RegisterMonitoringSizedServicePrometheus creates new monitoring middleware and registers its collectors with reg.
+// If a collector cannot be registered, the collectors registered before it are unregistered.
+func RegisterMonitoringSizedServicePrometheus(reg alias2.Registerer, next alias1.SizedService, namespace, subsystem string) (alias1.SizedService, error) {
+	totalOps, failedOps, opsDuration, resultSize := NewMonitoringSizedServicePrometheusCollectors(namespace, subsystem)
+	collectors := []alias2.Collector{totalOps, failedOps, opsDuration, resultSize}
+	for i, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			for _, registered := range collectors[:i] {
+				reg.Unregister(registered)
+			}
+			return nil, err
+		}
+	}
+	return NewMonitoringSizedServicePrometheus(next, totalOps, failedOps, opsDuration, resultSize), nil
+}
+func (m *monitoringSizedServicePrometheus) List(arg1 alias4.Context) ([]alias1.Request, error) {
+	m.listTotalOps.Inc()
+	_start := alias3.Now()
+	result1, result2 := m.next.List(arg1)
+	if result2 == nil {
+		m.listResultSize.Observe(float64(len(result1)))
+	}
+	m.listOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.listFailedOps.Inc()
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServicePrometheus) Index(arg1 alias4.Context) (map[string]alias1.Request, error) {
+	m.indexTotalOps.Inc()
+	_start := alias3.Now()
+	result1, result2 := m.next.Index(arg1)
+	if result2 == nil {
+		m.indexResultSize.Observe(float64(len(result1)))
+	}
+	m.indexOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.indexFailedOps.Inc()
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServicePrometheus) Next(arg1 alias4.Context) (*alias1.Batch, error) {
+	m.nextTotalOps.Inc()
+	_start := alias3.Now()
+	result1, result2 := m.next.Next(arg1)
+	if result2 == nil && result1 != nil {
+		m.nextResultSize.Observe(float64(result1.Len()))
+	}
+	m.nextOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.nextFailedOps.Inc()
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServicePrometheus) Current(arg1 alias4.Context) (alias1.Collection, error) {
+	m.currentTotalOps.Inc()
+	_start := alias3.Now()
+	result1, result2 := m.next.Current(arg1)
+	if result2 == nil && result1 != nil {
+		if _value := alias5.ValueOf(result1); _value.Kind() != alias5.Pointer || !_value.IsNil() {
+			m.currentResultSize.Observe(float64(result1.Len()))
+		}
+	}
+	m.currentOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.currentFailedOps.Inc()
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServicePrometheus) Partition(arg1 alias4.Context, arg2 []alias1.Request) ([]alias1.Request, []alias1.Request, error) {
+	m.partitionTotalOps.Inc()
+	_start := alias3.Now()
+	result1, result2, result3 := m.next.Partition(arg1, arg2)
+	if result3 == nil {
+		m.partitionResultSize.Observe(float64(len(result2)))
+	}
+	m.partitionOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result3 != nil {
+		m.partitionFailedOps.Inc()
+	}
+	return result1, result2, result3
+}
+func (m *monitoringSizedServicePrometheus) Lookup(arg1 string) ([]alias1.Request, bool) {
+	m.lookupTotalOps.Inc()
+	_start := alias3.Now()
+	result1, result2 := m.next.Lookup(arg1)
+	if result2 {
+		m.lookupResultSize.Observe(float64(len(result1)))
+	}
+	m.lookupOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if !result2 {
+		m.lookupFailedOps.Inc()
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServicePrometheus) Tags() []string {
+	m.tagsTotalOps.Inc()
+	_start := alias3.Now()
+	result1 := m.next.Tags()
+	m.tagsOpsDuration.Observe(alias3.Since(_start).Seconds())
+	return result1
+}
+func (m *monitoringSizedServicePrometheus) Ping(arg1 alias4.Context) error {
+	m.pingTotalOps.Inc()
+	_start := alias3.Now()
+	result1 := m.next.Ping(arg1)
+	m.pingOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.pingFailedOps.Inc()
+	}
+	return result1
+}
@@ -0,0 +1,129 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.SizedService
+// Source-Hash: sha256:1f2657f52a67efad3387ff686b72db66409045e62a90755ce723249266593bad
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringSizedServiceStatsd -result-size=true -type=monitoringSizedServiceStatsd -output-dir . -o monitoring_sized_service_statsd.go .. SizedService statsd
+package examplesmws
+
+import (
+	alias4 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/Bo0mer/gentools/pkg/statsd"
+	alias5 "reflect"
+	alias3 "time"
+)
+
+type monitoringSizedServiceStatsd struct {
+	next          alias1.SizedService
+	emitter       alias2.Emitter
+	listTags      []string
+	indexTags     []string
+	nextTags      []string
+	currentTags   []string
+	partitionTags []string
+	lookupTags    []string
+	tagsTags      []string
+	pingTags      []string
+}
+
+// NewMonitoringSizedServiceStatsd creates new monitoring middleware.
+func NewMonitoringSizedServiceStatsd(next alias1.SizedService, emitter alias2.Emitter) alias1.SizedService {
+	return &monitoringSizedServiceStatsd{next: next, emitter: emitter, listTags: []string{"operation:list"}, indexTags: []string{"operation:index"}, nextTags: []string{"operation:next"}, currentTags: []string{"operation:current"}, partitionTags: []string{"operation:partition"}, lookupTags: []string{"operation:lookup"}, tagsTags: []string{"operation:tags"}, pingTags: []string{"operation:ping"}}
+}
+func (m *monitoringSizedServiceStatsd) List(arg1 alias4.Context) ([]alias1.Request, error) {
+	m.emitter.Count("total_ops", 1, m.listTags...)
+	_start := alias3.Now()
+	result1, result2 := m.next.List(arg1)
+	m.emitter.Timing("ops_duration", alias3.Since(_start), m.listTags...)
+	if result2 != nil {
+		m.emitter.Count("failed_ops", 1, m.listTags...)
+	}
+	if result2 == nil {
+		m.emitter.Histogram("result_size", float64(len(result1)), m.listTags...)
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServiceStatsd) Index(arg1 alias4.Context) (map[string]alias1.Request, error) {
+	m.emitter.Count("total_ops", 1, m.indexTags...)
+	_start := alias3.Now()
+	result1, result2 := m.next.Index(arg1)
+	m.emitter.Timing("ops_duration", alias3.Since(_start), m.indexTags...)
+	if result2 != nil {
+		m.emitter.Count("failed_ops", 1, m.indexTags...)
+	}
+	if result2 == nil {
+		m.emitter.Histogram("result_size", float64(len(result1)), m.indexTags...)
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServiceStatsd) Next(arg1 alias4.Context) (*alias1.Batch, error) {
+	m.emitter.Count("total_ops", 1, m.nextTags...)
+	_start := alias3.Now()
+	result1, result2 := m.next.Next(arg1)
+	m.emitter.Timing("ops_duration", alias3.Since(_start), m.nextTags...)
+	if result2 != nil {
+		m.emitter.Count("failed_ops", 1, m.nextTags...)
+	}
+	if result2 == nil && result1 != nil {
+		m.emitter.Histogram("result_size", float64(result1.Len()), m.nextTags...)
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServiceStatsd) Current(arg1 alias4.Context) (alias1.Collection, error) {
+	m.emitter.Count("total_ops", 1, m.currentTags...)
+	_start := alias3.Now()
+	result1, result2 := m.next.Current(arg1)
+	m.emitter.Timing("ops_duration", alias3.Since(_start), m.currentTags...)
+	if result2 != nil {
+		m.emitter.Count("failed_ops", 1, m.currentTags...)
+	}
+	if result2 == nil && result1 != nil {
+		if _value := alias5.ValueOf(result1); _value.Kind() != alias5.Pointer || !_value.IsNil() {
+			m.emitter.Histogram("result_size", float64(result1.Len()), m.currentTags...)
+		}
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServiceStatsd) Partition(arg1 alias4.Context, arg2 []alias1.Request) ([]alias1.Request, []alias1.Request, error) {
+	m.emitter.Count("total_ops", 1, m.partitionTags...)
+	_start := alias3.Now()
+	result1, result2, result3 := m.next.Partition(arg1, arg2)
+	m.emitter.Timing("ops_duration", alias3.Since(_start), m.partitionTags...)
+	if result3 != nil {
+		m.emitter.Count("failed_ops", 1, m.partitionTags...)
+	}
+	if result3 == nil {
+		m.emitter.Histogram("result_size", float64(len(result2)), m.partitionTags...)
+	}
+	return result1, result2, result3
+}
+func (m *monitoringSizedServiceStatsd) Lookup(arg1 string) ([]alias1.Request, bool) {
+	m.emitter.Count("total_ops", 1, m.lookupTags...)
+	_start := alias3.Now()
+	result1, result2 := m.next.Lookup(arg1)
+	m.emitter.Timing("ops_duration", alias3.Since(_start), m.lookupTags...)
+	if !result2 {
+		m.emitter.Count("failed_ops", 1, m.lookupTags...)
+	}
+	if result2 {
+		m.emitter.Histogram("result_size", float64(len(result1)), m.lookupTags...)
+	}
+	return result1, result2
+}
+func (m *monitoringSizedServiceStatsd) Tags() []string {
+	m.emitter.Count("total_ops", 1, m.tagsTags...)
+	_start := alias3.Now()
+	result1 := m.next.Tags()
+	m.emitter.Timing("ops_duration", alias3.Since(_start), m.tagsTags...)
+	return result1
+}
+func (m *monitoringSizedServiceStatsd) Ping(arg1 alias4.Context) error {
+	m.emitter.Count("total_ops", 1, m.pingTags...)
+	_start := alias3.Now()
+	result1 := m.next.Ping(arg1)
+	m.emitter.Timing("ops_duration", alias3.Since(_start), m.pingTags...)
+	if result1 != nil {
+		m.emitter.Count("failed_ops", 1, m.pingTags...)
+	}
+	return result1
+}
@@ -0,0 +1,158 @@
+package examplesmws_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples"
+	"github.com/Bo0mer/gentools/cmd/mongen/examples/examplesmws"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// sizedService returns n requests from every method, in a nil batch if
+// nilBatch is set, or fails with err.
+type sizedService struct {
+	n        int
+	nilBatch bool
+	err      error
+}
+
+func (s sizedService) requests() []examples.Request {
+	return make([]examples.Request, s.n)
+}
+
+func (s sizedService) List(context.Context) ([]examples.Request, error) {
+	return s.requests(), s.err
+}
+
+func (s sizedService) Index(context.Context) (map[string]examples.Request, error) {
+	index := make(map[string]examples.Request)
+	for i := 0; i < s.n; i++ {
+		index[string(rune('a'+i))] = examples.Request{}
+	}
+	return index, s.err
+}
+
+func (s sizedService) batch() *examples.Batch {
+	if s.nilBatch {
+		return nil
+	}
+	return &examples.Batch{Requests: s.requests()}
+}
+
+func (s sizedService) Next(context.Context) (*examples.Batch, error) {
+	return s.batch(), s.err
+}
+
+func (s sizedService) Current(context.Context) (examples.Collection, error) {
+	return s.batch(), s.err
+}
+
+func (s sizedService) Partition(context.Context, []examples.Request) ([]examples.Request, []examples.Request, error) {
+	return nil, s.requests(), s.err
+}
+
+func (s sizedService) Lookup(string) ([]examples.Request, bool) {
+	return s.requests(), s.err == nil
+}
+
+func (s sizedService) Tags() []string { return make([]string, s.n) }
+
+func (s sizedService) Ping(context.Context) error { return s.err }
+
+// resultSizes returns the number and the sum of the result sizes recorded for
+// every operation.
+func resultSizes(t *testing.T, gatherer prometheus.Gatherer) map[string][2]float64 {
+	t.Helper()
+	families, err := gatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes := make(map[string][2]float64)
+	for _, family := range families {
+		if family.GetName() != "sized_result_size" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			h := metric.GetHistogram()
+			sizes[metric.GetLabel()[0].GetValue()] = [2]float64{float64(h.GetSampleCount()), h.GetSampleSum()}
+		}
+	}
+	return sizes
+}
+
+func TestSizedServiceRecordsTheSizesOfTheSelectedResults(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	svc, err := examplesmws.RegisterMonitoringSizedServicePrometheus(registry, sizedService{n: 3}, "", "sized")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	svc.List(ctx)
+	svc.Index(ctx)
+	svc.Next(ctx)
+	svc.Current(ctx)
+	svc.Partition(ctx, nil)
+	svc.Lookup("key")
+	svc.Tags()
+	svc.Ping(ctx)
+
+	// Partition records the size of its second result, selected with a
+	// directive, and Tags records none, as selected with a directive.
+	want := map[string][2]float64{
+		"list":      {1, 3},
+		"index":     {1, 3},
+		"next":      {1, 3},
+		"current":   {1, 3},
+		"partition": {1, 3},
+		"lookup":    {1, 3},
+	}
+	got := resultSizes(t, registry)
+	for operation, size := range want {
+		if got[operation] != size {
+			t.Errorf("got %v sizes of %s, want %v", got[operation], operation, size)
+		}
+	}
+	if _, ok := got["tags"]; ok {
+		t.Error("got sizes of tags, want none")
+	}
+}
+
+func TestSizedServiceDoesNotRecordTheSizesOfFailedCalls(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	svc, err := examplesmws.RegisterMonitoringSizedServicePrometheus(registry, sizedService{n: 3, err: errors.New("failed")}, "", "sized")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	svc.List(ctx)
+	svc.Next(ctx)
+	// Lookup fails if its second result is false.
+	svc.Lookup("key")
+
+	for operation, size := range resultSizes(t, registry) {
+		if size[0] != 0 {
+			t.Errorf("got %v sizes of %s, want none", size, operation)
+		}
+	}
+}
+
+func TestSizedServiceDoesNotRecordTheSizesOfNilPointers(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	svc, err := examplesmws.RegisterMonitoringSizedServicePrometheus(registry, sizedService{nilBatch: true}, "", "sized")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	// The Len method of a nil *Batch panics, whether it is returned as
+	// such or held by a Collection.
+	svc.Next(ctx)
+	svc.Current(ctx)
+
+	for operation, size := range resultSizes(t, registry) {
+		if size[0] != 0 {
+			t.Errorf("got %v sizes of %s, want none", size, operation)
+		}
+	}
+}
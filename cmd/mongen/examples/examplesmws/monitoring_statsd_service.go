@@ -0,0 +1,88 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.StatsdService
+// Source-Hash: sha256:dc6852063af9ef0f339ef4dbb1434a0767de5255ac6e50f5ca66a48de1d8eeee
+// Generator: mongen v2.1.0
+// Args: -classify-errors=true -output-dir . -o monitoring_statsd_service.go .. StatsdService statsd
+package examplesmws
+
+import (
+	alias6 "context"
+	alias5 "errors"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/Bo0mer/gentools/pkg/statsd"
+	alias4 "sync"
+	alias3 "time"
+)
+
+type monitoringStatsdService struct {
+	next           alias1.StatsdService
+	emitter        alias2.Emitter
+	classifyError  func(error) string
+	maxLabelValues int
+	labelsMu       alias4.Mutex
+	labelValues    map[string]map[string]bool
+	handleTags     []string
+	versionTags    []string
+}
+
+// NewMonitoringStatsdService creates new monitoring middleware.
+func NewMonitoringStatsdService(next alias1.StatsdService, emitter alias2.Emitter, maxLabelValues int, classifyError func(error) string) alias1.StatsdService {
+	return &monitoringStatsdService{next: next, emitter: emitter, handleTags: []string{"operation:handle"}, versionTags: []string{"operation:version"}, maxLabelValues: maxLabelValues, labelValues: map[string]map[string]bool{}, classifyError: classifyError}
+}
+
+// errorClass returns the class of the error recorded in the error_class label.
+func (m *monitoringStatsdService) errorClass(err error) string {
+	if err == nil {
+		return "failed"
+	}
+	if m.classifyError != nil {
+		return m.labelValue("error_class", m.classifyError(err))
+	}
+	switch {
+	case alias5.Is(err, alias6.Canceled):
+		return "canceled"
+	case alias5.Is(err, alias6.DeadlineExceeded):
+		return "timeout"
+	}
+	return "error"
+}
+
+// labelValue returns the value of the label, or "other" once the label has maxLabelValues distinct values.
+func (m *monitoringStatsdService) labelValue(label, value string) string {
+	if m.maxLabelValues <= 0 {
+		return value
+	}
+	m.labelsMu.Lock()
+	defer m.labelsMu.Unlock()
+	values := m.labelValues[label]
+	if values[value] {
+		return value
+	}
+	if len(values) >= m.maxLabelValues {
+		return "other"
+	}
+	if values == nil {
+		values = make(map[string]bool)
+		m.labelValues[label] = values
+	}
+	values[value] = true
+	return value
+}
+func (m *monitoringStatsdService) Handle(arg1 alias6.Context, arg2 alias1.Request) error {
+	_tags := alias2.Tags(m.handleTags, "tenant", m.labelValue("tenant", arg2.Tenant))
+	m.emitter.Count("total_ops", 1, _tags...)
+	_start := alias3.Now()
+	result1 := m.next.Handle(arg1, arg2)
+	m.emitter.Timing("ops_duration", alias3.Since(_start), _tags...)
+	if result1 != nil {
+		m.emitter.Count("failed_ops", 1, alias2.Tags(_tags, "error_class", m.errorClass(result1))...)
+	}
+	return result1
+}
+func (m *monitoringStatsdService) Version() string {
+	m.emitter.Count("total_ops", 1, m.versionTags...)
+	_start := alias3.Now()
+	result1 := m.next.Version()
+	m.emitter.Timing("ops_duration", alias3.Since(_start), m.versionTags...)
+	return result1
+}
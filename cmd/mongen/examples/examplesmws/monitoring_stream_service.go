@@ -0,0 +1,195 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.StreamService
+// Source-Hash: sha256:9b2c0858cc67a241429c8ec0d73540ef73f5f798262c186fb414282354713de1
+// Generator: mongen v2.1.0
+// Args: -in-flight=true -metrics=true -streams=true -output-dir . -o monitoring_stream_service.go .. StreamService go-kit
+package examplesmws
+
+import (
+	alias6 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias7 "github.com/Bo0mer/gentools/pkg/stream"
+	alias2 "github.com/go-kit/kit/metrics"
+	alias4 "github.com/go-kit/kit/metrics/prometheus"
+	alias5 "github.com/prometheus/client_golang/prometheus"
+	alias8 "io"
+	alias9 "iter"
+	alias3 "time"
+)
+
+type monitoringStreamService struct {
+	next           alias1.StreamService
+	watchOperation monitoringStreamServiceOperation
+	openOperation  monitoringStreamServiceOperation
+	listOperation  monitoringStreamServiceOperation
+	scanOperation  monitoringStreamServiceOperation
+	closeOperation monitoringStreamServiceOperation
+}
+type monitoringStreamServiceOperation struct {
+	totalOps    alias2.Counter
+	failedOps   alias2.Counter
+	opsDuration alias2.Histogram
+	inFlightOps alias2.Gauge
+	streamSize  alias2.Histogram
+}
+
+// NewMonitoringStreamService creates new monitoring middleware.
+//
+// The operations of the methods that return an iter.Seq or an iter.Seq2
+// end when the first iteration over the returned sequence ends. A sequence
+// that is never ranged over is never recorded, and its operation remains
+// in flight, so range over every sequence that is returned.
+func NewMonitoringStreamService(next alias1.StreamService, totalOps alias2.Counter, failedOps alias2.Counter, opsDuration alias2.Histogram, inFlightOps alias2.Gauge, streamSize alias2.Histogram) alias1.StreamService {
+	m := &monitoringStreamService{next: next}
+	bind := func(operation string, duration alias2.Histogram) monitoringStreamServiceOperation {
+		return monitoringStreamServiceOperation{totalOps: totalOps.With("operation", operation), failedOps: failedOps.With("operation", operation), opsDuration: duration.With("operation", operation), inFlightOps: inFlightOps.With("operation", operation), streamSize: streamSize.With("operation", operation)}
+	}
+	m.watchOperation = bind("watch", opsDuration)
+	m.openOperation = bind("open", opsDuration)
+	m.listOperation = bind("list", opsDuration)
+	m.scanOperation = bind("scan", opsDuration)
+	m.closeOperation = bind("close", opsDuration)
+	return m
+}
+
+// MonitoringStreamServiceMetrics holds the metrics recorded by the monitoring middleware.
+type MonitoringStreamServiceMetrics struct {
+	TotalOps    alias2.Counter
+	FailedOps   alias2.Counter
+	OpsDuration alias2.Histogram
+	InFlightOps alias2.Gauge
+	StreamSize  alias2.Histogram
+}
+
+// NewMonitoringStreamServiceMetrics creates Prometheus metrics with the specified namespace and registers
+// them with the default registerer.
+func NewMonitoringStreamServiceMetrics(namespace string) *MonitoringStreamServiceMetrics {
+	return &MonitoringStreamServiceMetrics{TotalOps: alias4.NewCounterFrom(alias5.CounterOpts{Namespace: namespace, Name: "total_ops", Help: "Total number of operations."}, []string{"operation"}), FailedOps: alias4.NewCounterFrom(alias5.CounterOpts{Namespace: namespace, Name: "failed_ops", Help: "Number of failed operations."}, []string{"operation"}), OpsDuration: alias4.NewHistogramFrom(alias5.HistogramOpts{Namespace: namespace, Name: "ops_duration_seconds", Help: "Duration of operations in seconds.", Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}}, []string{"operation"}), InFlightOps: alias4.NewGaugeFrom(alias5.GaugeOpts{Namespace: namespace, Name: "in_flight_ops", Help: "Number of operations in progress."}, []string{"operation"}), StreamSize: alias4.NewHistogramFrom(alias5.HistogramOpts{Namespace: namespace, Name: "stream_size", Help: "Number of items or bytes received from the streams returned by operations.", Buckets: []float64{1, 4, 16, 64, 256, 1024, 4096, 16384, 65536, 262144}}, []string{"operation"})}
+}
+
+// Wrap wraps next with monitoring middleware created by NewMonitoringStreamService that records the metrics.
+func (ms *MonitoringStreamServiceMetrics) Wrap(next alias1.StreamService) alias1.StreamService {
+	return NewMonitoringStreamService(next, ms.TotalOps, ms.FailedOps, ms.OpsDuration, ms.InFlightOps, ms.StreamSize)
+}
+func (m *monitoringStreamService) Watch(arg1 alias6.Context, arg2 string) (<-chan alias1.Request, error) {
+	m.watchOperation.totalOps.Add(1)
+	m.watchOperation.inFlightOps.Add(1)
+	_streaming := false
+	defer func() {
+		if !_streaming {
+			m.watchOperation.inFlightOps.Add(-1)
+		}
+	}()
+	_start := alias3.Now()
+	result1, result2 := m.next.Watch(arg1, arg2)
+	if result1 != nil && result2 == nil {
+		_streaming = true
+		result1 = alias7.Chan(result1, func(_size int64, _err error) {
+			m.watchOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+			if _err != nil {
+				m.watchOperation.failedOps.Add(1)
+			}
+			m.watchOperation.streamSize.Observe(float64(_size))
+			m.watchOperation.inFlightOps.Add(-1)
+		})
+		return result1, result2
+	}
+	m.watchOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.watchOperation.failedOps.Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringStreamService) Open(arg1 string) (alias8.ReadCloser, error) {
+	m.openOperation.totalOps.Add(1)
+	m.openOperation.inFlightOps.Add(1)
+	_streaming := false
+	defer func() {
+		if !_streaming {
+			m.openOperation.inFlightOps.Add(-1)
+		}
+	}()
+	_start := alias3.Now()
+	result1, result2 := m.next.Open(arg1)
+	if result1 != nil && result2 == nil {
+		_streaming = true
+		result1 = alias7.ReadCloser(result1, func(_size int64, _err error) {
+			m.openOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+			if _err != nil {
+				m.openOperation.failedOps.Add(1)
+			}
+			m.openOperation.streamSize.Observe(float64(_size))
+			m.openOperation.inFlightOps.Add(-1)
+		})
+		return result1, result2
+	}
+	m.openOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.openOperation.failedOps.Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringStreamService) List() alias9.Seq[alias1.Request] {
+	m.listOperation.totalOps.Add(1)
+	m.listOperation.inFlightOps.Add(1)
+	_streaming := false
+	defer func() {
+		if !_streaming {
+			m.listOperation.inFlightOps.Add(-1)
+		}
+	}()
+	_start := alias3.Now()
+	result1 := m.next.List()
+	if result1 != nil {
+		_streaming = true
+		result1 = alias7.Seq(result1, func(_size int64, _err error) {
+			m.listOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+			if _err != nil {
+				m.listOperation.failedOps.Add(1)
+			}
+			m.listOperation.streamSize.Observe(float64(_size))
+			m.listOperation.inFlightOps.Add(-1)
+		})
+		return result1
+	}
+	m.listOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	return result1
+}
+func (m *monitoringStreamService) Scan(arg1 int) alias9.Seq2[alias1.Request, error] {
+	m.scanOperation.totalOps.Add(1)
+	m.scanOperation.inFlightOps.Add(1)
+	_streaming := false
+	defer func() {
+		if !_streaming {
+			m.scanOperation.inFlightOps.Add(-1)
+		}
+	}()
+	_start := alias3.Now()
+	result1 := m.next.Scan(arg1)
+	if result1 != nil {
+		_streaming = true
+		result1 = alias7.Seq2(result1, func(_size int64, _err error) {
+			m.scanOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+			if _err != nil {
+				m.scanOperation.failedOps.Add(1)
+			}
+			m.scanOperation.streamSize.Observe(float64(_size))
+			m.scanOperation.inFlightOps.Add(-1)
+		})
+		return result1
+	}
+	m.scanOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	return result1
+}
+func (m *monitoringStreamService) Close() error {
+	m.closeOperation.totalOps.Add(1)
+	m.closeOperation.inFlightOps.Add(1)
+	defer m.closeOperation.inFlightOps.Add(-1)
+	_start := alias3.Now()
+	result1 := m.next.Close()
+	m.closeOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.closeOperation.failedOps.Add(1)
+	}
+	return result1
+}
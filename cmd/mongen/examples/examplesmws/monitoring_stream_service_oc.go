@@ -0,0 +1,248 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.StreamService
+// Source-Hash: sha256:9b2c0858cc67a241429c8ec0d73540ef73f5f798262c186fb414282354713de1
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringStreamServiceOC -in-flight=true -metrics=true -streams=true -type=monitoringStreamServiceOC -output-dir . -o monitoring_stream_service_oc.go .. StreamService opencensus
+package examplesmws
+
+import (
+	alias1 "context"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias7 "github.com/Bo0mer/gentools/pkg/stream"
+	alias3 "go.opencensus.io/stats"
+	alias6 "go.opencensus.io/stats/view"
+	alias4 "go.opencensus.io/tag"
+	alias8 "io"
+	alias9 "iter"
+	alias2 "time"
+)
+
+type monitoringStreamServiceOC struct {
+	next           alias5.StreamService
+	totalOps       *alias3.Int64Measure
+	failedOps      *alias3.Int64Measure
+	opsDuration    *alias3.Float64Measure
+	ctxFunc        func(alias1.Context) alias1.Context
+	inFlightOps    *alias3.Int64Measure
+	streamSize     *alias3.Int64Measure
+	watchOperation alias4.Mutator
+	watchTags      *alias4.Map
+	openOperation  alias4.Mutator
+	openTags       *alias4.Map
+	listOperation  alias4.Mutator
+	listTags       *alias4.Map
+	scanOperation  alias4.Mutator
+	scanTags       *alias4.Map
+	closeOperation alias4.Mutator
+	closeTags      *alias4.Map
+}
+
+// NewMonitoringStreamServiceOC creates new monitoring middleware.
+//
+// The operations of the methods that return an iter.Seq or an iter.Seq2
+// end when the first iteration over the returned sequence ends. A sequence
+// that is never ranged over is never recorded, and its operation remains
+// in flight, so range over every sequence that is returned.
+func NewMonitoringStreamServiceOC(next alias5.StreamService, totalOps *alias3.Int64Measure, failedOps *alias3.Int64Measure, opsDuration *alias3.Float64Measure, ctxFunc func(alias1.Context) alias1.Context, inFlightOps *alias3.Int64Measure, streamSize *alias3.Int64Measure) alias5.StreamService {
+	m := &monitoringStreamServiceOC{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, ctxFunc: ctxFunc, inFlightOps: inFlightOps, streamSize: streamSize}
+	operationTagKey := alias4.MustNewKey("operation")
+	bind := func(operation string) (alias4.Mutator, *alias4.Map) {
+		mutator := alias4.Insert(operationTagKey, operation)
+		ctx, _ := alias4.New(alias1.Background(), mutator)
+		return mutator, alias4.FromContext(ctx)
+	}
+	m.watchOperation, m.watchTags = bind("watch")
+	m.openOperation, m.openTags = bind("open")
+	m.listOperation, m.listTags = bind("list")
+	m.scanOperation, m.scanTags = bind("scan")
+	m.closeOperation, m.closeTags = bind("close")
+	return m
+}
+
+// tagContext returns the context tagged with the mutators, the first of which tags the operation.
+// A context without tags is given the operationTags when the operation is its only tag, which saves copying them.
+// The mutators that fail, e.g. because of invalid label values, are left out.
+func (m *monitoringStreamServiceOC) tagContext(ctx alias1.Context, operationTags *alias4.Map, mutators ...alias4.Mutator) alias1.Context {
+	if len(mutators) == 1 && alias4.FromContext(ctx) == nil {
+		return alias4.NewContext(ctx, operationTags)
+	}
+	if taggedCtx, err := alias4.New(ctx, mutators...); err == nil {
+		return taggedCtx
+	}
+	for _, mutator := range mutators {
+		if taggedCtx, err := alias4.New(ctx, mutator); err == nil {
+			ctx = taggedCtx
+		}
+	}
+	return ctx
+}
+
+// MonitoringStreamServiceOCMetrics holds the metrics recorded by the monitoring middleware.
+type MonitoringStreamServiceOCMetrics struct {
+	TotalOps    *alias3.Int64Measure
+	FailedOps   *alias3.Int64Measure
+	OpsDuration *alias3.Float64Measure
+	InFlightOps *alias3.Int64Measure
+	StreamSize  *alias3.Int64Measure
+	Views       []*alias6.View
+}
+
+// NewMonitoringStreamServiceOCMetrics creates measures with names prefixed by the specified namespace
+// and the views that aggregate them. The views must be registered with view.Register.
+func NewMonitoringStreamServiceOCMetrics(namespace string) *MonitoringStreamServiceOCMetrics {
+	ms := &MonitoringStreamServiceOCMetrics{TotalOps: alias3.Int64(namespace+"/total_ops", "Total number of operations.", alias3.UnitDimensionless), FailedOps: alias3.Int64(namespace+"/failed_ops", "Number of failed operations.", alias3.UnitDimensionless), OpsDuration: alias3.Float64(namespace+"/ops_duration_seconds", "Duration of operations in seconds.", alias3.UnitSeconds), InFlightOps: alias3.Int64(namespace+"/in_flight_ops", "Number of operations in progress.", alias3.UnitDimensionless), StreamSize: alias3.Int64(namespace+"/stream_size", "Number of items or bytes received from the streams returned by operations.", alias3.UnitDimensionless)}
+	ms.Views = []*alias6.View{{Name: namespace + "/total_ops", Description: "Total number of operations.", Measure: ms.TotalOps, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Count()}, {Name: namespace + "/failed_ops", Description: "Number of failed operations.", Measure: ms.FailedOps, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Count()}, {Name: namespace + "/ops_duration_seconds", Description: "Duration of operations in seconds.", Measure: ms.OpsDuration, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Distribution(.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10)}, {Name: namespace + "/in_flight_ops", Description: "Number of operations in progress.", Measure: ms.InFlightOps, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Sum()}, {Name: namespace + "/stream_size", Description: "Number of items or bytes received from the streams returned by operations.", Measure: ms.StreamSize, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Distribution(1, 4, 16, 64, 256, 1024, 4096, 16384, 65536, 262144)}}
+	return ms
+}
+
+// Wrap wraps next with monitoring middleware created by NewMonitoringStreamServiceOC that records the metrics.
+func (ms *MonitoringStreamServiceOCMetrics) Wrap(next alias5.StreamService, ctxFunc func(alias1.Context) alias1.Context) alias5.StreamService {
+	return NewMonitoringStreamServiceOC(next, ms.TotalOps, ms.FailedOps, ms.OpsDuration, ctxFunc, ms.InFlightOps, ms.StreamSize)
+}
+func (m *monitoringStreamServiceOC) Watch(arg1 alias1.Context, arg2 string) (<-chan alias5.Request, error) {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.watchTags, m.watchOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	alias3.Record(ctx, m.inFlightOps.M(1))
+	_streaming := false
+	defer func() {
+		if !_streaming {
+			alias3.Record(ctx, m.inFlightOps.M(-1))
+		}
+	}()
+	_start := alias2.Now()
+	result1, result2 := m.next.Watch(arg1, arg2)
+	if result1 != nil && result2 == nil {
+		_streaming = true
+		result1 = alias7.Chan(result1, func(_size int64, _err error) {
+			alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+			if _err != nil {
+				alias3.Record(ctx, m.failedOps.M(1))
+			}
+			alias3.Record(ctx, m.streamSize.M(_size))
+			alias3.Record(ctx, m.inFlightOps.M(-1))
+		})
+		return result1, result2
+	}
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result2 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1, result2
+}
+func (m *monitoringStreamServiceOC) Open(arg1 string) (alias8.ReadCloser, error) {
+	ctx := alias1.Background()
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.openTags, m.openOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	alias3.Record(ctx, m.inFlightOps.M(1))
+	_streaming := false
+	defer func() {
+		if !_streaming {
+			alias3.Record(ctx, m.inFlightOps.M(-1))
+		}
+	}()
+	_start := alias2.Now()
+	result1, result2 := m.next.Open(arg1)
+	if result1 != nil && result2 == nil {
+		_streaming = true
+		result1 = alias7.ReadCloser(result1, func(_size int64, _err error) {
+			alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+			if _err != nil {
+				alias3.Record(ctx, m.failedOps.M(1))
+			}
+			alias3.Record(ctx, m.streamSize.M(_size))
+			alias3.Record(ctx, m.inFlightOps.M(-1))
+		})
+		return result1, result2
+	}
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result2 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1, result2
+}
+func (m *monitoringStreamServiceOC) List() alias9.Seq[alias5.Request] {
+	ctx := alias1.Background()
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.listTags, m.listOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	alias3.Record(ctx, m.inFlightOps.M(1))
+	_streaming := false
+	defer func() {
+		if !_streaming {
+			alias3.Record(ctx, m.inFlightOps.M(-1))
+		}
+	}()
+	_start := alias2.Now()
+	result1 := m.next.List()
+	if result1 != nil {
+		_streaming = true
+		result1 = alias7.Seq(result1, func(_size int64, _err error) {
+			alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+			if _err != nil {
+				alias3.Record(ctx, m.failedOps.M(1))
+			}
+			alias3.Record(ctx, m.streamSize.M(_size))
+			alias3.Record(ctx, m.inFlightOps.M(-1))
+		})
+		return result1
+	}
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	return result1
+}
+func (m *monitoringStreamServiceOC) Scan(arg1 int) alias9.Seq2[alias5.Request, error] {
+	ctx := alias1.Background()
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.scanTags, m.scanOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	alias3.Record(ctx, m.inFlightOps.M(1))
+	_streaming := false
+	defer func() {
+		if !_streaming {
+			alias3.Record(ctx, m.inFlightOps.M(-1))
+		}
+	}()
+	_start := alias2.Now()
+	result1 := m.next.Scan(arg1)
+	if result1 != nil {
+		_streaming = true
+		result1 = alias7.Seq2(result1, func(_size int64, _err error) {
+			alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+			if _err != nil {
+				alias3.Record(ctx, m.failedOps.M(1))
+			}
+			alias3.Record(ctx, m.streamSize.M(_size))
+			alias3.Record(ctx, m.inFlightOps.M(-1))
+		})
+		return result1
+	}
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	return result1
+}
+func (m *monitoringStreamServiceOC) Close() error {
+	ctx := alias1.Background()
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.closeTags, m.closeOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	alias3.Record(ctx, m.inFlightOps.M(1))
+	defer alias3.Record(ctx, m.inFlightOps.M(-1))
+	_start := alias2.Now()
+	result1 := m.next.Close()
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result1 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1
+}
@@ -0,0 +1,127 @@
+package examplesmws_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples"
+	"github.com/Bo0mer/gentools/cmd/mongen/examples/examplesmws"
+	"go.opencensus.io/stats/view"
+)
+
+// streamService returns streams of n requests, the last of which fails with
+// err if it is set.
+type streamService struct {
+	n   int
+	err error
+}
+
+func (s streamService) Watch(context.Context, string) (<-chan examples.Request, error) {
+	c := make(chan examples.Request, s.n)
+	for i := 0; i < s.n; i++ {
+		c <- examples.Request{}
+	}
+	close(c)
+	return c, nil
+}
+
+func (s streamService) Open(string) (io.ReadCloser, error) { return nil, s.err }
+
+func (s streamService) List() iter.Seq[examples.Request] { return nil }
+
+func (s streamService) Scan(int) iter.Seq2[examples.Request, error] {
+	return func(yield func(examples.Request, error) bool) {
+		for i := 0; i < s.n; i++ {
+			var err error
+			if i == s.n-1 {
+				err = s.err
+			}
+			if !yield(examples.Request{}, err) {
+				return
+			}
+		}
+	}
+}
+
+func (s streamService) Close() error { return nil }
+
+// viewRows returns the rows of the view with the name, by the value of their
+// operation tag.
+func viewRows(t *testing.T, name string) map[string]view.AggregationData {
+	t.Helper()
+	rows, err := view.RetrieveData(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make(map[string]view.AggregationData)
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key.Name() == "operation" {
+				data[tag.Value] = row.Data
+			}
+		}
+	}
+	return data
+}
+
+func TestStreamServiceOCRecordsOperationsOnceTheirStreamsEnd(t *testing.T) {
+	const namespace = "stream_oc_test"
+	metrics := examplesmws.NewMonitoringStreamServiceOCMetrics(namespace)
+	if err := view.Register(metrics.Views...); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { view.Unregister(metrics.Views...) })
+	svc := metrics.Wrap(streamService{n: 3, err: errors.New("failed")}, nil)
+
+	c, err := svc.Watch(context.Background(), "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scan := svc.Scan(1)
+	if got := viewRows(t, namespace+"/in_flight_ops"); got["watch"].(*view.SumData).Value != 1 {
+		t.Errorf("got %v watch operations in flight before the stream ends, want 1", got["watch"])
+	}
+	if _, ok := viewRows(t, namespace+"/ops_duration_seconds")["scan"]; ok {
+		t.Error("got the duration of scan before its sequence is ranged over")
+	}
+	for range c {
+	}
+	for range scan {
+	}
+	// Open fails without a stream, and is recorded when it returns.
+	svc.Open("key")
+
+	for operation, want := range map[string]struct {
+		failed bool
+		size   float64
+	}{
+		"watch": {size: 3},
+		// The pair with the error is not counted.
+		"scan": {failed: true, size: 2},
+		"open": {failed: true},
+	} {
+		if got := viewRows(t, namespace+"/ops_duration_seconds")[operation]; got == nil || got.(*view.DistributionData).Count != 1 {
+			t.Errorf("got durations %v of %s, want 1", got, operation)
+		}
+		_, failed := viewRows(t, namespace+"/failed_ops")[operation]
+		if failed != want.failed {
+			t.Errorf("got %s failed %t, want %t", operation, failed, want.failed)
+		}
+		if got := viewRows(t, namespace+"/in_flight_ops")[operation]; got.(*view.SumData).Value != 0 {
+			t.Errorf("got %v %s operations in flight, want 0", got, operation)
+		}
+		size, ok := viewRows(t, namespace+"/stream_size")[operation].(*view.DistributionData)
+		if want.size == 0 {
+			if ok {
+				t.Errorf("got stream size %v of %s, want none", size, operation)
+			}
+			continue
+		}
+		if !ok || size.Count != 1 || size.Mean != want.size {
+			t.Errorf("got stream size %v of %s, want %v", size, operation, want.size)
+		}
+	}
+}
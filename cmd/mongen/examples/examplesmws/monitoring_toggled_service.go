@@ -0,0 +1,97 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.ToggledService
+// Source-Hash: sha256:ff7c38db0518e648c58d7912ef210fc9eba3ef8e4dd8e66bd5e4b1e7d4924824
+// Generator: mongen v2.1.0
+// Args: -metrics=true -toggles=true -output-dir . -o monitoring_toggled_service.go .. ToggledService go-kit
+package examplesmws
+
+import (
+	alias7 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias6 "github.com/Bo0mer/gentools/pkg/toggle"
+	alias2 "github.com/go-kit/kit/metrics"
+	alias4 "github.com/go-kit/kit/metrics/prometheus"
+	alias5 "github.com/prometheus/client_golang/prometheus"
+	alias3 "time"
+)
+
+type monitoringToggledService struct {
+	next            alias1.ToggledService
+	doWorkOperation monitoringToggledServiceOperation
+	doWorkToggle    *alias6.Method
+	notifyOperation monitoringToggledServiceOperation
+	notifyToggle    *alias6.Method
+	closeOperation  monitoringToggledServiceOperation
+	closeToggle     *alias6.Method
+}
+type monitoringToggledServiceOperation struct {
+	totalOps    alias2.Counter
+	failedOps   alias2.Counter
+	opsDuration alias2.Histogram
+}
+
+// NewMonitoringToggledService creates new monitoring middleware.
+func NewMonitoringToggledService(next alias1.ToggledService, totalOps alias2.Counter, failedOps alias2.Counter, opsDuration alias2.Histogram, toggles *alias6.Controls) alias1.ToggledService {
+	m := &monitoringToggledService{next: next, doWorkToggle: toggles.Method("ToggledService.DoWork"), notifyToggle: toggles.Method("ToggledService.Notify"), closeToggle: toggles.Method("ToggledService.Close")}
+	bind := func(operation string, duration alias2.Histogram) monitoringToggledServiceOperation {
+		return monitoringToggledServiceOperation{totalOps: totalOps.With("operation", operation), failedOps: failedOps.With("operation", operation), opsDuration: duration.With("operation", operation)}
+	}
+	m.doWorkOperation = bind("do_work", opsDuration)
+	m.notifyOperation = bind("notify", opsDuration)
+	m.closeOperation = bind("close", opsDuration)
+	return m
+}
+
+// MonitoringToggledServiceMetrics holds the metrics recorded by the monitoring middleware.
+type MonitoringToggledServiceMetrics struct {
+	TotalOps    alias2.Counter
+	FailedOps   alias2.Counter
+	OpsDuration alias2.Histogram
+}
+
+// NewMonitoringToggledServiceMetrics creates Prometheus metrics with the specified namespace and registers
+// them with the default registerer.
+func NewMonitoringToggledServiceMetrics(namespace string) *MonitoringToggledServiceMetrics {
+	return &MonitoringToggledServiceMetrics{TotalOps: alias4.NewCounterFrom(alias5.CounterOpts{Namespace: namespace, Name: "total_ops", Help: "Total number of operations."}, []string{"operation"}), FailedOps: alias4.NewCounterFrom(alias5.CounterOpts{Namespace: namespace, Name: "failed_ops", Help: "Number of failed operations."}, []string{"operation"}), OpsDuration: alias4.NewHistogramFrom(alias5.HistogramOpts{Namespace: namespace, Name: "ops_duration_seconds", Help: "Duration of operations in seconds.", Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}}, []string{"operation"})}
+}
+
+// Wrap wraps next with monitoring middleware created by NewMonitoringToggledService that records the metrics.
+func (ms *MonitoringToggledServiceMetrics) Wrap(next alias1.ToggledService, toggles *alias6.Controls) alias1.ToggledService {
+	return NewMonitoringToggledService(next, ms.TotalOps, ms.FailedOps, ms.OpsDuration, toggles)
+}
+func (m *monitoringToggledService) DoWork(arg1 alias7.Context, arg2 string) (int, error) {
+	if !m.doWorkToggle.Sample() {
+		return m.next.DoWork(arg1, arg2)
+	}
+	m.doWorkOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	m.doWorkOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.doWorkOperation.failedOps.Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringToggledService) Notify(arg1 alias7.Context, arg2 []string) error {
+	if !m.notifyToggle.Sample() {
+		return m.next.Notify(arg1, arg2)
+	}
+	m.notifyOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1 := m.next.Notify(arg1, arg2)
+	m.notifyOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.notifyOperation.failedOps.Add(1)
+	}
+	return result1
+}
+func (m *monitoringToggledService) Close() {
+	if !m.closeToggle.Sample() {
+		m.next.Close()
+	} else {
+		m.closeOperation.totalOps.Add(1)
+		_start := alias3.Now()
+		m.next.Close()
+		m.closeOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	}
+}
@@ -0,0 +1,150 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.ToggledService
+// Source-Hash: sha256:ff7c38db0518e648c58d7912ef210fc9eba3ef8e4dd8e66bd5e4b1e7d4924824
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringToggledServiceExpvar -toggles=true -type=monitoringToggledServiceExpvar -output-dir . -o monitoring_toggled_service_expvar.go .. ToggledService expvar
+package examplesmws
+
+import (
+	alias6 "context"
+	alias2 "expvar"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias5 "github.com/Bo0mer/gentools/pkg/toggle"
+	alias4 "sync"
+	alias3 "time"
+)
+
+type monitoringToggledServiceExpvar struct {
+	next           alias1.ToggledService
+	totalOps       *alias2.Map
+	failedOps      *alias2.Map
+	doWorkDuration *alias2.Map
+	doWorkToggle   *alias5.Method
+	notifyDuration *alias2.Map
+	notifyToggle   *alias5.Method
+	closeDuration  *alias2.Map
+	closeToggle    *alias5.Method
+}
+
+// NewMonitoringToggledServiceExpvar creates new monitoring middleware that publishes its variables with names starting with prefix.
+func NewMonitoringToggledServiceExpvar(next alias1.ToggledService, prefix string, toggles *alias5.Controls) alias1.ToggledService {
+	totalOps := monitoringToggledServiceExpvarPublish(prefix + "total_ops")
+	failedOps := monitoringToggledServiceExpvarPublish(prefix + "failed_ops")
+	opsDuration := monitoringToggledServiceExpvarPublish(prefix + "ops_duration_seconds")
+	totalOps.Add("do_work", 0)
+	failedOps.Add("do_work", 0)
+	totalOps.Add("notify", 0)
+	failedOps.Add("notify", 0)
+	totalOps.Add("close", 0)
+	failedOps.Add("close", 0)
+	return &monitoringToggledServiceExpvar{next: next, totalOps: totalOps, failedOps: failedOps, doWorkDuration: monitoringToggledServiceExpvarGet(opsDuration, "do_work"), notifyDuration: monitoringToggledServiceExpvarGet(opsDuration, "notify"), closeDuration: monitoringToggledServiceExpvarGet(opsDuration, "close"), doWorkToggle: toggles.Method("ToggledService.DoWork"), notifyToggle: toggles.Method("ToggledService.Notify"), closeToggle: toggles.Method("ToggledService.Close")}
+}
+
+// monitoringToggledServiceExpvarMu guards the lookup and publishing of the maps of monitoringToggledServiceExpvar.
+var monitoringToggledServiceExpvarMu alias4.Mutex
+
+// monitoringToggledServiceExpvarPublish returns the map published under the name, publishing a new map if no variable is published under it yet.
+// It panics if a variable that is not a map is published under the name.
+func monitoringToggledServiceExpvarPublish(name string) *alias2.Map {
+	monitoringToggledServiceExpvarMu.Lock()
+	defer monitoringToggledServiceExpvarMu.Unlock()
+	v := alias2.Get(name)
+	if v == nil {
+		return alias2.NewMap(name)
+	}
+	if m, ok := v.(*alias2.Map); ok {
+		return m
+	}
+	panic("expvar: " + name + " is published and is not an *expvar.Map")
+}
+
+// monitoringToggledServiceExpvarGet returns the map stored under the key of m, storing a new map under it if there is none.
+func monitoringToggledServiceExpvarGet(m *alias2.Map, key string) *alias2.Map {
+	monitoringToggledServiceExpvarMu.Lock()
+	defer monitoringToggledServiceExpvarMu.Unlock()
+	if sub, ok := m.Get(key).(*alias2.Map); ok {
+		return sub
+	}
+	sub := new(alias2.Map)
+	m.Set(key, sub)
+	return sub
+}
+
+// observe records the duration of an operation, in seconds, in its duration map.
+func (m *monitoringToggledServiceExpvar) observe(duration *alias2.Map, seconds float64) {
+	duration.AddFloat("sum", seconds)
+	duration.Add("count", 1)
+	switch {
+	case seconds <= 0.005:
+		duration.Add("le_0.005", 1)
+		fallthrough
+	case seconds <= 0.01:
+		duration.Add("le_0.01", 1)
+		fallthrough
+	case seconds <= 0.025:
+		duration.Add("le_0.025", 1)
+		fallthrough
+	case seconds <= 0.05:
+		duration.Add("le_0.05", 1)
+		fallthrough
+	case seconds <= 0.1:
+		duration.Add("le_0.1", 1)
+		fallthrough
+	case seconds <= 0.25:
+		duration.Add("le_0.25", 1)
+		fallthrough
+	case seconds <= 0.5:
+		duration.Add("le_0.5", 1)
+		fallthrough
+	case seconds <= 1:
+		duration.Add("le_1", 1)
+		fallthrough
+	case seconds <= 2.5:
+		duration.Add("le_2.5", 1)
+		fallthrough
+	case seconds <= 5:
+		duration.Add("le_5", 1)
+		fallthrough
+	case seconds <= 10:
+		duration.Add("le_10", 1)
+		fallthrough
+	default:
+		duration.Add("le_+Inf", 1)
+	}
+}
+func (m *monitoringToggledServiceExpvar) DoWork(arg1 alias6.Context, arg2 string) (int, error) {
+	if !m.doWorkToggle.Sample() {
+		return m.next.DoWork(arg1, arg2)
+	}
+	_start := alias3.Now()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	m.totalOps.Add("do_work", 1)
+	if result2 != nil {
+		m.failedOps.Add("do_work", 1)
+	}
+	m.observe(m.doWorkDuration, alias3.Since(_start).Seconds())
+	return result1, result2
+}
+func (m *monitoringToggledServiceExpvar) Notify(arg1 alias6.Context, arg2 []string) error {
+	if !m.notifyToggle.Sample() {
+		return m.next.Notify(arg1, arg2)
+	}
+	_start := alias3.Now()
+	result1 := m.next.Notify(arg1, arg2)
+	m.totalOps.Add("notify", 1)
+	if result1 != nil {
+		m.failedOps.Add("notify", 1)
+	}
+	m.observe(m.notifyDuration, alias3.Since(_start).Seconds())
+	return result1
+}
+func (m *monitoringToggledServiceExpvar) Close() {
+	if !m.closeToggle.Sample() {
+		m.next.Close()
+	} else {
+		_start := alias3.Now()
+		m.next.Close()
+		m.totalOps.Add("close", 1)
+		m.observe(m.closeDuration, alias3.Since(_start).Seconds())
+	}
+}
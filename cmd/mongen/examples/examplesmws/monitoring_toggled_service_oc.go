@@ -0,0 +1,138 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.ToggledService
+// Source-Hash: sha256:ff7c38db0518e648c58d7912ef210fc9eba3ef8e4dd8e66bd5e4b1e7d4924824
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringToggledServiceOC -metrics=true -toggles=true -type=monitoringToggledServiceOC -output-dir . -o monitoring_toggled_service_oc.go .. ToggledService opencensus
+package examplesmws
+
+import (
+	alias1 "context"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias7 "github.com/Bo0mer/gentools/pkg/toggle"
+	alias3 "go.opencensus.io/stats"
+	alias6 "go.opencensus.io/stats/view"
+	alias4 "go.opencensus.io/tag"
+	alias2 "time"
+)
+
+type monitoringToggledServiceOC struct {
+	next            alias5.ToggledService
+	totalOps        *alias3.Int64Measure
+	failedOps       *alias3.Int64Measure
+	opsDuration     *alias3.Float64Measure
+	ctxFunc         func(alias1.Context) alias1.Context
+	doWorkOperation alias4.Mutator
+	doWorkTags      *alias4.Map
+	doWorkToggle    *alias7.Method
+	notifyOperation alias4.Mutator
+	notifyTags      *alias4.Map
+	notifyToggle    *alias7.Method
+	closeOperation  alias4.Mutator
+	closeTags       *alias4.Map
+	closeToggle     *alias7.Method
+}
+
+// NewMonitoringToggledServiceOC creates new monitoring middleware.
+func NewMonitoringToggledServiceOC(next alias5.ToggledService, totalOps *alias3.Int64Measure, failedOps *alias3.Int64Measure, opsDuration *alias3.Float64Measure, ctxFunc func(alias1.Context) alias1.Context, toggles *alias7.Controls) alias5.ToggledService {
+	m := &monitoringToggledServiceOC{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, ctxFunc: ctxFunc, doWorkToggle: toggles.Method("ToggledService.DoWork"), notifyToggle: toggles.Method("ToggledService.Notify"), closeToggle: toggles.Method("ToggledService.Close")}
+	operationTagKey := alias4.MustNewKey("operation")
+	bind := func(operation string) (alias4.Mutator, *alias4.Map) {
+		mutator := alias4.Insert(operationTagKey, operation)
+		ctx, _ := alias4.New(alias1.Background(), mutator)
+		return mutator, alias4.FromContext(ctx)
+	}
+	m.doWorkOperation, m.doWorkTags = bind("do_work")
+	m.notifyOperation, m.notifyTags = bind("notify")
+	m.closeOperation, m.closeTags = bind("close")
+	return m
+}
+
+// tagContext returns the context tagged with the mutators, the first of which tags the operation.
+// A context without tags is given the operationTags when the operation is its only tag, which saves copying them.
+// The mutators that fail, e.g. because of invalid label values, are left out.
+func (m *monitoringToggledServiceOC) tagContext(ctx alias1.Context, operationTags *alias4.Map, mutators ...alias4.Mutator) alias1.Context {
+	if len(mutators) == 1 && alias4.FromContext(ctx) == nil {
+		return alias4.NewContext(ctx, operationTags)
+	}
+	if taggedCtx, err := alias4.New(ctx, mutators...); err == nil {
+		return taggedCtx
+	}
+	for _, mutator := range mutators {
+		if taggedCtx, err := alias4.New(ctx, mutator); err == nil {
+			ctx = taggedCtx
+		}
+	}
+	return ctx
+}
+
+// MonitoringToggledServiceOCMetrics holds the metrics recorded by the monitoring middleware.
+type MonitoringToggledServiceOCMetrics struct {
+	TotalOps    *alias3.Int64Measure
+	FailedOps   *alias3.Int64Measure
+	OpsDuration *alias3.Float64Measure
+	Views       []*alias6.View
+}
+
+// NewMonitoringToggledServiceOCMetrics creates measures with names prefixed by the specified namespace
+// and the views that aggregate them. The views must be registered with view.Register.
+func NewMonitoringToggledServiceOCMetrics(namespace string) *MonitoringToggledServiceOCMetrics {
+	ms := &MonitoringToggledServiceOCMetrics{TotalOps: alias3.Int64(namespace+"/total_ops", "Total number of operations.", alias3.UnitDimensionless), FailedOps: alias3.Int64(namespace+"/failed_ops", "Number of failed operations.", alias3.UnitDimensionless), OpsDuration: alias3.Float64(namespace+"/ops_duration_seconds", "Duration of operations in seconds.", alias3.UnitSeconds)}
+	ms.Views = []*alias6.View{{Name: namespace + "/total_ops", Description: "Total number of operations.", Measure: ms.TotalOps, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Count()}, {Name: namespace + "/failed_ops", Description: "Number of failed operations.", Measure: ms.FailedOps, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Count()}, {Name: namespace + "/ops_duration_seconds", Description: "Duration of operations in seconds.", Measure: ms.OpsDuration, TagKeys: []alias4.Key{alias4.MustNewKey("operation")}, Aggregation: alias6.Distribution(.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10)}}
+	return ms
+}
+
+// Wrap wraps next with monitoring middleware created by NewMonitoringToggledServiceOC that records the metrics.
+func (ms *MonitoringToggledServiceOCMetrics) Wrap(next alias5.ToggledService, ctxFunc func(alias1.Context) alias1.Context, toggles *alias7.Controls) alias5.ToggledService {
+	return NewMonitoringToggledServiceOC(next, ms.TotalOps, ms.FailedOps, ms.OpsDuration, ctxFunc, toggles)
+}
+func (m *monitoringToggledServiceOC) DoWork(arg1 alias1.Context, arg2 string) (int, error) {
+	if !m.doWorkToggle.Sample() {
+		return m.next.DoWork(arg1, arg2)
+	}
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.doWorkTags, m.doWorkOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result2 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1, result2
+}
+func (m *monitoringToggledServiceOC) Notify(arg1 alias1.Context, arg2 []string) error {
+	if !m.notifyToggle.Sample() {
+		return m.next.Notify(arg1, arg2)
+	}
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.notifyTags, m.notifyOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1 := m.next.Notify(arg1, arg2)
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result1 != nil {
+		alias3.Record(ctx, m.failedOps.M(1))
+	}
+	return result1
+}
+func (m *monitoringToggledServiceOC) Close() {
+	if !m.closeToggle.Sample() {
+		m.next.Close()
+	} else {
+		ctx := alias1.Background()
+		if m.ctxFunc != nil {
+			ctx = m.ctxFunc(ctx)
+		}
+		ctx = m.tagContext(ctx, m.closeTags, m.closeOperation)
+		alias3.Record(ctx, m.totalOps.M(1))
+		_start := alias2.Now()
+		m.next.Close()
+		alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	}
+}
@@ -0,0 +1,99 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.ToggledService
+// Source-Hash: sha256:ff7c38db0518e648c58d7912ef210fc9eba3ef8e4dd8e66bd5e4b1e7d4924824
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringToggledServiceOtel -toggles=true -type=monitoringToggledServiceOtel -output-dir . -o monitoring_toggled_service_otel.go .. ToggledService otel
+package examplesmws
+
+import (
+	alias1 "context"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias6 "github.com/Bo0mer/gentools/pkg/toggle"
+	alias4 "go.opentelemetry.io/otel/attribute"
+	alias3 "go.opentelemetry.io/otel/metric"
+	alias2 "time"
+)
+
+type monitoringToggledServiceOtel struct {
+	next               alias5.ToggledService
+	totalOps           alias3.Int64Counter
+	failedOps          alias3.Int64Counter
+	opsDuration        alias3.Float64Histogram
+	doWorkAttrs        alias3.MeasurementOption
+	doWorkSuccessAttrs alias3.MeasurementOption
+	doWorkErrorAttrs   alias3.MeasurementOption
+	doWorkToggle       *alias6.Method
+	notifyAttrs        alias3.MeasurementOption
+	notifySuccessAttrs alias3.MeasurementOption
+	notifyErrorAttrs   alias3.MeasurementOption
+	notifyToggle       *alias6.Method
+	closeAttrs         alias3.MeasurementOption
+	closeSuccessAttrs  alias3.MeasurementOption
+	closeErrorAttrs    alias3.MeasurementOption
+	closeToggle        *alias6.Method
+}
+
+// NewMonitoringToggledServiceOtel creates new monitoring middleware.
+func NewMonitoringToggledServiceOtel(next alias5.ToggledService, totalOps, failedOps alias3.Int64Counter, opsDuration alias3.Float64Histogram, toggles *alias6.Controls) alias5.ToggledService {
+	return &monitoringToggledServiceOtel{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, doWorkToggle: toggles.Method("ToggledService.DoWork"), notifyToggle: toggles.Method("ToggledService.Notify"), closeToggle: toggles.Method("ToggledService.Close"), doWorkAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "do_work"))), doWorkSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "do_work"), alias4.String("outcome", "success"))), doWorkErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "do_work"), alias4.String("outcome", "error"))), notifyAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "notify"))), notifySuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "notify"), alias4.String("outcome", "success"))), notifyErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "notify"), alias4.String("outcome", "error"))), closeAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "close"))), closeSuccessAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "close"), alias4.String("outcome", "success"))), closeErrorAttrs: alias3.WithAttributeSet(alias4.NewSet(alias4.String("operation", "close"), alias4.String("outcome", "error")))}
+}
+
+// NewMonitoringToggledServiceOtelFromMeter creates new monitoring middleware with instruments created by meter.
+func NewMonitoringToggledServiceOtelFromMeter(next alias5.ToggledService, meter alias3.Meter, toggles *alias6.Controls) (alias5.ToggledService, error) {
+	totalOps, err := meter.Int64Counter("total_ops", alias3.WithDescription("Total number of operations."))
+	if err != nil {
+		return nil, err
+	}
+	failedOps, err := meter.Int64Counter("failed_ops", alias3.WithDescription("Number of failed operations."))
+	if err != nil {
+		return nil, err
+	}
+	opsDuration, err := meter.Float64Histogram("ops_duration", alias3.WithDescription("Duration of operations."), alias3.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return NewMonitoringToggledServiceOtel(next, totalOps, failedOps, opsDuration, toggles), nil
+}
+func (m *monitoringToggledServiceOtel) DoWork(arg1 alias1.Context, arg2 string) (int, error) {
+	if !m.doWorkToggle.Sample() {
+		return m.next.DoWork(arg1, arg2)
+	}
+	ctx := arg1
+	m.totalOps.Add(ctx, 1, m.doWorkAttrs)
+	_start := alias2.Now()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	_outcome := m.doWorkSuccessAttrs
+	if result2 != nil {
+		_outcome = m.doWorkErrorAttrs
+		m.failedOps.Add(ctx, 1, m.doWorkErrorAttrs)
+	}
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), _outcome)
+	return result1, result2
+}
+func (m *monitoringToggledServiceOtel) Notify(arg1 alias1.Context, arg2 []string) error {
+	if !m.notifyToggle.Sample() {
+		return m.next.Notify(arg1, arg2)
+	}
+	ctx := arg1
+	m.totalOps.Add(ctx, 1, m.notifyAttrs)
+	_start := alias2.Now()
+	result1 := m.next.Notify(arg1, arg2)
+	_outcome := m.notifySuccessAttrs
+	if result1 != nil {
+		_outcome = m.notifyErrorAttrs
+		m.failedOps.Add(ctx, 1, m.notifyErrorAttrs)
+	}
+	m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), _outcome)
+	return result1
+}
+func (m *monitoringToggledServiceOtel) Close() {
+	if !m.closeToggle.Sample() {
+		m.next.Close()
+	} else {
+		ctx := alias1.Background()
+		m.totalOps.Add(ctx, 1, m.closeAttrs)
+		_start := alias2.Now()
+		m.next.Close()
+		m.opsDuration.Record(ctx, alias2.Since(_start).Seconds(), m.closeSuccessAttrs)
+	}
+}
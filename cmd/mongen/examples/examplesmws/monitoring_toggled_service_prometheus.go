@@ -0,0 +1,95 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.ToggledService
+// Source-Hash: sha256:ff7c38db0518e648c58d7912ef210fc9eba3ef8e4dd8e66bd5e4b1e7d4924824
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringToggledServicePrometheus -toggles=true -type=monitoringToggledServicePrometheus -output-dir . -o monitoring_toggled_service_prometheus.go .. ToggledService prometheus
+package examplesmws
+
+import (
+	alias5 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias4 "github.com/Bo0mer/gentools/pkg/toggle"
+	alias2 "github.com/prometheus/client_golang/prometheus"
+	alias3 "time"
+)
+
+type monitoringToggledServicePrometheus struct {
+	next              alias1.ToggledService
+	doWorkTotalOps    alias2.Counter
+	doWorkFailedOps   alias2.Counter
+	doWorkOpsDuration alias2.Observer
+	doWorkToggle      *alias4.Method
+	notifyTotalOps    alias2.Counter
+	notifyFailedOps   alias2.Counter
+	notifyOpsDuration alias2.Observer
+	notifyToggle      *alias4.Method
+	closeTotalOps     alias2.Counter
+	closeFailedOps    alias2.Counter
+	closeOpsDuration  alias2.Observer
+	closeToggle       *alias4.Method
+}
+
+// NewMonitoringToggledServicePrometheus creates new monitoring middleware.
+func NewMonitoringToggledServicePrometheus(next alias1.ToggledService, totalOps, failedOps *alias2.CounterVec, opsDuration *alias2.HistogramVec, toggles *alias4.Controls) alias1.ToggledService {
+	return &monitoringToggledServicePrometheus{next: next, doWorkTotalOps: totalOps.WithLabelValues("do_work"), doWorkFailedOps: failedOps.WithLabelValues("do_work"), doWorkOpsDuration: opsDuration.WithLabelValues("do_work"), notifyTotalOps: totalOps.WithLabelValues("notify"), notifyFailedOps: failedOps.WithLabelValues("notify"), notifyOpsDuration: opsDuration.WithLabelValues("notify"), closeTotalOps: totalOps.WithLabelValues("close"), closeFailedOps: failedOps.WithLabelValues("close"), closeOpsDuration: opsDuration.WithLabelValues("close"), doWorkToggle: toggles.Method("ToggledService.DoWork"), notifyToggle: toggles.Method("ToggledService.Notify"), closeToggle: toggles.Method("ToggledService.Close")}
+}
+
+// NewMonitoringToggledServicePrometheusCollectors creates the collectors expected by NewMonitoringToggledServicePrometheus.
+func NewMonitoringToggledServicePrometheusCollectors(namespace, subsystem string) (totalOps, failedOps *alias2.CounterVec, opsDuration *alias2.HistogramVec) {
+	totalOps = alias2.NewCounterVec(alias2.CounterOpts{Namespace: namespace, Subsystem: subsystem, Name: "total_ops", Help: "Total number of operations."}, []string{"operation"})
+	failedOps = alias2.NewCounterVec(alias2.CounterOpts{Namespace: namespace, Subsystem: subsystem, Name: "failed_ops", Help: "Number of failed operations."}, []string{"operation"})
+	opsDuration = alias2.NewHistogramVec(alias2.HistogramOpts{Namespace: namespace, Subsystem: subsystem, Name: "ops_duration_seconds", Help: "Duration of operations in seconds.", Buckets: alias2.DefBuckets}, []string{"operation"})
+	return totalOps, failedOps, opsDuration
+}
+
+// RegisterMonitoringToggledServicePrometheus creates new monitoring middleware and registers its collectors with reg.
+// If a collector cannot be registered, the collectors registered before it are unregistered.
+func RegisterMonitoringToggledServicePrometheus(reg alias2.Registerer, next alias1.ToggledService, namespace, subsystem string, toggles *alias4.Controls) (alias1.ToggledService, error) {
+	totalOps, failedOps, opsDuration := NewMonitoringToggledServicePrometheusCollectors(namespace, subsystem)
+	collectors := []alias2.Collector{totalOps, failedOps, opsDuration}
+	for i, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			for _, registered := range collectors[:i] {
+				reg.Unregister(registered)
+			}
+			return nil, err
+		}
+	}
+	return NewMonitoringToggledServicePrometheus(next, totalOps, failedOps, opsDuration, toggles), nil
+}
+func (m *monitoringToggledServicePrometheus) DoWork(arg1 alias5.Context, arg2 string) (int, error) {
+	if !m.doWorkToggle.Sample() {
+		return m.next.DoWork(arg1, arg2)
+	}
+	m.doWorkTotalOps.Inc()
+	_start := alias3.Now()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	m.doWorkOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.doWorkFailedOps.Inc()
+	}
+	return result1, result2
+}
+func (m *monitoringToggledServicePrometheus) Notify(arg1 alias5.Context, arg2 []string) error {
+	if !m.notifyToggle.Sample() {
+		return m.next.Notify(arg1, arg2)
+	}
+	m.notifyTotalOps.Inc()
+	_start := alias3.Now()
+	result1 := m.next.Notify(arg1, arg2)
+	m.notifyOpsDuration.Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.notifyFailedOps.Inc()
+	}
+	return result1
+}
+func (m *monitoringToggledServicePrometheus) Close() {
+	if !m.closeToggle.Sample() {
+		m.next.Close()
+	} else {
+		m.closeTotalOps.Inc()
+		_start := alias3.Now()
+		m.next.Close()
+		m.closeOpsDuration.Observe(alias3.Since(_start).Seconds())
+	}
+}
@@ -0,0 +1,78 @@
+package examplesmws_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/examples/examplesmws"
+	"github.com/Bo0mer/gentools/pkg/toggle"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// toggledService counts the calls of its methods.
+type toggledService struct{ calls map[string]int }
+
+func (s toggledService) DoWork(context.Context, string) (int, error) {
+	s.calls["do_work"]++
+	return 0, nil
+}
+
+func (s toggledService) Notify(context.Context, []string) error {
+	s.calls["notify"]++
+	return nil
+}
+
+func (s toggledService) Close() { s.calls["close"]++ }
+
+func TestToggledServiceMonitorsTheToggledCalls(t *testing.T) {
+	const calls = 10000
+	for _, tc := range []struct {
+		name   string
+		toggle func(*toggle.Controls)
+		// min and max bound the calls of DoWork that are counted
+		min, max float64
+	}{
+		{name: "enabled", toggle: func(*toggle.Controls) {}, min: calls, max: calls},
+		{name: "disabled", toggle: func(c *toggle.Controls) { c.Disable("ToggledService.DoWork") }},
+		// The bounds are 10 standard deviations away from the expected
+		// count.
+		{name: "sampled", toggle: func(c *toggle.Controls) { c.SetRate("ToggledService.DoWork", 0.25) }, min: 2067, max: 2933},
+		{
+			name: "enabled again",
+			toggle: func(c *toggle.Controls) {
+				c.Disable("ToggledService.DoWork")
+				c.Enable("ToggledService.DoWork")
+			},
+			min: calls,
+			max: calls,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			toggles := new(toggle.Controls)
+			next := toggledService{calls: make(map[string]int)}
+			svc, err := examplesmws.RegisterMonitoringToggledServicePrometheus(registry, next, "", "toggled", toggles)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.toggle(toggles)
+
+			for i := 0; i < calls; i++ {
+				svc.DoWork(context.Background(), "work")
+			}
+			svc.Close()
+
+			// Every call reaches next, whether it is monitored or not.
+			if next.calls["do_work"] != calls {
+				t.Errorf("got %d calls of next, want %d", next.calls["do_work"], calls)
+			}
+			if got := counterValue(t, registry, "toggled_total_ops", map[string]string{"operation": "do_work"}); got < tc.min || got > tc.max {
+				t.Errorf("got %v calls of DoWork counted, want between %v and %v", got, tc.min, tc.max)
+			}
+			// The other methods are toggled separately.
+			if got := counterValue(t, registry, "toggled_total_ops", map[string]string{"operation": "close"}); got != 1 {
+				t.Errorf("got %v calls of Close counted, want 1", got)
+			}
+		})
+	}
+}
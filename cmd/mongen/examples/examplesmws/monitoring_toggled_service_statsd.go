@@ -0,0 +1,66 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.ToggledService
+// Source-Hash: sha256:ff7c38db0518e648c58d7912ef210fc9eba3ef8e4dd8e66bd5e4b1e7d4924824
+// Generator: mongen v2.1.0
+// Args: -constructor=NewMonitoringToggledServiceStatsd -toggles=true -type=monitoringToggledServiceStatsd -output-dir . -o monitoring_toggled_service_statsd.go .. ToggledService statsd
+package examplesmws
+
+import (
+	alias5 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/Bo0mer/gentools/pkg/statsd"
+	alias4 "github.com/Bo0mer/gentools/pkg/toggle"
+	alias3 "time"
+)
+
+type monitoringToggledServiceStatsd struct {
+	next         alias1.ToggledService
+	emitter      alias2.Emitter
+	doWorkTags   []string
+	doWorkToggle *alias4.Method
+	notifyTags   []string
+	notifyToggle *alias4.Method
+	closeTags    []string
+	closeToggle  *alias4.Method
+}
+
+// NewMonitoringToggledServiceStatsd creates new monitoring middleware.
+func NewMonitoringToggledServiceStatsd(next alias1.ToggledService, emitter alias2.Emitter, toggles *alias4.Controls) alias1.ToggledService {
+	return &monitoringToggledServiceStatsd{next: next, emitter: emitter, doWorkTags: []string{"operation:do_work"}, notifyTags: []string{"operation:notify"}, closeTags: []string{"operation:close"}, doWorkToggle: toggles.Method("ToggledService.DoWork"), notifyToggle: toggles.Method("ToggledService.Notify"), closeToggle: toggles.Method("ToggledService.Close")}
+}
+func (m *monitoringToggledServiceStatsd) DoWork(arg1 alias5.Context, arg2 string) (int, error) {
+	if !m.doWorkToggle.Sample() {
+		return m.next.DoWork(arg1, arg2)
+	}
+	m.emitter.Count("total_ops", 1, m.doWorkTags...)
+	_start := alias3.Now()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	m.emitter.Timing("ops_duration", alias3.Since(_start), m.doWorkTags...)
+	if result2 != nil {
+		m.emitter.Count("failed_ops", 1, m.doWorkTags...)
+	}
+	return result1, result2
+}
+func (m *monitoringToggledServiceStatsd) Notify(arg1 alias5.Context, arg2 []string) error {
+	if !m.notifyToggle.Sample() {
+		return m.next.Notify(arg1, arg2)
+	}
+	m.emitter.Count("total_ops", 1, m.notifyTags...)
+	_start := alias3.Now()
+	result1 := m.next.Notify(arg1, arg2)
+	m.emitter.Timing("ops_duration", alias3.Since(_start), m.notifyTags...)
+	if result1 != nil {
+		m.emitter.Count("failed_ops", 1, m.notifyTags...)
+	}
+	return result1
+}
+func (m *monitoringToggledServiceStatsd) Close() {
+	if !m.closeToggle.Sample() {
+		m.next.Close()
+	} else {
+		m.emitter.Count("total_ops", 1, m.closeTags...)
+		_start := alias3.Now()
+		m.next.Close()
+		m.emitter.Timing("ops_duration", alias3.Since(_start), m.closeTags...)
+	}
+}
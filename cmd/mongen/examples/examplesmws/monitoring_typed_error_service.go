@@ -0,0 +1,116 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.TypedErrorService
+// Source-Hash: sha256:68b18396d951544c570839b8e5fb769e6c788f42726b6915b78ced31da670c51
+// Generator: mongen v2.1.0
+// Args: -classify-errors=true -output-dir . -o monitoring_typed_error_service.go .. TypedErrorService go-kit
+package examplesmws
+
+import (
+	alias6 "context"
+	alias5 "errors"
+	alias1 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias2 "github.com/go-kit/kit/metrics"
+	alias4 "sync"
+	alias3 "time"
+)
+
+type monitoringTypedErrorService struct {
+	next             alias1.TypedErrorService
+	classifyError    func(error) string
+	maxLabelValues   int
+	labelsMu         alias4.Mutex
+	labelValues      map[string]map[string]bool
+	getOperation     monitoringTypedErrorServiceOperation
+	checkOperation   monitoringTypedErrorServiceOperation
+	resolveOperation monitoringTypedErrorServiceOperation
+}
+type monitoringTypedErrorServiceOperation struct {
+	totalOps    alias2.Counter
+	failedOps   alias2.Counter
+	opsDuration alias2.Histogram
+}
+
+// NewMonitoringTypedErrorService creates new monitoring middleware.
+func NewMonitoringTypedErrorService(next alias1.TypedErrorService, totalOps alias2.Counter, failedOps alias2.Counter, opsDuration alias2.Histogram, maxLabelValues int, classifyError func(error) string) alias1.TypedErrorService {
+	m := &monitoringTypedErrorService{next: next, maxLabelValues: maxLabelValues, labelValues: map[string]map[string]bool{}, classifyError: classifyError}
+	bind := func(operation string, duration alias2.Histogram) monitoringTypedErrorServiceOperation {
+		return monitoringTypedErrorServiceOperation{totalOps: totalOps.With("operation", operation), failedOps: failedOps.With("operation", operation), opsDuration: duration.With("operation", operation)}
+	}
+	m.getOperation = bind("get", opsDuration)
+	m.checkOperation = bind("check", opsDuration)
+	m.resolveOperation = bind("resolve", opsDuration)
+	return m
+}
+
+// errorClass returns the class of the error recorded in the error_class label.
+func (m *monitoringTypedErrorService) errorClass(err error) string {
+	if err == nil {
+		return "failed"
+	}
+	if m.classifyError != nil {
+		return m.labelValue("error_class", m.classifyError(err))
+	}
+	switch {
+	case alias5.Is(err, alias6.Canceled):
+		return "canceled"
+	case alias5.Is(err, alias6.DeadlineExceeded):
+		return "timeout"
+	}
+	return "error"
+}
+
+// labelValue returns the value of the label, or "other" once the label has maxLabelValues distinct values.
+func (m *monitoringTypedErrorService) labelValue(label, value string) string {
+	if m.maxLabelValues <= 0 {
+		return value
+	}
+	m.labelsMu.Lock()
+	defer m.labelsMu.Unlock()
+	values := m.labelValues[label]
+	if values[value] {
+		return value
+	}
+	if len(values) >= m.maxLabelValues {
+		return "other"
+	}
+	if values == nil {
+		values = make(map[string]bool)
+		m.labelValues[label] = values
+	}
+	values[value] = true
+	return value
+}
+func (m *monitoringTypedErrorService) Get(arg1 alias6.Context, arg2 string) (string, *alias1.NotFoundError) {
+	m.getOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2 := m.next.Get(arg1, arg2)
+	m.getOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil {
+		m.getOperation.failedOps.With("error_class", m.errorClass(result2)).Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringTypedErrorService) Check(arg1 alias6.Context) (error, bool) {
+	m.checkOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2 := m.next.Check(arg1)
+	m.checkOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result1 != nil {
+		m.checkOperation.failedOps.With("error_class", m.errorClass(result1)).Add(1)
+	}
+	return result1, result2
+}
+func (m *monitoringTypedErrorService) Resolve(arg1 alias6.Context, arg2 string) (string, *alias1.NotFoundError) {
+	m.resolveOperation.totalOps.Add(1)
+	_start := alias3.Now()
+	result1, result2 := m.next.Resolve(arg1, arg2)
+	m.resolveOperation.opsDuration.Observe(alias3.Since(_start).Seconds())
+	if result2 != nil || result1 == "" {
+		var _err error
+		if result2 != nil {
+			_err = result2
+		}
+		m.resolveOperation.failedOps.With("error_class", m.errorClass(_err)).Add(1)
+	}
+	return result1, result2
+}
@@ -0,0 +1,162 @@
+// Code generated by mongen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/mongen/examples.TypedErrorService
+// Source-Hash: sha256:68b18396d951544c570839b8e5fb769e6c788f42726b6915b78ced31da670c51
+// Generator: mongen v2.1.0
+// Args: -classify-errors=true -constructor=NewMonitoringTypedErrorServiceOC -type=monitoringTypedErrorServiceOC -output-dir . -o monitoring_typed_error_service_oc.go .. TypedErrorService opencensus
+package examplesmws
+
+import (
+	alias1 "context"
+	alias7 "errors"
+	alias5 "github.com/Bo0mer/gentools/cmd/mongen/examples"
+	alias3 "go.opencensus.io/stats"
+	alias4 "go.opencensus.io/tag"
+	alias6 "sync"
+	alias2 "time"
+)
+
+type monitoringTypedErrorServiceOC struct {
+	next             alias5.TypedErrorService
+	totalOps         *alias3.Int64Measure
+	failedOps        *alias3.Int64Measure
+	opsDuration      *alias3.Float64Measure
+	ctxFunc          func(alias1.Context) alias1.Context
+	errorClassTagKey alias4.Key
+	classifyError    func(error) string
+	maxLabelValues   int
+	labelsMu         alias6.Mutex
+	labelValues      map[string]map[string]bool
+	getOperation     alias4.Mutator
+	getTags          *alias4.Map
+	checkOperation   alias4.Mutator
+	checkTags        *alias4.Map
+	resolveOperation alias4.Mutator
+	resolveTags      *alias4.Map
+}
+
+// NewMonitoringTypedErrorServiceOC creates new monitoring middleware.
+func NewMonitoringTypedErrorServiceOC(next alias5.TypedErrorService, totalOps *alias3.Int64Measure, failedOps *alias3.Int64Measure, opsDuration *alias3.Float64Measure, ctxFunc func(alias1.Context) alias1.Context, maxLabelValues int, classifyError func(error) string) alias5.TypedErrorService {
+	m := &monitoringTypedErrorServiceOC{next: next, totalOps: totalOps, failedOps: failedOps, opsDuration: opsDuration, ctxFunc: ctxFunc, maxLabelValues: maxLabelValues, labelValues: map[string]map[string]bool{}, classifyError: classifyError, errorClassTagKey: alias4.MustNewKey("error_class")}
+	operationTagKey := alias4.MustNewKey("operation")
+	bind := func(operation string) (alias4.Mutator, *alias4.Map) {
+		mutator := alias4.Insert(operationTagKey, operation)
+		ctx, _ := alias4.New(alias1.Background(), mutator)
+		return mutator, alias4.FromContext(ctx)
+	}
+	m.getOperation, m.getTags = bind("get")
+	m.checkOperation, m.checkTags = bind("check")
+	m.resolveOperation, m.resolveTags = bind("resolve")
+	return m
+}
+
+// tagContext returns the context tagged with the mutators, the first of which tags the operation.
+// A context without tags is given the operationTags when the operation is its only tag, which saves copying them.
+// The mutators that fail, e.g. because of invalid label values, are left out.
+func (m *monitoringTypedErrorServiceOC) tagContext(ctx alias1.Context, operationTags *alias4.Map, mutators ...alias4.Mutator) alias1.Context {
+	if len(mutators) == 1 && alias4.FromContext(ctx) == nil {
+		return alias4.NewContext(ctx, operationTags)
+	}
+	if taggedCtx, err := alias4.New(ctx, mutators...); err == nil {
+		return taggedCtx
+	}
+	for _, mutator := range mutators {
+		if taggedCtx, err := alias4.New(ctx, mutator); err == nil {
+			ctx = taggedCtx
+		}
+	}
+	return ctx
+}
+
+// errorClass returns the class of the error recorded in the error_class label.
+func (m *monitoringTypedErrorServiceOC) errorClass(err error) string {
+	if err == nil {
+		return "failed"
+	}
+	if m.classifyError != nil {
+		return m.labelValue("error_class", m.classifyError(err))
+	}
+	switch {
+	case alias7.Is(err, alias1.Canceled):
+		return "canceled"
+	case alias7.Is(err, alias1.DeadlineExceeded):
+		return "timeout"
+	}
+	return "error"
+}
+
+// labelValue returns the value of the label, or "other" once the label has maxLabelValues distinct values.
+func (m *monitoringTypedErrorServiceOC) labelValue(label, value string) string {
+	if m.maxLabelValues <= 0 {
+		return value
+	}
+	m.labelsMu.Lock()
+	defer m.labelsMu.Unlock()
+	values := m.labelValues[label]
+	if values[value] {
+		return value
+	}
+	if len(values) >= m.maxLabelValues {
+		return "other"
+	}
+	if values == nil {
+		values = make(map[string]bool)
+		m.labelValues[label] = values
+	}
+	values[value] = true
+	return value
+}
+func (m *monitoringTypedErrorServiceOC) Get(arg1 alias1.Context, arg2 string) (string, *alias5.NotFoundError) {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.getTags, m.getOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1, result2 := m.next.Get(arg1, arg2)
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result2 != nil {
+		if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.errorClassTagKey, m.errorClass(result2))}, m.failedOps.M(1)); err != nil {
+			alias3.Record(ctx, m.failedOps.M(1))
+		}
+	}
+	return result1, result2
+}
+func (m *monitoringTypedErrorServiceOC) Check(arg1 alias1.Context) (error, bool) {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.checkTags, m.checkOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1, result2 := m.next.Check(arg1)
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result1 != nil {
+		if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.errorClassTagKey, m.errorClass(result1))}, m.failedOps.M(1)); err != nil {
+			alias3.Record(ctx, m.failedOps.M(1))
+		}
+	}
+	return result1, result2
+}
+func (m *monitoringTypedErrorServiceOC) Resolve(arg1 alias1.Context, arg2 string) (string, *alias5.NotFoundError) {
+	ctx := arg1
+	if m.ctxFunc != nil {
+		ctx = m.ctxFunc(ctx)
+	}
+	ctx = m.tagContext(ctx, m.resolveTags, m.resolveOperation)
+	alias3.Record(ctx, m.totalOps.M(1))
+	_start := alias2.Now()
+	result1, result2 := m.next.Resolve(arg1, arg2)
+	alias3.Record(ctx, m.opsDuration.M(alias2.Since(_start).Seconds()))
+	if result2 != nil || result1 == "" {
+		var _err error
+		if result2 != nil {
+			_err = result2
+		}
+		if err := alias3.RecordWithTags(ctx, []alias4.Mutator{alias4.Upsert(m.errorClassTagKey, m.errorClass(_err))}, m.failedOps.M(1)); err != nil {
+			alias3.Record(ctx, m.failedOps.M(1))
+		}
+	}
+	return result1, result2
+}
@@ -1,9 +1,15 @@
 package examples
 
-import "context"
+import (
+	"context"
+	"io"
+	"iter"
+)
 
 //go:generate mongen . GoKitService go-kit
 //go:generate mongen . OCService opencensus
+//go:generate mongen . PrometheusService prometheus
+//go:generate mongen . OtelService otel
 
 type GoKitService interface {
 	DoWork(int, string) (string, error)
@@ -14,3 +20,241 @@ type OCService interface {
 	DoWork(int, string) (string, error)
 	DoWorkCtx(context.Context, int, string) (string, error)
 }
+
+type PrometheusService interface {
+	DoWork(int, string) (string, error)
+	DoWorkCtx(context.Context, int, string) (string, error)
+}
+
+type OtelService interface {
+	DoWork(int, string) (string, error)
+	DoWorkCtx(context.Context, int, string) (string, error)
+}
+
+//go:generate mongen . LabeledService go-kit
+//go:generate mongen -o monitoring_labeled_service_oc.go -type monitoringLabeledServiceOC -constructor NewMonitoringLabeledServiceOC . LabeledService opencensus
+
+// Request is a request handled by LabeledService.
+type Request struct {
+	Tenant string
+}
+
+// LabeledService records the region of every call and the tenant of the
+// handled requests as additional labels.
+//
+//mongen:label region Region(arg1)
+type LabeledService interface {
+	//mongen:label tenant arg2.Tenant
+	Handle(context.Context, Request) error
+	Ping(context.Context) error
+}
+
+type regionKey struct{}
+
+// Region returns the region stored in the context.
+func Region(ctx context.Context) string {
+	region, _ := ctx.Value(regionKey{}).(string)
+	return region
+}
+
+//go:generate mongen -classify-errors . ClassifiedService go-kit
+//go:generate mongen -classify-errors -o monitoring_classified_service_oc.go -type monitoringClassifiedServiceOC -constructor NewMonitoringClassifiedServiceOC . ClassifiedService opencensus
+
+// ClassifiedService records the class of its errors as a label of the failed
+// operations.
+type ClassifiedService interface {
+	DoWork(context.Context, int) (string, error)
+}
+
+//go:generate mongen -in-flight -outcome . InFlightService go-kit
+//go:generate mongen -in-flight -outcome -o monitoring_in_flight_service_oc.go -type monitoringInFlightServiceOC -constructor NewMonitoringInFlightServiceOC . InFlightService opencensus
+
+// InFlightService tracks the calls in progress and records the duration of
+// successful and failed calls separately.
+type InFlightService interface {
+	DoWork(context.Context, int) (string, error)
+	Notify(string)
+}
+
+//go:generate mongen -metrics -in-flight -outcome . MetricsService go-kit
+//go:generate mongen -metrics -in-flight -outcome -o monitoring_metrics_service_oc.go -type monitoringMetricsServiceOC -constructor NewMonitoringMetricsServiceOC . MetricsService opencensus
+
+// MetricsService is wrapped with metrics created by the generated code.
+type MetricsService interface {
+	DoWork(context.Context, int) (string, error)
+}
+
+//go:generate mongen -labels-func -metrics . ContextLabeledService go-kit
+
+// ContextLabeledService records labels taken from the context of its calls.
+type ContextLabeledService interface {
+	//mongen:label tenant arg2.Tenant
+	Handle(context.Context, Request) error
+	Ping(context.Context) error
+	Version() string
+}
+
+//go:generate mongen -name-template "{{.PackageName}}.{{.Name}}" -key-case camel -classify-errors . NamedService opencensus
+//go:generate mongen -name-case kebab -o monitoring_named_service_otel.go -type monitoringNamedServiceOtel -constructor NewMonitoringNamedServiceOtel . NamedService otel
+
+// NamedService is monitored with operation names and label keys that follow
+// a naming policy.
+type NamedService interface {
+	ChargeCard(context.Context, int) error
+}
+
+//go:generate mongen . ExpvarService expvar
+
+// ExpvarService is monitored with expvar, without third-party dependencies.
+type ExpvarService interface {
+	DoWork(int, string) (string, error)
+	Close()
+}
+
+//go:generate mongen -classify-errors . StatsdService statsd
+
+// StatsdService is monitored with a StatsD emitter.
+type StatsdService interface {
+	//mongen:label tenant arg2.Tenant
+	Handle(context.Context, Request) error
+	Version() string
+}
+
+//go:generate mongen -streams -metrics -in-flight . StreamService go-kit
+//go:generate mongen -streams -metrics -in-flight -o monitoring_stream_service_oc.go -type monitoringStreamServiceOC -constructor NewMonitoringStreamServiceOC . StreamService opencensus
+
+// StreamService returns streams, which are measured until they end.
+type StreamService interface {
+	Watch(context.Context, string) (<-chan Request, error)
+	Open(string) (io.ReadCloser, error)
+	List() iter.Seq[Request]
+	Scan(int) iter.Seq2[Request, error]
+	Close() error
+}
+
+//go:generate mongen -classify-errors -outcome . PredicateService go-kit
+
+// Status is the status of a PredicateService.
+type Status int
+
+// StatusOK is the status of a healthy PredicateService.
+const StatusOK Status = 0
+
+// PredicateService has methods that fail without returning an error.
+type PredicateService interface {
+	//gentools:fail !result2
+	Lookup(string) (string, bool)
+	//gentools:fail result1 != StatusOK
+	Status(context.Context) (Status, error)
+	//gentools:fail !found || len(value) > limit
+	Find(key string, limit int) (value string, found bool)
+}
+
+//go:generate mongen -classify-errors . TypedErrorService go-kit
+//go:generate mongen -classify-errors -o monitoring_typed_error_service_oc.go -type monitoringTypedErrorServiceOC -constructor NewMonitoringTypedErrorServiceOC . TypedErrorService opencensus
+
+// NotFoundError is returned by a TypedErrorService for missing keys.
+type NotFoundError struct {
+	Key string
+}
+
+func (e *NotFoundError) Error() string {
+	return "not found: " + e.Key
+}
+
+// TypedErrorService returns errors of concrete types, and not only as the
+// last result.
+type TypedErrorService interface {
+	Get(context.Context, string) (string, *NotFoundError)
+	Check(context.Context) (error, bool)
+	//gentools:fail result1 == ""
+	Resolve(context.Context, string) (string, *NotFoundError)
+}
+
+//go:generate mongen -metrics -outcome -method-buckets Rebuild=batch . BucketedService go-kit
+//go:generate mongen -metrics -buckets batch=1,10,60,600 -method-buckets Rebuild=batch -o monitoring_bucketed_service_oc.go -type monitoringBucketedServiceOC -constructor NewMonitoringBucketedServiceOC . BucketedService opencensus
+//go:generate mongen -buckets batch=1,10,60,600 -method-buckets Rebuild=batch -o monitoring_bucketed_service_prometheus.go -type monitoringBucketedServicePrometheus -constructor NewMonitoringBucketedServicePrometheus . BucketedService prometheus
+//go:generate mongen -catalog json -metrics -outcome -method-buckets Rebuild=batch . BucketedService go-kit
+//go:generate mongen -catalog grafana -metrics -outcome -method-buckets Rebuild=batch . BucketedService go-kit
+//go:generate mongen -catalog rules -metrics -outcome -method-buckets Rebuild=batch -alert-latency 0.5,batch=600 . BucketedService go-kit
+
+// BucketedService has operations whose durations differ by orders of
+// magnitude, recorded with separate buckets.
+//
+//mongen:buckets batch 1 10 60 600 3600
+type BucketedService interface {
+	//mongen:buckets cache .00001 .0001 .001 .01
+	Get(context.Context, string) (string, error)
+	//mongen:buckets cache
+	Put(context.Context, string, string) error
+	Rebuild(context.Context) error
+	Ping(context.Context) error
+}
+
+//go:generate mongen -result-size -metrics . SizedService go-kit
+//go:generate mongen -result-size -metrics -o monitoring_sized_service_oc.go -type monitoringSizedServiceOC -constructor NewMonitoringSizedServiceOC . SizedService opencensus
+//go:generate mongen -result-size -o monitoring_sized_service_prometheus.go -type monitoringSizedServicePrometheus -constructor NewMonitoringSizedServicePrometheus . SizedService prometheus
+//go:generate mongen -result-size -o monitoring_sized_service_otel.go -type monitoringSizedServiceOtel -constructor NewMonitoringSizedServiceOtel . SizedService otel
+//go:generate mongen -result-size -o monitoring_sized_service_expvar.go -type monitoringSizedServiceExpvar -constructor NewMonitoringSizedServiceExpvar . SizedService expvar
+//go:generate mongen -result-size -o monitoring_sized_service_statsd.go -type monitoringSizedServiceStatsd -constructor NewMonitoringSizedServiceStatsd . SizedService statsd
+
+// Batch is a batch of requests returned by a SizedService.
+type Batch struct {
+	Requests []Request
+}
+
+// Len returns the number of requests in the batch.
+func (b *Batch) Len() int {
+	return len(b.Requests)
+}
+
+// Collection is a collection whose size is returned by Len.
+type Collection interface {
+	Len() int
+}
+
+// SizedService returns collections, whose sizes are recorded.
+type SizedService interface {
+	List(context.Context) ([]Request, error)
+	Index(context.Context) (map[string]Request, error)
+	Next(context.Context) (*Batch, error)
+	// Current may return a Collection that holds a nil *Batch.
+	Current(context.Context) (Collection, error)
+	//mongen:size result2
+	Partition(context.Context, []Request) ([]Request, []Request, error)
+	//gentools:fail !result2
+	Lookup(string) ([]Request, bool)
+	//mongen:size none
+	Tags() []string
+	Ping(context.Context) error
+}
+
+//go:generate mongen -toggles -metrics . ToggledService go-kit
+//go:generate mongen -toggles -metrics -o monitoring_toggled_service_oc.go -type monitoringToggledServiceOC -constructor NewMonitoringToggledServiceOC . ToggledService opencensus
+//go:generate mongen -toggles -o monitoring_toggled_service_prometheus.go -type monitoringToggledServicePrometheus -constructor NewMonitoringToggledServicePrometheus . ToggledService prometheus
+//go:generate mongen -toggles -o monitoring_toggled_service_otel.go -type monitoringToggledServiceOtel -constructor NewMonitoringToggledServiceOtel . ToggledService otel
+//go:generate mongen -toggles -o monitoring_toggled_service_expvar.go -type monitoringToggledServiceExpvar -constructor NewMonitoringToggledServiceExpvar . ToggledService expvar
+//go:generate mongen -toggles -o monitoring_toggled_service_statsd.go -type monitoringToggledServiceStatsd -constructor NewMonitoringToggledServiceStatsd . ToggledService statsd
+
+// ToggledService has methods whose monitoring is disabled or sampled at
+// runtime.
+type ToggledService interface {
+	DoWork(context.Context, string) (int, error)
+	Notify(context.Context, []string) error
+	Close()
+}
+
+//go:generate mongen -panics -classify-errors -outcome -in-flight . PanickyService go-kit
+//go:generate mongen -panics -classify-errors -outcome -o monitoring_panicky_service_oc.go -type monitoringPanickyServiceOC -constructor NewMonitoringPanickyServiceOC . PanickyService opencensus
+//go:generate mongen -panics -o monitoring_panicky_service_prometheus.go -type monitoringPanickyServicePrometheus -constructor NewMonitoringPanickyServicePrometheus . PanickyService prometheus
+//go:generate mongen -panics -o monitoring_panicky_service_otel.go -type monitoringPanickyServiceOtel -constructor NewMonitoringPanickyServiceOtel . PanickyService otel
+//go:generate mongen -panics -o monitoring_panicky_service_expvar.go -type monitoringPanickyServiceExpvar -constructor NewMonitoringPanickyServiceExpvar . PanickyService expvar
+//go:generate mongen -panics -classify-errors -toggles -o monitoring_panicky_service_statsd.go -type monitoringPanickyServiceStatsd -constructor NewMonitoringPanickyServiceStatsd . PanickyService statsd
+
+// PanickyService has methods whose panics are recorded before they are raised
+// again.
+type PanickyService interface {
+	DoWork(context.Context, string) (int, error)
+	Notify(context.Context, []string) error
+	Close()
+}
@@ -0,0 +1,213 @@
+package commonbuilders
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/directive"
+)
+
+var bucketGroupName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// BucketGroups collects the groups of operations whose durations are recorded
+// by separate metrics, with their own bucket bounds in seconds. Groups are
+// declared with
+//
+//	//mongen:buckets GROUP BOUND...
+//
+// directives in the doc comment of the interface or its methods, or with the
+// Buckets of the model config. A method selects a group with a
+//
+//	//mongen:buckets GROUP
+//
+// directive in its doc comment, or with the MethodBuckets of the model config.
+// A method that declares a group selects it as well. The model config takes
+// precedence over the directives. The other methods record their durations
+// with the default duration metric.
+type BucketGroups struct {
+	cfg      ModelConfig
+	declared map[string][]string
+	groups   []string
+}
+
+// NewBucketGroups creates bucket groups for the interface described by cfg.
+func NewBucketGroups(cfg ModelConfig) *BucketGroups {
+	return &BucketGroups{cfg: cfg}
+}
+
+// AddMethod selects the group of the method. It returns the group, which is
+// empty if the method records its duration with the default metric, and
+// reports whether the method is the first one to select it.
+func (g *BucketGroups) AddMethod(method *astgen.MethodConfig) (string, bool, error) {
+	if g.declared == nil {
+		g.declared = make(map[string][]string)
+		for _, d := range directive.Parse("mongen", g.cfg.Doc) {
+			if d.Name != "buckets" {
+				continue
+			}
+			if _, err := g.declare(d); err != nil {
+				return "", false, fmt.Errorf("interface %s: invalid buckets directive %q: %v", g.cfg.InterfaceName, d.Args, err)
+			}
+		}
+		for name, bounds := range g.cfg.Buckets {
+			g.declared[name] = bounds
+		}
+	}
+
+	var group string
+	for _, d := range directive.Parse("mongen", method.Doc) {
+		if d.Name != "buckets" {
+			continue
+		}
+		name, err := g.declare(d)
+		if err != nil {
+			return "", false, fmt.Errorf("method %s: invalid buckets directive %q: %v", method.MethodName, d.Args, err)
+		}
+		group = name
+	}
+	if name, ok := g.cfg.MethodBuckets[method.MethodName]; ok {
+		group = name
+	}
+	if group == "" {
+		return "", false, nil
+	}
+	if _, ok := g.declared[group]; !ok {
+		return "", false, fmt.Errorf("method %s: undeclared bucket group %q", method.MethodName, group)
+	}
+
+	for _, name := range g.groups {
+		if name == group {
+			return group, false, nil
+		}
+	}
+	g.groups = append(g.groups, group)
+	return group, true, nil
+}
+
+// declare declares the group of the directive if it has bounds, and returns
+// its name.
+func (g *BucketGroups) declare(d directive.Directive) (string, error) {
+	fields := d.Fields()
+	if len(fields) == 0 {
+		return "", fmt.Errorf("expected a group name")
+	}
+	name := fields[0]
+	if err := validateBucketGroupName(name); err != nil {
+		return "", err
+	}
+	if len(fields) == 1 {
+		return name, nil
+	}
+	if err := validateBounds(fields[1:]); err != nil {
+		return "", err
+	}
+	if _, ok := g.cfg.Buckets[name]; !ok {
+		g.declared[name] = fields[1:]
+	}
+	return name, nil
+}
+
+// Groups returns the groups selected by the methods, in the order they were
+// first selected.
+func (g *BucketGroups) Groups() []string {
+	return g.groups
+}
+
+// Bounds returns the bucket bounds of the group.
+func (g *BucketGroups) Bounds(group string) []string {
+	return g.declared[group]
+}
+
+// DurationMetric returns the metric of the duration of the operations of the
+// group, or OpsDurationMetric for the empty group.
+func DurationMetric(group string) Metric {
+	if group == "" {
+		return OpsDurationMetric
+	}
+	var camel strings.Builder
+	for _, word := range strings.Split(group, "_") {
+		if word != "" {
+			camel.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return Metric{
+		Param: OpsDurationMetricName + camel.String(),
+		Name:  group + "_" + OpsDurationMetric.Name,
+		Help:  fmt.Sprintf("Duration of %s operations in seconds.", group),
+	}
+}
+
+// ParseBuckets parses bucket groups in the form
+// "cache=.0001,.001,.01;batch=1,10,60".
+func ParseBuckets(s string) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	if s == "" {
+		return groups, nil
+	}
+	for _, group := range strings.Split(s, ";") {
+		name, bounds, ok := strings.Cut(group, "=")
+		if !ok {
+			return nil, fmt.Errorf("bucket group %q: expected NAME=BOUND,...", group)
+		}
+		name = strings.TrimSpace(name)
+		if err := validateBucketGroupName(name); err != nil {
+			return nil, err
+		}
+		var list []string
+		for _, bound := range strings.Split(bounds, ",") {
+			list = append(list, strings.TrimSpace(bound))
+		}
+		if err := validateBounds(list); err != nil {
+			return nil, fmt.Errorf("bucket group %s: %v", name, err)
+		}
+		groups[name] = list
+	}
+	return groups, nil
+}
+
+// ParseMethodBuckets parses the groups selected by methods in the form
+// "Get=cache,Rebuild=batch".
+func ParseMethodBuckets(s string) (map[string]string, error) {
+	methods := make(map[string]string)
+	if s == "" {
+		return methods, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		method, group, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("method bucket group %q: expected METHOD=GROUP", pair)
+		}
+		group = strings.TrimSpace(group)
+		if err := validateBucketGroupName(group); err != nil {
+			return nil, err
+		}
+		methods[strings.TrimSpace(method)] = group
+	}
+	return methods, nil
+}
+
+func validateBucketGroupName(name string) error {
+	if !bucketGroupName.MatchString(name) {
+		return fmt.Errorf("%q is not a valid bucket group name", name)
+	}
+	return nil
+}
+
+func validateBounds(bounds []string) error {
+	prev := 0.0
+	for i, bound := range bounds {
+		v, err := strconv.ParseFloat(bound, 64)
+		if err != nil || math.IsInf(v, 0) || math.IsNaN(v) {
+			return fmt.Errorf("invalid bound %q", bound)
+		}
+		if v <= 0 || i > 0 && v <= prev {
+			return fmt.Errorf("bounds must be positive and increasing")
+		}
+		prev = v
+	}
+	return nil
+}
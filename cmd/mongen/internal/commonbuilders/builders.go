@@ -5,6 +5,8 @@ import (
 	"go/token"
 
 	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/resolution"
+	"github.com/Bo0mer/gentools/pkg/transformation"
 )
 
 // constructor parameter names
@@ -13,11 +15,122 @@ const (
 	TotalOpsMetricName    = "totalOps"
 	FailedOpsMetricName   = "failedOps"
 	OpsDurationMetricName = "opsDuration"
+	InFlightOpsMetricName = "inFlightOps"
+	StreamSizeMetricName  = "streamSize"
+	ResultSizeMetricName  = "resultSize"
 
 	// context decorator param
 	ContextDecoratorFuncName = "ctxFunc"
+
+	// labels from context param
+	LabelsFuncName = "labelsFunc"
+
+	// label that distinguishes the methods
+	OperationLabel = "operation"
 )
 
+// ModelConfig describes the monitoring implementation that should be
+// generated.
+type ModelConfig struct {
+	// InterfacePath is the import path of the package declaring the interface.
+	InterfacePath string
+	// InterfaceName is the name of the wrapped interface.
+	InterfaceName string
+	// StructName is the name of the generated wrapper type.
+	StructName string
+	// ConstructorName is the name of the generated constructor.
+	ConstructorName string
+	// TargetPkg is the name of the package the code is generated in.
+	TargetPkg string
+	// TargetPath is the import path of the package the code is generated in.
+	// It may be empty if unknown.
+	TargetPath string
+	// Doc is the doc comment of the interface, if any.
+	Doc *ast.CommentGroup
+	// Context is the context of the file declaring the interface.
+	Context *resolution.LocatorContext
+	// ClassifyErrors makes the constructor accept an error classifier whose
+	// result is recorded as a label of the failed operations.
+	ClassifyErrors bool
+	// InFlightOps makes the constructor accept a gauge of the operations
+	// in progress.
+	InFlightOps bool
+	// RecordOutcome adds the outcome of the operation, success or error,
+	// as a label of the operation duration.
+	RecordOutcome bool
+	// Metrics adds a type that creates the metrics expected by the
+	// constructor and wraps implementations with them.
+	Metrics bool
+	// LabelsFunc makes the constructor accept a function that returns
+	// additional label pairs from the context of every call.
+	LabelsFunc bool
+	// Streams makes the operations that return streams end when their
+	// stream ends, and accept a histogram of the stream sizes.
+	Streams bool
+	// ResultSize makes the constructor accept a histogram of the sizes of
+	// the results of the operations, as selected by FindSizeResult.
+	ResultSize bool
+	// Toggles makes the constructor accept the runtime controls that
+	// disable or sample the instrumentation of the methods.
+	Toggles bool
+	// Panics makes the methods record a panic of the call as a failed
+	// operation, of the "panic" error class if errors are classified,
+	// before raising it again.
+	Panics bool
+	// Naming is the policy for operation names and label keys.
+	Naming transformation.Naming
+	// Buckets declares bucket groups by name, with their bucket bounds in
+	// seconds, in addition to the ones declared with directives.
+	Buckets map[string][]string
+	// MethodBuckets selects the bucket groups of methods by method name.
+	MethodBuckets map[string]string
+}
+
+// OperationName returns the name of the operation of the method, which is
+// recorded as the value of the operation label.
+func (cfg ModelConfig) OperationName(methodName string) (string, error) {
+	return cfg.Naming.Operation(transformation.Operation{
+		Package:   cfg.InterfacePath,
+		Interface: cfg.InterfaceName,
+		Method:    methodName,
+	})
+}
+
+// LabelKeys are the keys of the labels recorded by the generated code, in
+// addition to the declared ones.
+type LabelKeys struct {
+	Operation  string
+	ErrorClass string
+	Outcome    string
+}
+
+// LabelKeys returns the keys of the labels in the key case of the naming
+// policy.
+func (cfg ModelConfig) LabelKeys() LabelKeys {
+	return LabelKeys{
+		Operation:  cfg.Naming.Key(OperationLabel),
+		ErrorClass: cfg.Naming.Key(ErrorClassLabel),
+		Outcome:    cfg.Naming.Key(OutcomeLabel),
+	}
+}
+
+// ContextParam returns the name of the first parameter of the method if it is
+// a context.Context, or nil otherwise.
+func ContextParam(method *astgen.MethodConfig, contextPackageAlias string) *ast.Ident {
+	if len(method.MethodParams) == 0 {
+		return nil
+	}
+	param := method.MethodParams[0]
+	sel, ok := param.Type.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Context" {
+		return nil
+	}
+	if id, ok := sel.X.(*ast.Ident); !ok || id.Name != contextPackageAlias {
+		return nil
+	}
+	return ast.NewIdent(param.Names[0].Name)
+}
+
 type StartTimeRecorder struct {
 	TimePackageAlias string
 	StartFieldName   string
@@ -114,3 +227,12 @@ func (r *ReturnResults) Build() ast.Stmt {
 		Results: resultSelectors,
 	}
 }
+
+// Stmts returns the return statement, or none if the method has no results,
+// whose calls end at the end of the method instead.
+func (r *ReturnResults) Stmts() []ast.Stmt {
+	if !r.method.HasResults() {
+		return nil
+	}
+	return []ast.Stmt{r.Build()}
+}
@@ -0,0 +1,166 @@
+package commonbuilders
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"github.com/Bo0mer/gentools/pkg/astgen"
+)
+
+// names used by the generated error classification
+const (
+	ClassifyErrorParamName = "classifyError"
+	ErrorClassLabel        = "error_class"
+	errorClassMethodName   = "errorClass"
+)
+
+// default error classes
+const (
+	canceledErrorClass = "canceled"
+	timeoutErrorClass  = "timeout"
+	defaultErrorClass  = "error"
+
+	// FailedErrorClass is the class of the calls that failed by their
+	// failure predicate, without an error.
+	FailedErrorClass = "failed"
+
+	// PanicErrorClass is the class of the calls that panicked.
+	PanicErrorClass = "panic"
+)
+
+// ErrorClassParam returns the constructor parameter that accepts the error
+// classifier.
+func ErrorClassParam() *ast.Field {
+	return &ast.Field{
+		Names: []*ast.Ident{ast.NewIdent(ClassifyErrorParamName)},
+		Type:  errorClassifierType(),
+	}
+}
+
+// AddErrorClassField adds the field that holds the error classifier to the
+// struct.
+func AddErrorClassField(strct *astgen.Struct) {
+	strct.AddFieldWithType(ClassifyErrorParamName, errorClassifierType())
+}
+
+// ErrorClassInit returns the struct field initializer for the error
+// classifier.
+func ErrorClassInit() ast.Expr {
+	return &ast.KeyValueExpr{
+		Key:   ast.NewIdent(ClassifyErrorParamName),
+		Value: ast.NewIdent(ClassifyErrorParamName),
+	}
+}
+
+func errorClassifierType() ast.Expr {
+	return &ast.FuncType{
+		Params: &ast.FieldList{
+			List: []*ast.Field{{Type: ast.NewIdent("error")}},
+		},
+		Results: &ast.FieldList{
+			List: []*ast.Field{{Type: ast.NewIdent("string")}},
+		},
+	}
+}
+
+// ErrorClass returns an expression that classifies err using the error
+// classifier of the receiver.
+func ErrorClass(receiverName string, err ast.Expr) ast.Expr {
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   ast.NewIdent(receiverName),
+			Sel: ast.NewIdent(errorClassMethodName),
+		},
+		Args: []ast.Expr{err},
+	}
+}
+
+// ErrorClassMethod builds the method that classifies errors for the error
+// class label.
+type ErrorClassMethod struct {
+	StructName          string
+	ErrorsPackageAlias  string
+	ContextPackageAlias string
+	// Key is the key of the error class label.
+	Key string
+}
+
+// Build builds a method in the form:
+//
+//	func (m *monitoringService) errorClass(err error) string {
+//		if err == nil {
+//			return "failed"
+//		}
+//		if m.classifyError != nil {
+//			return m.labelValue("error_class", m.classifyError(err))
+//		}
+//		switch {
+//		case errors.Is(err, context.Canceled):
+//			return "canceled"
+//		case errors.Is(err, context.DeadlineExceeded):
+//			return "timeout"
+//		}
+//		return "error"
+//	}
+func (e ErrorClassMethod) Build() ast.Decl {
+	classifier := &ast.SelectorExpr{X: ast.NewIdent("m"), Sel: ast.NewIdent(ClassifyErrorParamName)}
+	err := ast.NewIdent("err")
+	ret := func(result ast.Expr) *ast.ReturnStmt {
+		return &ast.ReturnStmt{Results: []ast.Expr{result}}
+	}
+	errorIs := func(target string) *ast.CaseClause {
+		return &ast.CaseClause{
+			List: []ast.Expr{
+				&ast.CallExpr{
+					Fun:  astgen.QualifiedName(e.ErrorsPackageAlias, "Is"),
+					Args: []ast.Expr{err, astgen.QualifiedName(e.ContextPackageAlias, target)},
+				},
+			},
+		}
+	}
+	// the classes returned by the classifier are guarded like label values
+	classify := &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: ast.NewIdent("m"), Sel: ast.NewIdent(labelValueMethodName)},
+		Args: []ast.Expr{StringLit(e.Key), &ast.CallExpr{Fun: classifier, Args: []ast.Expr{err}}},
+	}
+	canceled := errorIs("Canceled")
+	canceled.Body = []ast.Stmt{ret(StringLit(canceledErrorClass))}
+	deadlineExceeded := errorIs("DeadlineExceeded")
+	deadlineExceeded.Body = []ast.Stmt{ret(StringLit(timeoutErrorClass))}
+
+	method := astgen.NewMethod(errorClassMethodName, "m", e.StructName)
+	method.SetType(&ast.FuncType{
+		Params: &ast.FieldList{
+			List: []*ast.Field{{Names: []*ast.Ident{err}, Type: ast.NewIdent("error")}},
+		},
+		Results: &ast.FieldList{
+			List: []*ast.Field{{Type: ast.NewIdent("string")}},
+		},
+	})
+	method.AddStatements([]ast.Stmt{
+		// calls that failed by their failure predicate may return no error
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{X: err, Op: token.EQL, Y: ast.NewIdent("nil")},
+			Body: &ast.BlockStmt{List: []ast.Stmt{ret(StringLit(FailedErrorClass))}},
+		},
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{X: classifier, Op: token.NEQ, Y: ast.NewIdent("nil")},
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{ret(classify)},
+			},
+		},
+		&ast.SwitchStmt{
+			Body: &ast.BlockStmt{List: []ast.Stmt{canceled, deadlineExceeded}},
+		},
+		ret(StringLit(defaultErrorClass)),
+	})
+
+	decl := method.Build().(*ast.FuncDecl)
+	decl.Doc = &ast.CommentGroup{
+		List: []*ast.Comment{{
+			Text: fmt.Sprintf("// %s returns the class of the error recorded in the %s label.", errorClassMethodName, e.Key),
+		}},
+	}
+	return decl
+}
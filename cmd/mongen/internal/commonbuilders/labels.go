@@ -0,0 +1,394 @@
+package commonbuilders
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/directive"
+	"github.com/Bo0mer/gentools/pkg/resolution"
+)
+
+// names used by the generated label guard
+const (
+	MaxLabelValuesParamName = "maxLabelValues"
+	labelValuesFieldName    = "labelValues"
+	labelsMutexFieldName    = "labelsMu"
+	labelValueMethodName    = "labelValue"
+
+	// OtherLabelValue is recorded instead of the values of a label that
+	// exceed its cardinality limit.
+	OtherLabelValue = "other"
+)
+
+// Labels collects the extra labels declared with
+//
+//	//mongen:label NAME EXPRESSION
+//
+// directives in the doc comments of the interface and its methods. Labels
+// declared for the interface apply to all of its methods. The expression is
+// evaluated in the monitoring method, where the method arguments are named
+// arg1, arg2 and so on, and must be a string.
+type Labels struct {
+	importer resolution.Importer
+	doc      *ast.CommentGroup
+	context  *resolution.LocatorContext
+
+	keys   LabelKeys
+	names  []string
+	values map[string]map[string]ast.Expr
+
+	// classifyErrors reports whether the error classes are recorded, as
+	// labels whose values are guarded.
+	classifyErrors bool
+	// labelsFunc reports whether the labels returned by the labelsFunc of
+	// the constructor are recorded, with guarded values.
+	labelsFunc bool
+}
+
+// NewLabels creates labels for the interface described by cfg. Packages
+// referred to by the label expressions are imported with importer.
+func NewLabels(importer resolution.Importer, cfg ModelConfig) *Labels {
+	return &Labels{
+		importer: importer,
+		doc:      cfg.Doc,
+		context:  cfg.Context,
+		keys:     cfg.LabelKeys(),
+		values:   make(map[string]map[string]ast.Expr),
+
+		classifyErrors: cfg.ClassifyErrors,
+		labelsFunc:     cfg.LabelsFunc,
+	}
+}
+
+// AddMethod resolves the labels that apply to the method.
+func (l *Labels) AddMethod(method *astgen.MethodConfig) error {
+	locals := make(map[string]bool)
+	for _, param := range method.MethodParams {
+		locals[param.Names[0].String()] = true
+	}
+
+	values := make(map[string]ast.Expr)
+	add := func(doc *ast.CommentGroup, context *resolution.LocatorContext) error {
+		for _, d := range directive.Parse("mongen", doc) {
+			if d.Name != "label" {
+				continue
+			}
+			name, value, err := l.parseLabel(d, context, locals)
+			if err != nil {
+				return fmt.Errorf("method %s: invalid label directive %q: %v", method.MethodName, d.Args, err)
+			}
+			values[name] = value
+			l.addName(name)
+		}
+		return nil
+	}
+	if err := add(l.doc, l.context); err != nil {
+		return err
+	}
+	if err := add(method.Doc, method.Context); err != nil {
+		return err
+	}
+	l.values[method.MethodName] = values
+	return nil
+}
+
+func (l *Labels) parseLabel(d directive.Directive, context *resolution.LocatorContext, locals map[string]bool) (string, ast.Expr, error) {
+	fields := d.Fields()
+	if len(fields) < 2 {
+		return "", nil, fmt.Errorf("expected a label name and an expression")
+	}
+	name := fields[0]
+	if !token.IsIdentifier(name) {
+		return "", nil, fmt.Errorf("%q is not a valid label name", name)
+	}
+	if name == l.keys.Operation {
+		return "", nil, fmt.Errorf("the %s label is always recorded", name)
+	}
+	expr, err := parser.ParseExpr(strings.TrimSpace(strings.TrimPrefix(d.Args, name)))
+	if err != nil {
+		return "", nil, err
+	}
+	expr, err = resolution.ResolveExpr(l.importer, context, expr, locals)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, expr, nil
+}
+
+func (l *Labels) addName(name string) {
+	for _, n := range l.names {
+		if n == name {
+			return
+		}
+	}
+	l.names = append(l.names, name)
+}
+
+// Empty reports whether no extra labels were declared.
+func (l *Labels) Empty() bool {
+	return len(l.names) == 0
+}
+
+// Guarded reports whether label values are passed through the label guard,
+// which is the case if extra labels are declared, if errors are classified or
+// if labels are returned by labelsFunc.
+func (l *Labels) Guarded() bool {
+	return !l.Empty() || l.classifyErrors || l.labelsFunc
+}
+
+// Keys returns the keys of the labels that are recorded in addition to the
+// declared ones.
+func (l *Labels) Keys() LabelKeys {
+	return l.keys
+}
+
+// Names returns the names of the extra labels in the order they were
+// declared.
+func (l *Labels) Names() []string {
+	return l.names
+}
+
+// MethodNames returns the names of the extra labels declared for the method,
+// in the order of Names.
+func (l *Labels) MethodNames(methodName string) []string {
+	var names []string
+	for _, name := range l.names {
+		if _, ok := l.values[methodName][name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Values returns the expressions that compute the values of the extra labels
+// in the method, in the order of Names. The values are passed through the
+// label guard of the receiver. Labels not declared for the method are empty.
+func (l *Labels) Values(receiverName, methodName string) []ast.Expr {
+	var values []ast.Expr
+	for _, name := range l.names {
+		value, ok := l.values[methodName][name]
+		if !ok {
+			values = append(values, StringLit(""))
+			continue
+		}
+		values = append(values, &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   ast.NewIdent(receiverName),
+				Sel: ast.NewIdent(labelValueMethodName),
+			},
+			Args: []ast.Expr{StringLit(name), value},
+		})
+	}
+	return values
+}
+
+// GuardedLabelValue returns a call of the label guard of the receiver m,
+// which returns the value of the label to record.
+func GuardedLabelValue(label, value ast.Expr) ast.Expr {
+	return &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: ast.NewIdent("m"), Sel: ast.NewIdent(labelValueMethodName)},
+		Args: []ast.Expr{label, value},
+	}
+}
+
+// AddLabelGuardFields adds the fields used by the label guard to the struct.
+func AddLabelGuardFields(strct *astgen.Struct, syncPackageAlias string) {
+	strct.AddFieldWithType(MaxLabelValuesParamName, ast.NewIdent("int"))
+	strct.AddField(labelsMutexFieldName, syncPackageAlias, "Mutex")
+	strct.AddFieldWithType(labelValuesFieldName, labelValuesType())
+}
+
+// LabelGuardInit returns the struct fields initializers for the label guard.
+func LabelGuardInit() []ast.Expr {
+	return []ast.Expr{
+		&ast.KeyValueExpr{
+			Key:   ast.NewIdent(MaxLabelValuesParamName),
+			Value: ast.NewIdent(MaxLabelValuesParamName),
+		},
+		&ast.KeyValueExpr{
+			Key:   ast.NewIdent(labelValuesFieldName),
+			Value: &ast.CompositeLit{Type: labelValuesType()},
+		},
+	}
+}
+
+// LabelGuardParam returns the constructor parameter that limits the number
+// of distinct values of each label.
+func LabelGuardParam() *ast.Field {
+	return &ast.Field{
+		Names: []*ast.Ident{ast.NewIdent(MaxLabelValuesParamName)},
+		Type:  ast.NewIdent("int"),
+	}
+}
+
+func labelValuesType() ast.Expr {
+	return &ast.MapType{
+		Key: ast.NewIdent("string"),
+		Value: &ast.MapType{
+			Key:   ast.NewIdent("string"),
+			Value: ast.NewIdent("bool"),
+		},
+	}
+}
+
+// LabelGuardMethod builds the method that caps the cardinality of the extra
+// labels.
+type LabelGuardMethod struct {
+	StructName string
+}
+
+// Build builds a method in the form:
+//
+//	func (m *monitoringService) labelValue(label, value string) string {
+//		if m.maxLabelValues <= 0 {
+//			return value
+//		}
+//		m.labelsMu.Lock()
+//		defer m.labelsMu.Unlock()
+//		values := m.labelValues[label]
+//		if values[value] {
+//			return value
+//		}
+//		if len(values) >= m.maxLabelValues {
+//			return "other"
+//		}
+//		if values == nil {
+//			values = make(map[string]bool)
+//			m.labelValues[label] = values
+//		}
+//		values[value] = true
+//		return value
+//	}
+func (g LabelGuardMethod) Build() ast.Decl {
+	field := func(name string) *ast.SelectorExpr {
+		return &ast.SelectorExpr{X: ast.NewIdent("m"), Sel: ast.NewIdent(name)}
+	}
+	call := func(fun ast.Expr, args ...ast.Expr) *ast.CallExpr {
+		return &ast.CallExpr{Fun: fun, Args: args}
+	}
+	ret := func(result ast.Expr) *ast.ReturnStmt {
+		return &ast.ReturnStmt{Results: []ast.Expr{result}}
+	}
+	values := ast.NewIdent("values")
+	value := ast.NewIdent("value")
+	label := ast.NewIdent("label")
+	labelValues := &ast.IndexExpr{X: field(labelValuesFieldName), Index: label}
+
+	method := astgen.NewMethod(labelValueMethodName, "m", g.StructName)
+	method.SetType(&ast.FuncType{
+		Params: &ast.FieldList{
+			List: []*ast.Field{
+				{Names: []*ast.Ident{label, value}, Type: ast.NewIdent("string")},
+			},
+		},
+		Results: &ast.FieldList{
+			List: []*ast.Field{{Type: ast.NewIdent("string")}},
+		},
+	})
+	method.AddStatements([]ast.Stmt{
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{
+				X:  field(MaxLabelValuesParamName),
+				Op: token.LEQ,
+				Y:  &ast.BasicLit{Kind: token.INT, Value: "0"},
+			},
+			Body: &ast.BlockStmt{List: []ast.Stmt{ret(value)}},
+		},
+		&ast.ExprStmt{X: call(&ast.SelectorExpr{X: field(labelsMutexFieldName), Sel: ast.NewIdent("Lock")})},
+		&ast.DeferStmt{Call: call(&ast.SelectorExpr{X: field(labelsMutexFieldName), Sel: ast.NewIdent("Unlock")})},
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{values},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{labelValues},
+		},
+		&ast.IfStmt{
+			Cond: &ast.IndexExpr{X: values, Index: value},
+			Body: &ast.BlockStmt{List: []ast.Stmt{ret(value)}},
+		},
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{
+				X:  call(ast.NewIdent("len"), values),
+				Op: token.GEQ,
+				Y:  field(MaxLabelValuesParamName),
+			},
+			Body: &ast.BlockStmt{List: []ast.Stmt{ret(StringLit(OtherLabelValue))}},
+		},
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{X: values, Op: token.EQL, Y: ast.NewIdent("nil")},
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{
+					&ast.AssignStmt{
+						Lhs: []ast.Expr{values},
+						Tok: token.ASSIGN,
+						Rhs: []ast.Expr{call(ast.NewIdent("make"), &ast.MapType{Key: ast.NewIdent("string"), Value: ast.NewIdent("bool")})},
+					},
+					&ast.AssignStmt{
+						Lhs: []ast.Expr{labelValues},
+						Tok: token.ASSIGN,
+						Rhs: []ast.Expr{values},
+					},
+				},
+			},
+		},
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.IndexExpr{X: values, Index: value}},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{ast.NewIdent("true")},
+		},
+		ret(value),
+	})
+
+	decl := method.Build().(*ast.FuncDecl)
+	decl.Doc = &ast.CommentGroup{
+		List: []*ast.Comment{{
+			Text: fmt.Sprintf("// %s returns the value of the label, or %q once the label has maxLabelValues distinct values.", labelValueMethodName, OtherLabelValue),
+		}},
+	}
+	return decl
+}
+
+// LabelNamesVar builds an exported variable that lists the names of all
+// labels recorded by the generated code, so that metric vectors and views can
+// be declared with them.
+type LabelNamesVar struct {
+	Name   string
+	Labels *Labels
+}
+
+// Build builds a declaration in the form:
+//
+//	var MonitoringServiceLabels = []string{"operation", "tenant"}
+func (v LabelNamesVar) Build() ast.Decl {
+	names := []ast.Expr{StringLit(v.Labels.Keys().Operation)}
+	for _, name := range v.Labels.Names() {
+		names = append(names, StringLit(name))
+	}
+	return &ast.GenDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{{
+				Text: fmt.Sprintf("// %s are the names of the labels recorded by the monitoring middleware.", v.Name),
+			}},
+		},
+		Tok: token.VAR,
+		Specs: []ast.Spec{
+			&ast.ValueSpec{
+				Names: []*ast.Ident{ast.NewIdent(v.Name)},
+				Values: []ast.Expr{
+					&ast.CompositeLit{
+						Type: &ast.ArrayType{Elt: ast.NewIdent("string")},
+						Elts: names,
+					},
+				},
+			},
+		},
+	}
+}
+
+// StringLit returns a string literal with the specified value.
+func StringLit(s string) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", s)}
+}
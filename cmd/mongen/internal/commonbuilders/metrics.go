@@ -0,0 +1,188 @@
+package commonbuilders
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// Metric describes a metric expected by the generated constructor.
+type Metric struct {
+	// Param is the name of the constructor parameter.
+	Param string
+	// Name is the name of the metric, without namespace.
+	Name string
+	// Help describes the metric.
+	Help string
+}
+
+// the metrics expected by the generated constructors
+var (
+	TotalOpsMetric    = Metric{TotalOpsMetricName, "total_ops", "Total number of operations."}
+	FailedOpsMetric   = Metric{FailedOpsMetricName, "failed_ops", "Number of failed operations."}
+	OpsDurationMetric = Metric{OpsDurationMetricName, "ops_duration_seconds", "Duration of operations in seconds."}
+	InFlightOpsMetric = Metric{InFlightOpsMetricName, "in_flight_ops", "Number of operations in progress."}
+	StreamSizeMetric  = Metric{StreamSizeMetricName, "stream_size", "Number of items or bytes received from the streams returned by operations."}
+	ResultSizeMetric  = Metric{ResultSizeMetricName, "result_size", "Number of items in the results of operations."}
+)
+
+// DurationBuckets are the default buckets of the operation duration, in
+// seconds.
+var DurationBuckets = []string{".005", ".01", ".025", ".05", ".1", ".25", ".5", "1", "2.5", "5", "10"}
+
+// SizeBuckets are the default buckets of sizes, in items or bytes.
+var SizeBuckets = []string{"1", "4", "16", "64", "256", "1024", "4096", "16384", "65536", "262144"}
+
+// Field returns the name of the field that holds the metric in the type
+// generated by MetricsStruct.
+func (m Metric) Field() string {
+	return strings.ToUpper(m.Param[:1]) + m.Param[1:]
+}
+
+// LabelNames returns the names of the labels the metric is recorded with.
+func (m Metric) LabelNames(labels *Labels, classifyErrors, recordOutcome bool) []string {
+	keys := labels.Keys()
+	names := append([]string{keys.Operation}, labels.Names()...)
+	switch {
+	case m == FailedOpsMetric && classifyErrors:
+		names = append(names, keys.ErrorClass)
+	case strings.HasPrefix(m.Param, OpsDurationMetricName) && recordOutcome:
+		names = append(names, keys.Outcome)
+	}
+	return names
+}
+
+// MetricsTypeName returns the name of the type that holds the metrics
+// expected by the constructor.
+func MetricsTypeName(constructorName string) string {
+	return DerivedName("", constructorName, "Metrics")
+}
+
+// MetricsConstructorName returns the name of the function that creates the
+// metrics expected by the constructor.
+func MetricsConstructorName(constructorName string) string {
+	return DerivedName("New", constructorName, "Metrics")
+}
+
+// MetricsStruct builds the exported type that holds the metrics expected by
+// the constructor.
+type MetricsStruct struct {
+	Name   string
+	Fields []*ast.Field
+}
+
+// Build builds a declaration in the form:
+//
+//	type MonitoringServiceMetrics struct {
+//		TotalOps    metrics.Counter
+//		...
+//	}
+func (s MetricsStruct) Build() ast.Decl {
+	return &ast.GenDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{{
+				Text: fmt.Sprintf("// %s holds the metrics recorded by the monitoring middleware.", s.Name),
+			}},
+		},
+		Tok: token.TYPE,
+		Specs: []ast.Spec{
+			&ast.TypeSpec{
+				Name: ast.NewIdent(s.Name),
+				Type: &ast.StructType{Fields: &ast.FieldList{List: s.Fields}},
+			},
+		},
+	}
+}
+
+// ConstructorParams is implemented by the builders of the generated
+// constructors.
+type ConstructorParams interface {
+	// Params returns the parameters of the constructor.
+	Params() []*ast.Field
+}
+
+// MetricsWrapMethod builds a method of the metrics type that wraps an
+// implementation using the constructor.
+type MetricsWrapMethod struct {
+	TypeName        string
+	ConstructorName string
+	Constructor     ConstructorParams
+	InterfaceType   ast.Expr
+	// Buckets are the bucket groups whose duration metrics the constructor
+	// expects, if any.
+	Buckets *BucketGroups
+}
+
+// Build builds a method in the form:
+//
+//	func (ms *MonitoringServiceMetrics) Wrap(next service.Service, maxLabelValues int) service.Service {
+//		return NewMonitoringService(next, ms.TotalOps, ms.FailedOps, ms.OpsDuration, maxLabelValues)
+//	}
+//
+// The parameters of the constructor that are not metrics are parameters of
+// the method as well.
+func (w MetricsWrapMethod) Build() ast.Decl {
+	metrics := make(map[string]Metric)
+	for _, m := range []Metric{TotalOpsMetric, FailedOpsMetric, OpsDurationMetric, InFlightOpsMetric, StreamSizeMetric, ResultSizeMetric} {
+		metrics[m.Param] = m
+	}
+	if w.Buckets != nil {
+		for _, group := range w.Buckets.Groups() {
+			m := DurationMetric(group)
+			metrics[m.Param] = m
+		}
+	}
+
+	var params []*ast.Field
+	var args []ast.Expr
+	for _, param := range w.Constructor.Params() {
+		name := param.Names[0].Name
+		if m, ok := metrics[name]; ok {
+			args = append(args, &ast.SelectorExpr{X: ast.NewIdent("ms"), Sel: ast.NewIdent(m.Field())})
+			continue
+		}
+		params = append(params, param)
+		args = append(args, ast.NewIdent(name))
+	}
+
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{{
+				Text: fmt.Sprintf("// Wrap wraps next with monitoring middleware created by %s that records the metrics.", w.ConstructorName),
+			}},
+		},
+		Recv: &ast.FieldList{
+			List: []*ast.Field{{
+				Names: []*ast.Ident{ast.NewIdent("ms")},
+				Type:  &ast.StarExpr{X: ast.NewIdent(w.TypeName)},
+			}},
+		},
+		Name: ast.NewIdent("Wrap"),
+		Type: &ast.FuncType{
+			Params:  &ast.FieldList{List: params},
+			Results: &ast.FieldList{List: []*ast.Field{{Type: w.InterfaceType}}},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ReturnStmt{
+					Results: []ast.Expr{
+						&ast.CallExpr{Fun: ast.NewIdent(w.ConstructorName), Args: args},
+					},
+				},
+			},
+		},
+	}
+}
+
+// StringSliceLit returns a []string literal with the specified values.
+func StringSliceLit(values []string) *ast.CompositeLit {
+	var elts []ast.Expr
+	for _, v := range values {
+		elts = append(elts, StringLit(v))
+	}
+	return &ast.CompositeLit{
+		Type: &ast.ArrayType{Elt: ast.NewIdent("string")},
+		Elts: elts,
+	}
+}
@@ -0,0 +1,59 @@
+package commonbuilders
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// constructorPrefix is the prefix of the names of the generated constructors
+// that is left out of the names of the declarations that accompany them. The
+// prefix of unexported constructors is lowercase.
+const constructorPrefix = "New"
+
+// DerivedName returns the name of a declaration that accompanies the
+// constructor, made of the prefix, the name of the constructor without its New
+// prefix and the suffix, e.g. MonitoringServiceMetrics or
+// RegisterMonitoringService. The New prefix is left out only if an uppercase
+// letter follows it, so that constructors such as New11 or Newer are kept
+// whole. The declarations of an unexported constructor are unexported as
+// well, e.g. newService is accompanied by serviceMetrics and
+// registerService.
+func DerivedName(prefix, constructorName, suffix string) string {
+	exported := token.IsExported(constructorName)
+	namePrefix := constructorPrefix
+	if !exported {
+		namePrefix = strings.ToLower(constructorPrefix)
+	}
+	name := constructorName
+	if rest := strings.TrimPrefix(name, namePrefix); rest != name {
+		if r, _ := utf8.DecodeRuneInString(rest); unicode.IsUpper(r) {
+			name = rest
+		}
+	}
+	name = prefix + withFirstRune(name, unicode.ToUpper) + suffix
+	if !exported {
+		return withFirstRune(name, unicode.ToLower)
+	}
+	return name
+}
+
+// withFirstRune returns s with its first rune mapped by f.
+func withFirstRune(s string, f func(rune) rune) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(f(r)) + s[size:]
+}
+
+// CheckDerivedNames reports an error if the name of a declaration that
+// accompanies the constructor is the name of the generated type. Only the
+// names of the declarations that are generated are checked.
+func CheckDerivedNames(typeName string, derivedNames ...string) error {
+	for _, name := range derivedNames {
+		if name == typeName {
+			return fmt.Errorf("cannot name the generated type %q: the name is taken by a declaration derived from the constructor name", typeName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package commonbuilders_test
+
+import (
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
+)
+
+func TestDerivedName(t *testing.T) {
+	for _, tc := range []struct {
+		constructor string
+		want        string
+		wantNew     string
+	}{
+		{"NewMonitoringService", "MonitoringServiceMetrics", "NewMonitoringServiceMetrics"},
+		{"NewX", "XMetrics", "NewXMetrics"},
+		{"New", "NewMetrics", "NewNewMetrics"},
+		{"New11", "New11Metrics", "NewNew11Metrics"},
+		{"Newer", "NewerMetrics", "NewNewerMetrics"},
+		{"NewNewX", "NewXMetrics", "NewNewXMetrics"},
+		{"Monitoring", "MonitoringMetrics", "NewMonitoringMetrics"},
+		{"newX", "xMetrics", "newXMetrics"},
+		{"newService", "serviceMetrics", "newServiceMetrics"},
+		{"new", "newMetrics", "newNewMetrics"},
+		{"newer", "newerMetrics", "newNewerMetrics"},
+		{"monitoring", "monitoringMetrics", "newMonitoringMetrics"},
+		{"_newService", "_newServiceMetrics", "new_newServiceMetrics"},
+	} {
+		if got := commonbuilders.MetricsTypeName(tc.constructor); got != tc.want {
+			t.Errorf("%s: got type %q, want %q", tc.constructor, got, tc.want)
+		}
+		if got := commonbuilders.MetricsConstructorName(tc.constructor); got != tc.wantNew {
+			t.Errorf("%s: got constructor %q, want %q", tc.constructor, got, tc.wantNew)
+		}
+	}
+	for _, tc := range []struct {
+		constructor string
+		want        string
+	}{
+		{"NewMonitoringService", "RegisterMonitoringService"},
+		{"newRich", "registerRich"},
+	} {
+		if got := commonbuilders.DerivedName("Register", tc.constructor, ""); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.constructor, got, tc.want)
+		}
+	}
+}
+
+func TestCheckDerivedNames(t *testing.T) {
+	if err := commonbuilders.CheckDerivedNames("monitoringService", "MonitoringServiceMetrics", "NewMonitoringServiceMetrics"); err != nil {
+		t.Error(err)
+	}
+	if err := commonbuilders.CheckDerivedNames("serviceMetrics", "serviceMetrics", "newServiceMetrics"); err == nil {
+		t.Error("got no error for a type named as a derived declaration")
+	}
+	if err := commonbuilders.CheckDerivedNames("serviceMetrics"); err != nil {
+		t.Errorf("got %v, want no error without derived declarations", err)
+	}
+}
@@ -0,0 +1,148 @@
+package commonbuilders
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/Bo0mer/gentools/pkg/astgen"
+)
+
+// outcome label and its values
+const (
+	OutcomeLabel        = "outcome"
+	outcomeVarName      = "_outcome"
+	successOutcomeValue = "success"
+	errorOutcomeValue   = "error"
+)
+
+// ErrorResult returns the name of the first result of the method whose type
+// implements error, or nil if there is none.
+func ErrorResult(method *astgen.MethodConfig) *ast.Ident {
+	if result := method.ErrorResult(); result != nil {
+		return ast.NewIdent(result.Name)
+	}
+	return nil
+}
+
+// errVarName is the name of the variable that holds the error result of the
+// method converted to error.
+const errVarName = "_err"
+
+// Failure determines whether a call to a method failed: the method returned a
+// non-nil error, or the failure predicate declared for it holds.
+type Failure struct {
+	// Err is the error result of the method, if any.
+	Err *astgen.ErrorResult
+	// Predicate is the failure predicate of the method, if any.
+	Predicate ast.Expr
+	// Panic reports whether the call panicked rather than returned.
+	Panic bool
+}
+
+// PanicFailure is the failure of the calls that panicked. It is recorded in
+// the statements passed to astgen.Recover, where the call failed for sure.
+var PanicFailure = Failure{Panic: true}
+
+// NewFailure returns the failure of the method.
+func NewFailure(method *astgen.MethodConfig) Failure {
+	return Failure{Err: method.ErrorResult(), Predicate: method.FailurePredicate}
+}
+
+// Possible reports whether a call to the method can fail at all.
+func (f Failure) Possible() bool {
+	return f.Err != nil || f.Predicate != nil
+}
+
+// Cond returns the condition under which the call failed, e.g.
+// err != nil || !ok. It must only be called if the failure is possible.
+func (f Failure) Cond() ast.Expr {
+	if f.Err == nil {
+		return f.Predicate
+	}
+	errCond := &ast.BinaryExpr{X: ast.NewIdent(f.Err.Name), Op: token.NEQ, Y: ast.NewIdent("nil")}
+	if f.Predicate == nil {
+		return errCond
+	}
+	return &ast.BinaryExpr{X: errCond, Op: token.LOR, Y: f.Predicate}
+}
+
+// Class returns an expression that classifies the failure with the error
+// classifier of the receiver, and the statements that must precede it in the
+// body of the if statement with Cond. Calls that failed without an error are
+// of the "failed" class, and calls that panicked of the "panic" class.
+//
+// If the failure predicate held, the error may be nil. An error of a concrete
+// type, e.g. *MyError, is then converted to error by the statements first, so
+// that a nil one is classified as nil rather than as a typed nil.
+func (f Failure) Class(receiverName string) ([]ast.Stmt, ast.Expr) {
+	if f.Panic {
+		return nil, StringLit(PanicErrorClass)
+	}
+	if f.Err == nil {
+		return nil, StringLit(FailedErrorClass)
+	}
+	if f.Predicate == nil {
+		return nil, ErrorClass(receiverName, ast.NewIdent(f.Err.Name))
+	}
+	stmts, err := f.Err.AsError(errVarName)
+	return stmts, ErrorClass(receiverName, err)
+}
+
+// PanicOutcome returns the outcome of the calls that panicked, which is
+// "error".
+func PanicOutcome() ast.Expr {
+	return ErrorOutcome()
+}
+
+// SuccessOutcome returns the outcome of the calls that succeeded.
+func SuccessOutcome() ast.Expr {
+	return StringLit(successOutcomeValue)
+}
+
+// ErrorOutcome returns the outcome of the calls that failed.
+func ErrorOutcome() ast.Expr {
+	return StringLit(errorOutcomeValue)
+}
+
+// Outcome determines the outcome of a call to the method, after the results
+// have been assigned.
+type Outcome struct {
+	Method *astgen.MethodConfig
+}
+
+// Build builds the statements that compute the outcome and the expression
+// that refers to it. If the call can fail, the statements are in the form:
+//
+//	_outcome := "success"
+//	if err != nil {
+//		_outcome = "error"
+//	}
+//
+// Otherwise there are no statements and the outcome is always "success".
+func (o Outcome) Build() ([]ast.Stmt, ast.Expr) {
+	failure := NewFailure(o.Method)
+	if !failure.Possible() {
+		return nil, StringLit(successOutcomeValue)
+	}
+
+	outcome := ast.NewIdent(outcomeVarName)
+	return []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{outcome},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{StringLit(successOutcomeValue)},
+		},
+		&ast.IfStmt{
+			Cond: failure.Cond(),
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{
+					&ast.AssignStmt{
+						Lhs: []ast.Expr{outcome},
+						Tok: token.ASSIGN,
+						Rhs: []ast.Expr{StringLit(errorOutcomeValue)},
+					},
+				},
+			},
+		},
+	}, outcome
+}
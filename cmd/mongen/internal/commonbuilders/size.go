@@ -0,0 +1,210 @@
+package commonbuilders
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/directive"
+)
+
+// SizeResult describes a result of a method whose size is recorded after the
+// call: the number of items in a slice or a map, or the value returned by the
+// Len method of the result.
+type SizeResult struct {
+	// Name is the name of the result.
+	Name *ast.Ident
+	// Len reports whether the size is returned by the Len method of the
+	// result rather than by the len builtin.
+	Len bool
+	// Nillable reports whether the Len method may not be callable on the
+	// result, e.g. a nil interface, so that it is only measured if it is not
+	// nil.
+	Nillable bool
+	// Interface reports whether the result is an interface, which may hold
+	// a nil pointer whose Len method panics, so that it is only measured if
+	// it does not.
+	Interface bool
+
+	// reflectPackage is the alias of the reflect package, which tells nil
+	// pointers held by interfaces apart.
+	reflectPackage string
+}
+
+// AddImports adds the imports of the statements that record the size of the
+// result with addImport.
+func (s *SizeResult) AddImports(addImport func(pkgName, location string) string) {
+	if s.Interface {
+		s.reflectPackage = addImport("", "reflect")
+	}
+}
+
+// FindSizeResult returns the result of the method whose size is recorded. It
+// is the first slice, map or type with a Len() int method among the results,
+// unless another one is selected with a
+//
+//	//mongen:size RESULT
+//
+// directive in the doc comment of the method, where RESULT is result1,
+// result2 and so on. The sizes of the results of the method are not recorded
+// at all if RESULT is "none".
+func FindSizeResult(method *astgen.MethodConfig) (SizeResult, bool, error) {
+	selected := ""
+	for _, d := range directive.Parse("mongen", method.Doc) {
+		if d.Name != "size" {
+			continue
+		}
+		fields := d.Fields()
+		if len(fields) != 1 {
+			return SizeResult{}, false, fmt.Errorf("method %s: invalid size directive %q: expected a result or none", method.MethodName, d.Args)
+		}
+		selected = fields[0]
+	}
+	if selected == "none" {
+		return SizeResult{}, false, nil
+	}
+
+	for i, result := range method.MethodResults {
+		name := result.Names[0].String()
+		if selected != "" && name != selected {
+			continue
+		}
+		size, ok := sizeResult(method, i)
+		if ok {
+			return size, true, nil
+		}
+		if selected != "" {
+			return SizeResult{}, false, fmt.Errorf("method %s: invalid size directive %q: %s is not a slice, a map or a type with a Len() int method", method.MethodName, selected, name)
+		}
+	}
+	if selected != "" {
+		return SizeResult{}, false, fmt.Errorf("method %s: invalid size directive %q: no such result", method.MethodName, selected)
+	}
+	return SizeResult{}, false, nil
+}
+
+// sizeResult describes the result at index i, if its size can be recorded.
+// Without the types of the results, only slices and maps are recognized, by
+// their syntax.
+func sizeResult(method *astgen.MethodConfig, i int) (SizeResult, bool) {
+	name := ast.NewIdent(method.MethodResults[i].Names[0].String())
+	if method.ResultTypes == nil {
+		switch t := method.MethodResults[i].Type.(type) {
+		case *ast.ArrayType:
+			return SizeResult{Name: name}, t.Len == nil
+		case *ast.MapType:
+			return SizeResult{Name: name}, true
+		}
+		return SizeResult{}, false
+	}
+
+	t := method.ResultTypes[i]
+	switch t.Underlying().(type) {
+	case *types.Slice, *types.Map:
+		return SizeResult{Name: name}, true
+	}
+	obj, _, _ := types.LookupFieldOrMethod(t, false, nil, "Len")
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return SizeResult{}, false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 || !types.Identical(sig.Results().At(0).Type(), types.Typ[types.Int]) {
+		return SizeResult{}, false
+	}
+	switch t.Underlying().(type) {
+	case *types.Pointer:
+		return SizeResult{Name: name, Len: true, Nillable: true}, true
+	case *types.Interface:
+		return SizeResult{Name: name, Len: true, Nillable: true, Interface: true}, true
+	}
+	return SizeResult{Name: name, Len: true}, true
+}
+
+// RecordSize builds the statements that record the size of the result with
+// the statements built by record, if the call did not fail:
+//
+//	if err == nil && result1 != nil {
+//		m.resultSize.Observe(float64(result1.Len()))
+//	}
+//
+// The size of an interface result is recorded only if it does not hold a nil
+// pointer, so that the wrapper does not panic where the wrapped call did not:
+//
+//	if err == nil && result1 != nil {
+//		if _value := reflect.ValueOf(result1); _value.Kind() != reflect.Pointer || !_value.IsNil() {
+//			m.resultSize.Observe(float64(result1.Len()))
+//		}
+//	}
+//
+// The size passed to record is an int.
+func RecordSize(method *astgen.MethodConfig, size SizeResult, record func(size ast.Expr) []ast.Stmt) []ast.Stmt {
+	var sizeExpr ast.Expr = &ast.CallExpr{Fun: ast.NewIdent("len"), Args: []ast.Expr{size.Name}}
+	if size.Len {
+		sizeExpr = &ast.CallExpr{Fun: &ast.SelectorExpr{X: size.Name, Sel: ast.NewIdent("Len")}}
+	}
+
+	var conds []ast.Expr
+	failure := NewFailure(method)
+	if failure.Err != nil {
+		conds = append(conds, &ast.BinaryExpr{X: ast.NewIdent(failure.Err.Name), Op: token.EQL, Y: ast.NewIdent("nil")})
+	}
+	if failure.Predicate != nil {
+		conds = append(conds, not(failure.Predicate))
+	}
+	if size.Nillable {
+		conds = append(conds, &ast.BinaryExpr{X: size.Name, Op: token.NEQ, Y: ast.NewIdent("nil")})
+	}
+	stmts := record(sizeExpr)
+	if size.Interface {
+		value := ast.NewIdent("_value")
+		reflectName := func(name string) ast.Expr { return astgen.QualifiedName(size.reflectPackage, name) }
+		valueMethod := func(name string) ast.Expr {
+			return &ast.CallExpr{Fun: &ast.SelectorExpr{X: value, Sel: ast.NewIdent(name)}}
+		}
+		stmts = []ast.Stmt{
+			&ast.IfStmt{
+				Init: &ast.AssignStmt{
+					Lhs: []ast.Expr{value},
+					Tok: token.DEFINE,
+					Rhs: []ast.Expr{&ast.CallExpr{Fun: reflectName("ValueOf"), Args: []ast.Expr{size.Name}}},
+				},
+				Cond: &ast.BinaryExpr{
+					X:  &ast.BinaryExpr{X: valueMethod("Kind"), Op: token.NEQ, Y: reflectName("Pointer")},
+					Op: token.LOR,
+					Y:  &ast.UnaryExpr{Op: token.NOT, X: valueMethod("IsNil")},
+				},
+				Body: &ast.BlockStmt{List: stmts},
+			},
+		}
+	}
+	if len(conds) == 0 {
+		return stmts
+	}
+
+	cond := conds[0]
+	for _, c := range conds[1:] {
+		cond = &ast.BinaryExpr{X: cond, Op: token.LAND, Y: c}
+	}
+	return []ast.Stmt{
+		&ast.IfStmt{
+			Cond: cond,
+			Body: &ast.BlockStmt{List: stmts},
+		},
+	}
+}
+
+// not returns the negation of the boolean expression.
+func not(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			return e.X
+		}
+	case *ast.Ident, *ast.CallExpr, *ast.SelectorExpr, *ast.ParenExpr:
+		return &ast.UnaryExpr{Op: token.NOT, X: expr}
+	}
+	return &ast.UnaryExpr{Op: token.NOT, X: &ast.ParenExpr{X: expr}}
+}
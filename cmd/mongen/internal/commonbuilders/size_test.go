@@ -0,0 +1,170 @@
+package commonbuilders_test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
+	"github.com/Bo0mer/gentools/pkg/astgen"
+)
+
+// sizeSource declares the types of the results of the methods of the tests.
+const sizeSource = `package sized
+
+type Lener struct{}
+
+func (Lener) Len() int { return 0 }
+
+type PtrLener struct{}
+
+func (*PtrLener) Len() int { return 0 }
+
+type Collection interface{ Len() int }
+
+type WideLener struct{}
+
+func (WideLener) Len() int64 { return 0 }
+`
+
+// sizeMethod returns a method whose results have the types, named result1,
+// result2 and so on, with the doc comment lines. The types of the results are
+// known only if typed is set.
+func sizeMethod(t *testing.T, typed bool, doc []string, results ...string) *astgen.MethodConfig {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "sized.go", sizeSource, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("sized", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	method := &astgen.MethodConfig{MethodName: "Get"}
+	if len(doc) > 0 {
+		method.Doc = &ast.CommentGroup{}
+		for _, text := range doc {
+			method.Doc.List = append(method.Doc.List, &ast.Comment{Text: text})
+		}
+	}
+	for i, result := range results {
+		expr, err := parser.ParseExpr(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		method.MethodResults = append(method.MethodResults, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(fmt.Sprintf("result%d", i+1))},
+			Type:  expr,
+		})
+		if typed {
+			tv, err := types.Eval(fset, pkg, token.NoPos, result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			method.ResultTypes = append(method.ResultTypes, tv.Type)
+		}
+	}
+	return method
+}
+
+func TestFindSizeResult(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		untyped bool
+		doc     []string
+		results []string
+		// want is the name of the sized result, if any
+		want          string
+		wantLen       bool
+		wantNillable  bool
+		wantInterface bool
+	}{
+		{name: "slice", results: []string{"[]int", "error"}, want: "result1"},
+		{name: "map", results: []string{"int", "map[string]int", "error"}, want: "result2"},
+		{name: "array", results: []string{"[3]int", "error"}},
+		{name: "Len method", results: []string{"Lener", "error"}, want: "result1", wantLen: true},
+		{name: "Len method of a pointer", results: []string{"*PtrLener", "error"}, want: "result1", wantLen: true, wantNillable: true},
+		{name: "Len method of an interface", results: []string{"Collection", "error"}, want: "result1", wantLen: true, wantNillable: true, wantInterface: true},
+		{name: "Len method not returning int", results: []string{"WideLener", "error"}},
+		{name: "first of several", results: []string{"[]int", "[]string", "error"}, want: "result1"},
+		{name: "selected", doc: []string{"//mongen:size result2"}, results: []string{"[]int", "[]string", "error"}, want: "result2"},
+		{name: "none", doc: []string{"//mongen:size none"}, results: []string{"[]int", "error"}},
+		{name: "no result", results: []string{"error"}},
+		{name: "untyped slice", untyped: true, results: []string{"[]int", "error"}, want: "result1"},
+		{name: "untyped map", untyped: true, results: []string{"map[string]int", "error"}, want: "result1"},
+		{name: "untyped Len method", untyped: true, results: []string{"Lener", "error"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			size, ok, err := commonbuilders.FindSizeResult(sizeMethod(t, !tc.untyped, tc.doc, tc.results...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.want == "" {
+				if ok {
+					t.Errorf("got %s sized, want none", size.Name)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("got none sized, want %s", tc.want)
+			}
+			if size.Name.Name != tc.want || size.Len != tc.wantLen || size.Nillable != tc.wantNillable || size.Interface != tc.wantInterface {
+				t.Errorf("got %s with Len %t, Nillable %t and Interface %t, want %s with %t, %t and %t",
+					size.Name, size.Len, size.Nillable, size.Interface, tc.want, tc.wantLen, tc.wantNillable, tc.wantInterface)
+			}
+		})
+	}
+}
+
+func TestFindSizeResultRejectsInvalidDirectives(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		doc     string
+		results []string
+		want    string
+	}{
+		{
+			name:    "not a collection",
+			doc:     "//mongen:size result1",
+			results: []string{"int", "[]int", "error"},
+			want:    "result1 is not a slice, a map or a type with a Len() int method",
+		},
+		{
+			name:    "Len method not returning int",
+			doc:     "//mongen:size result1",
+			results: []string{"WideLener", "error"},
+			want:    "result1 is not a slice, a map or a type with a Len() int method",
+		},
+		{
+			name:    "no such result",
+			doc:     "//mongen:size result3",
+			results: []string{"[]int", "error"},
+			want:    "no such result",
+		},
+		{
+			name:    "no result",
+			doc:     "//mongen:size",
+			results: []string{"[]int", "error"},
+			want:    "expected a result or none",
+		},
+		{
+			name:    "several results",
+			doc:     "//mongen:size result1 result2",
+			results: []string{"[]int", "[]int", "error"},
+			want:    "expected a result or none",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := commonbuilders.FindSizeResult(sizeMethod(t, true, []string{tc.doc}, tc.results...))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got error %v, want one containing %q", err, tc.want)
+			}
+		})
+	}
+}
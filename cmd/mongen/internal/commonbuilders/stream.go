@@ -0,0 +1,75 @@
+package commonbuilders
+
+import (
+	"go/ast"
+
+	"github.com/Bo0mer/gentools/pkg/astgen"
+)
+
+// StreamPackage is the import path of the package that measures streams in
+// the generated code.
+const StreamPackage = "github.com/Bo0mer/gentools/pkg/stream"
+
+// SequencesDoc documents the constructor of a middleware whose methods return
+// sequences, with the lines after the comment markers.
+var SequencesDoc = []string{
+	"",
+	" The operations of the methods that return an iter.Seq or an iter.Seq2",
+	" end when the first iteration over the returned sequence ends. A sequence",
+	" that is never ranged over is never recorded, and its operation remains",
+	" in flight, so range over every sequence that is returned.",
+}
+
+// StreamResult describes a result of a method that is a stream, measured
+// until it ends rather than until the method returns.
+type StreamResult struct {
+	// Name is the name of the result.
+	Name *ast.Ident
+	// Wrap is the name of the function of the stream package that wraps it.
+	Wrap string
+}
+
+// FindStreamResult returns the first result of the method that is a stream:
+// a receive-only channel, an iter.Seq, an iter.Seq2 or an io.ReadCloser. The
+// packages of the results are recognized by the aliases they are imported
+// with, as returned by importAlias.
+func FindStreamResult(method *astgen.MethodConfig, importAlias func(location string) (string, bool)) (StreamResult, bool) {
+	isQualified := func(expr ast.Expr, location, name string) bool {
+		sel, ok := expr.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != name {
+			return false
+		}
+		id, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return false
+		}
+		alias, ok := importAlias(location)
+		return ok && id.Name == alias
+	}
+
+	for _, result := range method.MethodResults {
+		var wrap string
+		switch t := result.Type.(type) {
+		case *ast.ChanType:
+			if t.Dir == ast.RECV {
+				wrap = "Chan"
+			}
+		case *ast.IndexExpr:
+			if isQualified(t.X, "iter", "Seq") {
+				wrap = "Seq"
+			}
+		case *ast.IndexListExpr:
+			if isQualified(t.X, "iter", "Seq2") {
+				wrap = "Seq2"
+			}
+		case *ast.SelectorExpr:
+			if isQualified(t, "io", "ReadCloser") {
+				wrap = "ReadCloser"
+			}
+		}
+		if wrap != "" {
+			return StreamResult{Name: ast.NewIdent(result.Names[0].String()), Wrap: wrap}, true
+		}
+	}
+	return StreamResult{}, false
+}
@@ -0,0 +1,647 @@
+package expvar
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
+	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/transformation"
+)
+
+// names used by the generated code
+const (
+	prefixParamName       = "prefix"
+	observeMethodName     = "observe"
+	observeSizeMethodName = "observeSize"
+
+	// keys of the duration and size maps
+	durationSumKey   = "sum"
+	durationCountKey = "count"
+)
+
+// formatBound returns the bucket bound with a leading zero, e.g. 0.005.
+func formatBound(bound string) string {
+	if v, err := strconv.ParseFloat(bound, 64); err == nil {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return bound
+}
+
+// bucketKey returns the key of the duration or size map that counts the
+// operations that took at most bound seconds, or whose results had at most
+// bound items.
+func bucketKey(bound string) string {
+	return "le_" + formatBound(bound)
+}
+
+// durationFieldName returns the name of the field that holds the duration
+// map of the method.
+func durationFieldName(methodName string) string {
+	return lowerFirst(methodName) + "Duration"
+}
+
+// sizeFieldName returns the name of the field that holds the result size map
+// of the method.
+func sizeFieldName(methodName string) string {
+	return lowerFirst(methodName) + "ResultSize"
+}
+
+// mutexName, publishFuncName and getFuncName return the names of the
+// package-level mutex and functions that publish and look up the maps of the
+// struct. They are named after the struct, so that the implementations of
+// several interfaces can be generated in the same package.
+func mutexName(structName string) string {
+	return lowerFirst(structName) + "Mu"
+}
+
+func publishFuncName(structName string) string {
+	return lowerFirst(structName) + "Publish"
+}
+
+func getFuncName(structName string) string {
+	return lowerFirst(structName) + "Get"
+}
+
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+type constructorBuilder struct {
+	expvarPackageName    string
+	interfacePackageName string
+	interfaceName        string
+	structName           string
+	constructorName      string
+	// toggles are the runtime controls of the methods, if enabled.
+	toggles *astgen.Toggles
+
+	methodNames []string
+	operations  []string
+	// sized reports whether the size of the results of the method is
+	// recorded.
+	sized []bool
+}
+
+func newConstructorBuilder(expvarPackageName, packageName, interfaceName, structName, constructorName string) *constructorBuilder {
+	return &constructorBuilder{
+		expvarPackageName:    expvarPackageName,
+		interfacePackageName: packageName,
+		interfaceName:        interfaceName,
+		structName:           structName,
+		constructorName:      constructorName,
+	}
+}
+
+// AddMethod makes the constructor publish the variables of the method.
+func (c *constructorBuilder) AddMethod(methodName, operation string, sized bool) {
+	c.methodNames = append(c.methodNames, methodName)
+	c.operations = append(c.operations, operation)
+	c.sized = append(c.sized, sized)
+}
+
+// Build builds the constructor. The maps are published once and reused by
+// wrappers created with the same prefix, even by the wrappers of other
+// interfaces. The generated publish and get functions look them up under a
+// package-level mutex, and panic if a variable of another type is already
+// published under the name of a map:
+//
+//	totalOps := monitoringServicePublish(prefix + "total_ops")
+//	failedOps := monitoringServicePublish(prefix + "failed_ops")
+//	opsDuration := monitoringServicePublish(prefix + "ops_duration_seconds")
+//	totalOps.Add("do_work", 0)
+//	failedOps.Add("do_work", 0)
+//	return &monitoringService{next: next, totalOps: totalOps, failedOps: failedOps, doWorkDuration: monitoringServiceGet(opsDuration, "do_work")}
+//
+// The result size maps are published like the duration maps, if the sizes of
+// the results of any method are recorded.
+func (c *constructorBuilder) Build() ast.Decl {
+	call := func(fun ast.Expr, args ...ast.Expr) *ast.CallExpr {
+		return &ast.CallExpr{Fun: fun, Args: args}
+	}
+	method := func(x, name string) ast.Expr {
+		return &ast.SelectorExpr{X: ast.NewIdent(x), Sel: ast.NewIdent(name)}
+	}
+	// publish builds the statement that publishes the map of the metric:
+	//   totalOps := monitoringServicePublish(prefix + "total_ops")
+	publish := func(metric commonbuilders.Metric) ast.Stmt {
+		return &ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent(metric.Param)},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{call(ast.NewIdent(publishFuncName(c.structName)), &ast.BinaryExpr{
+				X:  ast.NewIdent(prefixParamName),
+				Op: token.ADD,
+				Y:  commonbuilders.StringLit(metric.Name),
+			})},
+		}
+	}
+	// get builds the expression that returns the map of the operation within
+	// the map of the metric.
+	get := func(metric commonbuilders.Metric, operation string) ast.Expr {
+		return call(ast.NewIdent(getFuncName(c.structName)), ast.NewIdent(metric.Param), commonbuilders.StringLit(operation))
+	}
+
+	stmts := []ast.Stmt{
+		publish(commonbuilders.TotalOpsMetric),
+		publish(commonbuilders.FailedOpsMetric),
+	}
+	if len(c.methodNames) > 0 {
+		stmts = append(stmts, publish(commonbuilders.OpsDurationMetric))
+	}
+	for _, sized := range c.sized {
+		if sized {
+			stmts = append(stmts, publish(commonbuilders.ResultSizeMetric))
+			break
+		}
+	}
+
+	// Publish zero counts of all operations, so that they are listed before
+	// they are called or fail.
+	zero := &ast.BasicLit{Kind: token.INT, Value: "0"}
+	for _, operation := range c.operations {
+		stmts = append(stmts,
+			&ast.ExprStmt{X: call(method(commonbuilders.TotalOpsMetricName, "Add"), commonbuilders.StringLit(operation), zero)},
+			&ast.ExprStmt{X: call(method(commonbuilders.FailedOpsMetricName, "Add"), commonbuilders.StringLit(operation), zero)},
+		)
+	}
+
+	fieldInit := func(name string, value ast.Expr) ast.Expr {
+		return &ast.KeyValueExpr{Key: ast.NewIdent(name), Value: value}
+	}
+	elts := []ast.Expr{
+		fieldInit("next", ast.NewIdent("next")),
+		fieldInit(commonbuilders.TotalOpsMetricName, ast.NewIdent(commonbuilders.TotalOpsMetricName)),
+		fieldInit(commonbuilders.FailedOpsMetricName, ast.NewIdent(commonbuilders.FailedOpsMetricName)),
+	}
+	for i, methodName := range c.methodNames {
+		elts = append(elts, fieldInit(durationFieldName(methodName), get(commonbuilders.OpsDurationMetric, c.operations[i])))
+		if c.sized[i] {
+			elts = append(elts, fieldInit(sizeFieldName(methodName), get(commonbuilders.ResultSizeMetric, c.operations[i])))
+		}
+	}
+	params := []*ast.Field{
+		{
+			Names: []*ast.Ident{ast.NewIdent("next")},
+			Type:  astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
+		},
+		{
+			Names: []*ast.Ident{ast.NewIdent(prefixParamName)},
+			Type:  ast.NewIdent("string"),
+		},
+	}
+	if c.toggles != nil {
+		elts = append(elts, c.toggles.Init()...)
+		params = append(params, c.toggles.Param())
+	}
+	stmts = append(stmts, &ast.ReturnStmt{
+		Results: []ast.Expr{
+			&ast.UnaryExpr{
+				Op: token.AND,
+				X: &ast.CompositeLit{
+					Type: ast.NewIdent(c.structName),
+					Elts: elts,
+				},
+			},
+		},
+	})
+
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{
+					Text: fmt.Sprintf("// %s creates new monitoring middleware that publishes its variables with names starting with prefix.", c.constructorName),
+				},
+			},
+		},
+		Name: ast.NewIdent(c.constructorName),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: params,
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{
+					{
+						Type: astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
+					},
+				},
+			},
+		},
+		Body: &ast.BlockStmt{List: stmts},
+	}
+}
+
+// mutexDecl builds the package-level mutex that guards the lookup and
+// publishing of the maps of the struct.
+type mutexDecl struct {
+	syncPackageName string
+	structName      string
+}
+
+// Build builds a declaration in the form:
+//
+//	var monitoringServiceMu sync.Mutex
+func (d mutexDecl) Build() ast.Decl {
+	name := mutexName(d.structName)
+	return &ast.GenDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{Text: fmt.Sprintf("// %s guards the lookup and publishing of the maps of %s.", name, d.structName)},
+			},
+		},
+		Tok: token.VAR,
+		Specs: []ast.Spec{
+			&ast.ValueSpec{
+				Names: []*ast.Ident{ast.NewIdent(name)},
+				Type:  astgen.QualifiedName(d.syncPackageName, "Mutex"),
+			},
+		},
+	}
+}
+
+// lockStmts builds the statements that lock the mutex of the struct until
+// the function returns.
+func lockStmts(structName string) []ast.Stmt {
+	mutexMethod := func(name string) *ast.CallExpr {
+		return &ast.CallExpr{Fun: &ast.SelectorExpr{X: ast.NewIdent(mutexName(structName)), Sel: ast.NewIdent(name)}}
+	}
+	return []ast.Stmt{
+		&ast.ExprStmt{X: mutexMethod("Lock")},
+		&ast.DeferStmt{Call: mutexMethod("Unlock")},
+	}
+}
+
+// publishFunc builds the function that returns the map published under a
+// name.
+type publishFunc struct {
+	expvarPackageName string
+	structName        string
+}
+
+// Build builds a function in the form:
+//
+//	func monitoringServicePublish(name string) *expvar.Map {
+//		monitoringServiceMu.Lock()
+//		defer monitoringServiceMu.Unlock()
+//		v := expvar.Get(name)
+//		if v == nil {
+//			return expvar.NewMap(name)
+//		}
+//		if m, ok := v.(*expvar.Map); ok {
+//			return m
+//		}
+//		panic("expvar: " + name + " is published and is not an *expvar.Map")
+//	}
+func (f publishFunc) Build() ast.Decl {
+	mapType := &ast.StarExpr{X: astgen.QualifiedName(f.expvarPackageName, "Map")}
+	name, v, m, ok := ast.NewIdent("name"), ast.NewIdent("v"), ast.NewIdent("m"), ast.NewIdent("ok")
+	nilIdent := ast.NewIdent("nil")
+
+	stmts := append(lockStmts(f.structName),
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{v},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.CallExpr{Fun: astgen.QualifiedName(f.expvarPackageName, "Get"), Args: []ast.Expr{name}}},
+		},
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{X: v, Op: token.EQL, Y: nilIdent},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				&ast.ReturnStmt{Results: []ast.Expr{
+					&ast.CallExpr{Fun: astgen.QualifiedName(f.expvarPackageName, "NewMap"), Args: []ast.Expr{name}},
+				}},
+			}},
+		},
+		&ast.IfStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{m, ok},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.TypeAssertExpr{X: v, Type: mapType}},
+			},
+			Cond: ok,
+			Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{m}}}},
+		},
+		&ast.ExprStmt{X: &ast.CallExpr{
+			Fun: ast.NewIdent("panic"),
+			Args: []ast.Expr{&ast.BinaryExpr{
+				X:  &ast.BinaryExpr{X: commonbuilders.StringLit("expvar: "), Op: token.ADD, Y: name},
+				Op: token.ADD,
+				Y:  commonbuilders.StringLit(" is published and is not an *expvar.Map"),
+			}},
+		}},
+	)
+
+	funcName := publishFuncName(f.structName)
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{Text: fmt.Sprintf("// %s returns the map published under the name, publishing a new map if no variable is published under it yet.", funcName)},
+				{Text: "// It panics if a variable that is not a map is published under the name."},
+			},
+		},
+		Name: ast.NewIdent(funcName),
+		Type: &ast.FuncType{
+			Params:  &ast.FieldList{List: []*ast.Field{{Names: []*ast.Ident{name}, Type: ast.NewIdent("string")}}},
+			Results: &ast.FieldList{List: []*ast.Field{{Type: mapType}}},
+		},
+		Body: &ast.BlockStmt{List: stmts},
+	}
+}
+
+// getFunc builds the function that returns the map stored under a key of a
+// map.
+type getFunc struct {
+	expvarPackageName string
+	structName        string
+}
+
+// Build builds a function in the form:
+//
+//	func monitoringServiceGet(m *expvar.Map, key string) *expvar.Map {
+//		monitoringServiceMu.Lock()
+//		defer monitoringServiceMu.Unlock()
+//		if sub, ok := m.Get(key).(*expvar.Map); ok {
+//			return sub
+//		}
+//		sub := new(expvar.Map)
+//		m.Set(key, sub)
+//		return sub
+//	}
+func (f getFunc) Build() ast.Decl {
+	mapType := func() ast.Expr { return &ast.StarExpr{X: astgen.QualifiedName(f.expvarPackageName, "Map")} }
+	m, key, sub, ok := ast.NewIdent("m"), ast.NewIdent("key"), ast.NewIdent("sub"), ast.NewIdent("ok")
+	mapMethod := func(name string, args ...ast.Expr) *ast.CallExpr {
+		return &ast.CallExpr{Fun: &ast.SelectorExpr{X: m, Sel: ast.NewIdent(name)}, Args: args}
+	}
+
+	stmts := append(lockStmts(f.structName),
+		&ast.IfStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{sub, ok},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.TypeAssertExpr{X: mapMethod("Get", key), Type: mapType()}},
+			},
+			Cond: ok,
+			Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{sub}}}},
+		},
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{sub},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent("new"), Args: []ast.Expr{astgen.QualifiedName(f.expvarPackageName, "Map")}}},
+		},
+		&ast.ExprStmt{X: mapMethod("Set", key, sub)},
+		&ast.ReturnStmt{Results: []ast.Expr{sub}},
+	)
+
+	funcName := getFuncName(f.structName)
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{Text: fmt.Sprintf("// %s returns the map stored under the key of m, storing a new map under it if there is none.", funcName)},
+			},
+		},
+		Name: ast.NewIdent(funcName),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{List: []*ast.Field{
+				{Names: []*ast.Ident{m}, Type: mapType()},
+				{Names: []*ast.Ident{key}, Type: ast.NewIdent("string")},
+			}},
+			Results: &ast.FieldList{List: []*ast.Field{{Type: mapType()}}},
+		},
+		Body: &ast.BlockStmt{List: stmts},
+	}
+}
+
+// observeMethod builds the method that records the duration of an operation
+// in its duration map, or the size of its result in its size map.
+type observeMethod struct {
+	expvarPackageName string
+	structName        string
+	// sizes makes the method record sizes with the size buckets rather
+	// than durations with the duration buckets.
+	sizes bool
+}
+
+// Build builds a method in the form:
+//
+//	func (m *monitoringService) observe(duration *expvar.Map, seconds float64) {
+//		duration.AddFloat("sum", seconds)
+//		duration.Add("count", 1)
+//		switch {
+//		case seconds <= 0.005:
+//			duration.Add("le_0.005", 1)
+//			fallthrough
+//		...
+//		default:
+//			duration.Add("le_+Inf", 1)
+//		}
+//	}
+//
+// The buckets are cumulative: every bucket counts the operations that took at
+// most its bound, and the +Inf bucket counts all operations. Sizes are recorded the same way by the
+// observeSize method, as int64 values.
+func (o observeMethod) Build() ast.Decl {
+	name, doc := observeMethodName, "records the duration of an operation, in seconds, in its duration map."
+	hist := ast.NewIdent("duration")
+	value := ast.NewIdent("seconds")
+	valueType, sumMethod, buckets := "float64", "AddFloat", commonbuilders.DurationBuckets
+	if o.sizes {
+		name, doc = observeSizeMethodName, "records the number of items in the result of an operation in its size map."
+		hist, value = ast.NewIdent("sizes"), ast.NewIdent("size")
+		valueType, sumMethod, buckets = "int64", "Add", commonbuilders.SizeBuckets
+	}
+	one := &ast.BasicLit{Kind: token.INT, Value: "1"}
+	add := func(method, key string, arg ast.Expr) ast.Stmt {
+		return &ast.ExprStmt{
+			X: &ast.CallExpr{
+				Fun:  &ast.SelectorExpr{X: hist, Sel: ast.NewIdent(method)},
+				Args: []ast.Expr{commonbuilders.StringLit(key), arg},
+			},
+		}
+	}
+
+	var clauses []ast.Stmt
+	for _, bound := range buckets {
+		clauses = append(clauses, &ast.CaseClause{
+			List: []ast.Expr{&ast.BinaryExpr{X: value, Op: token.LEQ, Y: &ast.BasicLit{Kind: token.FLOAT, Value: formatBound(bound)}}},
+			Body: []ast.Stmt{add("Add", bucketKey(bound), one), &ast.BranchStmt{Tok: token.FALLTHROUGH}},
+		})
+	}
+	clauses = append(clauses, &ast.CaseClause{
+		Body: []ast.Stmt{add("Add", bucketKey("+Inf"), one)},
+	})
+
+	method := astgen.NewMethod(name, "m", o.structName)
+	method.SetType(&ast.FuncType{
+		Params: &ast.FieldList{
+			List: []*ast.Field{
+				{Names: []*ast.Ident{hist}, Type: &ast.StarExpr{X: astgen.QualifiedName(o.expvarPackageName, "Map")}},
+				{Names: []*ast.Ident{value}, Type: ast.NewIdent(valueType)},
+			},
+		},
+	})
+	method.AddStatements([]ast.Stmt{
+		add(sumMethod, durationSumKey, value),
+		add("Add", durationCountKey, one),
+		&ast.SwitchStmt{Body: &ast.BlockStmt{List: clauses}},
+	})
+
+	decl := method.Build().(*ast.FuncDecl)
+	decl.Doc = &ast.CommentGroup{
+		List: []*ast.Comment{{
+			Text: fmt.Sprintf("// %s %s", name, doc),
+		}},
+	}
+	return decl
+}
+
+// monitoringMethodBuilder is responsible for creating a method that implements
+// the original method from the interface and records its calls, errors and
+// duration in expvar maps.
+type monitoringMethodBuilder struct {
+	methodConfig     *astgen.MethodConfig
+	method           *astgen.Method
+	operation        string
+	timePackageAlias string
+
+	// size is the result whose size is recorded, if any.
+	size *commonbuilders.SizeResult
+	// panics makes the method record a panic of the call.
+	panics bool
+}
+
+func newMonitoringMethodBuilder(structName string, methodConfig *astgen.MethodConfig, operation, timePackageAlias string) *monitoringMethodBuilder {
+	return &monitoringMethodBuilder{
+		methodConfig:     methodConfig,
+		method:           astgen.NewMethod(methodConfig.MethodName, "m", structName),
+		operation:        operation,
+		timePackageAlias: timePackageAlias,
+	}
+}
+
+// SetSize makes the method record the size of the result.
+func (b *monitoringMethodBuilder) SetSize(size commonbuilders.SizeResult) {
+	b.size = &size
+}
+
+// RecordPanics makes the method record a panic of the call as a failed
+// operation, before raising it again.
+func (b *monitoringMethodBuilder) RecordPanics() {
+	b.panics = true
+}
+
+// Build builds the monitoring method:
+//
+//	_start := time.Now()
+//	result1, result2 := m.next.DoWork(arg1, arg2)
+//	m.totalOps.Add("do_work", 1)
+//	if result2 != nil {
+//		m.failedOps.Add("do_work", 1)
+//	}
+//	m.observe(m.doWorkDuration, time.Since(_start).Seconds())
+//	return result1, result2
+//
+// If the size of a result is recorded, it is observed after the failures:
+//
+//	if result2 == nil {
+//		m.observeSize(m.doWorkResultSize, int64(len(result1)))
+//	}
+//
+// If panics are recorded, a deferred call records them before the call:
+//
+//	defer func() {
+//		if _panic := recover(); _panic != nil {
+//			m.totalOps.Add("do_work", 1)
+//			m.failedOps.Add("do_work", 1)
+//			m.observe(m.doWorkDuration, time.Since(_start).Seconds())
+//			panic(_panic)
+//		}
+//	}()
+func (b *monitoringMethodBuilder) Build() ast.Decl {
+	b.method.SetType(&ast.FuncType{
+		Params: &ast.FieldList{
+			List: b.methodConfig.MethodParams,
+		},
+		Results: &ast.FieldList{
+			List: transformation.FieldsAsAnonymous(b.methodConfig.MethodResults),
+		},
+	})
+
+	field := func(name string) *ast.SelectorExpr {
+		return &ast.SelectorExpr{X: ast.NewIdent("m"), Sel: ast.NewIdent(name)}
+	}
+	count := func(counter string) ast.Stmt {
+		return &ast.ExprStmt{
+			X: &ast.CallExpr{
+				Fun:  &ast.SelectorExpr{X: field(counter), Sel: ast.NewIdent("Add")},
+				Args: []ast.Expr{commonbuilders.StringLit(b.operation), &ast.BasicLit{Kind: token.INT, Value: "1"}},
+			},
+		}
+	}
+
+	// m.observe(m.doWorkDuration, time.Since(_start).Seconds())
+	observe := func() ast.Stmt {
+		return &ast.ExprStmt{
+			X: &ast.CallExpr{
+				Fun: field(observeMethodName),
+				Args: []ast.Expr{
+					field(durationFieldName(b.methodConfig.MethodName)),
+					&ast.CallExpr{
+						Fun: &ast.SelectorExpr{
+							X: &ast.CallExpr{
+								Fun:  astgen.QualifiedName(b.timePackageAlias, "Since"),
+								Args: []ast.Expr{ast.NewIdent("_start")},
+							},
+							Sel: ast.NewIdent("Seconds"),
+						},
+					},
+				},
+			},
+		}
+	}
+
+	b.method.AddStatement(commonbuilders.RecordStartTime(b.timePackageAlias).Build())
+	if b.panics {
+		b.method.AddStatement(astgen.Recover(
+			count(commonbuilders.TotalOpsMetricName),
+			count(commonbuilders.FailedOpsMetricName),
+			observe(),
+		))
+	}
+
+	methodInvocation := commonbuilders.NewMethodInvocation(b.methodConfig)
+	methodInvocation.SetReceiver(field("next"))
+	b.method.AddStatement(methodInvocation.Build())
+
+	b.method.AddStatement(count(commonbuilders.TotalOpsMetricName))
+	if failure := commonbuilders.NewFailure(b.methodConfig); failure.Possible() {
+		b.method.AddStatement(&ast.IfStmt{
+			Cond: failure.Cond(),
+			Body: &ast.BlockStmt{List: []ast.Stmt{count(commonbuilders.FailedOpsMetricName)}},
+		})
+	}
+	if b.size != nil {
+		b.method.AddStatements(commonbuilders.RecordSize(b.methodConfig, *b.size, func(size ast.Expr) []ast.Stmt {
+			return []ast.Stmt{
+				&ast.ExprStmt{
+					X: &ast.CallExpr{
+						Fun: field(observeSizeMethodName),
+						Args: []ast.Expr{
+							field(sizeFieldName(b.methodConfig.MethodName)),
+							&ast.CallExpr{Fun: ast.NewIdent("int64"), Args: []ast.Expr{size}},
+						},
+					},
+				},
+			}
+		}))
+	}
+
+	b.method.AddStatement(observe())
+
+	b.method.AddStatements(commonbuilders.NewReturnResults(b.methodConfig).Stmts())
+
+	return b.method.Build()
+}
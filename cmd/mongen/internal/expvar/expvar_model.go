@@ -0,0 +1,112 @@
+package expvar
+
+import (
+	"go/ast"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
+	"github.com/Bo0mer/gentools/pkg/astgen"
+)
+
+type expvarModel struct {
+	cfg         commonbuilders.ModelConfig
+	fileBuilder *astgen.File
+	structName  string
+	strct       *astgen.Struct
+	constructor *constructorBuilder
+	toggles     *astgen.Toggles
+	// sized reports whether the size of the results of any method is
+	// recorded.
+	sized bool
+
+	expvarPackageAlias string
+	timePackageAlias   string
+}
+
+func NewExpvarModel(cfg commonbuilders.ModelConfig) *expvarModel {
+	file := astgen.NewFile(cfg.TargetPkg, cfg.TargetPath)
+	strct := astgen.NewStruct(cfg.StructName)
+	file.AppendDeclaration(strct)
+
+	m := &expvarModel{
+		cfg:         cfg,
+		fileBuilder: file,
+		structName:  cfg.StructName,
+		strct:       strct,
+	}
+	sourcePackageAlias := m.AddImport("", cfg.InterfacePath)
+	m.expvarPackageAlias = m.AddImport("", "expvar")
+	m.timePackageAlias = m.AddImport("", "time")
+	syncPackageAlias := m.AddImport("", "sync")
+
+	strct.AddField("next", sourcePackageAlias, cfg.InterfaceName)
+	strct.AddFieldWithType(commonbuilders.TotalOpsMetricName, m.mapType())
+	strct.AddFieldWithType(commonbuilders.FailedOpsMetricName, m.mapType())
+
+	m.constructor = newConstructorBuilder(m.expvarPackageAlias, sourcePackageAlias, cfg.InterfaceName, cfg.StructName, cfg.ConstructorName)
+	file.AppendDeclaration(m.constructor)
+	file.AppendDeclaration(mutexDecl{syncPackageName: syncPackageAlias, structName: cfg.StructName})
+	file.AppendDeclaration(publishFunc{expvarPackageName: m.expvarPackageAlias, structName: cfg.StructName})
+	file.AppendDeclaration(getFunc{expvarPackageName: m.expvarPackageAlias, structName: cfg.StructName})
+	file.AppendDeclaration(observeMethod{expvarPackageName: m.expvarPackageAlias, structName: cfg.StructName})
+
+	if cfg.Toggles {
+		m.toggles = astgen.NewToggles(m.AddImport("", astgen.TogglePackage), cfg.InterfaceName)
+		m.constructor.toggles = m.toggles
+	}
+
+	return m
+}
+
+func (m *expvarModel) mapType() ast.Expr {
+	return &ast.StarExpr{X: astgen.QualifiedName(m.expvarPackageAlias, "Map")}
+}
+
+func (m *expvarModel) AddImport(pkgName, location string) string {
+	return m.fileBuilder.AddImport(pkgName, location)
+}
+
+func (m *expvarModel) AddMethod(method *astgen.MethodConfig) error {
+	operation, err := m.cfg.OperationName(method.MethodName)
+	if err != nil {
+		return err
+	}
+	var size commonbuilders.SizeResult
+	var sized bool
+	if m.cfg.ResultSize {
+		size, sized, err = commonbuilders.FindSizeResult(method)
+		if err != nil {
+			return err
+		}
+	}
+
+	m.strct.AddFieldWithType(durationFieldName(method.MethodName), m.mapType())
+	if sized {
+		m.strct.AddFieldWithType(sizeFieldName(method.MethodName), m.mapType())
+		if !m.sized {
+			m.sized = true
+			m.fileBuilder.AppendDeclaration(observeMethod{expvarPackageName: m.expvarPackageAlias, structName: m.structName, sizes: true})
+		}
+	}
+	m.constructor.AddMethod(method.MethodName, operation, sized)
+
+	mmb := newMonitoringMethodBuilder(m.structName, method, operation, m.timePackageAlias)
+	if sized {
+		size.AddImports(m.AddImport)
+		mmb.SetSize(size)
+	}
+	if m.cfg.Panics {
+		mmb.RecordPanics()
+	}
+
+	var decl astgen.DeclarationBuilder = mmb
+	if m.toggles != nil {
+		m.toggles.AddMethod(m.strct, method.MethodName)
+		decl = m.toggles.Guard(method, mmb)
+	}
+	m.fileBuilder.AppendDeclaration(decl)
+	return nil
+}
+
+func (m *expvarModel) Build() *ast.File {
+	return m.fileBuilder.Build()
+}
@@ -4,92 +4,528 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
 	"github.com/Bo0mer/gentools/pkg/astgen"
 	"github.com/Bo0mer/gentools/pkg/transformation"
 )
 
+// options holds the optional features of the generated implementation.
+type options struct {
+	classifyErrors bool
+	inFlightOps    bool
+	recordOutcome  bool
+	labelsFunc     bool
+	streams        bool
+	resultSize     bool
+	panics         bool
+
+	// contextPackageAlias is the alias of the context package, if
+	// labelsFunc is set.
+	contextPackageAlias string
+}
+
+func newOptions(cfg commonbuilders.ModelConfig) options {
+	return options{
+		classifyErrors: cfg.ClassifyErrors,
+		inFlightOps:    cfg.InFlightOps,
+		recordOutcome:  cfg.RecordOutcome,
+		labelsFunc:     cfg.LabelsFunc,
+		streams:        cfg.Streams,
+		resultSize:     cfg.ResultSize,
+		panics:         cfg.Panics,
+	}
+}
+
+// operationFieldName returns the name of the struct field that holds the
+// metrics bound to the operation label of a method.
+func operationFieldName(methodName string) string {
+	return lowerFirst(methodName) + "Operation"
+}
+
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+// operationTypeName returns the name of the type that holds the metrics bound
+// to the operation label of a method.
+func operationTypeName(structName string) string {
+	return structName + "Operation"
+}
+
 type constructorBuilder struct {
 	metricsPackageName   string
 	interfacePackageName string
 	interfaceName        string
+	structName           string
+	constructorName      string
+	labels               *commonbuilders.Labels
+	buckets              *commonbuilders.BucketGroups
+	options              options
+	// toggles are the runtime controls of the methods, if enabled.
+	toggles      *astgen.Toggles
+	methodNames  []string
+	operations   []string
+	bucketGroups []string
+	// sequences reports whether a method returns a sequence that is
+	// measured until it is ranged over.
+	sequences bool
 }
 
-func newConstructorBuilder(metricsPackageName, packageName, interfaceName string) *constructorBuilder {
+func newConstructorBuilder(metricsPackageName, packageName, interfaceName, structName, constructorName string, labels *commonbuilders.Labels, buckets *commonbuilders.BucketGroups, opts options) *constructorBuilder {
 	return &constructorBuilder{
 		metricsPackageName:   metricsPackageName,
 		interfacePackageName: packageName,
 		interfaceName:        interfaceName,
+		structName:           structName,
+		constructorName:      constructorName,
+		labels:               labels,
+		buckets:              buckets,
+		options:              opts,
 	}
 }
 
+// AddMethod makes the constructor bind the metrics to the operation label of
+// the method. The duration of the operation is recorded by the histogram of
+// its bucket group, if any.
+func (c *constructorBuilder) AddMethod(methodName, operation, bucketGroup string) {
+	c.methodNames = append(c.methodNames, methodName)
+	c.operations = append(c.operations, operation)
+	c.bucketGroups = append(c.bucketGroups, bucketGroup)
+}
+
+// MeasureSequences makes the constructor document that the operations of the
+// methods that return sequences end only when the sequences are ranged over.
+func (c *constructorBuilder) MeasureSequences() {
+	c.sequences = true
+}
+
+// Build builds the constructor. The metrics are bound to the operation label
+// of every method once, by a helper in the body of the constructor:
+//
+//	m := &monitoringService{next: next}
+//	bind := func(operation string, duration metrics.Histogram) monitoringServiceOperation {
+//		return monitoringServiceOperation{totalOps: totalOps.With("operation", operation), ...}
+//	}
+//	m.doWorkOperation = bind("do_work", opsDuration)
+//	return m
 func (c *constructorBuilder) Build() ast.Decl {
-	funcBody := &ast.BlockStmt{
-		List: []ast.Stmt{
-			&ast.ReturnStmt{
-				Results: []ast.Expr{
-					// TODO(borshukov): Find a better way to do this.
-					ast.NewIdent(fmt.Sprintf("&monitoring%s{next, totalOps, failedOps, opsDuration}", c.interfaceName)),
+	fieldInit := func(name string) ast.Expr {
+		return &ast.KeyValueExpr{Key: ast.NewIdent(name), Value: ast.NewIdent(name)}
+	}
+	elts := []ast.Expr{
+		fieldInit("next"),
+	}
+	if c.labels.Guarded() {
+		elts = append(elts, commonbuilders.LabelGuardInit()...)
+	}
+	if c.options.classifyErrors {
+		elts = append(elts, commonbuilders.ErrorClassInit())
+	}
+	if c.options.labelsFunc {
+		elts = append(elts, fieldInit(commonbuilders.LabelsFuncName))
+	}
+	if c.toggles != nil {
+		elts = append(elts, c.toggles.Init()...)
+	}
+
+	middleware := ast.NewIdent("m")
+	stmts := []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{middleware},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{
+				&ast.UnaryExpr{
+					Op: token.AND,
+					X: &ast.CompositeLit{
+						Type: ast.NewIdent(c.structName),
+						Elts: elts,
+					},
 				},
 			},
 		},
 	}
+	if len(c.methodNames) > 0 {
+		bind := ast.NewIdent("bind")
+		stmts = append(stmts, &ast.AssignStmt{
+			Lhs: []ast.Expr{bind},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{c.bindOperation()},
+		})
+		for i, methodName := range c.methodNames {
+			stmts = append(stmts, &ast.AssignStmt{
+				Lhs: []ast.Expr{
+					&ast.SelectorExpr{
+						X:   middleware,
+						Sel: ast.NewIdent(operationFieldName(methodName)),
+					},
+				},
+				Tok: token.ASSIGN,
+				Rhs: []ast.Expr{
+					&ast.CallExpr{
+						Fun: bind,
+						Args: []ast.Expr{
+							commonbuilders.StringLit(c.operations[i]),
+							ast.NewIdent(commonbuilders.DurationMetric(c.bucketGroups[i]).Param),
+						},
+					},
+				},
+			})
+		}
+	}
+	stmts = append(stmts, &ast.ReturnStmt{Results: []ast.Expr{middleware}})
 
-	funcName := fmt.Sprintf("NewMonitoring%s", c.interfaceName)
+	funcName := c.constructorName
+	doc := []*ast.Comment{{Text: fmt.Sprintf("// %s creates new monitoring middleware.", funcName)}}
+	if c.sequences {
+		for _, line := range commonbuilders.SequencesDoc {
+			doc = append(doc, &ast.Comment{Text: "//" + line})
+		}
+	}
 	return &ast.FuncDecl{
-		Doc: &ast.CommentGroup{
-			List: []*ast.Comment{&ast.Comment{
-				Text: fmt.Sprintf("// %s creates new monitoring middleware.", funcName),
-			}},
-		},
+		Doc:  &ast.CommentGroup{List: doc},
 		Name: ast.NewIdent(funcName),
 		Type: &ast.FuncType{
 			Params: &ast.FieldList{
+				List: c.Params(),
+			},
+			Results: &ast.FieldList{
 				List: []*ast.Field{
 					&ast.Field{
-						Names: []*ast.Ident{ast.NewIdent("next")},
-						Type: &ast.SelectorExpr{
-							X:   ast.NewIdent(c.interfacePackageName),
-							Sel: ast.NewIdent(c.interfaceName),
-						},
+						Names: []*ast.Ident{ast.NewIdent("")},
+						Type:  astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
 					},
-					&ast.Field{
-						Names: []*ast.Ident{ast.NewIdent(commonbuilders.TotalOpsMetricName)},
+				},
+			},
+		},
+		Body: &ast.BlockStmt{List: stmts},
+	}
+}
+
+// bindOperation builds the helper that binds the metrics to the operation
+// label of a method. The duration of the operation is recorded by the
+// histogram passed to the helper, which depends on the bucket group of the
+// method.
+func (c *constructorBuilder) bindOperation() ast.Expr {
+	operationParam := ast.NewIdent("operation")
+	durationParam := ast.NewIdent("duration")
+	operation := []ast.Expr{
+		commonbuilders.StringLit(c.labels.Keys().Operation),
+		operationParam,
+	}
+	bindFrom := func(metric string, param *ast.Ident) ast.Expr {
+		return &ast.KeyValueExpr{
+			Key: ast.NewIdent(metric),
+			Value: &ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X:   param,
+					Sel: ast.NewIdent("With"),
+				},
+				Args: operation,
+			},
+		}
+	}
+	bind := func(metric string) ast.Expr {
+		return bindFrom(metric, ast.NewIdent(metric))
+	}
+	metrics := []ast.Expr{
+		bind(commonbuilders.TotalOpsMetricName),
+		bind(commonbuilders.FailedOpsMetricName),
+	}
+	if c.options.recordOutcome {
+		// The outcomes are bound as well, so that the calls only observe
+		// the durations:
+		//   opsDurationSuccess: duration.With("operation", operation, "outcome", "success"),
+		//   opsDurationError: duration.With("operation", operation, "outcome", "error"),
+		outcomeKey := commonbuilders.StringLit(c.labels.Keys().Outcome)
+		bindOutcome := func(metric string, outcome ast.Expr) ast.Expr {
+			bound := bindFrom(metric, durationParam).(*ast.KeyValueExpr)
+			call := bound.Value.(*ast.CallExpr)
+			call.Args = append(append([]ast.Expr(nil), call.Args...), outcomeKey, outcome)
+			return bound
+		}
+		metrics = append(metrics,
+			bindOutcome(opsDurationSuccessName, commonbuilders.SuccessOutcome()),
+			bindOutcome(opsDurationErrorName, commonbuilders.ErrorOutcome()))
+	} else {
+		metrics = append(metrics, bindFrom(commonbuilders.OpsDurationMetricName, durationParam))
+	}
+	if c.options.inFlightOps {
+		metrics = append(metrics, bind(commonbuilders.InFlightOpsMetricName))
+	}
+	if c.options.streams {
+		metrics = append(metrics, bind(commonbuilders.StreamSizeMetricName))
+	}
+	if c.options.resultSize {
+		metrics = append(metrics, bind(commonbuilders.ResultSizeMetricName))
+	}
+
+	operationType := ast.NewIdent(operationTypeName(c.structName))
+	return &ast.FuncLit{
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: []*ast.Field{
+					{Names: []*ast.Ident{operationParam}, Type: ast.NewIdent("string")},
+					{
+						Names: []*ast.Ident{durationParam},
 						Type: &ast.SelectorExpr{
 							X:   ast.NewIdent(c.metricsPackageName),
-							Sel: ast.NewIdent("Counter"),
+							Sel: ast.NewIdent("Histogram"),
 						},
 					},
-					&ast.Field{
-						Names: []*ast.Ident{ast.NewIdent(commonbuilders.FailedOpsMetricName)},
-						Type: &ast.SelectorExpr{
-							X:   ast.NewIdent(c.metricsPackageName),
-							Sel: ast.NewIdent("Counter"),
-						},
+				},
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{{Type: operationType}},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ReturnStmt{
+					Results: []ast.Expr{
+						&ast.CompositeLit{Type: operationType, Elts: metrics},
 					},
-					&ast.Field{
-						Names: []*ast.Ident{ast.NewIdent(commonbuilders.OpsDurationMetricName)},
-						Type: &ast.SelectorExpr{
-							X:   ast.NewIdent(c.metricsPackageName),
-							Sel: ast.NewIdent("Histogram"),
-						},
+				},
+			},
+		},
+	}
+}
+
+// Params returns the parameters of the constructor.
+func (c *constructorBuilder) Params() []*ast.Field {
+	params := []*ast.Field{
+		&ast.Field{
+			Names: []*ast.Ident{ast.NewIdent("next")},
+			Type:  astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
+		},
+		&ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(commonbuilders.TotalOpsMetricName)},
+			Type: &ast.SelectorExpr{
+				X:   ast.NewIdent(c.metricsPackageName),
+				Sel: ast.NewIdent("Counter"),
+			},
+		},
+		&ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(commonbuilders.FailedOpsMetricName)},
+			Type: &ast.SelectorExpr{
+				X:   ast.NewIdent(c.metricsPackageName),
+				Sel: ast.NewIdent("Counter"),
+			},
+		},
+		&ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(commonbuilders.OpsDurationMetricName)},
+			Type: &ast.SelectorExpr{
+				X:   ast.NewIdent(c.metricsPackageName),
+				Sel: ast.NewIdent("Histogram"),
+			},
+		},
+	}
+	for _, group := range c.buckets.Groups() {
+		params = append(params, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(commonbuilders.DurationMetric(group).Param)},
+			Type: &ast.SelectorExpr{
+				X:   ast.NewIdent(c.metricsPackageName),
+				Sel: ast.NewIdent("Histogram"),
+			},
+		})
+	}
+	if c.options.inFlightOps {
+		params = append(params, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(commonbuilders.InFlightOpsMetricName)},
+			Type: &ast.SelectorExpr{
+				X:   ast.NewIdent(c.metricsPackageName),
+				Sel: ast.NewIdent("Gauge"),
+			},
+		})
+	}
+	if c.options.streams {
+		params = append(params, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(commonbuilders.StreamSizeMetricName)},
+			Type: &ast.SelectorExpr{
+				X:   ast.NewIdent(c.metricsPackageName),
+				Sel: ast.NewIdent("Histogram"),
+			},
+		})
+	}
+	if c.options.resultSize {
+		params = append(params, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(commonbuilders.ResultSizeMetricName)},
+			Type: &ast.SelectorExpr{
+				X:   ast.NewIdent(c.metricsPackageName),
+				Sel: ast.NewIdent("Histogram"),
+			},
+		})
+	}
+	if c.labels.Guarded() {
+		params = append(params, commonbuilders.LabelGuardParam())
+	}
+	if c.options.classifyErrors {
+		params = append(params, commonbuilders.ErrorClassParam())
+	}
+	if c.options.labelsFunc {
+		params = append(params, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(commonbuilders.LabelsFuncName)},
+			Type:  labelsFuncType(c.options.contextPackageAlias),
+		})
+	}
+	if c.toggles != nil {
+		params = append(params, c.toggles.Param())
+	}
+	return params
+}
+
+// metricsBuilder builds a function that creates the metrics expected by the
+// constructor as go-kit Prometheus metrics.
+type metricsBuilder struct {
+	kitPrometheusPackageName string
+	prometheusPackageName    string
+	typeName                 string
+	funcName                 string
+	labels                   *commonbuilders.Labels
+	buckets                  *commonbuilders.BucketGroups
+	options                  options
+}
+
+func newMetricsBuilder(kitPrometheusPackageName, prometheusPackageName, typeName, funcName string, labels *commonbuilders.Labels, buckets *commonbuilders.BucketGroups, opts options) *metricsBuilder {
+	return &metricsBuilder{
+		kitPrometheusPackageName: kitPrometheusPackageName,
+		prometheusPackageName:    prometheusPackageName,
+		typeName:                 typeName,
+		funcName:                 funcName,
+		labels:                   labels,
+		buckets:                  buckets,
+		options:                  opts,
+	}
+}
+
+// contextLabelsParamName is the name of the parameter that holds the names of
+// the labels returned by labelsFunc, if it is accepted.
+const contextLabelsParamName = "contextLabels"
+
+// Build builds a function in the form:
+//
+//	func NewMonitoringServiceMetrics(namespace string) *MonitoringServiceMetrics {
+//		return &MonitoringServiceMetrics{
+//			TotalOps: prometheus.NewCounterFrom(stdprometheus.CounterOpts{...}, []string{"operation"}),
+//			...
+//		}
+//	}
+//
+// If labelsFunc is accepted, the function accepts the names of its labels as
+// well, and the metrics are declared with them:
+//
+//	func NewMonitoringServiceMetrics(namespace string, contextLabels ...string) *MonitoringServiceMetrics {
+//		return &MonitoringServiceMetrics{
+//			TotalOps: prometheus.NewCounterFrom(stdprometheus.CounterOpts{...}, append([]string{"operation"}, contextLabels...)),
+//			...
+//		}
+//	}
+func (b *metricsBuilder) Build() ast.Decl {
+	newMetric := func(metric commonbuilders.Metric, kind string, extraOpts ...ast.Expr) ast.Expr {
+		opts := []ast.Expr{
+			&ast.KeyValueExpr{Key: ast.NewIdent("Namespace"), Value: ast.NewIdent("namespace")},
+			&ast.KeyValueExpr{Key: ast.NewIdent("Name"), Value: commonbuilders.StringLit(metric.Name)},
+			&ast.KeyValueExpr{Key: ast.NewIdent("Help"), Value: commonbuilders.StringLit(metric.Help)},
+		}
+		opts = append(opts, extraOpts...)
+		var labelNames ast.Expr = commonbuilders.StringSliceLit(metric.LabelNames(b.labels, b.options.classifyErrors, b.options.recordOutcome))
+		if b.options.labelsFunc {
+			// append([]string{"operation"}, contextLabels...)
+			labelNames = &ast.CallExpr{
+				Fun:      ast.NewIdent("append"),
+				Args:     []ast.Expr{labelNames, ast.NewIdent(contextLabelsParamName)},
+				Ellipsis: token.Pos(1),
+			}
+		}
+
+		return &ast.KeyValueExpr{
+			Key: ast.NewIdent(metric.Field()),
+			Value: &ast.CallExpr{
+				Fun: astgen.QualifiedName(b.kitPrometheusPackageName, "New"+kind+"From"),
+				Args: []ast.Expr{
+					&ast.CompositeLit{
+						Type: astgen.QualifiedName(b.prometheusPackageName, kind+"Opts"),
+						Elts: opts,
 					},
+					labelNames,
 				},
 			},
+		}
+	}
+
+	bucketsOpt := func(bounds []string) ast.Expr {
+		var buckets []ast.Expr
+		for _, bucket := range bounds {
+			buckets = append(buckets, &ast.BasicLit{Kind: token.FLOAT, Value: bucket})
+		}
+		return &ast.KeyValueExpr{
+			Key:   ast.NewIdent("Buckets"),
+			Value: &ast.CompositeLit{Type: &ast.ArrayType{Elt: ast.NewIdent("float64")}, Elts: buckets},
+		}
+	}
+	elts := []ast.Expr{
+		newMetric(commonbuilders.TotalOpsMetric, "Counter"),
+		newMetric(commonbuilders.FailedOpsMetric, "Counter"),
+		newMetric(commonbuilders.OpsDurationMetric, "Histogram", bucketsOpt(commonbuilders.DurationBuckets)),
+	}
+	for _, group := range b.buckets.Groups() {
+		elts = append(elts, newMetric(commonbuilders.DurationMetric(group), "Histogram", bucketsOpt(b.buckets.Bounds(group))))
+	}
+	if b.options.inFlightOps {
+		elts = append(elts, newMetric(commonbuilders.InFlightOpsMetric, "Gauge"))
+	}
+	if b.options.streams {
+		elts = append(elts, newMetric(commonbuilders.StreamSizeMetric, "Histogram", bucketsOpt(commonbuilders.SizeBuckets)))
+	}
+	if b.options.resultSize {
+		elts = append(elts, newMetric(commonbuilders.ResultSizeMetric, "Histogram", bucketsOpt(commonbuilders.SizeBuckets)))
+	}
+
+	funcName := b.funcName
+	doc := []*ast.Comment{
+		{Text: fmt.Sprintf("// %s creates Prometheus metrics with the specified namespace and registers", funcName)},
+		{Text: "// them with the default registerer."},
+	}
+	params := []*ast.Field{{
+		Names: []*ast.Ident{ast.NewIdent("namespace")},
+		Type:  ast.NewIdent("string"),
+	}}
+	if b.options.labelsFunc {
+		doc = append(doc,
+			&ast.Comment{Text: "//"},
+			&ast.Comment{Text: "// The metrics have the labels named by contextLabels as well, which must be the"},
+			&ast.Comment{Text: "// names of the label pairs returned by labelsFunc."})
+		params = append(params, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(contextLabelsParamName)},
+			Type:  &ast.Ellipsis{Elt: ast.NewIdent("string")},
+		})
+	}
+	return &ast.FuncDecl{
+		Doc:  &ast.CommentGroup{List: doc},
+		Name: ast.NewIdent(funcName),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: params,
+			},
 			Results: &ast.FieldList{
-				List: []*ast.Field{
-					&ast.Field{
-						Names: []*ast.Ident{ast.NewIdent("")},
-						Type: &ast.SelectorExpr{
-							X:   ast.NewIdent(c.interfacePackageName),
-							Sel: ast.NewIdent(c.interfaceName),
+				List: []*ast.Field{{Type: &ast.StarExpr{X: ast.NewIdent(b.typeName)}}},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ReturnStmt{
+					Results: []ast.Expr{
+						&ast.UnaryExpr{
+							Op: token.AND,
+							X:  &ast.CompositeLit{Type: ast.NewIdent(b.typeName), Elts: elts},
 						},
 					},
 				},
 			},
 		},
-		Body: funcBody,
 	}
 }
 
@@ -103,16 +539,38 @@ type monitoringMethodBuilder struct {
 	totalOps    *ast.SelectorExpr // selector for the struct member
 	failedOps   *ast.SelectorExpr // selector for the struct member
 	opsDuration *ast.SelectorExpr // selector for the struct member
+	inFlightOps *ast.SelectorExpr // selector for the struct member
+	streamSize  *ast.SelectorExpr // selector for the struct member
+	resultSize  *ast.SelectorExpr // selector for the struct member
+
+	// opsDurationSuccess and opsDurationError select the durations bound
+	// to the outcomes, if the outcome is recorded.
+	opsDurationSuccess *ast.SelectorExpr
+	opsDurationError   *ast.SelectorExpr
+
+	// stream is the result that is measured until it ends, if any, and
+	// streamPackageAlias is the alias of the package that wraps it.
+	stream             *commonbuilders.StreamResult
+	streamPackageAlias string
+
+	// size is the result whose size is recorded, if any.
+	size *commonbuilders.SizeResult
 
 	timePackageAlias string
+	labels           *commonbuilders.Labels
+	options          options
 }
 
-func newMonitoringMethodBuilder(structName string, methodConfig *astgen.MethodConfig) *monitoringMethodBuilder {
+func newMonitoringMethodBuilder(structName string, methodConfig *astgen.MethodConfig, labels *commonbuilders.Labels, opts options) *monitoringMethodBuilder {
 	method := astgen.NewMethod(methodConfig.MethodName, "m", structName)
 
+	// m.methodOperation.metric
 	selexpr := func(fieldName string) *ast.SelectorExpr {
 		return &ast.SelectorExpr{
-			X:   ast.NewIdent("m"),
+			X: &ast.SelectorExpr{
+				X:   ast.NewIdent("m"),
+				Sel: ast.NewIdent(operationFieldName(methodConfig.MethodName)),
+			},
 			Sel: ast.NewIdent(fieldName),
 		}
 	}
@@ -123,6 +581,14 @@ func newMonitoringMethodBuilder(structName string, methodConfig *astgen.MethodCo
 		totalOps:     selexpr(commonbuilders.TotalOpsMetricName),
 		failedOps:    selexpr(commonbuilders.FailedOpsMetricName),
 		opsDuration:  selexpr(commonbuilders.OpsDurationMetricName),
+		inFlightOps:  selexpr(commonbuilders.InFlightOpsMetricName),
+		streamSize:   selexpr(commonbuilders.StreamSizeMetricName),
+		resultSize:   selexpr(commonbuilders.ResultSizeMetricName),
+		labels:       labels,
+		options:      opts,
+
+		opsDurationSuccess: selexpr(opsDurationSuccessName),
+		opsDurationError:   selexpr(opsDurationErrorName),
 	}
 }
 
@@ -130,6 +596,18 @@ func (b *monitoringMethodBuilder) SetTimePackageAlias(alias string) {
 	b.timePackageAlias = alias
 }
 
+// SetStream makes the method measure the operation until the stream result
+// ends.
+func (b *monitoringMethodBuilder) SetStream(stream commonbuilders.StreamResult, streamPackageAlias string) {
+	b.stream = &stream
+	b.streamPackageAlias = streamPackageAlias
+}
+
+// SetSize makes the method record the size of the result.
+func (b *monitoringMethodBuilder) SetSize(size commonbuilders.SizeResult) {
+	b.size = &size
+}
+
 func (b *monitoringMethodBuilder) Build() ast.Decl {
 	b.method.SetType(&ast.FuncType{
 		Params: &ast.FieldList{
@@ -140,15 +618,82 @@ func (b *monitoringMethodBuilder) Build() ast.Decl {
 		},
 	})
 
+	// Compute the values of the extra labels once, if there are any
+	//   _labels := []string{"label", m.labelValue("label", arg1.Label)}
+	var labelsVar *ast.Ident
+	if !b.labels.Empty() {
+		labelsVar = ast.NewIdent("_labels")
+		b.method.AddStatement(b.labelValues(labelsVar))
+	}
+
+	// Add the labels from the context of the call
+	//   var _labels []string
+	//   _labels = m.appendContextLabels(_labels, ctx)
+	// The methods that take no context pass context.Background(), as all
+	// methods share the metrics and must record the same labels.
+	if b.options.labelsFunc {
+		var ctx ast.Expr = &ast.CallExpr{Fun: astgen.QualifiedName(b.options.contextPackageAlias, "Background")}
+		if ctxParam := commonbuilders.ContextParam(b.methodConfig, b.options.contextPackageAlias); ctxParam != nil {
+			ctx = ctxParam
+		}
+		if labelsVar == nil {
+			labelsVar = ast.NewIdent("_labels")
+			b.method.AddStatement(&ast.DeclStmt{
+				Decl: &ast.GenDecl{
+					Tok: token.VAR,
+					Specs: []ast.Spec{
+						&ast.ValueSpec{
+							Names: []*ast.Ident{labelsVar},
+							Type:  &ast.ArrayType{Elt: ast.NewIdent("string")},
+						},
+					},
+				},
+			})
+		}
+		b.method.AddStatement(appendContextLabels(labelsVar, ctx))
+	}
+
 	// Add increase total operations statement
-	//   m.totalOps.Add(1)
-	increaseTotalOps := &CounterAddAction{counterField: b.totalOps, operationName: b.methodConfig.MethodName}
+	//   m.methodOperation.totalOps.Add(1)
+	increaseTotalOps := &CounterAddAction{counterField: b.totalOps, labelsVar: labelsVar}
 	b.method.AddStatement(increaseTotalOps.Build())
 
+	// Track the operation in progress, even if the method panics
+	//   m.methodOperation.inFlightOps.Add(1)
+	//   defer m.methodOperation.inFlightOps.Add(-1)
+	// An operation that returned a stream is in progress until the stream
+	// ends, so it is tracked by the stream instead
+	//   _streaming := false
+	//   defer func() {
+	//     if !_streaming {
+	//       m.methodOperation.inFlightOps.Add(-1)
+	//     }
+	//   }()
+	if b.options.inFlightOps {
+		b.method.AddStatement(&ast.ExprStmt{X: gaugeAdd(b.inFlightOps, labelsVar, "1")})
+		if b.stream == nil {
+			b.method.AddStatement(&ast.DeferStmt{Call: gaugeAdd(b.inFlightOps, labelsVar, "-1")})
+		} else {
+			b.method.AddStatements(b.deferInFlightEnd(labelsVar))
+		}
+	}
+
 	// Add statement to capture current time
 	//   start := time.Now()
 	b.method.AddStatement(commonbuilders.RecordStartTime(b.timePackageAlias).Build())
 
+	// Record a panic of the call as a failure, and raise it again
+	//   defer func() {
+	//     if _panic := recover(); _panic != nil {
+	//       m.methodOperation.opsDuration.Observe(time.Since(_start).Seconds())
+	//       m.methodOperation.failedOps.With("error_class", "panic").Add(1)
+	//       panic(_panic)
+	//     }
+	//   }()
+	if b.options.panics {
+		b.method.AddStatement(astgen.Recover(b.recordPanic(labelsVar)...))
+	}
+
 	// Add method invocation:
 	//   result1, result2 := m.next.Method(arg1, arg2)
 	methodInvocation := commonbuilders.NewMethodInvocation(b.methodConfig)
@@ -158,39 +703,442 @@ func (b *monitoringMethodBuilder) Build() ast.Decl {
 	})
 	b.method.AddStatement(methodInvocation.Build())
 
-	// Record operation duration
-	//   m.opsDuration.Observe(time.Since(start))
-	b.method.AddStatement(NewRecordOpDuraton(b.timePackageAlias, b.opsDuration, b.methodConfig.MethodName).Build())
+	// Record the size of the result, if the call did not fail
+	//   if err == nil {
+	//     m.methodOperation.resultSize.Observe(float64(len(result1)))
+	//   }
+	if b.size != nil {
+		b.method.AddStatements(commonbuilders.RecordSize(b.methodConfig, *b.size, func(size ast.Expr) []ast.Stmt {
+			return []ast.Stmt{
+				&ast.ExprStmt{
+					X: &ast.CallExpr{
+						Fun: &ast.SelectorExpr{X: callWith(b.resultSize, labelsVar), Sel: ast.NewIdent("Observe")},
+						Args: []ast.Expr{
+							&ast.CallExpr{Fun: ast.NewIdent("float64"), Args: []ast.Expr{size}},
+						},
+					},
+				},
+			}
+		}))
+	}
 
-	// Add increase failed operations statement
-	//   if err != nil { m.failedOps.Add(1) }
-	increaseFailedOps := NewIncreaseFailedOps(b.methodConfig, b.failedOps)
-	b.method.AddStatement(increaseFailedOps.Build())
+	// Record the operation once its stream ends, if it returned one
+	if b.stream != nil {
+		b.method.AddStatement(b.wrapStream(labelsVar))
+	}
+
+	b.method.AddStatements(b.recordResults(b.methodConfig, labelsVar))
 
 	// Add return statement
 	//   return result1, result2
 	returnResults := commonbuilders.NewReturnResults(b.methodConfig)
-	b.method.AddStatement(returnResults.Build())
+	b.method.AddStatements(returnResults.Stmts())
 
 	return b.method.Build()
 }
 
-type CounterAddAction struct {
-	counterField  *ast.SelectorExpr
-	operationName string
+// recordResults builds the statements that record the duration and the
+// failure of the operation, given the results of the method.
+func (b *monitoringMethodBuilder) recordResults(method *astgen.MethodConfig, labelsVar *ast.Ident) []ast.Stmt {
+	var stmts []ast.Stmt
+
+	// Record operation duration
+	//   m.methodOperation.opsDuration.Observe(time.Since(start))
+	//   or, if the outcome is recorded, with the duration bound to it
+	//   _opsDuration := m.methodOperation.opsDurationSuccess
+	//   if err != nil { _opsDuration = m.methodOperation.opsDurationError }
+	//   _opsDuration.Observe(time.Since(start))
+	var opsDuration ast.Expr = b.opsDuration
+	if b.options.recordOutcome {
+		var outcomeStmts []ast.Stmt
+		outcomeStmts, opsDuration = b.durationByOutcome(method)
+		stmts = append(stmts, outcomeStmts...)
+	}
+	recordOpDuration := NewRecordOpDuraton(b.timePackageAlias, opsDuration)
+	recordOpDuration.labelsVar = labelsVar
+	stmts = append(stmts, recordOpDuration.Build())
+
+	// Add increase failed operations statement
+	//   if err != nil { m.methodOperation.failedOps.Add(1) }
+	increaseFailedOps := NewIncreaseFailedOps(method, b.failedOps)
+	increaseFailedOps.labelsVar = labelsVar
+	increaseFailedOps.classifyErrors = b.options.classifyErrors
+	increaseFailedOps.errorClassLabel = b.labels.Keys().ErrorClass
+	return append(stmts, increaseFailedOps.Build())
 }
 
-func (c *CounterAddAction) Build() ast.Stmt {
-	callWithExpr := &ast.CallExpr{
+// recordPanic builds the statements that record the duration and the failure
+// of the operation, if the call panicked.
+func (b *monitoringMethodBuilder) recordPanic(labelsVar *ast.Ident) []ast.Stmt {
+	var opsDuration ast.Expr = b.opsDuration
+	if b.options.recordOutcome {
+		opsDuration = b.opsDurationError
+	}
+	recordOpDuration := NewRecordOpDuraton(b.timePackageAlias, opsDuration)
+	recordOpDuration.labelsVar = labelsVar
+
+	increaseFailedOps := NewIncreaseFailedOps(b.methodConfig, b.failedOps)
+	increaseFailedOps.labelsVar = labelsVar
+	increaseFailedOps.classifyErrors = b.options.classifyErrors
+	increaseFailedOps.errorClassLabel = b.labels.Keys().ErrorClass
+	return append([]ast.Stmt{recordOpDuration.Build()}, increaseFailedOps.record(commonbuilders.PanicFailure)...)
+}
+
+// opsDurationSuccessName and opsDurationErrorName are the fields of the
+// durations bound to the outcomes.
+const (
+	opsDurationSuccessName = commonbuilders.OpsDurationMetricName + "Success"
+	opsDurationErrorName   = commonbuilders.OpsDurationMetricName + "Error"
+)
+
+// durationByOutcome builds the statements that select the duration bound to
+// the outcome of the call, and the expression that refers to it. If the call
+// can fail, the statements are in the form:
+//
+//	_opsDuration := m.methodOperation.opsDurationSuccess
+//	if err != nil {
+//		_opsDuration = m.methodOperation.opsDurationError
+//	}
+//
+// Otherwise there are no statements and the duration is the one of success.
+func (b *monitoringMethodBuilder) durationByOutcome(method *astgen.MethodConfig) ([]ast.Stmt, ast.Expr) {
+	failure := commonbuilders.NewFailure(method)
+	if !failure.Possible() {
+		return nil, b.opsDurationSuccess
+	}
+
+	opsDuration := ast.NewIdent("_opsDuration")
+	return []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{opsDuration},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{b.opsDurationSuccess},
+		},
+		&ast.IfStmt{
+			Cond: failure.Cond(),
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{
+					&ast.AssignStmt{
+						Lhs: []ast.Expr{opsDuration},
+						Tok: token.ASSIGN,
+						Rhs: []ast.Expr{b.opsDurationError},
+					},
+				},
+			},
+		},
+	}, opsDuration
+}
+
+// streamingVarName is the name of the variable that reports whether the
+// operation continues in a stream, which ends it.
+const streamingVarName = "_streaming"
+
+// deferInFlightEnd builds the statements that end the operation in progress
+// when the method returns, unless it returned a stream.
+func (b *monitoringMethodBuilder) deferInFlightEnd(labelsVar *ast.Ident) []ast.Stmt {
+	return []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent(streamingVarName)},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{ast.NewIdent("false")},
+		},
+		&ast.DeferStmt{
+			Call: &ast.CallExpr{
+				Fun: &ast.FuncLit{
+					Type: &ast.FuncType{Params: &ast.FieldList{}},
+					Body: &ast.BlockStmt{
+						List: []ast.Stmt{
+							&ast.IfStmt{
+								Cond: &ast.UnaryExpr{Op: token.NOT, X: ast.NewIdent(streamingVarName)},
+								Body: &ast.BlockStmt{
+									List: []ast.Stmt{&ast.ExprStmt{X: gaugeAdd(b.inFlightOps, labelsVar, "-1")}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// wrapStream builds a statement that wraps the stream result, if the method
+// returned one, and records the operation once the stream ends:
+//
+//	if result1 != nil && result2 == nil {
+//		result1 = stream.Chan(result1, func(_size int64, _err error) {
+//			m.methodOperation.opsDuration.Observe(time.Since(_start).Seconds())
+//			if _err != nil { m.methodOperation.failedOps.Add(1) }
+//			m.methodOperation.streamSize.Observe(float64(_size))
+//		})
+//		return result1, result2
+//	}
+//
+// The error that ended the stream is recorded in place of the error result.
+// If the operations in progress are tracked, the stream ends the operation:
+//
+//	_streaming = true
+//	result1 = stream.Chan(result1, func(_size int64, _err error) {
+//		...
+//		m.methodOperation.inFlightOps.Add(-1)
+//	})
+func (b *monitoringMethodBuilder) wrapStream(labelsVar *ast.Ident) ast.Stmt {
+	size, err := ast.NewIdent("_size"), ast.NewIdent("_err")
+	streamEnd := &astgen.MethodConfig{
+		MethodResults: []*ast.Field{{Names: []*ast.Ident{err}, Type: ast.NewIdent("error")}},
+	}
+
+	done := b.recordResults(streamEnd, labelsVar)
+	done = append(done, &ast.ExprStmt{
+		X: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: callWith(b.streamSize, labelsVar), Sel: ast.NewIdent("Observe")},
+			Args: []ast.Expr{
+				&ast.CallExpr{Fun: ast.NewIdent("float64"), Args: []ast.Expr{size}},
+			},
+		},
+	})
+	var body []ast.Stmt
+	if b.options.inFlightOps {
+		done = append(done, &ast.ExprStmt{X: gaugeAdd(b.inFlightOps, labelsVar, "-1")})
+		body = append(body, &ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent(streamingVarName)},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{ast.NewIdent("true")},
+		})
+	}
+
+	var cond ast.Expr = &ast.BinaryExpr{X: b.stream.Name, Op: token.NEQ, Y: ast.NewIdent("nil")}
+	if errorResult := commonbuilders.ErrorResult(b.methodConfig); errorResult != nil {
+		cond = &ast.BinaryExpr{
+			X:  cond,
+			Op: token.LAND,
+			Y:  &ast.BinaryExpr{X: errorResult, Op: token.EQL, Y: ast.NewIdent("nil")},
+		}
+	}
+
+	return &ast.IfStmt{
+		Cond: cond,
+		Body: &ast.BlockStmt{
+			List: append(body,
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{b.stream.Name},
+					Tok: token.ASSIGN,
+					Rhs: []ast.Expr{
+						&ast.CallExpr{
+							Fun: astgen.QualifiedName(b.streamPackageAlias, b.stream.Wrap),
+							Args: []ast.Expr{
+								b.stream.Name,
+								&ast.FuncLit{
+									Type: &ast.FuncType{
+										Params: &ast.FieldList{
+											List: []*ast.Field{
+												{Names: []*ast.Ident{size}, Type: ast.NewIdent("int64")},
+												{Names: []*ast.Ident{err}, Type: ast.NewIdent("error")},
+											},
+										},
+									},
+									Body: &ast.BlockStmt{List: done},
+								},
+							},
+						},
+					},
+				},
+				commonbuilders.NewReturnResults(b.methodConfig).Build(),
+			),
+		},
+	}
+}
+
+func (b *monitoringMethodBuilder) labelValues(labelsVar *ast.Ident) ast.Stmt {
+	var elts []ast.Expr
+	values := b.labels.Values("m", b.methodConfig.MethodName)
+	for i, name := range b.labels.Names() {
+		elts = append(elts, commonbuilders.StringLit(name), values[i])
+	}
+	return &ast.AssignStmt{
+		Lhs: []ast.Expr{labelsVar},
+		Tok: token.DEFINE,
+		Rhs: []ast.Expr{
+			&ast.CompositeLit{
+				Type: &ast.ArrayType{Elt: ast.NewIdent("string")},
+				Elts: elts,
+			},
+		},
+	}
+}
+
+// labelsFuncType builds the type of the labelsFunc field:
+// func(context.Context) []string
+func labelsFuncType(contextPackageAlias string) *ast.FuncType {
+	return &ast.FuncType{
+		Params: &ast.FieldList{
+			List: []*ast.Field{{Type: astgen.QualifiedName(contextPackageAlias, "Context")}},
+		},
+		Results: &ast.FieldList{
+			List: []*ast.Field{{Type: &ast.ArrayType{Elt: ast.NewIdent("string")}}},
+		},
+	}
+}
+
+// appendContextLabels builds a statement that appends the labels from the
+// context ctx to labelsVar:
+//
+//	_labels = m.appendContextLabels(_labels, ctx)
+func appendContextLabels(labelsVar *ast.Ident, ctx ast.Expr) ast.Stmt {
+	return &ast.AssignStmt{
+		Lhs: []ast.Expr{labelsVar},
+		Tok: token.ASSIGN,
+		Rhs: []ast.Expr{
+			&ast.CallExpr{
+				Fun:  &ast.SelectorExpr{X: ast.NewIdent("m"), Sel: ast.NewIdent(appendContextLabelsMethodName)},
+				Args: []ast.Expr{labelsVar, ctx},
+			},
+		},
+	}
+}
+
+// appendContextLabelsMethodName is the name of the method that appends the
+// labels returned by labelsFunc.
+const appendContextLabelsMethodName = "appendContextLabels"
+
+// appendContextLabelsMethod builds the method that appends the labels
+// returned by labelsFunc, with their values passed through the label guard.
+type appendContextLabelsMethod struct {
+	structName          string
+	contextPackageAlias string
+}
+
+// Build builds a method in the form:
+//
+//	func (m *monitoringService) appendContextLabels(labels []string, ctx context.Context) []string {
+//		if m.labelsFunc == nil {
+//			return labels
+//		}
+//		pairs := m.labelsFunc(ctx)
+//		for i := 1; i < len(pairs); i += 2 {
+//			labels = append(labels, pairs[i-1], m.labelValue(pairs[i-1], pairs[i]))
+//		}
+//		if len(pairs)%2 == 1 {
+//			labels = append(labels, pairs[len(pairs)-1])
+//		}
+//		return labels
+//	}
+//
+// A label without a value is appended as it is, so that go-kit records it
+// with its value for missing label values, as it does without the guard.
+func (g appendContextLabelsMethod) Build() ast.Decl {
+	labels := ast.NewIdent("labels")
+	pairs := ast.NewIdent("pairs")
+	i := ast.NewIdent("i")
+	one := &ast.BasicLit{Kind: token.INT, Value: "1"}
+	call := func(fun ast.Expr, args ...ast.Expr) *ast.CallExpr {
+		return &ast.CallExpr{Fun: fun, Args: args}
+	}
+	method := func(name string) *ast.SelectorExpr {
+		return &ast.SelectorExpr{X: ast.NewIdent("m"), Sel: ast.NewIdent(name)}
+	}
+	appendLabels := func(values ...ast.Expr) ast.Stmt {
+		return &ast.AssignStmt{
+			Lhs: []ast.Expr{labels},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{call(ast.NewIdent("append"), append([]ast.Expr{labels}, values...)...)},
+		}
+	}
+	ret := &ast.ReturnStmt{Results: []ast.Expr{labels}}
+	pair := func(index ast.Expr) ast.Expr { return &ast.IndexExpr{X: pairs, Index: index} }
+	name := pair(&ast.BinaryExpr{X: i, Op: token.SUB, Y: one})
+	pairsLen := call(ast.NewIdent("len"), pairs)
+
+	m := astgen.NewMethod(appendContextLabelsMethodName, "m", g.structName)
+	m.SetType(&ast.FuncType{
+		Params: &ast.FieldList{
+			List: []*ast.Field{
+				{Names: []*ast.Ident{labels}, Type: &ast.ArrayType{Elt: ast.NewIdent("string")}},
+				{Names: []*ast.Ident{ast.NewIdent("ctx")}, Type: astgen.QualifiedName(g.contextPackageAlias, "Context")},
+			},
+		},
+		Results: &ast.FieldList{
+			List: []*ast.Field{{Type: &ast.ArrayType{Elt: ast.NewIdent("string")}}},
+		},
+	})
+	m.AddStatements([]ast.Stmt{
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{X: method(commonbuilders.LabelsFuncName), Op: token.EQL, Y: ast.NewIdent("nil")},
+			Body: &ast.BlockStmt{List: []ast.Stmt{ret}},
+		},
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{pairs},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{call(method(commonbuilders.LabelsFuncName), ast.NewIdent("ctx"))},
+		},
+		&ast.ForStmt{
+			Init: &ast.AssignStmt{Lhs: []ast.Expr{i}, Tok: token.DEFINE, Rhs: []ast.Expr{one}},
+			Cond: &ast.BinaryExpr{X: i, Op: token.LSS, Y: pairsLen},
+			Post: &ast.AssignStmt{Lhs: []ast.Expr{i}, Tok: token.ADD_ASSIGN, Rhs: []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: "2"}}},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				appendLabels(name, commonbuilders.GuardedLabelValue(name, pair(i))),
+			}},
+		},
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{
+				X:  &ast.BinaryExpr{X: pairsLen, Op: token.REM, Y: &ast.BasicLit{Kind: token.INT, Value: "2"}},
+				Op: token.EQL,
+				Y:  one,
+			},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				appendLabels(pair(&ast.BinaryExpr{X: pairsLen, Op: token.SUB, Y: one})),
+			}},
+		},
+		ret,
+	})
+
+	decl := m.Build().(*ast.FuncDecl)
+	decl.Doc = &ast.CommentGroup{
+		List: []*ast.Comment{{
+			Text: fmt.Sprintf("// %s appends the label pairs returned by labelsFunc to labels, with their values capped like those of the other labels.", appendContextLabelsMethodName),
+		}},
+	}
+	return decl
+}
+
+// callWith builds a call to the With method of the metric, bound to the
+// operation label already, with the values of the extra labels taken from
+// labelsVar. If labelsVar is nil, it returns the metric itself.
+func callWith(metric ast.Expr, labelsVar *ast.Ident) ast.Expr {
+	if labelsVar == nil {
+		return metric
+	}
+	return &ast.CallExpr{
 		Fun: &ast.SelectorExpr{
-			X:   c.counterField,
+			X:   metric,
 			Sel: ast.NewIdent("With"),
 		},
+		Args:     []ast.Expr{labelsVar},
+		Ellipsis: token.Pos(1),
+	}
+}
+
+// gaugeAdd builds a call that adds delta to the gauge:
+// m.methodOperation.inFlightOps.Add(1)
+func gaugeAdd(gauge ast.Expr, labelsVar *ast.Ident, delta string) *ast.CallExpr {
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   callWith(gauge, labelsVar),
+			Sel: ast.NewIdent("Add"),
+		},
 		Args: []ast.Expr{
-			&ast.BasicLit{Kind: token.STRING, Value: `"operation"`},
-			&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf(`"%s"`, transformation.ToSnakeCase(c.operationName))},
+			&ast.BasicLit{Kind: token.FLOAT, Value: delta},
 		},
 	}
+}
+
+type CounterAddAction struct {
+	counterField *ast.SelectorExpr
+	labelsVar    *ast.Ident
+}
+
+func (c *CounterAddAction) Build() ast.Stmt {
+	callWithExpr := callWith(c.counterField, c.labelsVar)
 
 	callAddExpr := &ast.CallExpr{
 		Fun: &ast.SelectorExpr{
@@ -208,39 +1156,51 @@ func (c *CounterAddAction) Build() ast.Stmt {
 }
 
 type IncreaseFailedOps struct {
-	method       *astgen.MethodConfig
-	counterField *ast.SelectorExpr
+	method          *astgen.MethodConfig
+	counterField    *ast.SelectorExpr
+	labelsVar       *ast.Ident
+	classifyErrors  bool
+	errorClassLabel string
 }
 
 func NewIncreaseFailedOps(m *astgen.MethodConfig, counterField *ast.SelectorExpr) *IncreaseFailedOps {
-	return &IncreaseFailedOps{m, counterField}
+	return &IncreaseFailedOps{method: m, counterField: counterField}
 }
 
 func (i *IncreaseFailedOps) Build() ast.Stmt {
-	var errorResult ast.Expr
-	for _, result := range i.method.MethodResults {
-		if id, ok := result.Type.(*ast.Ident); ok {
-			if id.Name == "error" {
-				errorResult = ast.NewIdent(result.Names[0].String())
-				break
-			}
-		}
-	}
-
-	if errorResult == nil {
+	failure := commonbuilders.NewFailure(i.method)
+	if !failure.Possible() {
 		return &ast.EmptyStmt{}
 	}
 
-	callWithExpr := &ast.CallExpr{
-		Fun: &ast.SelectorExpr{
-			X:   i.counterField,
-			Sel: ast.NewIdent("With"),
-		},
-		Args: []ast.Expr{
-			&ast.BasicLit{Kind: token.STRING, Value: `"operation"`},
-			&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf(`"%s"`, transformation.ToSnakeCase(i.method.MethodName))},
+	return &ast.IfStmt{
+		Cond: failure.Cond(),
+		Body: &ast.BlockStmt{
+			List: i.record(failure),
 		},
 	}
+}
+
+// record builds the statements that increase the failed operations, with the
+// class of the failure if errors are classified.
+func (i *IncreaseFailedOps) record(failure commonbuilders.Failure) []ast.Stmt {
+	var body []ast.Stmt
+	callWithExpr := callWith(i.counterField, i.labelsVar)
+	if i.classifyErrors {
+		// ... .With("error_class", m.errorClass(err))
+		stmts, class := failure.Class("m")
+		body = append(body, stmts...)
+		callWithExpr = &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   callWithExpr,
+				Sel: ast.NewIdent("With"),
+			},
+			Args: []ast.Expr{
+				commonbuilders.StringLit(i.errorClassLabel),
+				class,
+			},
+		}
+	}
 
 	callAddExpr := &ast.CallExpr{
 		Fun: &ast.SelectorExpr{
@@ -256,29 +1216,19 @@ func (i *IncreaseFailedOps) Build() ast.Stmt {
 		X: callAddExpr,
 	}
 
-	return &ast.IfStmt{
-		Cond: &ast.BinaryExpr{
-			X:  errorResult,
-			Op: token.NEQ,
-			Y:  ast.NewIdent("nil"),
-		},
-		Body: &ast.BlockStmt{
-			List: []ast.Stmt{callStmt},
-		},
-	}
+	return append(body, callStmt)
 }
 
 type RecordOpDuration struct {
 	timePackageAlias string
-	opsDuration      *ast.SelectorExpr
-	operationName    string
+	opsDuration      ast.Expr
+	labelsVar        *ast.Ident
 }
 
-func NewRecordOpDuraton(timePackageAlias string, opsDuration *ast.SelectorExpr, operationName string) *RecordOpDuration {
+func NewRecordOpDuraton(timePackageAlias string, opsDuration ast.Expr) *RecordOpDuration {
 	return &RecordOpDuration{
 		timePackageAlias: timePackageAlias,
 		opsDuration:      opsDuration,
-		operationName:    operationName,
 	}
 }
 
@@ -298,16 +1248,7 @@ func (r RecordOpDuration) Build() ast.Stmt {
 		},
 	}
 
-	callWithExpr := &ast.CallExpr{
-		Fun: &ast.SelectorExpr{
-			X:   r.opsDuration,
-			Sel: ast.NewIdent("With"),
-		},
-		Args: []ast.Expr{
-			&ast.BasicLit{Kind: token.STRING, Value: `"operation"`},
-			&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf(`"%s"`, transformation.ToSnakeCase(r.operationName))},
-		},
-	}
+	callWithExpr := callWith(r.opsDuration, r.labelsVar)
 
 	observeCallExpr := &ast.CallExpr{
 		Fun: &ast.SelectorExpr{
@@ -8,46 +8,213 @@ import (
 )
 
 type goKitModel struct {
+	cfg         commonbuilders.ModelConfig
 	fileBuilder *astgen.File
 	structName  string
+	strct       *astgen.Struct
+	labels      *commonbuilders.Labels
+	labelsName  string
+	buckets     *commonbuilders.BucketGroups
+	toggles     *astgen.Toggles
+	constructor *constructorBuilder
+	// metrics is the type that holds the metrics expected by the
+	// constructor, if it is generated.
+	metrics *commonbuilders.MetricsStruct
 
+	metricsAlias     string
 	timePackageAlias string
+	options          options
 }
 
-func NewGoKitModel(interfacePath, interfaceName, structName, targetPkg string) *goKitModel {
-	file := astgen.NewFile(targetPkg)
-	strct := astgen.NewStruct(structName)
+func NewGoKitModel(cfg commonbuilders.ModelConfig) *goKitModel {
+	file := astgen.NewFile(cfg.TargetPkg, cfg.TargetPath)
+	strct := astgen.NewStruct(cfg.StructName)
 	file.AppendDeclaration(strct)
 
 	m := &goKitModel{
+		cfg:         cfg,
 		fileBuilder: file,
-		structName:  structName,
+		structName:  cfg.StructName,
+		strct:       strct,
+		labelsName:  commonbuilders.DerivedName("", cfg.ConstructorName, "Labels"),
+		options:     newOptions(cfg),
 	}
-	sourcePackageAlias := m.AddImport("", interfacePath)
+	m.labels = commonbuilders.NewLabels(m, cfg)
+	m.buckets = commonbuilders.NewBucketGroups(cfg)
+	sourcePackageAlias := m.AddImport("", cfg.InterfacePath)
 	metricsAlias := m.AddImport("", "github.com/go-kit/kit/metrics")
+	m.metricsAlias = metricsAlias
 	m.timePackageAlias = m.AddImport("", "time")
+	if cfg.LabelsFunc {
+		m.options.contextPackageAlias = m.AddImport("", "context")
+	}
 
-	constructorBuilder := newConstructorBuilder(metricsAlias, sourcePackageAlias, interfaceName)
+	operation := astgen.NewStruct(operationTypeName(cfg.StructName))
+	operation.AddField(commonbuilders.TotalOpsMetricName, metricsAlias, "Counter")
+	operation.AddField(commonbuilders.FailedOpsMetricName, metricsAlias, "Counter")
+	if cfg.RecordOutcome {
+		operation.AddField(opsDurationSuccessName, metricsAlias, "Histogram")
+		operation.AddField(opsDurationErrorName, metricsAlias, "Histogram")
+	} else {
+		operation.AddField(commonbuilders.OpsDurationMetricName, metricsAlias, "Histogram")
+	}
+	if cfg.InFlightOps {
+		operation.AddField(commonbuilders.InFlightOpsMetricName, metricsAlias, "Gauge")
+	}
+	if cfg.Streams {
+		operation.AddField(commonbuilders.StreamSizeMetricName, metricsAlias, "Histogram")
+	}
+	if cfg.ResultSize {
+		operation.AddField(commonbuilders.ResultSizeMetricName, metricsAlias, "Histogram")
+	}
+	file.AppendDeclaration(operation)
+
+	constructorBuilder := newConstructorBuilder(metricsAlias, sourcePackageAlias, cfg.InterfaceName, cfg.StructName, cfg.ConstructorName, m.labels, m.buckets, m.options)
 	file.AppendDeclaration(constructorBuilder)
+	m.constructor = constructorBuilder
+
+	strct.AddField("next", sourcePackageAlias, cfg.InterfaceName)
 
-	strct.AddField("next", sourcePackageAlias, interfaceName)
-	strct.AddField(commonbuilders.TotalOpsMetricName, metricsAlias, "Counter")
-	strct.AddField(commonbuilders.FailedOpsMetricName, metricsAlias, "Counter")
-	strct.AddField(commonbuilders.OpsDurationMetricName, metricsAlias, "Histogram")
+	if cfg.Metrics {
+		m.addMetrics(cfg, constructorBuilder, metricsAlias, sourcePackageAlias)
+	}
+
+	if cfg.ClassifyErrors {
+		commonbuilders.AddErrorClassField(strct)
+		commonbuilders.AddLabelGuardFields(strct, m.AddImport("", "sync"))
+		file.AppendDeclaration(commonbuilders.ErrorClassMethod{
+			StructName:          cfg.StructName,
+			ErrorsPackageAlias:  m.AddImport("", "errors"),
+			ContextPackageAlias: m.AddImport("", "context"),
+			Key:                 m.labels.Keys().ErrorClass,
+		})
+		file.AppendDeclaration(commonbuilders.LabelGuardMethod{StructName: cfg.StructName})
+	}
+
+	if cfg.LabelsFunc {
+		strct.AddFieldWithType(commonbuilders.LabelsFuncName, labelsFuncType(m.options.contextPackageAlias))
+		// The values returned by labelsFunc are guarded, like those of the
+		// labels declared with directives.
+		if !cfg.ClassifyErrors {
+			commonbuilders.AddLabelGuardFields(strct, m.AddImport("", "sync"))
+			file.AppendDeclaration(commonbuilders.LabelGuardMethod{StructName: cfg.StructName})
+		}
+		file.AppendDeclaration(appendContextLabelsMethod{structName: cfg.StructName, contextPackageAlias: m.options.contextPackageAlias})
+	}
+
+	if cfg.Toggles {
+		m.toggles = astgen.NewToggles(m.AddImport("", astgen.TogglePackage), cfg.InterfaceName)
+		constructorBuilder.toggles = m.toggles
+	}
 
 	return m
 }
 
+// addMetrics adds the declarations that create the metrics expected by the
+// constructor.
+func (m *goKitModel) addMetrics(cfg commonbuilders.ModelConfig, constructor *constructorBuilder, metricsAlias, sourcePackageAlias string) {
+	typeName := commonbuilders.MetricsTypeName(cfg.ConstructorName)
+	field := func(metric commonbuilders.Metric, typeName string) *ast.Field {
+		return &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(metric.Field())},
+			Type:  astgen.QualifiedName(metricsAlias, typeName),
+		}
+	}
+	fields := []*ast.Field{
+		field(commonbuilders.TotalOpsMetric, "Counter"),
+		field(commonbuilders.FailedOpsMetric, "Counter"),
+		field(commonbuilders.OpsDurationMetric, "Histogram"),
+	}
+	if cfg.InFlightOps {
+		fields = append(fields, field(commonbuilders.InFlightOpsMetric, "Gauge"))
+	}
+	if cfg.Streams {
+		fields = append(fields, field(commonbuilders.StreamSizeMetric, "Histogram"))
+	}
+	if cfg.ResultSize {
+		fields = append(fields, field(commonbuilders.ResultSizeMetric, "Histogram"))
+	}
+
+	m.metrics = &commonbuilders.MetricsStruct{Name: typeName, Fields: fields}
+	m.fileBuilder.AppendDeclaration(m.metrics)
+	m.fileBuilder.AppendDeclaration(newMetricsBuilder(
+		m.AddImport("", "github.com/go-kit/kit/metrics/prometheus"),
+		m.AddImport("", "github.com/prometheus/client_golang/prometheus"),
+		typeName, commonbuilders.MetricsConstructorName(cfg.ConstructorName), m.labels, m.buckets, m.options))
+	m.fileBuilder.AppendDeclaration(commonbuilders.MetricsWrapMethod{
+		TypeName:        typeName,
+		ConstructorName: cfg.ConstructorName,
+		Constructor:     constructor,
+		InterfaceType:   astgen.QualifiedName(sourcePackageAlias, cfg.InterfaceName),
+		Buckets:         m.buckets,
+	})
+}
+
 func (m *goKitModel) AddImport(pkgName, location string) string {
 	return m.fileBuilder.AddImport(pkgName, location)
 }
 
 func (m *goKitModel) AddMethod(method *astgen.MethodConfig) error {
-	mmb := newMonitoringMethodBuilder(m.structName, method)
+	hadLabels, guarded := !m.labels.Empty(), m.labels.Guarded()
+	if err := m.labels.AddMethod(method); err != nil {
+		return err
+	}
+	if !hadLabels && !m.labels.Empty() {
+		if !guarded {
+			commonbuilders.AddLabelGuardFields(m.strct, m.AddImport("", "sync"))
+		}
+		m.fileBuilder.AppendDeclaration(commonbuilders.LabelNamesVar{Name: m.labelsName, Labels: m.labels})
+		if !guarded {
+			m.fileBuilder.AppendDeclaration(commonbuilders.LabelGuardMethod{StructName: m.structName})
+		}
+	}
+
+	bucketGroup, first, err := m.buckets.AddMethod(method)
+	if err != nil {
+		return err
+	}
+	if first && m.metrics != nil {
+		m.metrics.Fields = append(m.metrics.Fields, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(commonbuilders.DurationMetric(bucketGroup).Field())},
+			Type:  astgen.QualifiedName(m.metricsAlias, "Histogram"),
+		})
+	}
+
+	operation, err := m.cfg.OperationName(method.MethodName)
+	if err != nil {
+		return err
+	}
+	m.strct.AddFieldWithType(operationFieldName(method.MethodName), ast.NewIdent(operationTypeName(m.structName)))
+	m.constructor.AddMethod(method.MethodName, operation, bucketGroup)
+
+	mmb := newMonitoringMethodBuilder(m.structName, method, m.labels, m.options)
 
 	mmb.SetTimePackageAlias(m.timePackageAlias)
+	if m.options.streams {
+		if stream, ok := commonbuilders.FindStreamResult(method, m.fileBuilder.ImportAlias); ok {
+			mmb.SetStream(stream, m.AddImport("", commonbuilders.StreamPackage))
+			if stream.Wrap == "Seq" || stream.Wrap == "Seq2" {
+				m.constructor.MeasureSequences()
+			}
+		}
+	}
+	if m.cfg.ResultSize {
+		size, ok, err := commonbuilders.FindSizeResult(method)
+		if err != nil {
+			return err
+		}
+		if ok {
+			size.AddImports(m.AddImport)
+			mmb.SetSize(size)
+		}
+	}
 
-	m.fileBuilder.AppendDeclaration(mmb)
+	var decl astgen.DeclarationBuilder = mmb
+	if m.toggles != nil {
+		m.toggles.AddMethod(m.strct, method.MethodName)
+		decl = m.toggles.Guard(method, mmb)
+	}
+	m.fileBuilder.AppendDeclaration(decl)
 	return nil
 }
 
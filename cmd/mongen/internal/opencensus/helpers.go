@@ -1,6 +1,11 @@
 package opencensus
 
-import "go/ast"
+import (
+	"go/ast"
+	"go/token"
+	"unicode"
+	"unicode/utf8"
+)
 
 // statsRecordCallExpr prepares the opencensus -> stats.Record(ctx, ... statement, sets the
 // provided parameter as second argument and returns the built expression.
@@ -17,6 +22,69 @@ func statsRecordCallExpr(statsPackageAlias string, ctxFieldName string, statToRe
 	}
 }
 
+// recordWithTagsStmt builds a statement that records the measurement with the tag mutator applied, or without it if
+// tagging fails:
+//
+//	if err := stats.RecordWithTags(ctx, []tag.Mutator{mutator}, measurement); err != nil {
+//		stats.Record(ctx, measurement)
+//	}
+func recordWithTagsStmt(statsPackageAlias, tagPackageAlias, ctxFieldName string, mutator, measurement ast.Expr) ast.Stmt {
+	errSel := ast.NewIdent("err")
+	return &ast.IfStmt{
+		Init: &ast.AssignStmt{
+			Lhs: []ast.Expr{errSel},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{
+				&ast.CallExpr{
+					Fun: &ast.SelectorExpr{
+						X:   ast.NewIdent(statsPackageAlias),
+						Sel: ast.NewIdent("RecordWithTags"),
+					},
+					Args: []ast.Expr{
+						ast.NewIdent(ctxFieldName),
+						&ast.CompositeLit{
+							Type: &ast.ArrayType{Elt: &ast.SelectorExpr{
+								X:   ast.NewIdent(tagPackageAlias),
+								Sel: ast.NewIdent("Mutator"),
+							}},
+							Elts: []ast.Expr{mutator},
+						},
+						measurement,
+					},
+				},
+			},
+		},
+		Cond: &ast.BinaryExpr{
+			X:  errSel,
+			Op: token.NEQ,
+			Y:  ast.NewIdent("nil"),
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ExprStmt{X: statsRecordCallExpr(statsPackageAlias, ctxFieldName, measurement)},
+			},
+		},
+	}
+}
+
+// upsertTagExpr builds a tag mutator that sets the tag to the value:
+// tag.Upsert(key, value)
+func upsertTagExpr(tagPackageAlias string, key, value ast.Expr) ast.Expr {
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   ast.NewIdent(tagPackageAlias),
+			Sel: ast.NewIdent("Upsert"),
+		},
+		Args: []ast.Expr{key, value},
+	}
+}
+
+// lowerFirst returns s with its first letter in lower case.
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
 // buildCtxFuncType builds a FuncType that accepts a context and returns a context.
 func buildCtxFuncType(ctxPackageAlias string) *ast.FuncType {
 	ctxField := []*ast.Field{
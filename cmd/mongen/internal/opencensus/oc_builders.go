@@ -4,50 +4,306 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"strconv"
 
 	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
 	"github.com/Bo0mer/gentools/pkg/astgen"
 	"github.com/Bo0mer/gentools/pkg/transformation"
 )
 
+// options holds the optional features of the generated implementation.
+type options struct {
+	classifyErrors bool
+	inFlightOps    bool
+	recordOutcome  bool
+	resultSize     bool
+	streams        bool
+	panics         bool
+}
+
+func newOptions(cfg commonbuilders.ModelConfig) options {
+	return options{
+		classifyErrors: cfg.ClassifyErrors,
+		inFlightOps:    cfg.InFlightOps,
+		recordOutcome:  cfg.RecordOutcome,
+		resultSize:     cfg.ResultSize,
+		streams:        cfg.Streams,
+		panics:         cfg.Panics,
+	}
+}
+
+// names of the struct fields that hold the tag keys
+const (
+	labelTagKeysFieldName     = "labelTagKeys"
+	errorClassTagKeyFieldName = "errorClassTagKey"
+	outcomeTagKeyFieldName    = "outcomeTagKey"
+)
+
+// operationTagFieldName returns the name of the struct field that holds the
+// tag mutator of the operation label of a method.
+func operationTagFieldName(methodName string) string {
+	return lowerFirst(methodName) + "Operation"
+}
+
+// operationTagsFieldName returns the name of the struct field that holds the
+// tags of a context tagged only with the operation label of a method.
+func operationTagsFieldName(methodName string) string {
+	return lowerFirst(methodName) + "Tags"
+}
+
 type ocConstructorBuilder struct {
 	metricsPackageName   string
 	contextPackageName   string
+	tagPackageName       string
 	interfacePackageName string
 	interfaceName        string
+	structName           string
+	constructorName      string
+	labels               *commonbuilders.Labels
+	buckets              *commonbuilders.BucketGroups
+	options              options
+	// toggles are the runtime controls of the methods, if enabled.
+	toggles     *astgen.Toggles
+	methodNames []string
+	operations  []string
+	// sequences reports whether a method returns a sequence that is
+	// measured until it is ranged over.
+	sequences bool
 }
 
 func newOCConstructorBuilder(
-	metricsPackageName, contextPackageName, packageName, interfaceName string) *ocConstructorBuilder {
+	metricsPackageName, contextPackageName, tagPackageName, packageName, interfaceName, structName, constructorName string,
+	labels *commonbuilders.Labels, buckets *commonbuilders.BucketGroups, opts options) *ocConstructorBuilder {
 	return &ocConstructorBuilder{
 		metricsPackageName:   metricsPackageName,
 		contextPackageName:   contextPackageName,
+		tagPackageName:       tagPackageName,
 		interfacePackageName: packageName,
 		interfaceName:        interfaceName,
+		structName:           structName,
+		constructorName:      constructorName,
+		labels:               labels,
+		buckets:              buckets,
+		options:              opts,
 	}
 }
 
+// AddMethod makes the constructor create the tag mutator and the tags of the
+// operation label of the method.
+func (c *ocConstructorBuilder) AddMethod(methodName, operation string) {
+	c.methodNames = append(c.methodNames, methodName)
+	c.operations = append(c.operations, operation)
+}
+
+// MeasureSequences makes the constructor document that the operations of the
+// methods that return sequences end only when the sequences are ranged over.
+func (c *ocConstructorBuilder) MeasureSequences() {
+	c.sequences = true
+}
+
 // Build builds the constructor method for given monitoring wrapper service using opencensus metrics.
+// The tag keys, and the tag mutators and the tags of the operation label are created once, by the constructor:
+//
+//	m := &monitoringService{...}
+//	operationTagKey := tag.MustNewKey("operation")
+//	bind := func(operation string) (tag.Mutator, *tag.Map) {
+//		mutator := tag.Insert(operationTagKey, operation)
+//		ctx, _ := tag.New(context.Background(), mutator)
+//		return mutator, tag.FromContext(ctx)
+//	}
+//	m.doWorkOperation, m.doWorkTags = bind("do_work")
+//	return m
 func (c *ocConstructorBuilder) Build() ast.Decl {
-	funcBody := &ast.BlockStmt{
-		List: []ast.Stmt{
-			&ast.ReturnStmt{
-				Results: []ast.Expr{
-					&ast.CompositeLit{
-						Type: ast.NewIdent(fmt.Sprintf("&monitoring%s", c.interfaceName)),
-						Elts: []ast.Expr{
-							ast.NewIdent("next"),
-							ast.NewIdent(commonbuilders.TotalOpsMetricName),
-							ast.NewIdent(commonbuilders.FailedOpsMetricName),
-							ast.NewIdent(commonbuilders.OpsDurationMetricName),
-							ast.NewIdent(commonbuilders.ContextDecoratorFuncName),
+	fieldInit := func(name string) ast.Expr {
+		return &ast.KeyValueExpr{Key: ast.NewIdent(name), Value: ast.NewIdent(name)}
+	}
+	elts := []ast.Expr{
+		fieldInit("next"),
+		fieldInit(commonbuilders.TotalOpsMetricName),
+		fieldInit(commonbuilders.FailedOpsMetricName),
+		fieldInit(commonbuilders.OpsDurationMetricName),
+		fieldInit(commonbuilders.ContextDecoratorFuncName),
+	}
+	for _, group := range c.buckets.Groups() {
+		elts = append(elts, fieldInit(commonbuilders.DurationMetric(group).Param))
+	}
+	if c.options.inFlightOps {
+		elts = append(elts, fieldInit(commonbuilders.InFlightOpsMetricName))
+	}
+	if c.options.streams {
+		elts = append(elts, fieldInit(commonbuilders.StreamSizeMetricName))
+	}
+	if c.options.resultSize {
+		elts = append(elts, fieldInit(commonbuilders.ResultSizeMetricName))
+	}
+	if c.labels.Guarded() {
+		elts = append(elts, commonbuilders.LabelGuardInit()...)
+	}
+	if c.options.classifyErrors {
+		elts = append(elts, commonbuilders.ErrorClassInit())
+	}
+	if c.toggles != nil {
+		elts = append(elts, c.toggles.Init()...)
+	}
+
+	newKey := func(name string) ast.Expr {
+		return &ast.CallExpr{
+			Fun:  astgen.QualifiedName(c.tagPackageName, "MustNewKey"),
+			Args: []ast.Expr{commonbuilders.StringLit(name)},
+		}
+	}
+	keys := c.labels.Keys()
+	if !c.labels.Empty() {
+		var labelKeys []ast.Expr
+		for _, name := range c.labels.Names() {
+			labelKeys = append(labelKeys, newKey(name))
+		}
+		elts = append(elts, &ast.KeyValueExpr{
+			Key: ast.NewIdent(labelTagKeysFieldName),
+			Value: &ast.CompositeLit{
+				Type: &ast.ArrayType{Elt: astgen.QualifiedName(c.tagPackageName, "Key")},
+				Elts: labelKeys,
+			},
+		})
+	}
+	if c.options.classifyErrors {
+		elts = append(elts, &ast.KeyValueExpr{Key: ast.NewIdent(errorClassTagKeyFieldName), Value: newKey(keys.ErrorClass)})
+	}
+	if c.options.recordOutcome {
+		elts = append(elts, &ast.KeyValueExpr{Key: ast.NewIdent(outcomeTagKeyFieldName), Value: newKey(keys.Outcome)})
+	}
+
+	middleware := ast.NewIdent("m")
+	stmts := []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{middleware},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{
+				&ast.UnaryExpr{
+					Op: token.AND,
+					X: &ast.CompositeLit{
+						Type: ast.NewIdent(c.structName),
+						Elts: elts,
+					},
+				},
+			},
+		},
+	}
+	if len(c.methodNames) > 0 {
+		operationTagKey := ast.NewIdent("operationTagKey")
+		bind := ast.NewIdent("bind")
+		stmts = append(stmts,
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{operationTagKey},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{newKey(keys.Operation)},
+			},
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{bind},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{c.bindOperation(operationTagKey)},
+			})
+		for i, methodName := range c.methodNames {
+			stmts = append(stmts, &ast.AssignStmt{
+				Lhs: []ast.Expr{
+					&ast.SelectorExpr{X: middleware, Sel: ast.NewIdent(operationTagFieldName(methodName))},
+					&ast.SelectorExpr{X: middleware, Sel: ast.NewIdent(operationTagsFieldName(methodName))},
+				},
+				Tok: token.ASSIGN,
+				Rhs: []ast.Expr{
+					&ast.CallExpr{Fun: bind, Args: []ast.Expr{commonbuilders.StringLit(c.operations[i])}},
+				},
+			})
+		}
+	}
+	stmts = append(stmts, &ast.ReturnStmt{Results: []ast.Expr{middleware}})
+	funcBody := &ast.BlockStmt{List: stmts}
+
+	funcName := c.constructorName
+	doc := []*ast.Comment{{Text: fmt.Sprintf("// %s creates new monitoring middleware.", funcName)}}
+	if c.sequences {
+		for _, line := range commonbuilders.SequencesDoc {
+			doc = append(doc, &ast.Comment{Text: "//" + line})
+		}
+	}
+	return &ast.FuncDecl{
+		Doc:  &ast.CommentGroup{List: doc},
+		Name: ast.NewIdent(funcName),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: c.Params(),
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{
+					{
+						Names: []*ast.Ident{ast.NewIdent("")},
+						Type:  astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
+					},
+				},
+			},
+		},
+		Body: funcBody,
+	}
+}
+
+// bindOperation builds the helper that creates the tag mutator of the
+// operation label of a method, and the tags of a context tagged only with it.
+// The operation names are valid tag values, so the error of tag.New is
+// ignored.
+func (c *ocConstructorBuilder) bindOperation(operationTagKey *ast.Ident) ast.Expr {
+	operation := ast.NewIdent("operation")
+	mutator := ast.NewIdent("mutator")
+	ctx := ast.NewIdent("ctx")
+	return &ast.FuncLit{
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: []*ast.Field{{Names: []*ast.Ident{operation}, Type: ast.NewIdent("string")}},
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{
+					{Type: astgen.QualifiedName(c.tagPackageName, "Mutator")},
+					{Type: &ast.StarExpr{X: astgen.QualifiedName(c.tagPackageName, "Map")}},
+				},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{mutator},
+					Tok: token.DEFINE,
+					Rhs: []ast.Expr{
+						&ast.CallExpr{
+							Fun:  astgen.QualifiedName(c.tagPackageName, "Insert"),
+							Args: []ast.Expr{operationTagKey, operation},
 						},
 					},
 				},
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{ctx, ast.NewIdent("_")},
+					Tok: token.DEFINE,
+					Rhs: []ast.Expr{
+						&ast.CallExpr{
+							Fun: astgen.QualifiedName(c.tagPackageName, "New"),
+							Args: []ast.Expr{
+								&ast.CallExpr{Fun: astgen.QualifiedName(c.contextPackageName, "Background")},
+								mutator,
+							},
+						},
+					},
+				},
+				&ast.ReturnStmt{
+					Results: []ast.Expr{
+						mutator,
+						&ast.CallExpr{Fun: astgen.QualifiedName(c.tagPackageName, "FromContext"), Args: []ast.Expr{ctx}},
+					},
+				},
 			},
 		},
 	}
+}
 
+// Params returns the parameters of the constructor.
+func (c *ocConstructorBuilder) Params() []*ast.Field {
 	funcParamExpr := func(name, pkg, pkgSel string, asPointer bool) *ast.Field {
 		if asPointer {
 			return &ast.Field{
@@ -57,10 +313,7 @@ func (c *ocConstructorBuilder) Build() ast.Decl {
 		}
 		return &ast.Field{
 			Names: []*ast.Ident{ast.NewIdent(name)},
-			Type: &ast.SelectorExpr{
-				X:   ast.NewIdent(pkg),
-				Sel: ast.NewIdent(pkgSel),
-			},
+			Type:  astgen.QualifiedName(pkg, pkgSel),
 		}
 	}
 
@@ -71,39 +324,207 @@ func (c *ocConstructorBuilder) Build() ast.Decl {
 		}
 	}
 
-	funcName := fmt.Sprintf("NewMonitoring%s", c.interfaceName)
+	params := []*ast.Field{
+		funcParamExpr("next", c.interfacePackageName, c.interfaceName, false),
+		funcParamExpr(commonbuilders.TotalOpsMetricName, c.metricsPackageName, "Int64Measure", true),
+		funcParamExpr(commonbuilders.FailedOpsMetricName, c.metricsPackageName, "Int64Measure", true),
+		funcParamExpr(commonbuilders.OpsDurationMetricName, c.metricsPackageName, "Float64Measure", true),
+	}
+	for _, group := range c.buckets.Groups() {
+		params = append(params, funcParamExpr(commonbuilders.DurationMetric(group).Param, c.metricsPackageName, "Float64Measure", true))
+	}
+	params = append(params, buildCtxFuncParam(commonbuilders.ContextDecoratorFuncName))
+	if c.options.inFlightOps {
+		params = append(params, funcParamExpr(commonbuilders.InFlightOpsMetricName, c.metricsPackageName, "Int64Measure", true))
+	}
+	if c.options.streams {
+		params = append(params, funcParamExpr(commonbuilders.StreamSizeMetricName, c.metricsPackageName, "Int64Measure", true))
+	}
+	if c.options.resultSize {
+		params = append(params, funcParamExpr(commonbuilders.ResultSizeMetricName, c.metricsPackageName, "Int64Measure", true))
+	}
+	if c.labels.Guarded() {
+		params = append(params, commonbuilders.LabelGuardParam())
+	}
+	if c.options.classifyErrors {
+		params = append(params, commonbuilders.ErrorClassParam())
+	}
+	if c.toggles != nil {
+		params = append(params, c.toggles.Param())
+	}
+	return params
+}
+
+// ocMetricsBuilder builds a function that creates the measures expected by
+// the constructor and the views that aggregate them.
+type ocMetricsBuilder struct {
+	packageAliases packageAliases
+	viewPkg        string
+	typeName       string
+	funcName       string
+	labels         *commonbuilders.Labels
+	buckets        *commonbuilders.BucketGroups
+	options        options
+}
+
+func newOCMetricsBuilder(aliases packageAliases, viewPkg, typeName, funcName string, labels *commonbuilders.Labels, buckets *commonbuilders.BucketGroups, opts options) *ocMetricsBuilder {
+	return &ocMetricsBuilder{
+		packageAliases: aliases,
+		viewPkg:        viewPkg,
+		typeName:       typeName,
+		funcName:       funcName,
+		labels:         labels,
+		buckets:        buckets,
+		options:        opts,
+	}
+}
+
+// Build builds a function in the form:
+//
+//	func NewMonitoringServiceMetrics(namespace string) *MonitoringServiceMetrics {
+//		ms := &MonitoringServiceMetrics{
+//			TotalOps: stats.Int64(namespace+"/total_ops", "Total number of operations.", stats.UnitDimensionless),
+//			...
+//		}
+//		ms.Views = []*view.View{
+//			{Name: namespace + "/total_ops", Description: "Total number of operations.", Measure: ms.TotalOps, TagKeys: []tag.Key{...}, Aggregation: view.Count()},
+//			...
+//		}
+//		return ms
+//	}
+func (b *ocMetricsBuilder) Build() ast.Decl {
+	ms := ast.NewIdent("ms")
+	name := func(metric commonbuilders.Metric) ast.Expr {
+		return &ast.BinaryExpr{
+			X:  ast.NewIdent("namespace"),
+			Op: token.ADD,
+			Y:  commonbuilders.StringLit("/" + metric.Name),
+		}
+	}
+	newMeasure := func(metric commonbuilders.Metric, kind, unit string) ast.Expr {
+		return &ast.KeyValueExpr{
+			Key: ast.NewIdent(metric.Field()),
+			Value: &ast.CallExpr{
+				Fun: astgen.QualifiedName(b.packageAliases.statsPkg, kind),
+				Args: []ast.Expr{
+					name(metric),
+					commonbuilders.StringLit(metric.Help),
+					astgen.QualifiedName(b.packageAliases.statsPkg, unit),
+				},
+			},
+		}
+	}
+	newView := func(metric commonbuilders.Metric, aggregation string, aggregationArgs ...ast.Expr) ast.Expr {
+		var tagKeys []ast.Expr
+		for _, label := range metric.LabelNames(b.labels, b.options.classifyErrors, b.options.recordOutcome) {
+			tagKeys = append(tagKeys, &ast.CallExpr{
+				Fun:  astgen.QualifiedName(b.packageAliases.tagPkg, "MustNewKey"),
+				Args: []ast.Expr{commonbuilders.StringLit(label)},
+			})
+		}
+		return &ast.CompositeLit{
+			Elts: []ast.Expr{
+				&ast.KeyValueExpr{Key: ast.NewIdent("Name"), Value: name(metric)},
+				&ast.KeyValueExpr{Key: ast.NewIdent("Description"), Value: commonbuilders.StringLit(metric.Help)},
+				&ast.KeyValueExpr{Key: ast.NewIdent("Measure"), Value: &ast.SelectorExpr{X: ms, Sel: ast.NewIdent(metric.Field())}},
+				&ast.KeyValueExpr{
+					Key: ast.NewIdent("TagKeys"),
+					Value: &ast.CompositeLit{
+						Type: &ast.ArrayType{Elt: astgen.QualifiedName(b.packageAliases.tagPkg, "Key")},
+						Elts: tagKeys,
+					},
+				},
+				&ast.KeyValueExpr{
+					Key: ast.NewIdent("Aggregation"),
+					Value: &ast.CallExpr{
+						Fun:  astgen.QualifiedName(b.viewPkg, aggregation),
+						Args: aggregationArgs,
+					},
+				},
+			},
+		}
+	}
+
+	buckets := func(bounds []string) []ast.Expr {
+		var buckets []ast.Expr
+		for _, bucket := range bounds {
+			buckets = append(buckets, &ast.BasicLit{Kind: token.FLOAT, Value: bucket})
+		}
+		return buckets
+	}
+	measures := []ast.Expr{
+		newMeasure(commonbuilders.TotalOpsMetric, "Int64", "UnitDimensionless"),
+		newMeasure(commonbuilders.FailedOpsMetric, "Int64", "UnitDimensionless"),
+		newMeasure(commonbuilders.OpsDurationMetric, "Float64", "UnitSeconds"),
+	}
+	views := []ast.Expr{
+		newView(commonbuilders.TotalOpsMetric, "Count"),
+		newView(commonbuilders.FailedOpsMetric, "Count"),
+		newView(commonbuilders.OpsDurationMetric, "Distribution", buckets(commonbuilders.DurationBuckets)...),
+	}
+	for _, group := range b.buckets.Groups() {
+		metric := commonbuilders.DurationMetric(group)
+		measures = append(measures, newMeasure(metric, "Float64", "UnitSeconds"))
+		views = append(views, newView(metric, "Distribution", buckets(b.buckets.Bounds(group))...))
+	}
+	if b.options.inFlightOps {
+		measures = append(measures, newMeasure(commonbuilders.InFlightOpsMetric, "Int64", "UnitDimensionless"))
+		views = append(views, newView(commonbuilders.InFlightOpsMetric, "Sum"))
+	}
+	if b.options.streams {
+		measures = append(measures, newMeasure(commonbuilders.StreamSizeMetric, "Int64", "UnitDimensionless"))
+		views = append(views, newView(commonbuilders.StreamSizeMetric, "Distribution", buckets(commonbuilders.SizeBuckets)...))
+	}
+	if b.options.resultSize {
+		measures = append(measures, newMeasure(commonbuilders.ResultSizeMetric, "Int64", "UnitDimensionless"))
+		views = append(views, newView(commonbuilders.ResultSizeMetric, "Distribution", buckets(commonbuilders.SizeBuckets)...))
+	}
+
+	funcName := b.funcName
 	return &ast.FuncDecl{
 		Doc: &ast.CommentGroup{
 			List: []*ast.Comment{
-				{
-					Text: fmt.Sprintf("// %s creates new monitoring middleware.", funcName),
-				},
+				{Text: fmt.Sprintf("// %s creates measures with names prefixed by the specified namespace", funcName)},
+				{Text: "// and the views that aggregate them. The views must be registered with view.Register."},
 			},
 		},
 		Name: ast.NewIdent(funcName),
 		Type: &ast.FuncType{
 			Params: &ast.FieldList{
-				List: []*ast.Field{
-					funcParamExpr("next", c.interfacePackageName, c.interfaceName, false),
-					funcParamExpr(commonbuilders.TotalOpsMetricName, c.metricsPackageName, "Int64Measure", true),
-					funcParamExpr(commonbuilders.FailedOpsMetricName, c.metricsPackageName, "Int64Measure", true),
-					funcParamExpr(commonbuilders.OpsDurationMetricName, c.metricsPackageName, "Float64Measure", true),
-					buildCtxFuncParam(commonbuilders.ContextDecoratorFuncName),
-				},
+				List: []*ast.Field{{
+					Names: []*ast.Ident{ast.NewIdent("namespace")},
+					Type:  ast.NewIdent("string"),
+				}},
 			},
 			Results: &ast.FieldList{
-				List: []*ast.Field{
-					{
-						Names: []*ast.Ident{ast.NewIdent("")},
-						Type: &ast.SelectorExpr{
-							X:   ast.NewIdent(c.interfacePackageName),
-							Sel: ast.NewIdent(c.interfaceName),
+				List: []*ast.Field{{Type: &ast.StarExpr{X: ast.NewIdent(b.typeName)}}},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{ms},
+					Tok: token.DEFINE,
+					Rhs: []ast.Expr{
+						&ast.UnaryExpr{
+							Op: token.AND,
+							X:  &ast.CompositeLit{Type: ast.NewIdent(b.typeName), Elts: measures},
 						},
 					},
 				},
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{&ast.SelectorExpr{X: ms, Sel: ast.NewIdent("Views")}},
+					Tok: token.ASSIGN,
+					Rhs: []ast.Expr{
+						&ast.CompositeLit{
+							Type: &ast.ArrayType{Elt: pointerExpr(b.viewPkg, "View")},
+							Elts: views,
+						},
+					},
+				},
+				&ast.ReturnStmt{Results: []ast.Expr{ms}},
 			},
 		},
-		Body: funcBody,
 	}
 }
 
@@ -119,12 +540,24 @@ type ocMonitoringMethodBuilder struct {
 	totalOps    *ast.SelectorExpr
 	failedOps   *ast.SelectorExpr
 	opsDuration *ast.SelectorExpr
+	inFlightOps *ast.SelectorExpr
+	streamSize  *ast.SelectorExpr
+	resultSize  *ast.SelectorExpr
 	ctxFuncSel  *ast.SelectorExpr
 
+	// size is the result whose size is recorded, if any.
+	size *commonbuilders.SizeResult
+	// stream is the result that is measured until it ends, if any, and
+	// streamPackageAlias is the alias of the package that wraps it.
+	stream             *commonbuilders.StreamResult
+	streamPackageAlias string
+
 	packageAliases packageAliases
+	labels         *commonbuilders.Labels
+	options        options
 }
 
-func newOCMonitoringMethodBuilder(structName string, methodConfig *astgen.MethodConfig, aliases packageAliases) *ocMonitoringMethodBuilder {
+func newOCMonitoringMethodBuilder(structName string, methodConfig *astgen.MethodConfig, aliases packageAliases, labels *commonbuilders.Labels, opts options) *ocMonitoringMethodBuilder {
 	receiverName := "m"
 	method := astgen.NewMethod(methodConfig.MethodName, receiverName, structName)
 
@@ -142,11 +575,34 @@ func newOCMonitoringMethodBuilder(structName string, methodConfig *astgen.Method
 		totalOps:       selexpr(commonbuilders.TotalOpsMetricName),
 		failedOps:      selexpr(commonbuilders.FailedOpsMetricName),
 		opsDuration:    selexpr(commonbuilders.OpsDurationMetricName),
+		inFlightOps:    selexpr(commonbuilders.InFlightOpsMetricName),
+		streamSize:     selexpr(commonbuilders.StreamSizeMetricName),
+		resultSize:     selexpr(commonbuilders.ResultSizeMetricName),
 		ctxFuncSel:     selexpr(commonbuilders.ContextDecoratorFuncName),
 		packageAliases: aliases,
+		labels:         labels,
+		options:        opts,
 	}
 }
 
+// SetOpsDuration makes the method record its duration with the measure held
+// by the named field rather than the default one.
+func (b *ocMonitoringMethodBuilder) SetOpsDuration(fieldName string) {
+	b.opsDuration = &ast.SelectorExpr{X: ast.NewIdent(b.receiverName), Sel: ast.NewIdent(fieldName)}
+}
+
+// SetSize makes the method record the size of the result.
+func (b *ocMonitoringMethodBuilder) SetSize(size commonbuilders.SizeResult) {
+	b.size = &size
+}
+
+// SetStream makes the method measure the operation until the stream result
+// ends, wrapping it with the stream package imported with the alias.
+func (b *ocMonitoringMethodBuilder) SetStream(stream commonbuilders.StreamResult, streamPackageAlias string) {
+	b.stream = &stream
+	b.streamPackageAlias = streamPackageAlias
+}
+
 func (b *ocMonitoringMethodBuilder) Build() ast.Decl {
 	// Add the func declaration
 	//   func ([b.method.receiverName] [b.method.receiverType]) [funcName]([MethodParams...]) ([MethodResults...]) {
@@ -160,8 +616,7 @@ func (b *ocMonitoringMethodBuilder) Build() ast.Decl {
 	})
 
 	const (
-		startFieldName = "start"
-		tagKeyVarName  = "tagKey"
+		startFieldName = "_start"
 		ctxFieldName   = "ctx"
 	)
 
@@ -186,30 +641,33 @@ func (b *ocMonitoringMethodBuilder) Build() ast.Decl {
 	}
 	b.method.AddStatement(ctxDecorator.Build())
 
-	snakeCaseMethodName := transformation.ToSnakeCase(b.methodConfig.MethodName)
-	// Create an opencensus tag
-	//   tagKey, _ := tag.MustNewKey("operation")
-	createTagKey := &createTagKey{
-		ctxFieldName:      ctxFieldName,
-		tagPackageAlias:   b.packageAliases.tagPkg,
-		tagKeyVarName:     tagKeyVarName,
-		wrappedMethodName: snakeCaseMethodName,
-	}
-	b.method.AddStatement(createTagKey.Build())
-
-	//  Insert the tag in to context
-	//   var err error
-	//   ctx, err = tag.New(ctx, tag.Insert(tagKey, toSnakeCase(c.operationName)))
-	//   if err != nil {
-	//     panic(err)
-	//   }
-	insertInContext := insertTagInContext{
-		ctxFieldName:      ctxFieldName,
-		tagPackageAlias:   b.packageAliases.tagPkg,
-		tagKeyVarName:     tagKeyVarName,
-		wrappedMethodName: snakeCaseMethodName,
-	}
-	b.method.AddStatements(insertInContext.Build())
+	// Tag the context with the operation and the extra labels. The tags that
+	// fail are left out.
+	//   ctx = m.tagContext(ctx, m.methodTags, m.methodOperation, tag.Insert(m.labelTagKeys[0], m.labelValue("label", arg1.Label)))
+	ctxIdent := ast.NewIdent(ctxFieldName)
+	b.method.AddStatement(&ast.AssignStmt{
+		Lhs: []ast.Expr{ctxIdent},
+		Tok: token.ASSIGN,
+		Rhs: []ast.Expr{
+			&ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X:   ast.NewIdent(b.receiverName),
+					Sel: ast.NewIdent(tagContextMethodName),
+				},
+				Args: append([]ast.Expr{
+					ctxIdent,
+					&ast.SelectorExpr{
+						X:   ast.NewIdent(b.receiverName),
+						Sel: ast.NewIdent(operationTagsFieldName(b.methodConfig.MethodName)),
+					},
+					&ast.SelectorExpr{
+						X:   ast.NewIdent(b.receiverName),
+						Sel: ast.NewIdent(operationTagFieldName(b.methodConfig.MethodName)),
+					},
+				}, b.labelMutators()...),
+			},
+		},
+	})
 
 	// Add increase total operations statement
 	// 	 stats.Record(ctx, m.totalOps.M(1))
@@ -220,13 +678,51 @@ func (b *ocMonitoringMethodBuilder) Build() ast.Decl {
 	}
 	b.method.AddStatement(increaseTotalOps.Build())
 
+	// Track the operation in progress, even if the method panics
+	//   stats.Record(ctx, m.inFlightOps.M(1))
+	//   defer stats.Record(ctx, m.inFlightOps.M(-1))
+	// An operation that returned a stream is in progress until the stream
+	// ends, so it is tracked by the stream instead
+	//   _streaming := false
+	//   defer func() {
+	//     if !_streaming {
+	//       stats.Record(ctx, m.inFlightOps.M(-1))
+	//     }
+	//   }()
+	if b.options.inFlightOps {
+		b.method.AddStatement(recordStat{
+			statsPackageAlias: b.packageAliases.statsPkg,
+			ctxFieldName:      ctxFieldName,
+			statField:         b.inFlightOps,
+		}.Build())
+		if b.stream == nil {
+			b.method.AddStatement(&ast.DeferStmt{
+				Call: b.endInFlight(ctxFieldName).(*ast.ExprStmt).X.(*ast.CallExpr),
+			})
+		} else {
+			b.method.AddStatements(b.deferInFlightEnd(ctxFieldName))
+		}
+	}
+
 	// Add statement to capture current time
-	//   start := time.Now()
+	//   _start := time.Now()
 	b.method.AddStatement(commonbuilders.StartTimeRecorder{
 		TimePackageAlias: b.packageAliases.timePkg,
 		StartFieldName:   startFieldName,
 	}.Build())
 
+	// Record a panic of the call as a failure, and raise it again
+	//   defer func() {
+	//     if _panic := recover(); _panic != nil {
+	//       stats.Record(ctx, m.opsDuration.M(time.Since(_start).Seconds()))
+	//       stats.Record(ctx, m.failedOps.M(1))
+	//       panic(_panic)
+	//     }
+	//   }()
+	if b.options.panics {
+		b.method.AddStatement(astgen.Recover(b.recordPanic(startFieldName, ctxFieldName)...))
+	}
+
 	// Add method invocation:
 	//   result1, result2 := m.next.Method(arg1, arg2)
 	methodInvocation := commonbuilders.NewMethodInvocation(b.methodConfig)
@@ -236,32 +732,259 @@ func (b *ocMonitoringMethodBuilder) Build() ast.Decl {
 	})
 	b.method.AddStatement(methodInvocation.Build())
 
+	// Record the size of the result, if the call did not fail
+	//   if err == nil { stats.Record(ctx, m.resultSize.M(int64(len(result1)))) }
+	if b.size != nil {
+		b.method.AddStatements(commonbuilders.RecordSize(b.methodConfig, *b.size, func(size ast.Expr) []ast.Stmt {
+			return []ast.Stmt{
+				&ast.ExprStmt{
+					X: statsRecordCallExpr(b.packageAliases.statsPkg, ctxFieldName, &ast.CallExpr{
+						Fun: &ast.SelectorExpr{X: b.resultSize, Sel: ast.NewIdent("M")},
+						Args: []ast.Expr{
+							&ast.CallExpr{Fun: ast.NewIdent("int64"), Args: []ast.Expr{size}},
+						},
+					}),
+				},
+			}
+		}))
+	}
+
+	// Record the operation once its stream ends, if it returned one
+	if b.stream != nil {
+		b.method.AddStatement(b.wrapStream(startFieldName, ctxFieldName))
+	}
+
+	b.method.AddStatements(b.recordResults(b.methodConfig, startFieldName, ctxFieldName))
+
+	// Add return statement
+	//   return result1, result2
+	returnResults := commonbuilders.NewReturnResults(b.methodConfig)
+	b.method.AddStatements(returnResults.Stmts())
+
+	return b.method.Build()
+}
+
+// recordResults builds the statements that record the duration and the
+// failure of the operation, given the results of the method.
+func (b *ocMonitoringMethodBuilder) recordResults(method *astgen.MethodConfig, startFieldName, ctxFieldName string) []ast.Stmt {
+	var stmts []ast.Stmt
+
 	// Record operation duration
-	//   stats.Record(ctx, m.opsDuration.M(time.Since(start).Seconds()))
-	b.method.AddStatement(recordOpsDurationStats{
+	//   stats.Record(ctx, m.opsDuration.M(time.Since(_start).Seconds()))
+	//   or, if the outcome is recorded
+	//   _outcome := "success"
+	//   if err != nil { _outcome = "error" }
+	//   stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(m.outcomeTagKey, _outcome)}, m.opsDuration.M(time.Since(_start).Seconds()))
+	recordOpsDuration := recordOpsDurationStats{
 		opsDurationField:  b.opsDuration,
+		receiverName:      b.receiverName,
 		statsPackageAlias: b.packageAliases.statsPkg,
+		tagPackageAlias:   b.packageAliases.tagPkg,
 		startFieldName:    startFieldName,
 		ctxFieldName:      ctxFieldName,
 		timePackageAlias:  b.packageAliases.timePkg,
-	}.Build())
+	}
+	if b.options.recordOutcome {
+		var outcomeStmts []ast.Stmt
+		outcomeStmts, recordOpsDuration.outcome = commonbuilders.Outcome{Method: method}.Build()
+		stmts = append(stmts, outcomeStmts...)
+	}
+	stmts = append(stmts, recordOpsDuration.Build())
 
 	// Add increase failed operations statement
 	//   if err != nil { m.failedOps.Add(1) }
-	b.method.AddStatement(incrementFailedOps{
+	//   or, if errors are classified
+	//   if err != nil { stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(m.errorClassTagKey, m.errorClass(err))}, m.failedOps.M(1)) }
+	return append(stmts, incrementFailedOps{
 		failedOpsField:    b.failedOps,
-		method:            b.methodConfig,
+		method:            method,
 		counterField:      "failedOps",
 		ctxFieldName:      ctxFieldName,
 		statsPackageAlias: b.packageAliases.statsPkg,
+		tagPackageAlias:   b.packageAliases.tagPkg,
+		receiverName:      b.receiverName,
+		classifyErrors:    b.options.classifyErrors,
 	}.Build())
+}
 
-	// Add return statement
-	//   return result1, result2
-	returnResults := commonbuilders.NewReturnResults(b.methodConfig)
-	b.method.AddStatement(returnResults.Build())
+// streamingVarName is the name of the variable that reports whether the
+// operation continues in a stream, which ends it.
+const streamingVarName = "_streaming"
 
-	return b.method.Build()
+// endInFlight builds the statement that ends the operation in progress:
+// stats.Record(ctx, m.inFlightOps.M(-1)).
+func (b *ocMonitoringMethodBuilder) endInFlight(ctxFieldName string) ast.Stmt {
+	return recordStat{
+		statsPackageAlias: b.packageAliases.statsPkg,
+		ctxFieldName:      ctxFieldName,
+		statField:         b.inFlightOps,
+		value:             "-1",
+	}.Build()
+}
+
+// deferInFlightEnd builds the statements that end the operation in progress
+// when the method returns, unless it returned a stream.
+func (b *ocMonitoringMethodBuilder) deferInFlightEnd(ctxFieldName string) []ast.Stmt {
+	return []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent(streamingVarName)},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{ast.NewIdent("false")},
+		},
+		&ast.DeferStmt{
+			Call: &ast.CallExpr{
+				Fun: &ast.FuncLit{
+					Type: &ast.FuncType{Params: &ast.FieldList{}},
+					Body: &ast.BlockStmt{
+						List: []ast.Stmt{
+							&ast.IfStmt{
+								Cond: &ast.UnaryExpr{Op: token.NOT, X: ast.NewIdent(streamingVarName)},
+								Body: &ast.BlockStmt{List: []ast.Stmt{b.endInFlight(ctxFieldName)}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// wrapStream builds a statement that wraps the stream result, if the method
+// returned one, and records the operation once the stream ends:
+//
+//	if result1 != nil && result2 == nil {
+//		result1 = stream.Chan(result1, func(_size int64, _err error) {
+//			stats.Record(ctx, m.opsDuration.M(time.Since(_start).Seconds()))
+//			if _err != nil { stats.Record(ctx, m.failedOps.M(1)) }
+//			stats.Record(ctx, m.streamSize.M(_size))
+//		})
+//		return result1, result2
+//	}
+//
+// The error that ended the stream is recorded in place of the error result.
+// If the operations in progress are tracked, the stream ends the operation:
+//
+//	_streaming = true
+//	result1 = stream.Chan(result1, func(_size int64, _err error) {
+//		...
+//		stats.Record(ctx, m.inFlightOps.M(-1))
+//	})
+func (b *ocMonitoringMethodBuilder) wrapStream(startFieldName, ctxFieldName string) ast.Stmt {
+	size, err := ast.NewIdent("_size"), ast.NewIdent("_err")
+	streamEnd := &astgen.MethodConfig{
+		MethodResults: []*ast.Field{{Names: []*ast.Ident{err}, Type: ast.NewIdent("error")}},
+	}
+
+	done := b.recordResults(streamEnd, startFieldName, ctxFieldName)
+	done = append(done, &ast.ExprStmt{
+		X: statsRecordCallExpr(b.packageAliases.statsPkg, ctxFieldName, &ast.CallExpr{
+			Fun:  &ast.SelectorExpr{X: b.streamSize, Sel: ast.NewIdent("M")},
+			Args: []ast.Expr{size},
+		}),
+	})
+	var body []ast.Stmt
+	if b.options.inFlightOps {
+		done = append(done, b.endInFlight(ctxFieldName))
+		body = append(body, &ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent(streamingVarName)},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{ast.NewIdent("true")},
+		})
+	}
+
+	var cond ast.Expr = &ast.BinaryExpr{X: b.stream.Name, Op: token.NEQ, Y: ast.NewIdent("nil")}
+	if errorResult := commonbuilders.ErrorResult(b.methodConfig); errorResult != nil {
+		cond = &ast.BinaryExpr{
+			X:  cond,
+			Op: token.LAND,
+			Y:  &ast.BinaryExpr{X: errorResult, Op: token.EQL, Y: ast.NewIdent("nil")},
+		}
+	}
+
+	return &ast.IfStmt{
+		Cond: cond,
+		Body: &ast.BlockStmt{
+			List: append(body,
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{b.stream.Name},
+					Tok: token.ASSIGN,
+					Rhs: []ast.Expr{
+						&ast.CallExpr{
+							Fun: astgen.QualifiedName(b.streamPackageAlias, b.stream.Wrap),
+							Args: []ast.Expr{
+								b.stream.Name,
+								&ast.FuncLit{
+									Type: &ast.FuncType{
+										Params: &ast.FieldList{
+											List: []*ast.Field{
+												{Names: []*ast.Ident{size}, Type: ast.NewIdent("int64")},
+												{Names: []*ast.Ident{err}, Type: ast.NewIdent("error")},
+											},
+										},
+									},
+									Body: &ast.BlockStmt{List: done},
+								},
+							},
+						},
+					},
+				},
+				commonbuilders.NewReturnResults(b.methodConfig).Build(),
+			),
+		},
+	}
+}
+
+// recordPanic builds the statements that record the duration and the failure
+// of the operation, if the call panicked.
+func (b *ocMonitoringMethodBuilder) recordPanic(startFieldName, ctxFieldName string) []ast.Stmt {
+	recordOpsDuration := recordOpsDurationStats{
+		opsDurationField:  b.opsDuration,
+		receiverName:      b.receiverName,
+		statsPackageAlias: b.packageAliases.statsPkg,
+		tagPackageAlias:   b.packageAliases.tagPkg,
+		startFieldName:    startFieldName,
+		ctxFieldName:      ctxFieldName,
+		timePackageAlias:  b.packageAliases.timePkg,
+	}
+	if b.options.recordOutcome {
+		recordOpsDuration.outcome = commonbuilders.PanicOutcome()
+	}
+	failedOps := incrementFailedOps{
+		failedOpsField:    b.failedOps,
+		method:            b.methodConfig,
+		counterField:      "failedOps",
+		ctxFieldName:      ctxFieldName,
+		statsPackageAlias: b.packageAliases.statsPkg,
+		tagPackageAlias:   b.packageAliases.tagPkg,
+		receiverName:      b.receiverName,
+		classifyErrors:    b.options.classifyErrors,
+	}
+	return append([]ast.Stmt{recordOpsDuration.Build()}, failedOps.buildRecordStmts(commonbuilders.PanicFailure)...)
+}
+
+// labelMutators builds the tag mutators that insert the extra labels:
+// tag.Insert(m.labelTagKeys[0], m.labelValue("label", arg1.Label)).
+func (b *ocMonitoringMethodBuilder) labelMutators() []ast.Expr {
+	var mutators []ast.Expr
+	for i, value := range b.labels.Values(b.receiverName, b.methodConfig.MethodName) {
+		mutators = append(mutators, &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   ast.NewIdent(b.packageAliases.tagPkg),
+				Sel: ast.NewIdent("Insert"),
+			},
+			Args: []ast.Expr{
+				&ast.IndexExpr{
+					X: &ast.SelectorExpr{
+						X:   ast.NewIdent(b.receiverName),
+						Sel: ast.NewIdent(labelTagKeysFieldName),
+					},
+					Index: &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(i)},
+				},
+				value,
+			},
+		})
+	}
+	return mutators
 }
 
 type contextParam struct {
@@ -313,9 +1036,9 @@ type contextDecorator struct {
 
 // Build builds a statement to decorate the context with the context func if it is provided.
 //
-//   if m.ctxFunc != nil {
-//     ctx = m.ctxFunc(ctx)
-//   }
+//	if m.ctxFunc != nil {
+//		ctx = m.ctxFunc(ctx)
+//	}
 func (c contextDecorator) Build() ast.Stmt {
 	ctxSel := ast.NewIdent(c.ctxFieldName)
 
@@ -344,151 +1067,145 @@ func (c contextDecorator) Build() ast.Stmt {
 	}
 }
 
-type createTagKey struct {
-	ctxFieldName      string
-	tagPackageAlias   string
-	tagKeyVarName     string
-	wrappedMethodName string
+// tagContextMethodName is the name of the method that tags the context of
+// every call.
+const tagContextMethodName = "tagContext"
+
+// tagContextMethod builds the method that tags the context of every call.
+type tagContextMethod struct {
+	structName          string
+	contextPackageAlias string
+	tagPackageAlias     string
 }
 
-// Build builds a opencensus tag key.
-//   tagKey, _ := tag.NewKey("operation")
-func (t createTagKey) Build() ast.Stmt {
-	// tagKey, _ :=
-	return &ast.AssignStmt{
-		Lhs: []ast.Expr{
-			ast.NewIdent(t.tagKeyVarName),
-		},
-		Tok: token.DEFINE,
-		// ... tag.NewKey("operation")
-		Rhs: []ast.Expr{
-			&ast.CallExpr{
-				Fun: &ast.SelectorExpr{
-					X:   ast.NewIdent(t.tagPackageAlias),
-					Sel: ast.NewIdent("MustNewKey"),
-				},
-				Args: []ast.Expr{
-					&ast.BasicLit{Kind: token.STRING, Value: `"operation"`},
-				},
+// Build builds a method in the form:
+//
+//	func (m *monitoringService) tagContext(ctx context.Context, operationTags *tag.Map, mutators ...tag.Mutator) context.Context {
+//		if len(mutators) == 1 && tag.FromContext(ctx) == nil {
+//			return tag.NewContext(ctx, operationTags)
+//		}
+//		if taggedCtx, err := tag.New(ctx, mutators...); err == nil {
+//			return taggedCtx
+//		}
+//		for _, mutator := range mutators {
+//			if taggedCtx, err := tag.New(ctx, mutator); err == nil {
+//				ctx = taggedCtx
+//			}
+//		}
+//		return ctx
+//	}
+func (t tagContextMethod) Build() ast.Decl {
+	ctx := ast.NewIdent("ctx")
+	operationTags := ast.NewIdent("operationTags")
+	mutators := ast.NewIdent("mutators")
+	mutator := ast.NewIdent("mutator")
+	taggedCtx := ast.NewIdent("taggedCtx")
+	err := ast.NewIdent("err")
+	contextType := astgen.QualifiedName(t.contextPackageAlias, "Context")
+	// if taggedCtx, err := tag.New(ctx, [args...]); err == nil { [then] }
+	ifTagged := func(args []ast.Expr, ellipsis bool, then ast.Stmt) ast.Stmt {
+		call := &ast.CallExpr{
+			Fun:  astgen.QualifiedName(t.tagPackageAlias, "New"),
+			Args: append([]ast.Expr{ctx}, args...),
+		}
+		if ellipsis {
+			call.Ellipsis = 1
+		}
+		return &ast.IfStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{taggedCtx, err},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{call},
 			},
-		},
+			Cond: &ast.BinaryExpr{X: err, Op: token.EQL, Y: ast.NewIdent("nil")},
+			Body: &ast.BlockStmt{List: []ast.Stmt{then}},
+		}
 	}
-}
-
-type insertTagInContext struct {
-	ctxFieldName      string
-	tagPackageAlias   string
-	tagKeyVarName     string
-	wrappedMethodName string
-}
-
-// Build creates a new context and adds to it the tag key with the method name as a value.
-//   var err error
-//   ctx, _ = tag.New(ctx, tag.Insert(tagKey, toSnakeCase([t.wrapped_method_name])))
-//   if err != nil {
-//     panic(err)
-//   }
-func (t insertTagInContext) Build() []ast.Stmt {
-	var stmts []ast.Stmt
-
-	errSel := ast.NewIdent("err")
-	newTagStmt := t.buildNewTagStmt(errSel)
 
-	stmts = append(stmts, t.buildVarErrStmt(errSel))
-	stmts = append(stmts, t.buildIfErrThenPanicStmt(newTagStmt, errSel))
-
-	return stmts
-}
-
-// buildVarErrStmt builds the `var err error` statement
-func (t insertTagInContext) buildVarErrStmt(errSel *ast.Ident) ast.Stmt {
-	return &ast.DeclStmt{
-		Decl: &ast.GenDecl{
-			Tok: token.VAR,
-			Specs: []ast.Spec{
-				&ast.TypeSpec{
-					Name: errSel,
-					Type: ast.NewIdent("error"),
-				},
+	method := astgen.NewMethod(tagContextMethodName, "m", t.structName)
+	method.SetType(&ast.FuncType{
+		Params: &ast.FieldList{
+			List: []*ast.Field{
+				{Names: []*ast.Ident{ctx}, Type: contextType},
+				{Names: []*ast.Ident{operationTags}, Type: &ast.StarExpr{X: astgen.QualifiedName(t.tagPackageAlias, "Map")}},
+				{Names: []*ast.Ident{mutators}, Type: &ast.Ellipsis{Elt: astgen.QualifiedName(t.tagPackageAlias, "Mutator")}},
 			},
 		},
-	}
-}
-
-// buildIfErrThenPanicStmt builds the if statement with the error check and panic if it's not nil
-//   if [initStmt]; [errSel] != nil {
-//     panic([errSel])
-//   }
-func (t insertTagInContext) buildIfErrThenPanicStmt(initStmt ast.Stmt, errSel ast.Expr) ast.Stmt {
-	panicCallExpr := &ast.CallExpr{
-		Fun:  ast.NewIdent("panic"),
-		Args: []ast.Expr{errSel},
-	}
-
-	return &ast.IfStmt{
-		Init: initStmt,
-		Cond: &ast.BinaryExpr{
-			X:  errSel,
-			Op: token.NEQ,
-			Y:  ast.NewIdent("nil"),
+		Results: &ast.FieldList{
+			List: []*ast.Field{{Type: contextType}},
 		},
-		Body: &ast.BlockStmt{
-			List: []ast.Stmt{
-				&ast.ExprStmt{
-					X: panicCallExpr,
+	})
+	method.AddStatements([]ast.Stmt{
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{
+				X: &ast.BinaryExpr{
+					X:  &ast.CallExpr{Fun: ast.NewIdent("len"), Args: []ast.Expr{mutators}},
+					Op: token.EQL,
+					Y:  &ast.BasicLit{Kind: token.INT, Value: "1"},
+				},
+				Op: token.LAND,
+				Y: &ast.BinaryExpr{
+					X:  &ast.CallExpr{Fun: astgen.QualifiedName(t.tagPackageAlias, "FromContext"), Args: []ast.Expr{ctx}},
+					Op: token.EQL,
+					Y:  ast.NewIdent("nil"),
 				},
 			},
-		},
-	}
-}
-
-// buildNewTagStmt builds the creation of the new tag and the assignment to the result variables.
-//   [t.ctxFieldName], [errSel] = tag.Insert(tagKey, [t.wrapped_method_name])
-func (t insertTagInContext) buildNewTagStmt(errSel ast.Expr) ast.Stmt {
-	// tag.Insert(tagKey, [t.wrapped_method_name])
-	insertKey := &ast.CallExpr{
-		Fun: &ast.SelectorExpr{
-			X:   ast.NewIdent(t.tagPackageAlias),
-			Sel: ast.NewIdent("Insert"),
-		},
-		Args: []ast.Expr{
-			ast.NewIdent(t.tagKeyVarName),
-			&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf(`"%s"`, t.wrappedMethodName)},
-		},
-	}
-
-	return &ast.AssignStmt{
-		Lhs: []ast.Expr{
-			ast.NewIdent(t.ctxFieldName),
-			errSel,
-		},
-		Tok: token.ASSIGN,
-		Rhs: []ast.Expr{
-			&ast.CallExpr{
-				// ... tag.New(...
-				Fun: &ast.SelectorExpr{
-					X:   ast.NewIdent(t.tagPackageAlias),
-					Sel: ast.NewIdent("New"),
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{
+					&ast.ReturnStmt{
+						Results: []ast.Expr{
+							&ast.CallExpr{Fun: astgen.QualifiedName(t.tagPackageAlias, "NewContext"), Args: []ast.Expr{ctx, operationTags}},
+						},
+					},
 				},
-				/// ... ctx, [tagInsertFuncCall])
-				Args: []ast.Expr{
-					ast.NewIdent(t.ctxFieldName),
-					insertKey,
+			},
+		},
+		ifTagged([]ast.Expr{mutators}, true, &ast.ReturnStmt{Results: []ast.Expr{taggedCtx}}),
+		&ast.RangeStmt{
+			Key:   ast.NewIdent("_"),
+			Value: mutator,
+			Tok:   token.DEFINE,
+			X:     mutators,
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{
+					ifTagged([]ast.Expr{mutator}, false, &ast.AssignStmt{
+						Lhs: []ast.Expr{ctx},
+						Tok: token.ASSIGN,
+						Rhs: []ast.Expr{taggedCtx},
+					}),
 				},
 			},
 		},
+		&ast.ReturnStmt{Results: []ast.Expr{ctx}},
+	})
+
+	decl := method.Build().(*ast.FuncDecl)
+	decl.Doc = &ast.CommentGroup{
+		List: []*ast.Comment{{
+			Text: fmt.Sprintf("// %s returns the context tagged with the mutators, the first of which tags the operation.", tagContextMethodName),
+		}, {
+			Text: "// A context without tags is given the operationTags when the operation is its only tag, which saves copying them.",
+		}, {
+			Text: "// The mutators that fail, e.g. because of invalid label values, are left out.",
+		}},
 	}
+	return decl
 }
 
 type recordStat struct {
 	statField         *ast.SelectorExpr
 	ctxFieldName      string
 	statsPackageAlias string
+	value             string // defaults to 1
 }
 
 // Build builds a statement in the form:
-// stats.Record(ctx, [statField].M(1))
+// stats.Record(ctx, [statField].M([value]))
 func (r recordStat) Build() ast.Stmt {
+	value := r.value
+	if value == "" {
+		value = "1"
+	}
 	return &ast.ExprStmt{
 		X: statsRecordCallExpr(r.statsPackageAlias, r.ctxFieldName, &ast.CallExpr{
 			Fun: &ast.SelectorExpr{
@@ -496,7 +1213,7 @@ func (r recordStat) Build() ast.Stmt {
 				Sel: ast.NewIdent("M"),
 			},
 			Args: []ast.Expr{
-				&ast.BasicLit{Kind: token.INT, Value: "1"},
+				&ast.BasicLit{Kind: token.INT, Value: value},
 			},
 		}),
 	}
@@ -504,38 +1221,52 @@ func (r recordStat) Build() ast.Stmt {
 
 type recordOpsDurationStats struct {
 	opsDurationField  *ast.SelectorExpr
+	receiverName      string
 	startFieldName    string
 	statsPackageAlias string
+	tagPackageAlias   string
 	ctxFieldName      string
 	timePackageAlias  string
+	outcome           ast.Expr // recorded as a tag, if not nil
 }
 
 // Build builds a statement in the form:
 // stats.Record(ctx, [opsDurationField].M([timePackageAlias].Since([startFieldName]).Seconds()))
+// or, if the outcome is recorded:
+//
+//	if err := stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(m.outcomeTagKey, [outcome])}, [opsDurationField].M(...)); err != nil {
+//		stats.Record(ctx, [opsDurationField].M(...))
+//	}
 func (r recordOpsDurationStats) Build() ast.Stmt {
-	return &ast.ExprStmt{
-		X: statsRecordCallExpr(r.statsPackageAlias, r.ctxFieldName, &ast.CallExpr{
-			// stats.Record(ctx, [opsDurationField].M(...)
-			Fun: &ast.SelectorExpr{
-				X:   r.opsDurationField,
-				Sel: ast.NewIdent("M"),
-			},
-			Args: []ast.Expr{
-				// [timePackageAlias].Since([startFieldName]).Seconds()
-				&ast.CallExpr{
-					Fun: &ast.SelectorExpr{
-						X: &ast.CallExpr{
-							Fun: &ast.SelectorExpr{
-								X:   ast.NewIdent(r.timePackageAlias),
-								Sel: ast.NewIdent("Since"),
-							},
-							Args: []ast.Expr{ast.NewIdent(r.startFieldName)},
+	measurement := &ast.CallExpr{
+		// [opsDurationField].M(...)
+		Fun: &ast.SelectorExpr{
+			X:   r.opsDurationField,
+			Sel: ast.NewIdent("M"),
+		},
+		Args: []ast.Expr{
+			// [timePackageAlias].Since([startFieldName]).Seconds()
+			&ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X: &ast.CallExpr{
+						Fun: &ast.SelectorExpr{
+							X:   ast.NewIdent(r.timePackageAlias),
+							Sel: ast.NewIdent("Since"),
 						},
-						Sel: ast.NewIdent("Seconds"),
+						Args: []ast.Expr{ast.NewIdent(r.startFieldName)},
 					},
+					Sel: ast.NewIdent("Seconds"),
 				},
 			},
-		}),
+		},
+	}
+	if r.outcome != nil {
+		outcomeTagKey := &ast.SelectorExpr{X: ast.NewIdent(r.receiverName), Sel: ast.NewIdent(outcomeTagKeyFieldName)}
+		return recordWithTagsStmt(r.statsPackageAlias, r.tagPackageAlias, r.ctxFieldName,
+			upsertTagExpr(r.tagPackageAlias, outcomeTagKey, r.outcome), measurement)
+	}
+	return &ast.ExprStmt{
+		X: statsRecordCallExpr(r.statsPackageAlias, r.ctxFieldName, measurement),
 	}
 }
 
@@ -545,36 +1276,46 @@ type incrementFailedOps struct {
 	counterField      string
 	ctxFieldName      string
 	statsPackageAlias string
+	tagPackageAlias   string
+	receiverName      string
+	classifyErrors    bool
 }
 
 func (i incrementFailedOps) Build() ast.Stmt {
-	var errorResult ast.Expr
-	for _, result := range i.method.MethodResults {
-		if id, ok := result.Type.(*ast.Ident); ok {
-			if id.Name == "error" {
-				errorResult = ast.NewIdent(result.Names[0].String())
-				break
-			}
-		}
-	}
-
-	// none of the returns is an error type
-	if errorResult == nil {
+	// the call cannot fail
+	failure := commonbuilders.NewFailure(i.method)
+	if !failure.Possible() {
 		return &ast.EmptyStmt{}
 	}
 
 	return &ast.IfStmt{
-		Cond: &ast.BinaryExpr{
-			X:  errorResult,
-			Op: token.NEQ,
-			Y:  ast.NewIdent("nil"),
-		},
+		Cond: failure.Cond(),
 		Body: &ast.BlockStmt{
-			List: []ast.Stmt{recordStat{
-				statsPackageAlias: i.statsPackageAlias,
-				statField:         i.failedOpsField,
-				ctxFieldName:      i.ctxFieldName,
-			}.Build()},
+			List: i.buildRecordStmts(failure),
 		},
 	}
 }
+
+// buildRecordStmts builds the statements that record the failed operation,
+// tagged with the class of the error if errors are classified:
+//
+//	if err := stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(m.errorClassTagKey, m.errorClass(err))}, m.failedOps.M(1)); err != nil {
+//		stats.Record(ctx, m.failedOps.M(1))
+//	}
+func (i incrementFailedOps) buildRecordStmts(failure commonbuilders.Failure) []ast.Stmt {
+	record := recordStat{
+		statsPackageAlias: i.statsPackageAlias,
+		statField:         i.failedOpsField,
+		ctxFieldName:      i.ctxFieldName,
+	}.Build()
+	if !i.classifyErrors {
+		return []ast.Stmt{record}
+	}
+
+	measurement := record.(*ast.ExprStmt).X.(*ast.CallExpr).Args[1]
+	errorClassTagKey := &ast.SelectorExpr{X: ast.NewIdent(i.receiverName), Sel: ast.NewIdent(errorClassTagKeyFieldName)}
+	stmts, class := failure.Class(i.receiverName)
+	return append(stmts, recordWithTagsStmt(i.statsPackageAlias, i.tagPackageAlias, i.ctxFieldName,
+		upsertTagExpr(i.tagPackageAlias, errorClassTagKey, class),
+		measurement))
+}
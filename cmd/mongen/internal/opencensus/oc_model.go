@@ -16,18 +16,32 @@ type packageAliases struct {
 }
 
 type opencensusModel struct {
+	cfg         commonbuilders.ModelConfig
 	fileBuilder *astgen.File
 	structName  string
+	strct       *astgen.Struct
+	labels      *commonbuilders.Labels
+	labelsName  string
+	buckets     *commonbuilders.BucketGroups
+	toggles     *astgen.Toggles
+	constructor *ocConstructorBuilder
+	// metrics is the type that holds the measures expected by the
+	// constructor, if it is generated.
+	metrics *commonbuilders.MetricsStruct
 
 	packageAliases packageAliases
+	options        options
 }
 
-func NewOpencensusModel(interfacePath, interfaceName, structName, targetPkg string) *opencensusModel {
-	file := astgen.NewFile(targetPkg)
+func NewOpencensusModel(cfg commonbuilders.ModelConfig) *opencensusModel {
+	file := astgen.NewFile(cfg.TargetPkg, cfg.TargetPath)
 
 	m := &opencensusModel{
+		cfg:         cfg,
 		fileBuilder: file,
-		structName:  structName,
+		structName:  cfg.StructName,
+		labelsName:  commonbuilders.DerivedName("", cfg.ConstructorName, "Labels"),
+		options:     newOptions(cfg),
 		packageAliases: packageAliases{
 			contextPkg: file.AddImport("", "context"),
 			timePkg:    file.AddImport("", "time"),
@@ -36,31 +50,177 @@ func NewOpencensusModel(interfacePath, interfaceName, structName, targetPkg stri
 		},
 	}
 
-	sourcePackageAlias := file.AddImport("", interfacePath)
+	m.labels = commonbuilders.NewLabels(m, cfg)
+	m.buckets = commonbuilders.NewBucketGroups(cfg)
+	sourcePackageAlias := file.AddImport("", cfg.InterfacePath)
 
-	strct := astgen.NewStruct(structName)
-	strct.AddField("next", sourcePackageAlias, interfaceName)
+	strct := astgen.NewStruct(cfg.StructName)
+	m.strct = strct
+	strct.AddField("next", sourcePackageAlias, cfg.InterfaceName)
 	strct.AddFieldWithType(commonbuilders.TotalOpsMetricName, pointerExpr(m.packageAliases.statsPkg, "Int64Measure"))
 	strct.AddFieldWithType(commonbuilders.FailedOpsMetricName, pointerExpr(m.packageAliases.statsPkg, "Int64Measure"))
 	strct.AddFieldWithType(commonbuilders.OpsDurationMetricName, pointerExpr(m.packageAliases.statsPkg, "Float64Measure"))
 	strct.AddFieldWithType(commonbuilders.ContextDecoratorFuncName, buildCtxFuncType(m.packageAliases.contextPkg))
+	if cfg.InFlightOps {
+		strct.AddFieldWithType(commonbuilders.InFlightOpsMetricName, pointerExpr(m.packageAliases.statsPkg, "Int64Measure"))
+	}
+	if cfg.Streams {
+		strct.AddFieldWithType(commonbuilders.StreamSizeMetricName, pointerExpr(m.packageAliases.statsPkg, "Int64Measure"))
+	}
+	if cfg.ResultSize {
+		strct.AddFieldWithType(commonbuilders.ResultSizeMetricName, pointerExpr(m.packageAliases.statsPkg, "Int64Measure"))
+	}
 	file.AppendDeclaration(strct)
 
 	constructorBuilder := newOCConstructorBuilder(
-		m.packageAliases.statsPkg, m.packageAliases.contextPkg, sourcePackageAlias, interfaceName)
+		m.packageAliases.statsPkg, m.packageAliases.contextPkg, m.packageAliases.tagPkg, sourcePackageAlias, cfg.InterfaceName, cfg.StructName, cfg.ConstructorName,
+		m.labels, m.buckets, m.options)
 	file.AppendDeclaration(constructorBuilder)
+	m.constructor = constructorBuilder
+	file.AppendDeclaration(tagContextMethod{
+		structName:          cfg.StructName,
+		contextPackageAlias: m.packageAliases.contextPkg,
+		tagPackageAlias:     m.packageAliases.tagPkg,
+	})
+
+	if cfg.ClassifyErrors {
+		strct.AddFieldWithType(errorClassTagKeyFieldName, astgen.QualifiedName(m.packageAliases.tagPkg, "Key"))
+	}
+	if cfg.RecordOutcome {
+		strct.AddFieldWithType(outcomeTagKeyFieldName, astgen.QualifiedName(m.packageAliases.tagPkg, "Key"))
+	}
+
+	if cfg.Metrics {
+		m.addMetrics(cfg, constructorBuilder, sourcePackageAlias)
+	}
+
+	if cfg.ClassifyErrors {
+		commonbuilders.AddErrorClassField(strct)
+		commonbuilders.AddLabelGuardFields(strct, file.AddImport("", "sync"))
+		file.AppendDeclaration(commonbuilders.ErrorClassMethod{
+			StructName:          cfg.StructName,
+			ErrorsPackageAlias:  file.AddImport("", "errors"),
+			ContextPackageAlias: m.packageAliases.contextPkg,
+			Key:                 m.labels.Keys().ErrorClass,
+		})
+		file.AppendDeclaration(commonbuilders.LabelGuardMethod{StructName: cfg.StructName})
+	}
+
+	if cfg.Toggles {
+		m.toggles = astgen.NewToggles(file.AddImport("", astgen.TogglePackage), cfg.InterfaceName)
+		constructorBuilder.toggles = m.toggles
+	}
 
 	return m
 }
 
+// addMetrics adds the declarations that create the measures expected by the
+// constructor and their views.
+func (m *opencensusModel) addMetrics(cfg commonbuilders.ModelConfig, constructor *ocConstructorBuilder, sourcePackageAlias string) {
+	typeName := commonbuilders.MetricsTypeName(cfg.ConstructorName)
+	viewPkg := m.AddImport("", "go.opencensus.io/stats/view")
+	field := func(name string, typ ast.Expr) *ast.Field {
+		return &ast.Field{Names: []*ast.Ident{ast.NewIdent(name)}, Type: typ}
+	}
+	fields := []*ast.Field{
+		field(commonbuilders.TotalOpsMetric.Field(), pointerExpr(m.packageAliases.statsPkg, "Int64Measure")),
+		field(commonbuilders.FailedOpsMetric.Field(), pointerExpr(m.packageAliases.statsPkg, "Int64Measure")),
+		field(commonbuilders.OpsDurationMetric.Field(), pointerExpr(m.packageAliases.statsPkg, "Float64Measure")),
+	}
+	if cfg.InFlightOps {
+		fields = append(fields, field(commonbuilders.InFlightOpsMetric.Field(), pointerExpr(m.packageAliases.statsPkg, "Int64Measure")))
+	}
+	if cfg.Streams {
+		fields = append(fields, field(commonbuilders.StreamSizeMetric.Field(), pointerExpr(m.packageAliases.statsPkg, "Int64Measure")))
+	}
+	if cfg.ResultSize {
+		fields = append(fields, field(commonbuilders.ResultSizeMetric.Field(), pointerExpr(m.packageAliases.statsPkg, "Int64Measure")))
+	}
+	fields = append(fields, field("Views", &ast.ArrayType{Elt: pointerExpr(viewPkg, "View")}))
+
+	m.metrics = &commonbuilders.MetricsStruct{Name: typeName, Fields: fields}
+	m.fileBuilder.AppendDeclaration(m.metrics)
+	m.fileBuilder.AppendDeclaration(newOCMetricsBuilder(m.packageAliases, viewPkg, typeName, commonbuilders.MetricsConstructorName(cfg.ConstructorName), m.labels, m.buckets, m.options))
+	m.fileBuilder.AppendDeclaration(commonbuilders.MetricsWrapMethod{
+		TypeName:        typeName,
+		ConstructorName: cfg.ConstructorName,
+		Constructor:     constructor,
+		InterfaceType:   astgen.QualifiedName(sourcePackageAlias, cfg.InterfaceName),
+		Buckets:         m.buckets,
+	})
+}
+
 func (m *opencensusModel) AddImport(pkgName, location string) string {
 	return m.fileBuilder.AddImport(pkgName, location)
 }
 
 func (m *opencensusModel) AddMethod(method *astgen.MethodConfig) error {
-	mmb := newOCMonitoringMethodBuilder(m.structName, method, m.packageAliases)
+	hadLabels, guarded := !m.labels.Empty(), m.labels.Guarded()
+	if err := m.labels.AddMethod(method); err != nil {
+		return err
+	}
+	if !hadLabels && !m.labels.Empty() {
+		if !guarded {
+			commonbuilders.AddLabelGuardFields(m.strct, m.AddImport("", "sync"))
+		}
+		m.strct.AddFieldWithType(labelTagKeysFieldName, &ast.ArrayType{Elt: astgen.QualifiedName(m.packageAliases.tagPkg, "Key")})
+		m.fileBuilder.AppendDeclaration(commonbuilders.LabelNamesVar{Name: m.labelsName, Labels: m.labels})
+		if !guarded {
+			m.fileBuilder.AppendDeclaration(commonbuilders.LabelGuardMethod{StructName: m.structName})
+		}
+	}
+
+	bucketGroup, first, err := m.buckets.AddMethod(method)
+	if err != nil {
+		return err
+	}
+	durationMetric := commonbuilders.DurationMetric(bucketGroup)
+	if first {
+		measure := pointerExpr(m.packageAliases.statsPkg, "Float64Measure")
+		m.strct.AddFieldWithType(durationMetric.Param, measure)
+		if m.metrics != nil {
+			// The views stay the last field.
+			last := len(m.metrics.Fields) - 1
+			field := &ast.Field{Names: []*ast.Ident{ast.NewIdent(durationMetric.Field())}, Type: measure}
+			m.metrics.Fields = append(m.metrics.Fields[:last], field, m.metrics.Fields[last])
+		}
+	}
 
-	m.fileBuilder.AppendDeclaration(mmb)
+	m.strct.AddFieldWithType(operationTagFieldName(method.MethodName), astgen.QualifiedName(m.packageAliases.tagPkg, "Mutator"))
+	m.strct.AddFieldWithType(operationTagsFieldName(method.MethodName), &ast.StarExpr{X: astgen.QualifiedName(m.packageAliases.tagPkg, "Map")})
+	operation, err := m.cfg.OperationName(method.MethodName)
+	if err != nil {
+		return err
+	}
+	m.constructor.AddMethod(method.MethodName, operation)
+
+	mmb := newOCMonitoringMethodBuilder(m.structName, method, m.packageAliases, m.labels, m.options)
+	mmb.SetOpsDuration(durationMetric.Param)
+	if m.options.streams {
+		if stream, ok := commonbuilders.FindStreamResult(method, m.fileBuilder.ImportAlias); ok {
+			mmb.SetStream(stream, m.AddImport("", commonbuilders.StreamPackage))
+			if stream.Wrap == "Seq" || stream.Wrap == "Seq2" {
+				m.constructor.MeasureSequences()
+			}
+		}
+	}
+	if m.cfg.ResultSize {
+		size, ok, err := commonbuilders.FindSizeResult(method)
+		if err != nil {
+			return err
+		}
+		if ok {
+			size.AddImports(m.AddImport)
+			mmb.SetSize(size)
+		}
+	}
+
+	var decl astgen.DeclarationBuilder = mmb
+	if m.toggles != nil {
+		m.toggles.AddMethod(m.strct, method.MethodName)
+		decl = m.toggles.Guard(method, mmb)
+	}
+	m.fileBuilder.AppendDeclaration(decl)
 	return nil
 }
 
@@ -0,0 +1,576 @@
+package otel
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
+	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/transformation"
+)
+
+// outcome attribute values recorded with the failed operations and the
+// durations, the same as those of the other providers
+const (
+	outcomeSuccess = "success"
+	outcomeError   = "error"
+)
+
+// suffixes of the names of the struct fields that hold the attribute options
+// of a method, with no outcome, the success outcome and the error outcome
+const (
+	attrsFieldSuffix        = "Attrs"
+	successAttrsFieldSuffix = "SuccessAttrs"
+	errorAttrsFieldSuffix   = "ErrorAttrs"
+)
+
+// attrsFieldName returns the name of the struct field that holds the
+// attribute option of a method with the suffix.
+func attrsFieldName(methodName, suffix string) string {
+	return lowerFirst(methodName) + suffix
+}
+
+type constructorBuilder struct {
+	metricPackageName    string
+	attributePackageName string
+	interfacePackageName string
+	interfaceName        string
+	structName           string
+	constructorName      string
+	resultSize           bool
+	keys                 commonbuilders.LabelKeys
+	// toggles are the runtime controls of the methods, if enabled.
+	toggles *astgen.Toggles
+
+	methodNames []string
+	operations  []string
+}
+
+func newConstructorBuilder(metricPackageName, attributePackageName, packageName, interfaceName, structName, constructorName string, resultSize bool, keys commonbuilders.LabelKeys) *constructorBuilder {
+	return &constructorBuilder{
+		metricPackageName:    metricPackageName,
+		attributePackageName: attributePackageName,
+		interfacePackageName: packageName,
+		interfaceName:        interfaceName,
+		structName:           structName,
+		constructorName:      constructorName,
+		resultSize:           resultSize,
+		keys:                 keys,
+	}
+}
+
+// AddMethod makes the constructor create the attribute options of the
+// method.
+func (c *constructorBuilder) AddMethod(methodName, operation string) {
+	c.methodNames = append(c.methodNames, methodName)
+	c.operations = append(c.operations, operation)
+}
+
+// instrumentNames returns the names of the instruments expected by the
+// constructor, in the order of its parameters.
+func instrumentNames(resultSize bool) []ast.Expr {
+	names := []ast.Expr{
+		ast.NewIdent(commonbuilders.TotalOpsMetricName),
+		ast.NewIdent(commonbuilders.FailedOpsMetricName),
+		ast.NewIdent(commonbuilders.OpsDurationMetricName),
+	}
+	if resultSize {
+		names = append(names, ast.NewIdent(commonbuilders.ResultSizeMetricName))
+	}
+	return names
+}
+
+// Build builds the constructor that wraps an implementation with pre-built
+// instruments. The attribute options of the methods are created once, by the
+// constructor:
+//
+//	return &monitoringService{..., doWorkAttrs: metric.WithAttributeSet(attribute.NewSet(attribute.String("operation", "do_work")))}
+func (c *constructorBuilder) Build() ast.Decl {
+	var elts []ast.Expr
+	for _, name := range append([]ast.Expr{ast.NewIdent("next")}, instrumentNames(c.resultSize)...) {
+		elts = append(elts, &ast.KeyValueExpr{Key: name, Value: name})
+	}
+	if c.toggles != nil {
+		elts = append(elts, c.toggles.Init()...)
+	}
+
+	attribute := func(key, value string) ast.Expr {
+		return &ast.CallExpr{
+			Fun:  astgen.QualifiedName(c.attributePackageName, "String"),
+			Args: []ast.Expr{stringLit(key), stringLit(value)},
+		}
+	}
+	withAttributeSet := func(attrs ...ast.Expr) ast.Expr {
+		return &ast.CallExpr{
+			Fun: astgen.QualifiedName(c.metricPackageName, "WithAttributeSet"),
+			Args: []ast.Expr{
+				&ast.CallExpr{
+					Fun:  astgen.QualifiedName(c.attributePackageName, "NewSet"),
+					Args: attrs,
+				},
+			},
+		}
+	}
+	for i, methodName := range c.methodNames {
+		operation := attribute(c.keys.Operation, c.operations[i])
+		options := []struct {
+			suffix string
+			attrs  []ast.Expr
+		}{
+			{attrsFieldSuffix, []ast.Expr{operation}},
+			{successAttrsFieldSuffix, []ast.Expr{operation, attribute(c.keys.Outcome, outcomeSuccess)}},
+			{errorAttrsFieldSuffix, []ast.Expr{operation, attribute(c.keys.Outcome, outcomeError)}},
+		}
+		for _, option := range options {
+			elts = append(elts, &ast.KeyValueExpr{
+				Key:   ast.NewIdent(attrsFieldName(methodName, option.suffix)),
+				Value: withAttributeSet(option.attrs...),
+			})
+		}
+	}
+
+	funcBody := &ast.BlockStmt{
+		List: []ast.Stmt{
+			&ast.ReturnStmt{
+				Results: []ast.Expr{
+					&ast.UnaryExpr{
+						Op: token.AND,
+						X: &ast.CompositeLit{
+							Type: ast.NewIdent(c.structName),
+							Elts: elts,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	params := []*ast.Field{
+		{
+			Names: []*ast.Ident{ast.NewIdent("next")},
+			Type:  astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
+		},
+		{
+			Names: []*ast.Ident{
+				ast.NewIdent(commonbuilders.TotalOpsMetricName),
+				ast.NewIdent(commonbuilders.FailedOpsMetricName),
+			},
+			Type: astgen.QualifiedName(c.metricPackageName, "Int64Counter"),
+		},
+		{
+			Names: []*ast.Ident{ast.NewIdent(commonbuilders.OpsDurationMetricName)},
+			Type:  astgen.QualifiedName(c.metricPackageName, "Float64Histogram"),
+		},
+	}
+	if c.resultSize {
+		params = append(params, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(commonbuilders.ResultSizeMetricName)},
+			Type:  astgen.QualifiedName(c.metricPackageName, "Int64Histogram"),
+		})
+	}
+	if c.toggles != nil {
+		params = append(params, c.toggles.Param())
+	}
+
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{
+					Text: fmt.Sprintf("// %s creates new monitoring middleware.", c.constructorName),
+				},
+			},
+		},
+		Name: ast.NewIdent(c.constructorName),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: params,
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{
+					{
+						Type: astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
+					},
+				},
+			},
+		},
+		Body: funcBody,
+	}
+}
+
+// meterConstructorBuilder builds a constructor that creates the instruments
+// from a metric.Meter.
+type meterConstructorBuilder struct {
+	metricPackageName    string
+	interfacePackageName string
+	interfaceName        string
+	constructorName      string
+	resultSize           bool
+	// toggles are the runtime controls of the methods, if enabled.
+	toggles *astgen.Toggles
+}
+
+func newMeterConstructorBuilder(metricPackageName, packageName, interfaceName, constructorName string, resultSize bool) *meterConstructorBuilder {
+	return &meterConstructorBuilder{
+		metricPackageName:    metricPackageName,
+		interfacePackageName: packageName,
+		interfaceName:        interfaceName,
+		constructorName:      constructorName,
+		resultSize:           resultSize,
+	}
+}
+
+// Build builds the constructor that creates the instruments with the meter
+// and wraps an implementation with them.
+func (c *meterConstructorBuilder) Build() ast.Decl {
+	metricOption := func(name, value string) ast.Expr {
+		return &ast.CallExpr{
+			Fun:  astgen.QualifiedName(c.metricPackageName, name),
+			Args: []ast.Expr{stringLit(value)},
+		}
+	}
+
+	// name, err := meter.Kind("metric_name", options...)
+	// if err != nil { return nil, err }
+	createInstrument := func(name, kind, metricName string, options ...ast.Expr) []ast.Stmt {
+		return []ast.Stmt{
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{ast.NewIdent(name), ast.NewIdent("err")},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{
+					&ast.CallExpr{
+						Fun: &ast.SelectorExpr{
+							X:   ast.NewIdent("meter"),
+							Sel: ast.NewIdent(kind),
+						},
+						Args: append([]ast.Expr{stringLit(metricName)}, options...),
+					},
+				},
+			},
+			&ast.IfStmt{
+				Cond: &ast.BinaryExpr{
+					X:  ast.NewIdent("err"),
+					Op: token.NEQ,
+					Y:  ast.NewIdent("nil"),
+				},
+				Body: &ast.BlockStmt{
+					List: []ast.Stmt{
+						&ast.ReturnStmt{
+							Results: []ast.Expr{ast.NewIdent("nil"), ast.NewIdent("err")},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	var stmts []ast.Stmt
+	stmts = append(stmts, createInstrument(commonbuilders.TotalOpsMetricName, "Int64Counter", "total_ops",
+		metricOption("WithDescription", "Total number of operations."))...)
+	stmts = append(stmts, createInstrument(commonbuilders.FailedOpsMetricName, "Int64Counter", "failed_ops",
+		metricOption("WithDescription", "Number of failed operations."))...)
+	stmts = append(stmts, createInstrument(commonbuilders.OpsDurationMetricName, "Float64Histogram", "ops_duration",
+		metricOption("WithDescription", "Duration of operations."),
+		metricOption("WithUnit", "s"))...)
+	if c.resultSize {
+		stmts = append(stmts, createInstrument(commonbuilders.ResultSizeMetricName, "Int64Histogram", commonbuilders.ResultSizeMetric.Name,
+			metricOption("WithDescription", commonbuilders.ResultSizeMetric.Help))...)
+	}
+
+	// return NewMonitoringX(next, totalOps, failedOps, opsDuration), nil
+	args := append([]ast.Expr{ast.NewIdent("next")}, instrumentNames(c.resultSize)...)
+	params := []*ast.Field{
+		{
+			Names: []*ast.Ident{ast.NewIdent("next")},
+			Type:  astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
+		},
+		{
+			Names: []*ast.Ident{ast.NewIdent("meter")},
+			Type:  astgen.QualifiedName(c.metricPackageName, "Meter"),
+		},
+	}
+	if c.toggles != nil {
+		args = append(args, ast.NewIdent(astgen.TogglesParamName))
+		params = append(params, c.toggles.Param())
+	}
+	stmts = append(stmts, &ast.ReturnStmt{
+		Results: []ast.Expr{
+			&ast.CallExpr{
+				Fun:  ast.NewIdent(c.constructorName),
+				Args: args,
+			},
+			ast.NewIdent("nil"),
+		},
+	})
+
+	funcName := c.constructorName + "FromMeter"
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{
+					Text: fmt.Sprintf("// %s creates new monitoring middleware with instruments created by meter.", funcName),
+				},
+			},
+		},
+		Name: ast.NewIdent(funcName),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: params,
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{
+					{Type: astgen.QualifiedName(c.interfacePackageName, c.interfaceName)},
+					{Type: ast.NewIdent("error")},
+				},
+			},
+		},
+		Body: &ast.BlockStmt{List: stmts},
+	}
+}
+
+// monitoringMethodBuilder is responsible for creating a method that implements
+// the original method from the interface and does all the measurement and
+// recording logic using OpenTelemetry.
+type monitoringMethodBuilder struct {
+	methodConfig *astgen.MethodConfig
+	method       *astgen.Method
+
+	// selectors for the struct members
+	totalOps    *ast.SelectorExpr
+	failedOps   *ast.SelectorExpr
+	opsDuration *ast.SelectorExpr
+	resultSize  *ast.SelectorExpr
+
+	// size is the result whose size is recorded, if any.
+	size *commonbuilders.SizeResult
+	// panics makes the method record a panic of the call.
+	panics bool
+
+	// selectors for the attribute options of the method, with no outcome,
+	// the success outcome and the error outcome
+	attrs        *ast.SelectorExpr
+	successAttrs *ast.SelectorExpr
+	errorAttrs   *ast.SelectorExpr
+
+	packageAliases packageAliases
+}
+
+func newMonitoringMethodBuilder(structName string, methodConfig *astgen.MethodConfig, aliases packageAliases) *monitoringMethodBuilder {
+	receiverName := "m"
+	method := astgen.NewMethod(methodConfig.MethodName, receiverName, structName)
+
+	selexpr := func(fieldName string) *ast.SelectorExpr {
+		return &ast.SelectorExpr{
+			X:   ast.NewIdent(receiverName),
+			Sel: ast.NewIdent(fieldName),
+		}
+	}
+
+	return &monitoringMethodBuilder{
+		methodConfig:   methodConfig,
+		method:         method,
+		totalOps:       selexpr(commonbuilders.TotalOpsMetricName),
+		failedOps:      selexpr(commonbuilders.FailedOpsMetricName),
+		opsDuration:    selexpr(commonbuilders.OpsDurationMetricName),
+		resultSize:     selexpr(commonbuilders.ResultSizeMetricName),
+		attrs:          selexpr(attrsFieldName(methodConfig.MethodName, attrsFieldSuffix)),
+		successAttrs:   selexpr(attrsFieldName(methodConfig.MethodName, successAttrsFieldSuffix)),
+		errorAttrs:     selexpr(attrsFieldName(methodConfig.MethodName, errorAttrsFieldSuffix)),
+		packageAliases: aliases,
+	}
+}
+
+// RecordPanics makes the method record a panic of the call as a failure,
+// before raising it again.
+func (b *monitoringMethodBuilder) RecordPanics() {
+	b.panics = true
+}
+
+// SetSize makes the method record the size of the result.
+func (b *monitoringMethodBuilder) SetSize(size commonbuilders.SizeResult) {
+	b.size = &size
+}
+
+// Build builds the monitoring method.
+func (b *monitoringMethodBuilder) Build() ast.Decl {
+	b.method.SetType(&ast.FuncType{
+		Params: &ast.FieldList{
+			List: b.methodConfig.MethodParams,
+		},
+		Results: &ast.FieldList{
+			List: transformation.FieldsAsAnonymous(b.methodConfig.MethodResults),
+		},
+	})
+
+	ctxVarName := "ctx"
+
+	// Use the context of the method, if any
+	//   ctx := arg1 or ctx := context.Background()
+	b.method.AddStatement(contextParam{
+		ctxFieldName:    ctxVarName,
+		ctxPackageAlias: b.packageAliases.contextPkg,
+		methodConfig:    b.methodConfig,
+	}.Build())
+
+	// Count the operation before invoking it
+	//   m.totalOps.Add(ctx, 1, m.methodAttrs)
+	b.method.AddStatement(b.record(b.totalOps, "Add", &ast.BasicLit{Kind: token.INT, Value: "1"}, b.attrs))
+
+	// Capture current time
+	//   _start := time.Now()
+	b.method.AddStatement(commonbuilders.RecordStartTime(b.packageAliases.timePkg).Build())
+
+	// Record a panic of the call as a failure, and raise it again
+	//   defer func() {
+	//     if _panic := recover(); _panic != nil {
+	//       m.failedOps.Add(ctx, 1, m.methodErrorAttrs)
+	//       m.opsDuration.Record(ctx, time.Since(_start).Seconds(), m.methodErrorAttrs)
+	//       panic(_panic)
+	//     }
+	//   }()
+	if b.panics {
+		b.method.AddStatement(astgen.Recover(
+			b.countFailure(),
+			b.recordDuration(b.errorAttrs),
+		))
+	}
+
+	// Invoke the wrapped method
+	//   result1, result2 := m.next.Method(arg1, arg2)
+	methodInvocation := commonbuilders.NewMethodInvocation(b.methodConfig)
+	methodInvocation.SetReceiver(&ast.SelectorExpr{
+		X:   ast.NewIdent("m"),
+		Sel: ast.NewIdent("next"),
+	})
+	b.method.AddStatement(methodInvocation.Build())
+
+	outcome := ast.Expr(b.successAttrs)
+
+	// Determine the outcome and count failures
+	//   _outcome := m.methodSuccessAttrs
+	//   if err != nil {
+	//     _outcome = m.methodErrorAttrs
+	//     m.failedOps.Add(ctx, 1, m.methodErrorAttrs)
+	//   }
+	if failure := commonbuilders.NewFailure(b.methodConfig); failure.Possible() {
+		outcome = ast.NewIdent("_outcome")
+		b.method.AddStatement(&ast.AssignStmt{
+			Lhs: []ast.Expr{outcome},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{b.successAttrs},
+		})
+		b.method.AddStatement(&ast.IfStmt{
+			Cond: failure.Cond(),
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{
+					&ast.AssignStmt{
+						Lhs: []ast.Expr{outcome},
+						Tok: token.ASSIGN,
+						Rhs: []ast.Expr{b.errorAttrs},
+					},
+					b.countFailure(),
+				},
+			},
+		})
+	}
+
+	// Record the size of the result, if the call did not fail
+	//   if err == nil {
+	//     m.resultSize.Record(ctx, int64(len(result1)), m.methodAttrs)
+	//   }
+	if b.size != nil {
+		b.method.AddStatements(commonbuilders.RecordSize(b.methodConfig, *b.size, func(size ast.Expr) []ast.Stmt {
+			value := &ast.CallExpr{Fun: ast.NewIdent("int64"), Args: []ast.Expr{size}}
+			return []ast.Stmt{b.record(b.resultSize, "Record", value, b.attrs)}
+		}))
+	}
+
+	// Record the duration of the operation
+	//   m.opsDuration.Record(ctx, time.Since(_start).Seconds(), _outcome)
+	b.method.AddStatement(b.recordDuration(outcome))
+
+	// Return the results
+	//   return result1, result2
+	b.method.AddStatements(commonbuilders.NewReturnResults(b.methodConfig).Stmts())
+
+	return b.method.Build()
+}
+
+// countFailure builds the statement that counts a failed operation, with the
+// error outcome.
+func (b *monitoringMethodBuilder) countFailure() ast.Stmt {
+	return b.record(b.failedOps, "Add", &ast.BasicLit{Kind: token.INT, Value: "1"}, b.errorAttrs)
+}
+
+// recordDuration builds the statement that records the duration of the
+// operation with the attribute option of its outcome.
+func (b *monitoringMethodBuilder) recordDuration(attrs ast.Expr) ast.Stmt {
+	return b.record(b.opsDuration, "Record", b.secondsSinceStart(), attrs)
+}
+
+// record builds a statement that records value with the instrument, e.g.
+// m.totalOps.Add(ctx, 1, attrs).
+func (b *monitoringMethodBuilder) record(instrument *ast.SelectorExpr, method string, value, options ast.Expr) ast.Stmt {
+	return &ast.ExprStmt{
+		X: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   instrument,
+				Sel: ast.NewIdent(method),
+			},
+			Args: []ast.Expr{ast.NewIdent("ctx"), value, options},
+		},
+	}
+}
+
+func (b *monitoringMethodBuilder) secondsSinceStart() ast.Expr {
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X: &ast.CallExpr{
+				Fun:  astgen.QualifiedName(b.packageAliases.timePkg, "Since"),
+				Args: []ast.Expr{ast.NewIdent("_start")},
+			},
+			Sel: ast.NewIdent("Seconds"),
+		},
+	}
+}
+
+type contextParam struct {
+	ctxFieldName    string
+	ctxPackageAlias string
+	methodConfig    *astgen.MethodConfig
+}
+
+// Build builds a context variable initialization. If the first parameter of
+// the method is a context it is used, otherwise context.Background() is.
+func (c contextParam) Build() ast.Stmt {
+	var rhs ast.Expr = &ast.CallExpr{
+		Fun: astgen.QualifiedName(c.ctxPackageAlias, "Background"),
+	}
+
+	if len(c.methodConfig.MethodParams) > 0 {
+		p1 := c.methodConfig.MethodParams[0]
+		if sel, ok := p1.Type.(*ast.SelectorExpr); ok && sel.Sel.String() == "Context" {
+			if id, ok := sel.X.(*ast.Ident); ok && id.String() == c.ctxPackageAlias {
+				rhs = p1.Names[0]
+			}
+		}
+	}
+
+	return &ast.AssignStmt{
+		Lhs: []ast.Expr{ast.NewIdent(c.ctxFieldName)},
+		Tok: token.DEFINE,
+		Rhs: []ast.Expr{rhs},
+	}
+}
+
+func stringLit(s string) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", s)}
+}
+
+// lowerFirst returns s with its first letter in lower case.
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
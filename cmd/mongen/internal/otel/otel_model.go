@@ -0,0 +1,119 @@
+package otel
+
+import (
+	"go/ast"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
+	"github.com/Bo0mer/gentools/pkg/astgen"
+)
+
+// packageAliases holds the aliases of all imported packages in the generated source file.
+type packageAliases struct {
+	contextPkg   string
+	timePkg      string
+	metricPkg    string
+	attributePkg string
+}
+
+type otelModel struct {
+	cfg         commonbuilders.ModelConfig
+	fileBuilder *astgen.File
+	structName  string
+	strct       *astgen.Struct
+	toggles     *astgen.Toggles
+	constructor *constructorBuilder
+
+	packageAliases packageAliases
+}
+
+func NewOtelModel(cfg commonbuilders.ModelConfig) *otelModel {
+	file := astgen.NewFile(cfg.TargetPkg, cfg.TargetPath)
+
+	m := &otelModel{
+		cfg:         cfg,
+		fileBuilder: file,
+		structName:  cfg.StructName,
+		packageAliases: packageAliases{
+			contextPkg:   file.AddImport("", "context"),
+			timePkg:      file.AddImport("", "time"),
+			metricPkg:    file.AddImport("", "go.opentelemetry.io/otel/metric"),
+			attributePkg: file.AddImport("", "go.opentelemetry.io/otel/attribute"),
+		},
+	}
+
+	sourcePackageAlias := file.AddImport("", cfg.InterfacePath)
+
+	metricType := func(name string) ast.Expr {
+		return astgen.QualifiedName(m.packageAliases.metricPkg, name)
+	}
+	strct := astgen.NewStruct(cfg.StructName)
+	strct.AddField("next", sourcePackageAlias, cfg.InterfaceName)
+	strct.AddFieldWithType(commonbuilders.TotalOpsMetricName, metricType("Int64Counter"))
+	strct.AddFieldWithType(commonbuilders.FailedOpsMetricName, metricType("Int64Counter"))
+	strct.AddFieldWithType(commonbuilders.OpsDurationMetricName, metricType("Float64Histogram"))
+	if cfg.ResultSize {
+		strct.AddFieldWithType(commonbuilders.ResultSizeMetricName, metricType("Int64Histogram"))
+	}
+	file.AppendDeclaration(strct)
+	m.strct = strct
+
+	constructor := newConstructorBuilder(
+		m.packageAliases.metricPkg, m.packageAliases.attributePkg, sourcePackageAlias,
+		cfg.InterfaceName, cfg.StructName, cfg.ConstructorName, cfg.ResultSize, cfg.LabelKeys())
+	file.AppendDeclaration(constructor)
+	m.constructor = constructor
+	meterConstructor := newMeterConstructorBuilder(
+		m.packageAliases.metricPkg, sourcePackageAlias, cfg.InterfaceName, cfg.ConstructorName, cfg.ResultSize)
+	file.AppendDeclaration(meterConstructor)
+
+	if cfg.Toggles {
+		m.toggles = astgen.NewToggles(file.AddImport("", astgen.TogglePackage), cfg.InterfaceName)
+		constructor.toggles = m.toggles
+		meterConstructor.toggles = m.toggles
+	}
+
+	return m
+}
+
+func (m *otelModel) AddImport(pkgName, location string) string {
+	return m.fileBuilder.AddImport(pkgName, location)
+}
+
+func (m *otelModel) AddMethod(method *astgen.MethodConfig) error {
+	operation, err := m.cfg.OperationName(method.MethodName)
+	if err != nil {
+		return err
+	}
+	for _, suffix := range []string{attrsFieldSuffix, successAttrsFieldSuffix, errorAttrsFieldSuffix} {
+		m.strct.AddFieldWithType(attrsFieldName(method.MethodName, suffix),
+			astgen.QualifiedName(m.packageAliases.metricPkg, "MeasurementOption"))
+	}
+	m.constructor.AddMethod(method.MethodName, operation)
+
+	mmb := newMonitoringMethodBuilder(m.structName, method, m.packageAliases)
+	if m.cfg.ResultSize {
+		size, ok, err := commonbuilders.FindSizeResult(method)
+		if err != nil {
+			return err
+		}
+		if ok {
+			size.AddImports(m.AddImport)
+			mmb.SetSize(size)
+		}
+	}
+	if m.cfg.Panics {
+		mmb.RecordPanics()
+	}
+
+	var decl astgen.DeclarationBuilder = mmb
+	if m.toggles != nil {
+		m.toggles.AddMethod(m.strct, method.MethodName)
+		decl = m.toggles.Guard(method, mmb)
+	}
+	m.fileBuilder.AppendDeclaration(decl)
+	return nil
+}
+
+func (m *otelModel) Build() *ast.File {
+	return m.fileBuilder.Build()
+}
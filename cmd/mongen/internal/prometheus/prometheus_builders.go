@@ -0,0 +1,687 @@
+package prometheus
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
+	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/transformation"
+)
+
+// methodFields holds the names of the struct fields that keep the collectors
+// pre-bound to the label values of a single method.
+type methodFields struct {
+	operation   string
+	totalOps    string
+	failedOps   string
+	opsDuration string
+	// durationVec is the name of the histogram vector that records the
+	// durations of the method, which depends on its bucket group.
+	durationVec string
+	// resultSize is empty if the size of the results of the method is not
+	// recorded.
+	resultSize string
+}
+
+func newMethodFields(methodName, operation, bucketGroup string) methodFields {
+	prefix := lowerFirst(methodName)
+	return methodFields{
+		operation:   operation,
+		totalOps:    prefix + "TotalOps",
+		failedOps:   prefix + "FailedOps",
+		opsDuration: prefix + "OpsDuration",
+		durationVec: commonbuilders.DurationMetric(bucketGroup).Param,
+	}
+}
+
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+type constructorBuilder struct {
+	prometheusPackageName string
+	interfacePackageName  string
+	interfaceName         string
+	structName            string
+	constructorName       string
+	buckets               *commonbuilders.BucketGroups
+	resultSize            bool
+	// toggles are the runtime controls of the methods, if enabled.
+	toggles *astgen.Toggles
+
+	methods []methodFields
+}
+
+func newConstructorBuilder(prometheusPackageName, packageName, interfaceName, structName, constructorName string, buckets *commonbuilders.BucketGroups, resultSize bool) *constructorBuilder {
+	return &constructorBuilder{
+		prometheusPackageName: prometheusPackageName,
+		interfacePackageName:  packageName,
+		interfaceName:         interfaceName,
+		structName:            structName,
+		constructorName:       constructorName,
+		buckets:               buckets,
+		resultSize:            resultSize,
+	}
+}
+
+// AddMethod makes the constructor bind the collectors for the method.
+func (c *constructorBuilder) AddMethod(fields methodFields) {
+	c.methods = append(c.methods, fields)
+}
+
+func (c *constructorBuilder) Build() ast.Decl {
+	elts := []ast.Expr{
+		&ast.KeyValueExpr{Key: ast.NewIdent("next"), Value: ast.NewIdent("next")},
+	}
+	for _, m := range c.methods {
+		elts = append(elts,
+			bindLabelValues(m.totalOps, commonbuilders.TotalOpsMetricName, m.operation),
+			bindLabelValues(m.failedOps, commonbuilders.FailedOpsMetricName, m.operation),
+			bindLabelValues(m.opsDuration, m.durationVec, m.operation),
+		)
+		if m.resultSize != "" {
+			elts = append(elts, bindLabelValues(m.resultSize, commonbuilders.ResultSizeMetricName, m.operation))
+		}
+	}
+	if c.toggles != nil {
+		elts = append(elts, c.toggles.Init()...)
+	}
+
+	funcBody := &ast.BlockStmt{
+		List: []ast.Stmt{
+			&ast.ReturnStmt{
+				Results: []ast.Expr{
+					&ast.UnaryExpr{
+						Op: token.AND,
+						X: &ast.CompositeLit{
+							Type: ast.NewIdent(c.structName),
+							Elts: elts,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	params := []*ast.Field{
+		&ast.Field{
+			Names: []*ast.Ident{ast.NewIdent("next")},
+			Type:  astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
+		},
+		&ast.Field{
+			Names: []*ast.Ident{
+				ast.NewIdent(commonbuilders.TotalOpsMetricName),
+				ast.NewIdent(commonbuilders.FailedOpsMetricName),
+			},
+			Type: pointerExpr(c.prometheusPackageName, "CounterVec"),
+		},
+		&ast.Field{
+			Names: histogramNames(c.buckets, c.resultSize),
+			Type:  pointerExpr(c.prometheusPackageName, "HistogramVec"),
+		},
+	}
+	if c.toggles != nil {
+		params = append(params, c.toggles.Param())
+	}
+
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{&ast.Comment{
+				Text: fmt.Sprintf("// %s creates new monitoring middleware.", c.constructorName),
+			}},
+		},
+		Name: ast.NewIdent(c.constructorName),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: params,
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{
+					&ast.Field{
+						Type: astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
+					},
+				},
+			},
+		},
+		Body: funcBody,
+	}
+}
+
+// histogramNames returns the names of the histogram vectors expected by the
+// constructor: the default duration vector, the duration vectors of the
+// bucket groups and the result size vector.
+func histogramNames(buckets *commonbuilders.BucketGroups, resultSize bool) []*ast.Ident {
+	names := []*ast.Ident{ast.NewIdent(commonbuilders.OpsDurationMetricName)}
+	for _, group := range buckets.Groups() {
+		names = append(names, ast.NewIdent(commonbuilders.DurationMetric(group).Param))
+	}
+	if resultSize {
+		names = append(names, ast.NewIdent(commonbuilders.ResultSizeMetricName))
+	}
+	return names
+}
+
+// collectorNames returns the names of the collectors expected by the
+// constructor, in the order of its parameters.
+func collectorNames(buckets *commonbuilders.BucketGroups, resultSize bool) []ast.Expr {
+	names := []ast.Expr{
+		ast.NewIdent(commonbuilders.TotalOpsMetricName),
+		ast.NewIdent(commonbuilders.FailedOpsMetricName),
+	}
+	for _, name := range histogramNames(buckets, resultSize) {
+		names = append(names, name)
+	}
+	return names
+}
+
+// bindLabelValues builds a struct field initializer that binds the vector
+// to the label value of an operation, e.g. field: vec.WithLabelValues("op").
+func bindLabelValues(field, vec, operation string) ast.Expr {
+	return &ast.KeyValueExpr{
+		Key: ast.NewIdent(field),
+		Value: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   ast.NewIdent(vec),
+				Sel: ast.NewIdent("WithLabelValues"),
+			},
+			Args: []ast.Expr{stringLit(operation)},
+		},
+	}
+}
+
+// collectorsBuilder builds a function that creates the collectors expected by
+// the constructor.
+type collectorsBuilder struct {
+	prometheusPackageName string
+	constructorName       string
+	// operationLabel is the name of the label that distinguishes the
+	// methods of the monitored interface.
+	operationLabel string
+	buckets        *commonbuilders.BucketGroups
+	resultSize     bool
+}
+
+func newCollectorsBuilder(prometheusPackageName, constructorName, operationLabel string, buckets *commonbuilders.BucketGroups, resultSize bool) *collectorsBuilder {
+	return &collectorsBuilder{
+		prometheusPackageName: prometheusPackageName,
+		constructorName:       constructorName,
+		operationLabel:        operationLabel,
+		buckets:               buckets,
+		resultSize:            resultSize,
+	}
+}
+
+func collectorsFuncName(constructorName string) string {
+	return constructorName + "Collectors"
+}
+
+func (c *collectorsBuilder) Build() ast.Decl {
+	newVec := func(name, vecType, optsType, metricName, help string, extraOpts ...ast.Expr) ast.Stmt {
+		opts := []ast.Expr{
+			&ast.KeyValueExpr{Key: ast.NewIdent("Namespace"), Value: ast.NewIdent("namespace")},
+			&ast.KeyValueExpr{Key: ast.NewIdent("Subsystem"), Value: ast.NewIdent("subsystem")},
+			&ast.KeyValueExpr{Key: ast.NewIdent("Name"), Value: stringLit(metricName)},
+			&ast.KeyValueExpr{Key: ast.NewIdent("Help"), Value: stringLit(help)},
+		}
+		opts = append(opts, extraOpts...)
+
+		return &ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent(name)},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{
+				&ast.CallExpr{
+					Fun: c.selector("New" + vecType),
+					Args: []ast.Expr{
+						&ast.CompositeLit{
+							Type: c.selector(optsType),
+							Elts: opts,
+						},
+						&ast.CompositeLit{
+							Type: &ast.ArrayType{Elt: ast.NewIdent("string")},
+							Elts: []ast.Expr{stringLit(c.operationLabel)},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	bucketsOpt := func(bounds []string) ast.Expr {
+		var buckets []ast.Expr
+		for _, bucket := range bounds {
+			buckets = append(buckets, &ast.BasicLit{Kind: token.FLOAT, Value: bucket})
+		}
+		return &ast.KeyValueExpr{
+			Key:   ast.NewIdent("Buckets"),
+			Value: &ast.CompositeLit{Type: &ast.ArrayType{Elt: ast.NewIdent("float64")}, Elts: buckets},
+		}
+	}
+
+	stmts := []ast.Stmt{
+		newVec(commonbuilders.TotalOpsMetricName, "CounterVec", "CounterOpts",
+			"total_ops", "Total number of operations."),
+		newVec(commonbuilders.FailedOpsMetricName, "CounterVec", "CounterOpts",
+			"failed_ops", "Number of failed operations."),
+		newVec(commonbuilders.OpsDurationMetricName, "HistogramVec", "HistogramOpts",
+			"ops_duration_seconds", "Duration of operations in seconds.",
+			&ast.KeyValueExpr{Key: ast.NewIdent("Buckets"), Value: c.selector("DefBuckets")}),
+	}
+	for _, group := range c.buckets.Groups() {
+		metric := commonbuilders.DurationMetric(group)
+		stmts = append(stmts, newVec(metric.Param, "HistogramVec", "HistogramOpts",
+			metric.Name, metric.Help, bucketsOpt(c.buckets.Bounds(group))))
+	}
+	if c.resultSize {
+		stmts = append(stmts, newVec(commonbuilders.ResultSizeMetricName, "HistogramVec", "HistogramOpts",
+			commonbuilders.ResultSizeMetric.Name, commonbuilders.ResultSizeMetric.Help,
+			bucketsOpt(commonbuilders.SizeBuckets)))
+	}
+	funcBody := &ast.BlockStmt{
+		List: append(stmts, &ast.ReturnStmt{Results: collectorNames(c.buckets, c.resultSize)}),
+	}
+
+	funcName := collectorsFuncName(c.constructorName)
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{&ast.Comment{
+				Text: fmt.Sprintf("// %s creates the collectors expected by %s.", funcName, c.constructorName),
+			}},
+		},
+		Name: ast.NewIdent(funcName),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: []*ast.Field{
+					&ast.Field{
+						Names: []*ast.Ident{ast.NewIdent("namespace"), ast.NewIdent("subsystem")},
+						Type:  ast.NewIdent("string"),
+					},
+				},
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{
+					&ast.Field{
+						Names: []*ast.Ident{
+							ast.NewIdent(commonbuilders.TotalOpsMetricName),
+							ast.NewIdent(commonbuilders.FailedOpsMetricName),
+						},
+						Type: pointerExpr(c.prometheusPackageName, "CounterVec"),
+					},
+					&ast.Field{
+						Names: histogramNames(c.buckets, c.resultSize),
+						Type:  pointerExpr(c.prometheusPackageName, "HistogramVec"),
+					},
+				},
+			},
+		},
+		Body: funcBody,
+	}
+}
+
+func (c *collectorsBuilder) selector(name string) *ast.SelectorExpr {
+	return &ast.SelectorExpr{
+		X:   ast.NewIdent(c.prometheusPackageName),
+		Sel: ast.NewIdent(name),
+	}
+}
+
+// registerBuilder builds a function that creates the collectors, registers
+// them and wraps an implementation with them.
+type registerBuilder struct {
+	prometheusPackageName string
+	interfacePackageName  string
+	interfaceName         string
+	constructorName       string
+	buckets               *commonbuilders.BucketGroups
+	resultSize            bool
+	// toggles are the runtime controls of the methods, if enabled.
+	toggles *astgen.Toggles
+}
+
+func newRegisterBuilder(prometheusPackageName, packageName, interfaceName, constructorName string, buckets *commonbuilders.BucketGroups, resultSize bool) *registerBuilder {
+	return &registerBuilder{
+		prometheusPackageName: prometheusPackageName,
+		interfacePackageName:  packageName,
+		interfaceName:         interfaceName,
+		constructorName:       constructorName,
+		buckets:               buckets,
+		resultSize:            resultSize,
+	}
+}
+
+func (r *registerBuilder) Build() ast.Decl {
+	metrics := collectorNames(r.buckets, r.resultSize)
+
+	// totalOps, failedOps, opsDuration := NewMonitoringXCollectors(namespace, subsystem)
+	createCollectors := &ast.AssignStmt{
+		Lhs: metrics,
+		Tok: token.DEFINE,
+		Rhs: []ast.Expr{
+			&ast.CallExpr{
+				Fun:  ast.NewIdent(collectorsFuncName(r.constructorName)),
+				Args: []ast.Expr{ast.NewIdent("namespace"), ast.NewIdent("subsystem")},
+			},
+		},
+	}
+
+	// collectors := []prometheus.Collector{totalOps, failedOps, opsDuration}
+	collectors := ast.NewIdent("collectors")
+	defineCollectors := &ast.AssignStmt{
+		Lhs: []ast.Expr{collectors},
+		Tok: token.DEFINE,
+		Rhs: []ast.Expr{
+			&ast.CompositeLit{
+				Type: &ast.ArrayType{
+					Elt: &ast.SelectorExpr{
+						X:   ast.NewIdent(r.prometheusPackageName),
+						Sel: ast.NewIdent("Collector"),
+					},
+				},
+				Elts: metrics,
+			},
+		},
+	}
+
+	// Unregister the collectors registered before the one that failed, so
+	// that the registration can be retried:
+	//
+	// for i, c := range collectors {
+	//   if err := reg.Register(c); err != nil {
+	//     for _, registered := range collectors[:i] {
+	//       reg.Unregister(registered)
+	//     }
+	//     return nil, err
+	//   }
+	// }
+	unregisterCollectors := &ast.RangeStmt{
+		Key:   ast.NewIdent("_"),
+		Value: ast.NewIdent("registered"),
+		Tok:   token.DEFINE,
+		X:     &ast.SliceExpr{X: collectors, High: ast.NewIdent("i")},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ExprStmt{
+					X: &ast.CallExpr{
+						Fun: &ast.SelectorExpr{
+							X:   ast.NewIdent("reg"),
+							Sel: ast.NewIdent("Unregister"),
+						},
+						Args: []ast.Expr{ast.NewIdent("registered")},
+					},
+				},
+			},
+		},
+	}
+	registerCollectors := &ast.RangeStmt{
+		Key:   ast.NewIdent("i"),
+		Value: ast.NewIdent("c"),
+		Tok:   token.DEFINE,
+		X:     collectors,
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.IfStmt{
+					Init: &ast.AssignStmt{
+						Lhs: []ast.Expr{ast.NewIdent("err")},
+						Tok: token.DEFINE,
+						Rhs: []ast.Expr{
+							&ast.CallExpr{
+								Fun: &ast.SelectorExpr{
+									X:   ast.NewIdent("reg"),
+									Sel: ast.NewIdent("Register"),
+								},
+								Args: []ast.Expr{ast.NewIdent("c")},
+							},
+						},
+					},
+					Cond: &ast.BinaryExpr{
+						X:  ast.NewIdent("err"),
+						Op: token.NEQ,
+						Y:  ast.NewIdent("nil"),
+					},
+					Body: &ast.BlockStmt{
+						List: []ast.Stmt{
+							unregisterCollectors,
+							&ast.ReturnStmt{
+								Results: []ast.Expr{ast.NewIdent("nil"), ast.NewIdent("err")},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// return NewMonitoringX(next, totalOps, failedOps, opsDuration), nil
+	args := append([]ast.Expr{ast.NewIdent("next")}, metrics...)
+	params := []*ast.Field{
+		&ast.Field{
+			Names: []*ast.Ident{ast.NewIdent("reg")},
+			Type: &ast.SelectorExpr{
+				X:   ast.NewIdent(r.prometheusPackageName),
+				Sel: ast.NewIdent("Registerer"),
+			},
+		},
+		&ast.Field{
+			Names: []*ast.Ident{ast.NewIdent("next")},
+			Type:  astgen.QualifiedName(r.interfacePackageName, r.interfaceName),
+		},
+		&ast.Field{
+			Names: []*ast.Ident{ast.NewIdent("namespace"), ast.NewIdent("subsystem")},
+			Type:  ast.NewIdent("string"),
+		},
+	}
+	if r.toggles != nil {
+		args = append(args, ast.NewIdent(astgen.TogglesParamName))
+		params = append(params, r.toggles.Param())
+	}
+	returnMiddleware := &ast.ReturnStmt{
+		Results: []ast.Expr{
+			&ast.CallExpr{
+				Fun:  ast.NewIdent(r.constructorName),
+				Args: args,
+			},
+			ast.NewIdent("nil"),
+		},
+	}
+
+	funcName := commonbuilders.DerivedName("Register", r.constructorName, "")
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				&ast.Comment{
+					Text: fmt.Sprintf("// %s creates new monitoring middleware and registers its collectors with reg.", funcName),
+				},
+				&ast.Comment{
+					Text: "// If a collector cannot be registered, the collectors registered before it are unregistered.",
+				},
+			},
+		},
+		Name: ast.NewIdent(funcName),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: params,
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{
+					&ast.Field{Type: astgen.QualifiedName(r.interfacePackageName, r.interfaceName)},
+					&ast.Field{Type: ast.NewIdent("error")},
+				},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{createCollectors, defineCollectors, registerCollectors, returnMiddleware},
+		},
+	}
+}
+
+// monitoringMethodBuilder is responsible for creating a method that implements
+// the original method from the interface and records its metrics using the
+// pre-bound collectors.
+type monitoringMethodBuilder struct {
+	methodConfig *astgen.MethodConfig
+	method       *astgen.Method
+
+	totalOps    *ast.SelectorExpr // selector for the struct member
+	failedOps   *ast.SelectorExpr // selector for the struct member
+	opsDuration *ast.SelectorExpr // selector for the struct member
+	resultSize  *ast.SelectorExpr // selector for the struct member
+
+	// size is the result whose size is recorded, if any.
+	size *commonbuilders.SizeResult
+	// panics makes the method record a panic of the call.
+	panics bool
+
+	timePackageAlias string
+}
+
+func newMonitoringMethodBuilder(structName string, methodConfig *astgen.MethodConfig, fields methodFields) *monitoringMethodBuilder {
+	method := astgen.NewMethod(methodConfig.MethodName, "m", structName)
+
+	selexpr := func(fieldName string) *ast.SelectorExpr {
+		return &ast.SelectorExpr{
+			X:   ast.NewIdent("m"),
+			Sel: ast.NewIdent(fieldName),
+		}
+	}
+
+	return &monitoringMethodBuilder{
+		methodConfig: methodConfig,
+		method:       method,
+		totalOps:     selexpr(fields.totalOps),
+		failedOps:    selexpr(fields.failedOps),
+		opsDuration:  selexpr(fields.opsDuration),
+		resultSize:   selexpr(fields.resultSize),
+	}
+}
+
+func (b *monitoringMethodBuilder) SetTimePackageAlias(alias string) {
+	b.timePackageAlias = alias
+}
+
+// SetSize makes the method record the size of the result.
+func (b *monitoringMethodBuilder) SetSize(size commonbuilders.SizeResult) {
+	b.size = &size
+}
+
+// RecordPanics makes the method record a panic of the call as a failure,
+// before raising it again.
+func (b *monitoringMethodBuilder) RecordPanics() {
+	b.panics = true
+}
+
+func (b *monitoringMethodBuilder) Build() ast.Decl {
+	b.method.SetType(&ast.FuncType{
+		Params: &ast.FieldList{
+			List: b.methodConfig.MethodParams,
+		},
+		Results: &ast.FieldList{
+			List: transformation.FieldsAsAnonymous(b.methodConfig.MethodResults),
+		},
+	})
+
+	// Increase total operations
+	//   m.doWorkTotalOps.Inc()
+	b.method.AddStatement(&ast.ExprStmt{X: callMethod(b.totalOps, "Inc")})
+
+	// Capture current time
+	//   _start := time.Now()
+	b.method.AddStatement(commonbuilders.RecordStartTime(b.timePackageAlias).Build())
+
+	// Record a panic of the call as a failure, and raise it again
+	//   defer func() {
+	//     if _panic := recover(); _panic != nil {
+	//       m.doWorkOpsDuration.Observe(time.Since(_start).Seconds())
+	//       m.doWorkFailedOps.Inc()
+	//       panic(_panic)
+	//     }
+	//   }()
+	if b.panics {
+		b.method.AddStatement(astgen.Recover(
+			b.observeDuration(),
+			&ast.ExprStmt{X: callMethod(b.failedOps, "Inc")},
+		))
+	}
+
+	// Invoke the wrapped method
+	//   result1, result2 := m.next.Method(arg1, arg2)
+	methodInvocation := commonbuilders.NewMethodInvocation(b.methodConfig)
+	methodInvocation.SetReceiver(&ast.SelectorExpr{
+		X:   ast.NewIdent("m"),
+		Sel: ast.NewIdent("next"),
+	})
+	b.method.AddStatement(methodInvocation.Build())
+
+	// Record the size of the result, if the call did not fail
+	//   if err == nil { m.doWorkResultSize.Observe(float64(len(result1))) }
+	if b.size != nil {
+		b.method.AddStatements(commonbuilders.RecordSize(b.methodConfig, *b.size, func(size ast.Expr) []ast.Stmt {
+			return []ast.Stmt{
+				&ast.ExprStmt{
+					X: callMethod(b.resultSize, "Observe", &ast.CallExpr{Fun: ast.NewIdent("float64"), Args: []ast.Expr{size}}),
+				},
+			}
+		}))
+	}
+
+	// Record operation duration
+	//   m.doWorkOpsDuration.Observe(time.Since(_start).Seconds())
+	b.method.AddStatement(b.observeDuration())
+
+	// Increase failed operations
+	//   if err != nil { m.doWorkFailedOps.Inc() }
+	if failure := commonbuilders.NewFailure(b.methodConfig); failure.Possible() {
+		b.method.AddStatement(&ast.IfStmt{
+			Cond: failure.Cond(),
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{&ast.ExprStmt{X: callMethod(b.failedOps, "Inc")}},
+			},
+		})
+	}
+
+	// Return the results
+	//   return result1, result2
+	b.method.AddStatements(commonbuilders.NewReturnResults(b.methodConfig).Stmts())
+
+	return b.method.Build()
+}
+
+func (b *monitoringMethodBuilder) observeDuration() ast.Stmt {
+	timeSinceCallExpr := &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   ast.NewIdent(b.timePackageAlias),
+			Sel: ast.NewIdent("Since"),
+		},
+		Args: []ast.Expr{ast.NewIdent("_start")},
+	}
+
+	return &ast.ExprStmt{
+		X: callMethod(b.opsDuration, "Observe", callMethod(timeSinceCallExpr, "Seconds")),
+	}
+}
+
+func callMethod(x ast.Expr, name string, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   x,
+			Sel: ast.NewIdent(name),
+		},
+		Args: args,
+	}
+}
+
+func pointerExpr(pkgName, typeName string) ast.Expr {
+	return &ast.StarExpr{
+		X: &ast.SelectorExpr{
+			X:   ast.NewIdent(pkgName),
+			Sel: ast.NewIdent(typeName),
+		},
+	}
+}
+
+func stringLit(s string) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", s)}
+}
@@ -0,0 +1,110 @@
+package prometheus
+
+import (
+	"go/ast"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
+	"github.com/Bo0mer/gentools/pkg/astgen"
+)
+
+type prometheusModel struct {
+	cfg         commonbuilders.ModelConfig
+	fileBuilder *astgen.File
+	structName  string
+	strct       *astgen.Struct
+	constructor *constructorBuilder
+	buckets     *commonbuilders.BucketGroups
+	toggles     *astgen.Toggles
+
+	prometheusPackageAlias string
+	timePackageAlias       string
+}
+
+func NewPrometheusModel(cfg commonbuilders.ModelConfig) *prometheusModel {
+	file := astgen.NewFile(cfg.TargetPkg, cfg.TargetPath)
+	strct := astgen.NewStruct(cfg.StructName)
+	file.AppendDeclaration(strct)
+
+	m := &prometheusModel{
+		cfg:         cfg,
+		fileBuilder: file,
+		structName:  cfg.StructName,
+		strct:       strct,
+		buckets:     commonbuilders.NewBucketGroups(cfg),
+	}
+	sourcePackageAlias := m.AddImport("", cfg.InterfacePath)
+	m.prometheusPackageAlias = m.AddImport("", "github.com/prometheus/client_golang/prometheus")
+	m.timePackageAlias = m.AddImport("", "time")
+
+	m.constructor = newConstructorBuilder(m.prometheusPackageAlias, sourcePackageAlias, cfg.InterfaceName, cfg.StructName, cfg.ConstructorName, m.buckets, cfg.ResultSize)
+	file.AppendDeclaration(m.constructor)
+	file.AppendDeclaration(newCollectorsBuilder(m.prometheusPackageAlias, cfg.ConstructorName, cfg.LabelKeys().Operation, m.buckets, cfg.ResultSize))
+	register := newRegisterBuilder(m.prometheusPackageAlias, sourcePackageAlias, cfg.InterfaceName, cfg.ConstructorName, m.buckets, cfg.ResultSize)
+	file.AppendDeclaration(register)
+
+	strct.AddField("next", sourcePackageAlias, cfg.InterfaceName)
+
+	if cfg.Toggles {
+		m.toggles = astgen.NewToggles(m.AddImport("", astgen.TogglePackage), cfg.InterfaceName)
+		m.constructor.toggles = m.toggles
+		register.toggles = m.toggles
+	}
+
+	return m
+}
+
+func (m *prometheusModel) AddImport(pkgName, location string) string {
+	return m.fileBuilder.AddImport(pkgName, location)
+}
+
+func (m *prometheusModel) AddMethod(method *astgen.MethodConfig) error {
+	operation, err := m.cfg.OperationName(method.MethodName)
+	if err != nil {
+		return err
+	}
+	var size commonbuilders.SizeResult
+	var sized bool
+	if m.cfg.ResultSize {
+		size, sized, err = commonbuilders.FindSizeResult(method)
+		if err != nil {
+			return err
+		}
+	}
+
+	bucketGroup, _, err := m.buckets.AddMethod(method)
+	if err != nil {
+		return err
+	}
+
+	fields := newMethodFields(method.MethodName, operation, bucketGroup)
+	m.strct.AddField(fields.totalOps, m.prometheusPackageAlias, "Counter")
+	m.strct.AddField(fields.failedOps, m.prometheusPackageAlias, "Counter")
+	m.strct.AddField(fields.opsDuration, m.prometheusPackageAlias, "Observer")
+	if sized {
+		fields.resultSize = lowerFirst(method.MethodName) + "ResultSize"
+		m.strct.AddField(fields.resultSize, m.prometheusPackageAlias, "Observer")
+	}
+	m.constructor.AddMethod(fields)
+
+	mmb := newMonitoringMethodBuilder(m.structName, method, fields)
+	mmb.SetTimePackageAlias(m.timePackageAlias)
+	if sized {
+		size.AddImports(m.AddImport)
+		mmb.SetSize(size)
+	}
+	if m.cfg.Panics {
+		mmb.RecordPanics()
+	}
+
+	var decl astgen.DeclarationBuilder = mmb
+	if m.toggles != nil {
+		m.toggles.AddMethod(m.strct, method.MethodName)
+		decl = m.toggles.Guard(method, mmb)
+	}
+	m.fileBuilder.AppendDeclaration(decl)
+	return nil
+}
+
+func (m *prometheusModel) Build() *ast.File {
+	return m.fileBuilder.Build()
+}
@@ -0,0 +1,347 @@
+package statsd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"slices"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
+	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/transformation"
+)
+
+// names used by the generated code
+const (
+	emitterParamName = "emitter"
+	tagsVarName      = "_tags"
+
+	// opsDurationName is the name of the timer of the operation duration.
+	// Timers are in milliseconds, so the name has no unit, unlike
+	// commonbuilders.OpsDurationMetric.
+	opsDurationName = "ops_duration"
+)
+
+// tagsFieldName returns the name of the field that holds the constant tags
+// of the method.
+func tagsFieldName(methodName string) string {
+	return lowerFirst(methodName) + "Tags"
+}
+
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+// tag returns the "key:value" tag literal.
+func tag(key, value string) ast.Expr {
+	return commonbuilders.StringLit(key + ":" + value)
+}
+
+type constructorBuilder struct {
+	statsdPackageName    string
+	interfacePackageName string
+	interfaceName        string
+	structName           string
+	constructorName      string
+	labels               *commonbuilders.Labels
+	classifyErrors       bool
+	// toggles are the runtime controls of the methods, if enabled.
+	toggles *astgen.Toggles
+
+	methodNames []string
+	operations  []string
+}
+
+func newConstructorBuilder(statsdPackageName, packageName, interfaceName, structName, constructorName string, labels *commonbuilders.Labels, classifyErrors bool) *constructorBuilder {
+	return &constructorBuilder{
+		statsdPackageName:    statsdPackageName,
+		interfacePackageName: packageName,
+		interfaceName:        interfaceName,
+		structName:           structName,
+		constructorName:      constructorName,
+		labels:               labels,
+		classifyErrors:       classifyErrors,
+	}
+}
+
+// AddMethod makes the constructor create the operation tag of the method.
+func (c *constructorBuilder) AddMethod(methodName, operation string) {
+	c.methodNames = append(c.methodNames, methodName)
+	c.operations = append(c.operations, operation)
+}
+
+// Build builds the constructor. The operation tag of every method is created
+// once, by the constructor:
+//
+//	return &monitoringService{next: next, emitter: emitter, doWorkTags: []string{"operation:do_work"}}
+func (c *constructorBuilder) Build() ast.Decl {
+	fieldInit := func(name string) ast.Expr {
+		return &ast.KeyValueExpr{Key: ast.NewIdent(name), Value: ast.NewIdent(name)}
+	}
+	elts := []ast.Expr{
+		fieldInit("next"),
+		fieldInit(emitterParamName),
+	}
+	// The tags are appended to when the method records more tags. Their
+	// capacity equals their length, so the appends never share memory.
+	for i, methodName := range c.methodNames {
+		elts = append(elts, &ast.KeyValueExpr{
+			Key: ast.NewIdent(tagsFieldName(methodName)),
+			Value: &ast.CompositeLit{
+				Type: &ast.ArrayType{Elt: ast.NewIdent("string")},
+				Elts: []ast.Expr{tag(c.labels.Keys().Operation, c.operations[i])},
+			},
+		})
+	}
+	if c.labels.Guarded() {
+		elts = append(elts, commonbuilders.LabelGuardInit()...)
+	}
+	if c.classifyErrors {
+		elts = append(elts, commonbuilders.ErrorClassInit())
+	}
+	if c.toggles != nil {
+		elts = append(elts, c.toggles.Init()...)
+	}
+
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{
+					Text: fmt.Sprintf("// %s creates new monitoring middleware.", c.constructorName),
+				},
+			},
+		},
+		Name: ast.NewIdent(c.constructorName),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: c.params(),
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{
+					{
+						Type: astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
+					},
+				},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ReturnStmt{
+					Results: []ast.Expr{
+						&ast.UnaryExpr{
+							Op: token.AND,
+							X: &ast.CompositeLit{
+								Type: ast.NewIdent(c.structName),
+								Elts: elts,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (c *constructorBuilder) params() []*ast.Field {
+	params := []*ast.Field{
+		{
+			Names: []*ast.Ident{ast.NewIdent("next")},
+			Type:  astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
+		},
+		{
+			Names: []*ast.Ident{ast.NewIdent(emitterParamName)},
+			Type:  astgen.QualifiedName(c.statsdPackageName, "Emitter"),
+		},
+	}
+	if c.labels.Guarded() {
+		params = append(params, commonbuilders.LabelGuardParam())
+	}
+	if c.classifyErrors {
+		params = append(params, commonbuilders.ErrorClassParam())
+	}
+	if c.toggles != nil {
+		params = append(params, c.toggles.Param())
+	}
+	return params
+}
+
+// monitoringMethodBuilder is responsible for creating a method that implements
+// the original method from the interface and emits its metrics.
+type monitoringMethodBuilder struct {
+	methodConfig     *astgen.MethodConfig
+	method           *astgen.Method
+	labels           *commonbuilders.Labels
+	classifyErrors   bool
+	timePackageAlias string
+
+	// statsdPackageAlias is the alias of the package that builds the tags.
+	statsdPackageAlias string
+
+	// size is the result whose size is recorded, if any.
+	size *commonbuilders.SizeResult
+	// panics makes the method record a panic of the call.
+	panics bool
+}
+
+func newMonitoringMethodBuilder(structName string, methodConfig *astgen.MethodConfig, labels *commonbuilders.Labels, classifyErrors bool, timePackageAlias, statsdPackageAlias string) *monitoringMethodBuilder {
+	return &monitoringMethodBuilder{
+		methodConfig:     methodConfig,
+		method:           astgen.NewMethod(methodConfig.MethodName, "m", structName),
+		labels:           labels,
+		classifyErrors:   classifyErrors,
+		timePackageAlias: timePackageAlias,
+
+		statsdPackageAlias: statsdPackageAlias,
+	}
+}
+
+// SetSize makes the method record the size of the result.
+func (b *monitoringMethodBuilder) SetSize(size commonbuilders.SizeResult) {
+	b.size = &size
+}
+
+// RecordPanics makes the method record a panic of the call as a failed
+// operation, before raising it again.
+func (b *monitoringMethodBuilder) RecordPanics() {
+	b.panics = true
+}
+
+// Build builds the monitoring method:
+//
+//	m.emitter.Count("total_ops", 1, m.doWorkTags...)
+//	_start := time.Now()
+//	result1, result2 := m.next.DoWork(arg1, arg2)
+//	m.emitter.Timing("ops_duration", time.Since(_start), m.doWorkTags...)
+//	if result2 != nil {
+//		m.emitter.Count("failed_ops", 1, statsd.Tags(m.doWorkTags, "error_class", m.errorClass(result2))...)
+//	}
+//	return result1, result2
+//
+// If extra labels are declared, their tags are added to the operation tag
+// once, before anything is emitted:
+//
+//	_tags := statsd.Tags(m.doWorkTags, "tenant", m.labelValue("tenant", arg2.Tenant))
+//
+// The tags are added to a new slice, as the operation tag is shared by the
+// concurrent calls, and the tags with empty values are skipped.
+//
+// If the size of a result is recorded, it is emitted after the failures:
+//
+//	if result2 == nil {
+//		m.emitter.Histogram("result_size", float64(len(result1)), m.doWorkTags...)
+//	}
+//
+// If panics are recorded, a deferred call emits them before the call:
+//
+//	defer func() {
+//		if _panic := recover(); _panic != nil {
+//			m.emitter.Timing("ops_duration", time.Since(_start), m.doWorkTags...)
+//			m.emitter.Count("failed_ops", 1, statsd.Tags(m.doWorkTags, "error_class", "panic")...)
+//			panic(_panic)
+//		}
+//	}()
+func (b *monitoringMethodBuilder) Build() ast.Decl {
+	b.method.SetType(&ast.FuncType{
+		Params: &ast.FieldList{
+			List: b.methodConfig.MethodParams,
+		},
+		Results: &ast.FieldList{
+			List: transformation.FieldsAsAnonymous(b.methodConfig.MethodResults),
+		},
+	})
+
+	field := func(name string) *ast.SelectorExpr {
+		return &ast.SelectorExpr{X: ast.NewIdent("m"), Sel: ast.NewIdent(name)}
+	}
+	addTags := func(tags ast.Expr, keyvals ...ast.Expr) ast.Expr {
+		return &ast.CallExpr{
+			Fun:  astgen.QualifiedName(b.statsdPackageAlias, "Tags"),
+			Args: append([]ast.Expr{tags}, keyvals...),
+		}
+	}
+
+	var tags ast.Expr = field(tagsFieldName(b.methodConfig.MethodName))
+	if names := b.labels.MethodNames(b.methodConfig.MethodName); len(names) > 0 {
+		// the labels not declared for the method have no tags
+		var keyvals []ast.Expr
+		values := b.labels.Values("m", b.methodConfig.MethodName)
+		for i, name := range b.labels.Names() {
+			if slices.Contains(names, name) {
+				keyvals = append(keyvals, commonbuilders.StringLit(name), values[i])
+			}
+		}
+		b.method.AddStatement(&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent(tagsVarName)},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{addTags(tags, keyvals...)},
+		})
+		tags = ast.NewIdent(tagsVarName)
+	}
+
+	b.method.AddStatement(b.emit("Count", commonbuilders.TotalOpsMetric.Name, &ast.BasicLit{Kind: token.INT, Value: "1"}, tags))
+
+	b.method.AddStatement(commonbuilders.RecordStartTime(b.timePackageAlias).Build())
+
+	timing := func() ast.Stmt {
+		return b.emit("Timing", opsDurationName, &ast.CallExpr{
+			Fun:  astgen.QualifiedName(b.timePackageAlias, "Since"),
+			Args: []ast.Expr{ast.NewIdent("_start")},
+		}, tags)
+	}
+	countFailed := func(failure commonbuilders.Failure) []ast.Stmt {
+		var stmts []ast.Stmt
+		failedTags := tags
+		if b.classifyErrors {
+			classStmts, class := failure.Class("m")
+			stmts = append(stmts, classStmts...)
+			failedTags = addTags(tags, commonbuilders.StringLit(b.labels.Keys().ErrorClass), class)
+		}
+		return append(stmts, b.emit("Count", commonbuilders.FailedOpsMetric.Name, &ast.BasicLit{Kind: token.INT, Value: "1"}, failedTags))
+	}
+
+	if b.panics {
+		b.method.AddStatement(astgen.Recover(append([]ast.Stmt{timing()}, countFailed(commonbuilders.PanicFailure)...)...))
+	}
+
+	methodInvocation := commonbuilders.NewMethodInvocation(b.methodConfig)
+	methodInvocation.SetReceiver(field("next"))
+	b.method.AddStatement(methodInvocation.Build())
+
+	b.method.AddStatement(timing())
+
+	if failure := commonbuilders.NewFailure(b.methodConfig); failure.Possible() {
+		b.method.AddStatement(&ast.IfStmt{
+			Cond: failure.Cond(),
+			Body: &ast.BlockStmt{List: countFailed(failure)},
+		})
+	}
+
+	if b.size != nil {
+		b.method.AddStatements(commonbuilders.RecordSize(b.methodConfig, *b.size, func(size ast.Expr) []ast.Stmt {
+			value := &ast.CallExpr{Fun: ast.NewIdent("float64"), Args: []ast.Expr{size}}
+			return []ast.Stmt{b.emit("Histogram", commonbuilders.ResultSizeMetric.Name, value, tags)}
+		}))
+	}
+
+	b.method.AddStatements(commonbuilders.NewReturnResults(b.methodConfig).Stmts())
+
+	return b.method.Build()
+}
+
+// emit builds a statement that emits the metric, e.g.
+// m.emitter.Count("total_ops", 1, m.doWorkTags...).
+func (b *monitoringMethodBuilder) emit(method, name string, value, tags ast.Expr) ast.Stmt {
+	return &ast.ExprStmt{
+		X: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   &ast.SelectorExpr{X: ast.NewIdent("m"), Sel: ast.NewIdent(emitterParamName)},
+				Sel: ast.NewIdent(method),
+			},
+			Args:     []ast.Expr{commonbuilders.StringLit(name), value, tags},
+			Ellipsis: token.Pos(1),
+		},
+	}
+}
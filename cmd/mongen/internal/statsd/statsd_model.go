@@ -0,0 +1,112 @@
+package statsd
+
+import (
+	"go/ast"
+
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
+	"github.com/Bo0mer/gentools/pkg/astgen"
+)
+
+type statsdModel struct {
+	cfg         commonbuilders.ModelConfig
+	fileBuilder *astgen.File
+	structName  string
+	strct       *astgen.Struct
+	labels      *commonbuilders.Labels
+	constructor *constructorBuilder
+	toggles     *astgen.Toggles
+
+	timePackageAlias   string
+	statsdPackageAlias string
+}
+
+func NewStatsdModel(cfg commonbuilders.ModelConfig) *statsdModel {
+	file := astgen.NewFile(cfg.TargetPkg, cfg.TargetPath)
+	strct := astgen.NewStruct(cfg.StructName)
+	file.AppendDeclaration(strct)
+
+	m := &statsdModel{
+		cfg:         cfg,
+		fileBuilder: file,
+		structName:  cfg.StructName,
+		strct:       strct,
+	}
+	m.labels = commonbuilders.NewLabels(m, cfg)
+	sourcePackageAlias := m.AddImport("", cfg.InterfacePath)
+	m.statsdPackageAlias = m.AddImport("", "github.com/Bo0mer/gentools/pkg/statsd")
+	m.timePackageAlias = m.AddImport("", "time")
+
+	m.constructor = newConstructorBuilder(m.statsdPackageAlias, sourcePackageAlias, cfg.InterfaceName, cfg.StructName, cfg.ConstructorName, m.labels, cfg.ClassifyErrors)
+	file.AppendDeclaration(m.constructor)
+
+	strct.AddField("next", sourcePackageAlias, cfg.InterfaceName)
+	strct.AddField(emitterParamName, m.statsdPackageAlias, "Emitter")
+
+	if cfg.ClassifyErrors {
+		commonbuilders.AddErrorClassField(strct)
+		commonbuilders.AddLabelGuardFields(strct, m.AddImport("", "sync"))
+		file.AppendDeclaration(commonbuilders.ErrorClassMethod{
+			StructName:          cfg.StructName,
+			ErrorsPackageAlias:  m.AddImport("", "errors"),
+			ContextPackageAlias: m.AddImport("", "context"),
+			Key:                 m.labels.Keys().ErrorClass,
+		})
+		file.AppendDeclaration(commonbuilders.LabelGuardMethod{StructName: cfg.StructName})
+	}
+
+	if cfg.Toggles {
+		m.toggles = astgen.NewToggles(m.AddImport("", astgen.TogglePackage), cfg.InterfaceName)
+		m.constructor.toggles = m.toggles
+	}
+
+	return m
+}
+
+func (m *statsdModel) AddImport(pkgName, location string) string {
+	return m.fileBuilder.AddImport(pkgName, location)
+}
+
+func (m *statsdModel) AddMethod(method *astgen.MethodConfig) error {
+	guarded := m.labels.Guarded()
+	if err := m.labels.AddMethod(method); err != nil {
+		return err
+	}
+	if !guarded && m.labels.Guarded() {
+		commonbuilders.AddLabelGuardFields(m.strct, m.AddImport("", "sync"))
+		m.fileBuilder.AppendDeclaration(commonbuilders.LabelGuardMethod{StructName: m.structName})
+	}
+
+	operation, err := m.cfg.OperationName(method.MethodName)
+	if err != nil {
+		return err
+	}
+	m.strct.AddFieldWithType(tagsFieldName(method.MethodName), &ast.ArrayType{Elt: ast.NewIdent("string")})
+	m.constructor.AddMethod(method.MethodName, operation)
+
+	mmb := newMonitoringMethodBuilder(m.structName, method, m.labels, m.cfg.ClassifyErrors, m.timePackageAlias, m.statsdPackageAlias)
+	if m.cfg.ResultSize {
+		size, ok, err := commonbuilders.FindSizeResult(method)
+		if err != nil {
+			return err
+		}
+		if ok {
+			size.AddImports(m.AddImport)
+			mmb.SetSize(size)
+		}
+	}
+	if m.cfg.Panics {
+		mmb.RecordPanics()
+	}
+
+	var decl astgen.DeclarationBuilder = mmb
+	if m.toggles != nil {
+		m.toggles.AddMethod(m.strct, method.MethodName)
+		decl = m.toggles.Guard(method, mmb)
+	}
+	m.fileBuilder.AppendDeclaration(decl)
+	return nil
+}
+
+func (m *statsdModel) Build() *ast.File {
+	return m.fileBuilder.Build()
+}
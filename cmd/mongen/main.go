@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"errors"
 	"flag"
 	"fmt"
@@ -14,46 +15,151 @@ import (
 	"path"
 	"path/filepath"
 
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/commonbuilders"
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/expvar"
 	"github.com/Bo0mer/gentools/cmd/mongen/internal/gokit"
 	"github.com/Bo0mer/gentools/cmd/mongen/internal/opencensus"
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/otel"
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/prometheus"
+	"github.com/Bo0mer/gentools/cmd/mongen/internal/statsd"
 
 	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/catalog"
+	"github.com/Bo0mer/gentools/pkg/output"
 	"github.com/Bo0mer/gentools/pkg/resolution"
 	"github.com/Bo0mer/gentools/pkg/transformation"
+	"github.com/Bo0mer/gentools/pkg/typecheck"
 )
 
 const (
 	goKitProvider      = "go-kit"
 	opencensusProvider = "opencensus"
+	prometheusProvider = "prometheus"
+	otelProvider       = "otel"
+	expvarProvider     = "expvar"
+	statsdProvider     = "statsd"
 )
 
 type args struct {
 	sourceDir          string
 	interfaceName      string
 	monitoringProvider string
+	buckets            map[string][]string
+	methodBuckets      map[string]string
 }
 
+var (
+	outputOptions  output.Options
+	classifyErrors bool
+	inFlightOps    bool
+	recordOutcome  bool
+	withMetrics    bool
+	labelsFunc     bool
+	streams        bool
+	resultSize     bool
+	toggles        bool
+	panics         bool
+	buckets        string
+	methodBuckets  string
+	catalogFormat  string
+	metricPrefix   string
+	ruleOptions    = catalog.RuleOptions{ErrorRatio: 0.05, Latency: 1}
+	naming         = transformation.Naming{Case: transformation.SnakeCase}
+)
+
 func init() {
+	outputOptions.RegisterFlags(flag.CommandLine)
+	naming.RegisterFlags(flag.CommandLine)
+	flag.BoolVar(&classifyErrors, "classify-errors", false, "Make the constructor accept an error classifier and record the class of every error")
+	flag.BoolVar(&inFlightOps, "in-flight", false, "Make the constructor accept a gauge of the operations in progress")
+	flag.BoolVar(&recordOutcome, "outcome", false, "Record the outcome, success or error, as a label of the operation duration")
+	flag.BoolVar(&withMetrics, "metrics", false, "Generate a type that creates the metrics and wraps implementations with them")
+	flag.BoolVar(&labelsFunc, "labels-func", false, "Make the constructor accept a function that returns label pairs from the context of every call")
+	flag.BoolVar(&streams, "streams", false, "Measure operations that return a stream until the stream ends, and record its size (go-kit, opencensus)")
+	flag.BoolVar(&resultSize, "result-size", false, "Record the number of items in the result of every operation")
+	flag.BoolVar(&toggles, "toggles", false, "Make the constructor accept runtime controls that disable or sample the monitoring of every method")
+	flag.BoolVar(&panics, "panics", false, "Record a panic of the wrapped implementation as a failed operation, and raise it again")
+	flag.StringVar(&buckets, "buckets", "", "Groups of operations whose durations are recorded with their own buckets, e.g. cache=.0001,.001;batch=60,600")
+	flag.StringVar(&methodBuckets, "method-buckets", "", "Bucket groups of methods, e.g. Get=cache,Rebuild=batch")
+	flag.StringVar(&catalogFormat, "catalog", "", "Write the catalog of the metrics instead of the source: json, grafana or rules")
+	flag.StringVar(&metricPrefix, "metric-prefix", "", "Prefix of the metric names in the catalog, e.g. the namespace and subsystem")
+	flag.Float64Var(&ruleOptions.ErrorRatio, "alert-error-ratio", ruleOptions.ErrorRatio, "Ratio of failed operations above which the rules alert, or 0 for none")
+	flag.Var(latencyFlag{&ruleOptions}, "alert-latency", "99th percentile of durations above which the rules alert, and that of bucket groups, e.g. 0.5,batch=600")
+
 	flag.Usage = func() {
 		var out io.Writer = os.Stdout
 
 		fmt.Fprintln(out, "A tool that generates monitoring wrappers for interfaces.")
-		fmt.Fprintf(out, "Usage: %s [-h] SOURCE_DIR INTERFACE_NAME [PROVIDER]\n", path.Base(os.Args[0]))
+		fmt.Fprintf(out, "Usage: %s [-h] [-check | -diff] [OPTIONS] SOURCE_DIR INTERFACE_NAME [PROVIDER]\n", path.Base(os.Args[0]))
 		fmt.Fprintln(out, "")
 		fmt.Fprintln(out, "  Arguments:")
 		fmt.Fprintln(out, "    SOURCE_DIR       Path to the file containing the interface")
 		fmt.Fprintln(out, "    INTERFACE_NAME   Name of the interface which will be wrapped")
 		fmt.Fprintln(out, "    PROVIDER         Monitoring provider to be used for the generated code")
-		fmt.Fprintf(out, "                     Can be one of:  %s  %s\n", goKitProvider, opencensusProvider)
+		fmt.Fprintf(out, "                     Can be one of:  %s  %s  %s  %s  %s  %s\n", goKitProvider, opencensusProvider, prometheusProvider, otelProvider, expvarProvider, statsdProvider)
 		fmt.Fprintln(out, "")
 		fmt.Fprintln(out, "  Options:")
 		fmt.Fprintln(out, "    -h               Print this text and exit")
+		fmt.Fprintln(out, "    -check           Fail with a diff if the output file is not up to date")
+		fmt.Fprintln(out, "    -diff            Print a diff against the output file instead of writing it")
+		fmt.Fprintln(out, "    -output-dir DIR  Directory of the generated package")
+		fmt.Fprintln(out, "                     Defaults to SOURCE_DIR/<package>mws")
+		fmt.Fprintln(out, "    -o FILE          Output file, relative to the output directory, or - for stdout")
+		fmt.Fprintln(out, "    -pkg NAME        Name of the generated package")
+		fmt.Fprintln(out, "    -type NAME       Name of the generated wrapper type")
+		fmt.Fprintln(out, "    -constructor NAME")
+		fmt.Fprintln(out, "                     Name of the generated constructor")
+		fmt.Fprintln(out, "    -classify-errors Make the constructor accept an error classifier and record")
+		fmt.Fprintln(out, "                     the class of every error as an error_class label (go-kit, opencensus, statsd)")
+		fmt.Fprintln(out, "    -in-flight       Make the constructor accept a gauge of the operations in progress (go-kit, opencensus)")
+		fmt.Fprintln(out, "    -outcome         Record the outcome, success or error, as a label of the operation duration")
+		fmt.Fprintln(out, "                     (go-kit, opencensus)")
+		fmt.Fprintln(out, "    -metrics         Generate a type that creates the metrics and wraps implementations with them")
+		fmt.Fprintln(out, "                     (go-kit, opencensus)")
+		fmt.Fprintln(out, "    -labels-func     Make the constructor accept a function that returns label pairs from the")
+		fmt.Fprintln(out, "                     context of every call (go-kit)")
+		fmt.Fprintln(out, "    -streams         Measure operations that return a <-chan T, iter.Seq, iter.Seq2 or io.ReadCloser")
+		fmt.Fprintln(out, "                     until the stream ends, and record its size (go-kit, opencensus)")
+		fmt.Fprintln(out, "    -result-size     Record the number of items in the first slice, map or type with a Len() int")
+		fmt.Fprintln(out, "                     method among the results of every operation (all providers)")
+		fmt.Fprintln(out, "    -toggles         Make the constructor accept runtime controls that disable or sample the")
+		fmt.Fprintln(out, "                     monitoring of every method (all providers)")
+		fmt.Fprintln(out, "    -panics          Record a panic of the wrapped implementation as a failed operation, of the")
+		fmt.Fprintln(out, "                     panic error class if errors are classified, and raise it again (all providers)")
+		fmt.Fprintln(out, "    -buckets GROUPS  Declare groups of operations whose durations are recorded by separate")
+		fmt.Fprintln(out, "                     metrics with their own buckets in seconds, e.g. cache=.0001,.001;batch=60,600")
+		fmt.Fprintln(out, "                     (go-kit, opencensus, prometheus)")
+		fmt.Fprintln(out, "    -method-buckets METHODS")
+		fmt.Fprintln(out, "                     Select the bucket groups of methods, e.g. Get=cache,Rebuild=batch")
+		fmt.Fprintln(out, "    -catalog FORMAT  Write the metrics, labels and operations of the implementation instead of")
+		fmt.Fprintln(out, "                     its source: json, or a Grafana dashboard (grafana) or Prometheus rules")
+		fmt.Fprintln(out, "                     (rules) rendered from it (go-kit with -metrics, prometheus)")
+		fmt.Fprintln(out, "    -metric-prefix PREFIX")
+		fmt.Fprintln(out, "                     Prefix of the metric names in the catalog, e.g. the namespace and subsystem")
+		fmt.Fprintln(out, "    -alert-error-ratio RATIO")
+		fmt.Fprintln(out, "                     Ratio of failed operations above which the rules alert, or 0 for none")
+		fmt.Fprintln(out, "                     Defaults to 0.05")
+		fmt.Fprintln(out, "    -alert-latency THRESHOLDS")
+		fmt.Fprintln(out, "                     99th percentile of durations above which the rules alert, or 0 for none,")
+		fmt.Fprintln(out, "                     and that of bucket groups, e.g. 0.5,batch=600")
+		fmt.Fprintln(out, "                     Defaults to 1, and to none for bucket groups")
+		fmt.Fprintln(out, "    -name-case CASE  Case of operation names: snake, kebab, camel or dotted")
+		fmt.Fprintln(out, "                     Defaults to snake")
+		fmt.Fprintln(out, "    -name-template TEMPLATE")
+		fmt.Fprintln(out, "                     Go template of operation names, e.g. {{.PackageName}}.{{.Name}}")
+		fmt.Fprintln(out, "    -key-case CASE   Case of label keys: snake, kebab, camel or dotted")
+		fmt.Fprintln(out, "    -prune           Remove files generated for interfaces that no longer exist")
+		fmt.Fprintln(out, "    -typecheck=false Do not type-check the generated source before writing it")
 		fmt.Fprintln(out, "")
 	}
 }
 
 func isValidProvider(provider string) bool {
-	return provider == goKitProvider || provider == opencensusProvider
+	switch provider {
+	case goKitProvider, opencensusProvider, prometheusProvider, otelProvider, expvarProvider, statsdProvider:
+		return true
+	}
+	return false
 }
 
 func parseArgs() (args, error) {
@@ -76,11 +182,49 @@ func parseArgs() (args, error) {
 			return args{}, fmt.Errorf("unknown monitoring provider: %s", monitoringProvider)
 		}
 	}
+	if err := providerCapabilities[monitoringProvider].checkFlags(monitoringProvider); err != nil {
+		return args{}, err
+	}
+	if err := outputOptions.Validate(); err != nil {
+		return args{}, err
+	}
+	if err := naming.Parse(); err != nil {
+		return args{}, err
+	}
+	bucketGroups, err := commonbuilders.ParseBuckets(buckets)
+	if err != nil {
+		return args{}, err
+	}
+	methodBucketGroups, err := commonbuilders.ParseMethodBuckets(methodBuckets)
+	if err != nil {
+		return args{}, err
+	}
+	switch catalogFormat {
+	case "", catalogJSON:
+	case catalogGrafana, catalogRules:
+		if monitoringProvider != goKitProvider && monitoringProvider != prometheusProvider {
+			return args{}, fmt.Errorf("cannot render %s from the metrics of the %s provider", catalogFormat, monitoringProvider)
+		}
+		// The metrics passed to the go-kit constructor are named by the
+		// caller, so only those created with -metrics have known names.
+		if monitoringProvider == goKitProvider && !withMetrics {
+			return args{}, fmt.Errorf("cannot render %s from the metrics of the %s provider without -metrics", catalogFormat, monitoringProvider)
+		}
+	default:
+		return args{}, fmt.Errorf("unknown catalog format: %s", catalogFormat)
+	}
+	if naming.KeyCase == transformation.KebabCase || naming.KeyCase == transformation.DottedCase {
+		if monitoringProvider == prometheusProvider || monitoringProvider == goKitProvider && withMetrics {
+			return args{}, fmt.Errorf("prometheus label names cannot be in %s case", naming.KeyCase)
+		}
+	}
 
 	return args{
 		sourceDir:          sourceDir,
 		interfaceName:      interfaceName,
 		monitoringProvider: monitoringProvider,
+		buckets:            bucketGroups,
+		methodBuckets:      methodBucketGroups,
 	}, nil
 }
 
@@ -94,7 +238,14 @@ func main() {
 	if err != nil {
 		log.Fatalf("error resolving import path of source directory: %v", err)
 	}
-	targetPkg := path.Base(sourcePkgPath) + "mws"
+	defaultFile := filename(args.interfaceName)
+	if catalogFormat != "" {
+		defaultFile = catalogFilename(args.interfaceName, catalogFormat)
+	}
+	target := outputOptions.Target(
+		filepath.Join(args.sourceDir, path.Base(sourcePkgPath)+"mws"),
+		defaultFile,
+	)
 
 	locator := resolution.NewLocator()
 
@@ -103,16 +254,70 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	sourceHash, err := locator.DeclarationHash(d)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := providerCapabilities[args.monitoringProvider].checkDirectives(args.monitoringProvider, d.Doc); err != nil {
+		log.Fatalf("interface %s: %v", args.interfaceName, err)
+	}
+
+	cfg := commonbuilders.ModelConfig{
+		InterfacePath:   sourcePkgPath,
+		InterfaceName:   args.interfaceName,
+		StructName:      fmt.Sprintf("monitoring%s", args.interfaceName),
+		ConstructorName: fmt.Sprintf("NewMonitoring%s", args.interfaceName),
+		TargetPkg:       target.Package,
+		TargetPath:      target.ImportPath,
+		Doc:             d.Doc,
+		Context:         resolution.NewASTFileLocatorContext(d.File, d.Location),
+		ClassifyErrors:  classifyErrors,
+		InFlightOps:     inFlightOps,
+		RecordOutcome:   recordOutcome,
+		Metrics:         withMetrics,
+		LabelsFunc:      labelsFunc,
+		Streams:         streams,
+		ResultSize:      resultSize,
+		Toggles:         toggles,
+		Panics:          panics,
+		Naming:          naming,
+		Buckets:         args.buckets,
+		MethodBuckets:   args.methodBuckets,
+	}
+	if outputOptions.TypeName != "" {
+		cfg.StructName = outputOptions.TypeName
+	}
+	if outputOptions.ConstructorName != "" {
+		cfg.ConstructorName = outputOptions.ConstructorName
+	}
+	if catalogFormat == "" {
+		if err := commonbuilders.CheckDerivedNames(cfg.StructName, derivedNames(args.monitoringProvider, cfg)...); err != nil {
+			log.Fatal(err)
+		}
+	}
 
-	typeName := fmt.Sprintf("monitoring%s", args.interfaceName)
+	header := output.Header{
+		Generator:  "mongen",
+		Version:    output.Version,
+		Source:     sourcePkgPath + "." + args.interfaceName,
+		SourceHash: sourceHash,
+		Args:       output.Args(flag.CommandLine, target, args.sourceDir, flag.Args()[1:]...),
+	}
 
-	model, err := newModel(args.monitoringProvider, sourcePkgPath, args.interfaceName, typeName, targetPkg)
+	if catalogFormat != "" {
+		if err := writeCatalog(args.monitoringProvider, cfg, d, locator, header, target); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	model, err := newModel(args.monitoringProvider, cfg)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 	generator := astgen.Generator{
-		Model:    model,
+		Model:    checkedModel{model: model, provider: args.monitoringProvider},
 		Locator:  locator,
 		Resolver: resolution.NewResolver(model, locator),
 	}
@@ -122,29 +327,71 @@ func main() {
 		log.Fatal(err)
 	}
 
-	targetPkgPath := filepath.Join(args.sourceDir, targetPkg)
-	if err := os.MkdirAll(targetPkgPath, 0777); err != nil {
-		log.Fatalf("error creating target package directory: %v", err)
+	var src bytes.Buffer
+	if err := WriteSource(model, header, &src); err != nil {
+		log.Fatal(err)
 	}
 
-	fd, err := os.Create(filepath.Join(targetPkgPath, filename(args.interfaceName)))
-	if err != nil {
-		log.Fatalf("error creating output source file: %v", err)
+	if outputOptions.TypeCheck {
+		if err := typecheck.Check("mongen", target.Dir, target.Name, src.Bytes()); err != nil {
+			log.Fatal(err)
+		}
 	}
-	defer fd.Close()
 
-	err = WriteSource(model, fd)
+	err = outputOptions.Save(target, src.Bytes(), "monitoring", header.Source)
 	if err != nil {
 		log.Fatal(err)
 	}
+}
 
-	wd, _ := os.Getwd()
-	outPath, err := filepath.Rel(wd, fd.Name())
+// writeCatalog writes the catalog of the monitoring implementation of the
+// interface, in the format selected by the -catalog option and with the
+// header, to the target.
+func writeCatalog(provider string, cfg commonbuilders.ModelConfig, d resolution.TypeDiscovery, locator *resolution.Locator, header output.Header, target output.Target) error {
+	model := newCatalogModel(provider, metricPrefix, cfg)
+	generator := astgen.Generator{
+		Model:    model,
+		Locator:  locator,
+		Resolver: resolution.NewResolver(model, locator),
+	}
+	if err := generator.ProcessInterface(d); err != nil {
+		return err
+	}
+
+	source := cfg.InterfacePath + "." + cfg.InterfaceName
+	c, err := model.Catalog(source)
 	if err != nil {
-		outPath = fd.Name()
+		return err
+	}
+	c.Header = header.Lines()
+	if catalogFormat == catalogRules {
+		if err := checkGroupLatency(c, ruleOptions); err != nil {
+			return err
+		}
 	}
-	fmt.Printf("Wrote monitoring implementation of %q to %q\n", sourcePkgPath+"."+args.interfaceName, outPath)
+	data, err := renderCatalog(c, catalogFormat, ruleOptions)
+	if err != nil {
+		return err
+	}
+	description := map[string]string{
+		catalogJSON:    "metric catalog",
+		catalogGrafana: "Grafana dashboard",
+		catalogRules:   "Prometheus rules",
+	}[catalogFormat]
+	return outputOptions.SaveFile(target, data, fmt.Sprintf("%s of %q", description, source))
+}
 
+// derivedNames returns the names of the declarations derived from the
+// constructor name that are generated for the provider. The names of the
+// declarations that depend on the labels of the interface are left out.
+func derivedNames(provider string, cfg commonbuilders.ModelConfig) []string {
+	switch {
+	case provider == prometheusProvider:
+		return []string{commonbuilders.DerivedName("Register", cfg.ConstructorName, "")}
+	case cfg.Metrics && (provider == goKitProvider || provider == opencensusProvider):
+		return []string{commonbuilders.MetricsTypeName(cfg.ConstructorName), commonbuilders.MetricsConstructorName(cfg.ConstructorName)}
+	}
+	return nil
 }
 
 func filename(interfaceName string) string {
@@ -169,18 +416,26 @@ type model interface {
 	astFileBuilder
 }
 
-func newModel(provider string, interfacePath, interfaceName, structName, targetPkg string) (model, error) {
+func newModel(provider string, cfg commonbuilders.ModelConfig) (model, error) {
 	switch provider {
 	case goKitProvider:
-		return gokit.NewGoKitModel(interfacePath, interfaceName, structName, targetPkg), nil
+		return gokit.NewGoKitModel(cfg), nil
 	case opencensusProvider:
-		return opencensus.NewOpencensusModel(interfacePath, interfaceName, structName, targetPkg), nil
+		return opencensus.NewOpencensusModel(cfg), nil
+	case prometheusProvider:
+		return prometheus.NewPrometheusModel(cfg), nil
+	case otelProvider:
+		return otel.NewOtelModel(cfg), nil
+	case expvarProvider:
+		return expvar.NewExpvarModel(cfg), nil
+	case statsdProvider:
+		return statsd.NewStatsdModel(cfg), nil
 	}
 	return nil, fmt.Errorf("unknown provider: %s", provider)
 }
 
-func WriteSource(generatedFile astFileBuilder, w io.Writer) error {
-	fmt.Fprintf(w, "// Code generated by mongen. DO NOT EDIT.\n")
+func WriteSource(generatedFile astFileBuilder, header output.Header, w io.Writer) error {
+	fmt.Fprint(w, header.String())
 	astFile := generatedFile.Build()
 
 	if err := format.Node(w, token.NewFileSet(), astFile); err != nil {
@@ -0,0 +1,56 @@
+package examplesmws_test
+
+import (
+	"sync"
+	"testing"
+
+	"go.opencensus.io/trace"
+)
+
+// spanRecorder records the spans exported by the traced calls.
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(span *trace.SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+// recordSpans samples every span and records the exported spans until the
+// test ends.
+func recordSpans(t *testing.T) *spanRecorder {
+	t.Helper()
+	r := new(spanRecorder)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	trace.RegisterExporter(r)
+	t.Cleanup(func() { trace.UnregisterExporter(r) })
+	return r
+}
+
+// take returns the spans recorded since the last call.
+func (r *spanRecorder) take() []*trace.SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spans := r.spans
+	r.spans = nil
+	return spans
+}
+
+// assertStatus asserts that the spans are a single one, failed with the
+// message, or not failed if the message is empty.
+func assertStatus(t *testing.T, spans []*trace.SpanData, message string) {
+	t.Helper()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	want := trace.Status{}
+	if message != "" {
+		want = trace.Status{Code: trace.StatusCodeUnknown, Message: message}
+	}
+	if spans[0].Status != want {
+		t.Errorf("got status %+v, want %+v", spans[0].Status, want)
+	}
+}
@@ -0,0 +1,41 @@
+// Code generated by tracegen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/tracegen/examples.PanickyService
+// Source-Hash: sha256:d01bc59f41257357df8a265013a1ffa59cc8c14ce4d23ab75305b6cb86bc75c2
+// Generator: tracegen v2.1.0
+// Args: -panics=true -output-dir . -o tracing_panicky_service.go .. PanickyService
+package examplesmws
+
+import (
+	alias3 "context"
+	alias4 "fmt"
+	alias1 "github.com/Bo0mer/gentools/cmd/tracegen/examples"
+	alias2 "go.opencensus.io/trace"
+)
+
+type tracingPanickyService struct {
+	next alias1.PanickyService
+}
+
+// NewTracingPanickyService creates new tracing middleware.
+func NewTracingPanickyService(next alias1.PanickyService) alias1.PanickyService {
+	return &tracingPanickyService{next: next}
+}
+func (m *tracingPanickyService) DoWork(arg1 alias3.Context, arg2 string) (int, error) {
+	arg1, _span := alias2.StartSpan(arg1, "github.com/Bo0mer/gentools/cmd/tracegen/examples.PanickyService.DoWork")
+	defer _span.End()
+	defer func() {
+		if _panic := recover(); _panic != nil {
+			_span.Annotate([]alias2.Attribute{alias2.StringAttribute("panic", alias4.Sprint(_panic))}, "panic")
+			_span.SetStatus(alias2.Status{Code: alias2.StatusCodeUnknown, Message: "panic"})
+			panic(_panic)
+		}
+	}()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	if result2 != nil {
+		_span.SetStatus(alias2.Status{Code: alias2.StatusCodeUnknown, Message: result2.Error()})
+	}
+	return result1, result2
+}
+func (m *tracingPanickyService) Close() {
+	m.next.Close()
+}
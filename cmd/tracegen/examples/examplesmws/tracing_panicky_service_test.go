@@ -0,0 +1,42 @@
+package examplesmws_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/tracegen/examples/examplesmws"
+	"go.opencensus.io/trace"
+)
+
+type panickyService struct{}
+
+func (panickyService) DoWork(context.Context, string) (int, error) {
+	panic("boom")
+}
+
+func (panickyService) Close() {}
+
+func TestPanickyServiceRecordsPanicsAndRaisesThemAgain(t *testing.T) {
+	spans := recordSpans(t)
+	svc := examplesmws.NewTracingPanickyService(panickyService{})
+
+	func() {
+		defer func() {
+			if got := recover(); got != "boom" {
+				t.Errorf("got panic %v, want boom", got)
+			}
+		}()
+		svc.DoWork(context.Background(), "work")
+	}()
+
+	got := spans.take()
+	if len(got) != 1 {
+		t.Fatalf("got %d spans, want 1", len(got))
+	}
+	if want := (trace.Status{Code: trace.StatusCodeUnknown, Message: "panic"}); got[0].Status != want {
+		t.Errorf("got status %+v, want %+v", got[0].Status, want)
+	}
+	if len(got[0].Annotations) != 1 || got[0].Annotations[0].Attributes["panic"] != "boom" {
+		t.Errorf("got annotations %+v, want the panic", got[0].Annotations)
+	}
+}
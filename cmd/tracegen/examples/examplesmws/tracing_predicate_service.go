@@ -0,0 +1,41 @@
+// Code generated by tracegen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/tracegen/examples.PredicateService
+// Source-Hash: sha256:b49ddcfa476a8faae8546ac7451509fc4d5e14449055b513618a915a06d9e5ce
+// Generator: tracegen v2.1.0
+// Args: -output-dir . -o tracing_predicate_service.go .. PredicateService
+package examplesmws
+
+import (
+	alias3 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/tracegen/examples"
+	alias2 "go.opencensus.io/trace"
+)
+
+type tracingPredicateService struct {
+	next alias1.PredicateService
+}
+
+// NewTracingPredicateService creates new tracing middleware.
+func NewTracingPredicateService(next alias1.PredicateService) alias1.PredicateService {
+	return &tracingPredicateService{next: next}
+}
+func (m *tracingPredicateService) Lookup(arg1 alias3.Context, arg2 string) (string, bool) {
+	arg1, _span := alias2.StartSpan(arg1, "github.com/Bo0mer/gentools/cmd/tracegen/examples.PredicateService.Lookup")
+	defer _span.End()
+	result1, result2 := m.next.Lookup(arg1, arg2)
+	if !result2 {
+		_span.SetStatus(alias2.Status{Code: alias2.StatusCodeUnknown, Message: "failed"})
+	}
+	return result1, result2
+}
+func (m *tracingPredicateService) Status(arg1 alias3.Context) (alias1.Status, error) {
+	arg1, _span := alias2.StartSpan(arg1, "github.com/Bo0mer/gentools/cmd/tracegen/examples.PredicateService.Status")
+	defer _span.End()
+	result1, result2 := m.next.Status(arg1)
+	if result2 != nil {
+		_span.SetStatus(alias2.Status{Code: alias2.StatusCodeUnknown, Message: result2.Error()})
+	} else if result1 != alias1.StatusOK {
+		_span.SetStatus(alias2.Status{Code: alias2.StatusCodeUnknown, Message: "failed"})
+	}
+	return result1, result2
+}
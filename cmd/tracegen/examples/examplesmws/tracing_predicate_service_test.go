@@ -0,0 +1,56 @@
+package examplesmws_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/tracegen/examples"
+	"github.com/Bo0mer/gentools/cmd/tracegen/examples/examplesmws"
+)
+
+type predicateService struct {
+	values map[string]string
+	status examples.Status
+	err    error
+}
+
+func (s predicateService) Lookup(_ context.Context, key string) (string, bool) {
+	value, ok := s.values[key]
+	return value, ok
+}
+
+func (s predicateService) Status(context.Context) (examples.Status, error) {
+	return s.status, s.err
+}
+
+func TestPredicateService(t *testing.T) {
+	spans := recordSpans(t)
+	ctx := context.Background()
+
+	lookup := func(key string) func(examples.PredicateService) {
+		return func(svc examples.PredicateService) { svc.Lookup(ctx, key) }
+	}
+	status := func(svc examples.PredicateService) { svc.Status(ctx) }
+	for _, tc := range []struct {
+		name string
+		next predicateService
+		call func(examples.PredicateService)
+		// wantMessage is the status message of the failed span, if any
+		wantMessage string
+	}{
+		{name: "lookup of an existing key", next: predicateService{values: map[string]string{"key": "value"}}, call: lookup("key")},
+		{name: "lookup of a missing key", call: lookup("key"), wantMessage: "failed"},
+		{name: "healthy status", call: status},
+		{name: "degraded status", next: predicateService{status: examples.StatusDegraded}, call: status, wantMessage: "failed"},
+		// The predicate does not hold, but the call returned an error.
+		{name: "status error", next: predicateService{err: errors.New("unavailable")}, call: status, wantMessage: "unavailable"},
+		// The error takes precedence over the predicate.
+		{name: "degraded status error", next: predicateService{status: examples.StatusDegraded, err: errors.New("unavailable")}, call: status, wantMessage: "unavailable"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.call(examplesmws.NewTracingPredicateService(tc.next))
+			assertStatus(t, spans.take(), tc.wantMessage)
+		})
+	}
+}
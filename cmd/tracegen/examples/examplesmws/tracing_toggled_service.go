@@ -0,0 +1,48 @@
+// Code generated by tracegen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/tracegen/examples.ToggledService
+// Source-Hash: sha256:a872fd7c692ad012ff464d007a5431d3d2c939e3822edab0a84bdf9a38f0a072
+// Generator: tracegen v2.1.0
+// Args: -toggles=true -output-dir . -o tracing_toggled_service.go .. ToggledService
+package examplesmws
+
+import (
+	alias4 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/tracegen/examples"
+	alias3 "github.com/Bo0mer/gentools/pkg/toggle"
+	alias2 "go.opencensus.io/trace"
+)
+
+type tracingToggledService struct {
+	next         alias1.ToggledService
+	doWorkToggle *alias3.Method
+	notifyToggle *alias3.Method
+}
+
+// NewTracingToggledService creates new tracing middleware.
+func NewTracingToggledService(next alias1.ToggledService, toggles *alias3.Controls) alias1.ToggledService {
+	return &tracingToggledService{next: next, doWorkToggle: toggles.Method("ToggledService.DoWork"), notifyToggle: toggles.Method("ToggledService.Notify")}
+}
+func (m *tracingToggledService) DoWork(arg1 alias4.Context, arg2 string) (int, error) {
+	if !m.doWorkToggle.Sample() {
+		return m.next.DoWork(arg1, arg2)
+	}
+	arg1, _span := alias2.StartSpan(arg1, "github.com/Bo0mer/gentools/cmd/tracegen/examples.ToggledService.DoWork")
+	defer _span.End()
+	result1, result2 := m.next.DoWork(arg1, arg2)
+	if result2 != nil {
+		_span.SetStatus(alias2.Status{Code: alias2.StatusCodeUnknown, Message: result2.Error()})
+	}
+	return result1, result2
+}
+func (m *tracingToggledService) Notify(arg1 alias4.Context, arg2 []string) error {
+	if !m.notifyToggle.Sample() {
+		return m.next.Notify(arg1, arg2)
+	}
+	arg1, _span := alias2.StartSpan(arg1, "github.com/Bo0mer/gentools/cmd/tracegen/examples.ToggledService.Notify")
+	defer _span.End()
+	result1 := m.next.Notify(arg1, arg2)
+	if result1 != nil {
+		_span.SetStatus(alias2.Status{Code: alias2.StatusCodeUnknown, Message: result1.Error()})
+	}
+	return result1
+}
@@ -0,0 +1,59 @@
+package examplesmws_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/tracegen/examples/examplesmws"
+	"github.com/Bo0mer/gentools/pkg/toggle"
+	"go.opencensus.io/trace"
+)
+
+type toggledService struct{}
+
+func (toggledService) DoWork(ctx context.Context, _ string) (int, error) {
+	if trace.FromContext(ctx) != nil {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (toggledService) Notify(context.Context, []string) error {
+	return nil
+}
+
+func TestToggledService(t *testing.T) {
+	spans := recordSpans(t)
+	ctx := context.Background()
+	toggles := new(toggle.Controls)
+	svc := examplesmws.NewTracingToggledService(toggledService{}, toggles)
+
+	toggles.Disable("ToggledService.DoWork")
+	// A disabled method passes the context of the caller to next.
+	if n, _ := svc.DoWork(ctx, "work"); n != 0 {
+		t.Errorf("got a span in the context of a disabled method")
+	}
+	svc.Notify(ctx, nil)
+	got := spans.take()
+	if len(got) != 1 || got[0].Name != "github.com/Bo0mer/gentools/cmd/tracegen/examples.ToggledService.Notify" {
+		t.Fatalf("got %v, want only the span of Notify", got)
+	}
+
+	toggles.Enable("ToggledService.DoWork")
+	if n, _ := svc.DoWork(ctx, "work"); n != 1 {
+		t.Errorf("got no span in the context of an enabled method")
+	}
+	if got := spans.take(); len(got) != 1 {
+		t.Errorf("got %d spans, want 1", len(got))
+	}
+}
+
+func TestToggledServiceWithoutControls(t *testing.T) {
+	spans := recordSpans(t)
+	svc := examplesmws.NewTracingToggledService(toggledService{}, nil)
+
+	svc.DoWork(context.Background(), "work")
+	if got := spans.take(); len(got) != 1 {
+		t.Errorf("got %d spans, want 1", len(got))
+	}
+}
@@ -0,0 +1,50 @@
+// Code generated by tracegen. DO NOT EDIT.
+// Source: github.com/Bo0mer/gentools/cmd/tracegen/examples.TypedErrorService
+// Source-Hash: sha256:ce2353b38c6f1922d2ab21ed30c835c9300cc543a7386f942c1f22dd9ea1dc63
+// Generator: tracegen v2.1.0
+// Args: -output-dir . -o tracing_typed_error_service.go .. TypedErrorService
+package examplesmws
+
+import (
+	alias3 "context"
+	alias1 "github.com/Bo0mer/gentools/cmd/tracegen/examples"
+	alias2 "go.opencensus.io/trace"
+)
+
+type tracingTypedErrorService struct {
+	next alias1.TypedErrorService
+}
+
+// NewTracingTypedErrorService creates new tracing middleware.
+func NewTracingTypedErrorService(next alias1.TypedErrorService) alias1.TypedErrorService {
+	return &tracingTypedErrorService{next: next}
+}
+func (m *tracingTypedErrorService) Get(arg1 alias3.Context, arg2 string) (string, *alias1.NotFoundError) {
+	arg1, _span := alias2.StartSpan(arg1, "github.com/Bo0mer/gentools/cmd/tracegen/examples.TypedErrorService.Get")
+	defer _span.End()
+	result1, result2 := m.next.Get(arg1, arg2)
+	if result2 != nil {
+		_span.SetStatus(alias2.Status{Code: alias2.StatusCodeUnknown, Message: result2.Error()})
+	}
+	return result1, result2
+}
+func (m *tracingTypedErrorService) Check(arg1 alias3.Context) (error, bool) {
+	arg1, _span := alias2.StartSpan(arg1, "github.com/Bo0mer/gentools/cmd/tracegen/examples.TypedErrorService.Check")
+	defer _span.End()
+	result1, result2 := m.next.Check(arg1)
+	if result1 != nil {
+		_span.SetStatus(alias2.Status{Code: alias2.StatusCodeUnknown, Message: result1.Error()})
+	}
+	return result1, result2
+}
+func (m *tracingTypedErrorService) Resolve(arg1 alias3.Context, arg2 string) (string, *alias1.NotFoundError) {
+	arg1, _span := alias2.StartSpan(arg1, "github.com/Bo0mer/gentools/cmd/tracegen/examples.TypedErrorService.Resolve")
+	defer _span.End()
+	result1, result2 := m.next.Resolve(arg1, arg2)
+	if result2 != nil {
+		_span.SetStatus(alias2.Status{Code: alias2.StatusCodeUnknown, Message: result2.Error()})
+	} else if result1 == "" {
+		_span.SetStatus(alias2.Status{Code: alias2.StatusCodeUnknown, Message: "failed"})
+	}
+	return result1, result2
+}
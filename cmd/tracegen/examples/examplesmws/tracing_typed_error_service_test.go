@@ -0,0 +1,70 @@
+package examplesmws_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bo0mer/gentools/cmd/tracegen/examples"
+	"github.com/Bo0mer/gentools/cmd/tracegen/examples/examplesmws"
+)
+
+type typedErrorService struct {
+	value string
+	err   *examples.NotFoundError
+}
+
+func (s typedErrorService) Get(context.Context, string) (string, *examples.NotFoundError) {
+	return s.value, s.err
+}
+
+func (s typedErrorService) Check(context.Context) (error, bool) {
+	return nil, true
+}
+
+func (s typedErrorService) Resolve(context.Context, string) (string, *examples.NotFoundError) {
+	return s.value, s.err
+}
+
+func TestTypedErrorServiceGet(t *testing.T) {
+	spans := recordSpans(t)
+
+	for _, tc := range []struct {
+		name string
+		next typedErrorService
+		// wantMessage is the status message of the failed span, if any
+		wantMessage string
+	}{
+		// A nil *NotFoundError is not mistaken for a failure.
+		{name: "existing key", next: typedErrorService{value: "value"}},
+		// The span of a method without a predicate fails with the error.
+		{name: "missing key", next: typedErrorService{err: &examples.NotFoundError{Key: "key"}}, wantMessage: "not found: key"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			examplesmws.NewTracingTypedErrorService(tc.next).Get(context.Background(), "key")
+			assertStatus(t, spans.take(), tc.wantMessage)
+		})
+	}
+}
+
+func TestTypedErrorServiceResolve(t *testing.T) {
+	spans := recordSpans(t)
+
+	for _, tc := range []struct {
+		name string
+		next typedErrorService
+		// wantMessage is the status message of the failed span, if any
+		wantMessage string
+	}{
+		// A nil *NotFoundError is not mistaken for a failure.
+		{name: "existing key", next: typedErrorService{value: "value"}},
+		{name: "empty value", wantMessage: "failed"},
+		{name: "missing key", next: typedErrorService{value: "value", err: &examples.NotFoundError{Key: "key"}}, wantMessage: "not found: key"},
+		// The error takes precedence over the predicate.
+		{name: "missing key and empty value", next: typedErrorService{err: &examples.NotFoundError{Key: "key"}}, wantMessage: "not found: key"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			examplesmws.NewTracingTypedErrorService(tc.next).Resolve(context.Background(), "key")
+			assertStatus(t, spans.take(), tc.wantMessage)
+		})
+	}
+}
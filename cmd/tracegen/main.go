@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"errors"
 	"flag"
 	"fmt"
@@ -13,16 +14,30 @@ import (
 	"path/filepath"
 
 	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/output"
 	"github.com/Bo0mer/gentools/pkg/resolution"
 	"github.com/Bo0mer/gentools/pkg/transformation"
+	"github.com/Bo0mer/gentools/pkg/typecheck"
+)
+
+var (
+	outputOptions output.Options
+	naming        = transformation.Naming{Template: "{{.Package}}.{{.Interface}}.{{.Name}}"}
+	toggles       bool
+	panics        bool
 )
 
 func init() {
+	outputOptions.RegisterFlags(flag.CommandLine)
+	naming.RegisterFlags(flag.CommandLine)
+	flag.BoolVar(&toggles, "toggles", false, "Make the constructor accept runtime controls that disable or sample the tracing of every method")
+	flag.BoolVar(&panics, "panics", false, "Annotate the span of a call with its panic and mark it failed, and raise the panic again")
+
 	flag.Usage = func() {
 		var out io.Writer = os.Stdout
 
 		fmt.Fprintln(out, "A tool that generates tracing wrappers for interfaces.")
-		fmt.Fprintf(out, "Usage: %s [-h] SOURCE_DIR INTERFACE_NAME\n", path.Base(os.Args[0]))
+		fmt.Fprintf(out, "Usage: %s [-h] [-check | -diff] [OPTIONS] SOURCE_DIR INTERFACE_NAME\n", path.Base(os.Args[0]))
 		fmt.Fprintln(out, "")
 		fmt.Fprintln(out, "  Arguments:")
 		fmt.Fprintln(out, "    SOURCE_DIR       Path to the file containing the interface")
@@ -30,6 +45,25 @@ func init() {
 		fmt.Fprintln(out, "")
 		fmt.Fprintln(out, "  Options:")
 		fmt.Fprintln(out, "    -h               Print this text and exit")
+		fmt.Fprintln(out, "    -check           Fail with a diff if the output file is not up to date")
+		fmt.Fprintln(out, "    -diff            Print a diff against the output file instead of writing it")
+		fmt.Fprintln(out, "    -output-dir DIR  Directory of the generated package")
+		fmt.Fprintln(out, "                     Defaults to SOURCE_DIR/<package>mws")
+		fmt.Fprintln(out, "    -o FILE          Output file, relative to the output directory, or - for stdout")
+		fmt.Fprintln(out, "    -pkg NAME        Name of the generated package")
+		fmt.Fprintln(out, "    -type NAME       Name of the generated wrapper type")
+		fmt.Fprintln(out, "    -constructor NAME")
+		fmt.Fprintln(out, "                     Name of the generated constructor")
+		fmt.Fprintln(out, "    -name-case CASE  Case of the method in span names: snake, kebab, camel or dotted")
+		fmt.Fprintln(out, "    -name-template TEMPLATE")
+		fmt.Fprintln(out, "                     Go template of span names")
+		fmt.Fprintln(out, "                     Defaults to {{.Package}}.{{.Interface}}.{{.Name}}")
+		fmt.Fprintln(out, "    -toggles         Make the constructor accept runtime controls that disable or sample the")
+		fmt.Fprintln(out, "                     tracing of every method")
+		fmt.Fprintln(out, "    -panics          Annotate the span of a call with its panic and mark it failed, and raise")
+		fmt.Fprintln(out, "                     the panic again")
+		fmt.Fprintln(out, "    -prune           Remove files generated for interfaces that no longer exist")
+		fmt.Fprintln(out, "    -typecheck=false Do not type-check the generated source before writing it")
 		fmt.Fprintln(out, "")
 	}
 }
@@ -46,6 +80,12 @@ func parseArgs() (sourceDir, interfaceName string, err error) {
 		return "", "", fmt.Errorf("error determining absolute path to source directory: %v", err)
 	}
 	interfaceName = flag.Arg(1)
+	if err := outputOptions.Validate(); err != nil {
+		return "", "", err
+	}
+	if err := naming.Parse(); err != nil {
+		return "", "", err
+	}
 
 	return sourceDir, interfaceName, nil
 }
@@ -59,7 +99,10 @@ func main() {
 	if err != nil {
 		log.Fatalf("error resolving import path of source directory: %v", err)
 	}
-	targetPkg := path.Base(sourcePkgPath) + "mws"
+	target := outputOptions.Target(
+		filepath.Join(sourceDir, path.Base(sourcePkgPath)+"mws"),
+		filename(interfaceName),
+	)
 
 	locator := resolution.NewLocator()
 
@@ -68,10 +111,21 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	sourceHash, err := locator.DeclarationHash(d)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	typeName := fmt.Sprintf("tracing%s", interfaceName)
+	if outputOptions.TypeName != "" {
+		typeName = outputOptions.TypeName
+	}
+	constructorName := fmt.Sprintf("NewTracing%s", interfaceName)
+	if outputOptions.ConstructorName != "" {
+		constructorName = outputOptions.ConstructorName
+	}
 
-	model := newModel(sourcePkgPath, interfaceName, typeName, targetPkg)
+	model := newModel(sourcePkgPath, interfaceName, typeName, constructorName, target.Package, target.ImportPath, naming, toggles, panics)
 	generator := astgen.Generator{
 		Model:    model,
 		Locator:  locator,
@@ -83,28 +137,28 @@ func main() {
 		log.Fatal(err)
 	}
 
-	targetPkgPath := filepath.Join(sourceDir, targetPkg)
-	if err := os.MkdirAll(targetPkgPath, 0777); err != nil {
-		log.Fatalf("error creating target package directory: %v", err)
+	header := output.Header{
+		Generator:  "tracegen",
+		Version:    output.Version,
+		Source:     sourcePkgPath + "." + interfaceName,
+		SourceHash: sourceHash,
+		Args:       output.Args(flag.CommandLine, target, sourceDir, flag.Args()[1:]...),
 	}
-
-	fd, err := os.Create(filepath.Join(targetPkgPath, filename(interfaceName)))
-	if err != nil {
-		log.Fatalf("error creating output source file: %v", err)
+	var src bytes.Buffer
+	if err := model.WriteSource(header, &src); err != nil {
+		log.Fatal(err)
 	}
-	defer fd.Close()
 
-	err = model.WriteSource(fd)
-	if err != nil {
-		log.Fatal(err)
+	if outputOptions.TypeCheck {
+		if err := typecheck.Check("tracegen", target.Dir, target.Name, src.Bytes()); err != nil {
+			log.Fatal(err)
+		}
 	}
 
-	wd, _ := os.Getwd()
-	path, err := filepath.Rel(wd, fd.Name())
+	err = outputOptions.Save(target, src.Bytes(), "tracing", header.Source)
 	if err != nil {
-		path = fd.Name()
+		log.Fatal(err)
 	}
-	fmt.Printf("Wrote tracing implementation of %q to %q\n", sourcePkgPath+"."+interfaceName, path)
 }
 
 func filename(interfaceName string) string {
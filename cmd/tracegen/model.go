@@ -6,8 +6,10 @@ import (
 	"go/format"
 	"go/token"
 	"io"
+	"strconv"
 
 	"github.com/Bo0mer/gentools/pkg/astgen"
+	"github.com/Bo0mer/gentools/pkg/output"
 	"github.com/Bo0mer/gentools/pkg/transformation"
 )
 
@@ -16,13 +18,17 @@ type model struct {
 	interfaceName string
 	fileBuilder   *astgen.File
 	structName    string
+	strct         *astgen.Struct
+	naming        transformation.Naming
+	toggles       *astgen.Toggles
+	panics        bool
 
 	tracePackageAlias   string
 	contextPackageAlias string
 }
 
-func newModel(interfacePath, interfaceName, structName, targetPkg string) *model {
-	file := astgen.NewFile(targetPkg)
+func newModel(interfacePath, interfaceName, structName, constructorName, targetPkg, targetPath string, naming transformation.Naming, toggles, panics bool) *model {
+	file := astgen.NewFile(targetPkg, targetPath)
 	strct := astgen.NewStruct(structName)
 	file.AppendDeclaration(strct)
 
@@ -31,20 +37,28 @@ func newModel(interfacePath, interfaceName, structName, targetPkg string) *model
 		interfaceName: interfaceName,
 		fileBuilder:   file,
 		structName:    structName,
+		strct:         strct,
+		naming:        naming,
+		panics:        panics,
 	}
 	sourcePackageAlias := m.AddImport("", interfacePath)
 	m.tracePackageAlias = m.AddImport("", "go.opencensus.io/trace")
 
-	constructorBuilder := newConstructorBuilder(sourcePackageAlias, interfaceName)
+	constructorBuilder := newConstructorBuilder(sourcePackageAlias, interfaceName, structName, constructorName)
 	file.AppendDeclaration(constructorBuilder)
 
 	strct.AddField("next", sourcePackageAlias, interfaceName)
 
+	if toggles {
+		m.toggles = astgen.NewToggles(m.AddImport("", astgen.TogglePackage), interfaceName)
+		constructorBuilder.toggles = m.toggles
+	}
+
 	return m
 }
 
-func (m *model) WriteSource(w io.Writer) error {
-	fmt.Fprintf(w, "// Code generated by tracegen. DO NOT EDIT.\n")
+func (m *model) WriteSource(header output.Header, w io.Writer) error {
+	fmt.Fprint(w, header.String())
 	astFile := m.fileBuilder.Build()
 
 	if err := format.Node(w, token.NewFileSet(), astFile); err != nil {
@@ -62,10 +76,28 @@ func (m *model) AddImport(pkgName, location string) string {
 }
 
 func (m *model) AddMethod(method *astgen.MethodConfig) error {
-	fullMethodName := fmt.Sprintf("%s.%s.%s", m.interfacePath, m.interfaceName, method.MethodName)
-	mmb := newTracingMethodBuilder(m.structName, method, m.tracePackageAlias, m.contextPackageAlias, fullMethodName)
+	spanName, err := m.naming.Operation(transformation.Operation{
+		Package:   m.interfacePath,
+		Interface: m.interfaceName,
+		Method:    method.MethodName,
+	})
+	if err != nil {
+		return err
+	}
+	mmb := newTracingMethodBuilder(m.structName, method, m.tracePackageAlias, m.contextPackageAlias, spanName)
+	// Only the calls with a span have a panic to annotate it with, or any
+	// instrumentation to bypass.
+	_, traced := contextParamName(method, m.contextPackageAlias)
+	if traced && m.panics {
+		mmb.AnnotatePanics(m.AddImport("", "fmt"))
+	}
 
-	m.fileBuilder.AppendDeclaration(mmb)
+	var decl astgen.DeclarationBuilder = mmb
+	if traced && m.toggles != nil {
+		m.toggles.AddMethod(m.strct, method.MethodName)
+		decl = m.toggles.Guard(method, mmb)
+	}
+	m.fileBuilder.AppendDeclaration(decl)
 	return nil
 }
 
@@ -80,28 +112,53 @@ func (m *model) resolveInterfaceType(location, name string) *ast.SelectorExpr {
 type constructorBuilder struct {
 	interfacePackageName string
 	interfaceName        string
+	structName           string
+	constructorName      string
+	// toggles are the runtime controls of the methods, if enabled.
+	toggles *astgen.Toggles
 }
 
-func newConstructorBuilder(packageName, interfaceName string) *constructorBuilder {
+func newConstructorBuilder(packageName, interfaceName, structName, constructorName string) *constructorBuilder {
 	return &constructorBuilder{
 		interfacePackageName: packageName,
 		interfaceName:        interfaceName,
+		structName:           structName,
+		constructorName:      constructorName,
 	}
 }
 
 func (c *constructorBuilder) Build() ast.Decl {
+	elts := []ast.Expr{
+		&ast.KeyValueExpr{Key: ast.NewIdent("next"), Value: ast.NewIdent("next")},
+	}
+	params := []*ast.Field{
+		&ast.Field{
+			Names: []*ast.Ident{ast.NewIdent("next")},
+			Type:  astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
+		},
+	}
+	if c.toggles != nil {
+		elts = append(elts, c.toggles.Init()...)
+		params = append(params, c.toggles.Param())
+	}
+
 	funcBody := &ast.BlockStmt{
 		List: []ast.Stmt{
 			&ast.ReturnStmt{
 				Results: []ast.Expr{
-					// TODO(borshukov): Find a better way to do this.
-					ast.NewIdent(fmt.Sprintf("&tracing%s{next}", c.interfaceName)),
+					&ast.UnaryExpr{
+						Op: token.AND,
+						X: &ast.CompositeLit{
+							Type: ast.NewIdent(c.structName),
+							Elts: elts,
+						},
+					},
 				},
 			},
 		},
 	}
 
-	funcName := fmt.Sprintf("NewTracing%s", c.interfaceName)
+	funcName := c.constructorName
 	return &ast.FuncDecl{
 		Doc: &ast.CommentGroup{
 			List: []*ast.Comment{&ast.Comment{
@@ -111,24 +168,13 @@ func (c *constructorBuilder) Build() ast.Decl {
 		Name: ast.NewIdent(funcName),
 		Type: &ast.FuncType{
 			Params: &ast.FieldList{
-				List: []*ast.Field{
-					&ast.Field{
-						Names: []*ast.Ident{ast.NewIdent("next")},
-						Type: &ast.SelectorExpr{
-							X:   ast.NewIdent(c.interfacePackageName),
-							Sel: ast.NewIdent(c.interfaceName),
-						},
-					},
-				},
+				List: params,
 			},
 			Results: &ast.FieldList{
 				List: []*ast.Field{
 					&ast.Field{
 						Names: []*ast.Ident{ast.NewIdent("")},
-						Type: &ast.SelectorExpr{
-							X:   ast.NewIdent(c.interfacePackageName),
-							Sel: ast.NewIdent(c.interfaceName),
-						},
+						Type:  astgen.QualifiedName(c.interfacePackageName, c.interfaceName),
 					},
 				},
 			},
@@ -137,12 +183,23 @@ func (c *constructorBuilder) Build() ast.Decl {
 	}
 }
 
+// failedStatusMessage is the status message of the spans of the calls that
+// failed without an error, as their failure predicate holds.
+const failedStatusMessage = "failed"
+
+// panicStatusMessage is the status message, and the annotation, of the spans
+// of the calls that panicked.
+const panicStatusMessage = "panic"
+
 type tracingMethodBuilder struct {
 	fullMethodName      string
 	methodConfig        *astgen.MethodConfig
 	method              *astgen.Method
 	tracePackageAlias   string
 	contextPackageAlias string
+	// fmtPackageAlias is the alias of the package that formats a panic, if
+	// panics are annotated.
+	fmtPackageAlias string
 }
 
 func newTracingMethodBuilder(structName string, methodConfig *astgen.MethodConfig, tracePackageAlias, contextPackageAlias, fullMethodName string) *tracingMethodBuilder {
@@ -156,6 +213,13 @@ func newTracingMethodBuilder(structName string, methodConfig *astgen.MethodConfi
 		contextPackageAlias: contextPackageAlias,
 	}
 }
+
+// AnnotatePanics makes the method annotate the span with a panic of the call
+// and mark it failed, before raising the panic again.
+func (b *tracingMethodBuilder) AnnotatePanics(fmtPackageAlias string) {
+	b.fmtPackageAlias = fmtPackageAlias
+}
+
 func (b *tracingMethodBuilder) Build() ast.Decl {
 	b.method.SetType(&ast.FuncType{
 		Params: &ast.FieldList{
@@ -169,34 +233,175 @@ func (b *tracingMethodBuilder) Build() ast.Decl {
 	// If the first parameter is context, add tracing call.
 	//   ctx, span := trace.StartSpan(ctx, "github.com/pkg.Component.Method")
 	//   defer span.End()
-	if len(b.methodConfig.MethodParams) > 0 {
-		p1 := b.methodConfig.MethodParams[0]
-		if sel, ok := p1.Type.(*ast.SelectorExpr); ok {
-			if sel.Sel.String() == "Context" {
-				if id, ok := sel.X.(*ast.Ident); ok && id.String() == b.contextPackageAlias {
-					b.method.AddStatement(
-						newTraceMethodInvocation(b.tracePackageAlias,
-							p1.Names[0].Name, b.fullMethodName))
-
-					b.method.AddStatement(newEndSpanStmt())
-
-				}
-			}
-		}
+	ctxParamName, traced := contextParamName(b.methodConfig, b.contextPackageAlias)
+	if traced {
+		b.method.AddStatement(
+			newTraceMethodInvocation(b.tracePackageAlias,
+				ctxParamName, b.fullMethodName))
+
+		b.method.AddStatement(newEndSpanStmt())
+	}
+
+	// Annotate the span with a panic of the call, and raise it again:
+	//   defer func() {
+	//     if _panic := recover(); _panic != nil {
+	//       _span.Annotate([]trace.Attribute{trace.StringAttribute("panic", fmt.Sprint(_panic))}, "panic")
+	//       _span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: "panic"})
+	//       panic(_panic)
+	//     }
+	//   }()
+	if traced && b.fmtPackageAlias != "" {
+		b.method.AddStatement(astgen.Recover(
+			newPanicAnnotationStmt(b.tracePackageAlias, b.fmtPackageAlias),
+			newFailedSpanStmt(b.tracePackageAlias, stringLit(panicStatusMessage)),
+		))
 	}
 
 	// Add method invocation:
-	//   result1, result2 := m.next.Method(arg1, arg2)
+	//   return m.next.Method(arg1, arg2)
 	methodInvocation := NewMethodInvocation(b.methodConfig)
 	methodInvocation.SetReceiver(&ast.SelectorExpr{
 		X:   ast.NewIdent("m"), // receiver name
 		Sel: ast.NewIdent("next"),
 	})
-	b.method.AddStatement(methodInvocation.Build())
+	errorResult := b.methodConfig.ErrorResult()
+	if !traced || (errorResult == nil && b.methodConfig.FailurePredicate == nil) {
+		b.method.AddStatement(methodInvocation.Build())
+		return b.method.Build()
+	}
+
+	// Mark the span as failed with the error if the call returned one, or
+	// with a generic message if the failure predicate holds, like mongen and
+	// logen do:
+	//   result1, result2, result3 := m.next.Method(arg1, arg2)
+	//   if result3 != nil {
+	//     _span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: result3.Error()})
+	//   } else if !result2 {
+	//     _span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: "failed"})
+	//   }
+	//   return result1, result2, result3
+	var results []ast.Expr
+	for _, result := range b.methodConfig.MethodResults {
+		results = append(results, ast.NewIdent(result.Names[0].Name))
+	}
+	if len(results) > 0 {
+		b.method.AddStatement(&ast.AssignStmt{
+			Lhs: results,
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{methodInvocation.Call()},
+		})
+	} else {
+		b.method.AddStatement(&ast.ExprStmt{X: methodInvocation.Call()})
+	}
+	var failed *ast.IfStmt
+	if pred := b.methodConfig.FailurePredicate; pred != nil {
+		failed = &ast.IfStmt{
+			Cond: pred,
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{newFailedSpanStmt(b.tracePackageAlias, stringLit(failedStatusMessage))},
+			},
+		}
+	}
+	if errorResult != nil {
+		message := &ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: ast.NewIdent(errorResult.Name), Sel: ast.NewIdent("Error")},
+		}
+		errorFailed := &ast.IfStmt{
+			Cond: &ast.BinaryExpr{X: ast.NewIdent(errorResult.Name), Op: token.NEQ, Y: ast.NewIdent("nil")},
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{newFailedSpanStmt(b.tracePackageAlias, message)},
+			},
+		}
+		if failed != nil {
+			errorFailed.Else = failed
+		}
+		failed = errorFailed
+	}
+	b.method.AddStatement(failed)
+	if len(results) > 0 {
+		b.method.AddStatement(&ast.ReturnStmt{Results: results})
+	}
 
 	return b.method.Build()
 }
 
+// contextParamName returns the name of the first parameter of the method if it
+// is a context.Context, whose calls are traced.
+func contextParamName(method *astgen.MethodConfig, contextPackageAlias string) (string, bool) {
+	if len(method.MethodParams) == 0 {
+		return "", false
+	}
+	p1 := method.MethodParams[0]
+	if sel, ok := p1.Type.(*ast.SelectorExpr); ok && sel.Sel.String() == "Context" {
+		if id, ok := sel.X.(*ast.Ident); ok && id.String() == contextPackageAlias {
+			return p1.Names[0].Name, true
+		}
+	}
+	return "", false
+}
+
+// newPanicAnnotationStmt builds a statement that annotates the span with a
+// recovered panic:
+// _span.Annotate([]trace.Attribute{trace.StringAttribute("panic", fmt.Sprint(_panic))}, "panic")
+func newPanicAnnotationStmt(tracePackageAlias, fmtPackageAlias string) ast.Stmt {
+	message := stringLit(panicStatusMessage)
+	return &ast.ExprStmt{
+		X: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   ast.NewIdent("_span"),
+				Sel: ast.NewIdent("Annotate"),
+			},
+			Args: []ast.Expr{
+				&ast.CompositeLit{
+					Type: &ast.ArrayType{Elt: astgen.QualifiedName(tracePackageAlias, "Attribute")},
+					Elts: []ast.Expr{
+						&ast.CallExpr{
+							Fun: astgen.QualifiedName(tracePackageAlias, "StringAttribute"),
+							Args: []ast.Expr{
+								message,
+								&ast.CallExpr{
+									Fun:  astgen.QualifiedName(fmtPackageAlias, "Sprint"),
+									Args: []ast.Expr{ast.NewIdent(astgen.PanicVarName)},
+								},
+							},
+						},
+					},
+				},
+				message,
+			},
+		},
+	}
+}
+
+// newFailedSpanStmt builds a statement that sets the status of the span to
+// unknown error with the message:
+// _span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: "failed"})
+func newFailedSpanStmt(tracePackageAlias string, message ast.Expr) ast.Stmt {
+	return &ast.ExprStmt{
+		X: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   ast.NewIdent("_span"),
+				Sel: ast.NewIdent("SetStatus"),
+			},
+			Args: []ast.Expr{
+				&ast.CompositeLit{
+					Type: astgen.QualifiedName(tracePackageAlias, "Status"),
+					Elts: []ast.Expr{
+						&ast.KeyValueExpr{
+							Key:   ast.NewIdent("Code"),
+							Value: astgen.QualifiedName(tracePackageAlias, "StatusCodeUnknown"),
+						},
+						&ast.KeyValueExpr{
+							Key:   ast.NewIdent("Message"),
+							Value: message,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func newEndSpanStmt() ast.Stmt {
 	callExpr := &ast.CallExpr{
 		Fun: &ast.SelectorExpr{
@@ -251,7 +456,8 @@ func NewMethodInvocation(method *astgen.MethodConfig) *MethodInvocation {
 	return &MethodInvocation{method: method}
 }
 
-func (m *MethodInvocation) Build() ast.Stmt {
+// Call returns the invocation of the method.
+func (m *MethodInvocation) Call() *ast.CallExpr {
 	var paramSelectors []ast.Expr
 	var ellipsisPos token.Pos
 	for _, param := range m.method.MethodParams {
@@ -261,7 +467,7 @@ func (m *MethodInvocation) Build() ast.Stmt {
 		}
 	}
 
-	callExpr := &ast.CallExpr{
+	return &ast.CallExpr{
 		Fun: &ast.SelectorExpr{
 			X:   m.receiver,
 			Sel: ast.NewIdent(m.method.MethodName),
@@ -269,7 +475,10 @@ func (m *MethodInvocation) Build() ast.Stmt {
 		Args:     paramSelectors,
 		Ellipsis: ellipsisPos,
 	}
+}
 
+func (m *MethodInvocation) Build() ast.Stmt {
+	callExpr := m.Call()
 	if m.method.HasResults() {
 		return &ast.ReturnStmt{
 			Results: []ast.Expr{callExpr},
@@ -277,3 +486,8 @@ func (m *MethodInvocation) Build() ast.Stmt {
 	}
 	return &ast.ExprStmt{X: callExpr}
 }
+
+// stringLit builds a string literal of s.
+func stringLit(s string) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(s)}
+}
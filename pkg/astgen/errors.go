@@ -0,0 +1,146 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+
+	"github.com/Bo0mer/gentools/pkg/resolution"
+)
+
+// ErrorResult describes the result of a method that reports its error.
+type ErrorResult struct {
+	// Name is the name of the result.
+	Name string
+
+	// Concrete reports whether the type of the result is not an interface,
+	// e.g. *MyError. A nil value of such a type converts to a non-nil
+	// error.
+	Concrete bool
+}
+
+// ErrorResult returns the first result of the method whose type implements
+// error and can be nil, in any position, or nil if there is none. If the
+// types of the results are unknown, the first result of the error type is
+// returned instead.
+func (s *MethodConfig) ErrorResult() *ErrorResult {
+	if s.ResultTypes == nil {
+		for _, result := range s.MethodResults {
+			if id, ok := result.Type.(*ast.Ident); ok && id.Name == "error" {
+				return &ErrorResult{Name: result.Names[0].String()}
+			}
+		}
+		return nil
+	}
+
+	errorType := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	for i, t := range s.ResultTypes {
+		if !isNillable(t) || !types.Implements(t, errorType) {
+			continue
+		}
+		return &ErrorResult{
+			Name:     s.MethodResults[i].Names[0].String(),
+			Concrete: !types.IsInterface(t),
+		}
+	}
+	return nil
+}
+
+// AsError builds the statements that convert the result to an error held by
+// the named variable, such that a nil result is a nil error:
+//
+//	var _err error
+//	if result2 != nil {
+//		_err = result2
+//	}
+//
+// There are no statements if the type of the result is an interface, and the
+// result itself is returned.
+func (r *ErrorResult) AsError(name string) ([]ast.Stmt, ast.Expr) {
+	if !r.Concrete {
+		return nil, ast.NewIdent(r.Name)
+	}
+	return []ast.Stmt{
+		&ast.DeclStmt{
+			Decl: &ast.GenDecl{
+				Tok: token.VAR,
+				Specs: []ast.Spec{
+					&ast.ValueSpec{
+						Names: []*ast.Ident{ast.NewIdent(name)},
+						Type:  ast.NewIdent("error"),
+					},
+				},
+			},
+		},
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{X: ast.NewIdent(r.Name), Op: token.NEQ, Y: ast.NewIdent("nil")},
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{
+					&ast.AssignStmt{
+						Lhs: []ast.Expr{ast.NewIdent(name)},
+						Tok: token.ASSIGN,
+						Rhs: []ast.Expr{ast.NewIdent(r.Name)},
+					},
+				},
+			},
+		},
+	}, ast.NewIdent(name)
+}
+
+func isNillable(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface, *types.Slice, *types.Map, *types.Chan, *types.Signature:
+		return true
+	}
+	return false
+}
+
+// interfaceType type checks the package of the discovered interface and
+// returns its type, or nil if the package does not type check.
+func (g *Generator) interfaceType(d resolution.TypeDiscovery) *types.Interface {
+	if g.packages == nil {
+		g.packages = make(map[string]*types.Package)
+		g.importer = importer.ForCompiler(token.NewFileSet(), "source", nil)
+	}
+	pkg, found := g.packages[d.Location]
+	if !found {
+		// Packages with errors that still type check are returned along
+		// with the error.
+		pkg, _ = g.importer.Import(d.Location)
+		g.packages[d.Location] = pkg
+	}
+	if pkg == nil {
+		return nil
+	}
+	obj, ok := pkg.Scope().Lookup(d.Spec.Name.Name).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	iface, _ := obj.Type().Underlying().(*types.Interface)
+	return iface
+}
+
+// resultTypes returns the types of the results of the named method of the
+// interface, or nil if they are unknown.
+func resultTypes(iface *types.Interface, name string, results int) []types.Type {
+	if iface == nil {
+		return nil
+	}
+	for i := 0; i < iface.NumMethods(); i++ {
+		method := iface.Method(i)
+		if method.Name() != name {
+			continue
+		}
+		tuple := method.Type().(*types.Signature).Results()
+		if tuple.Len() != results {
+			return nil
+		}
+		list := make([]types.Type, tuple.Len())
+		for j := range list {
+			list[j] = tuple.At(j).Type()
+		}
+		return list
+	}
+	return nil
+}
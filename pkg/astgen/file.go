@@ -13,16 +13,19 @@ type DeclarationBuilder interface {
 // File describes a single Go source file.
 type File struct {
 	packageName   string
+	location      string
 	importToAlias map[string]string
 	aliasToImport map[string]string
 	aliasCounter  int
 	declarations  []DeclarationBuilder
 }
 
-// NewFile returns new empty source file within the specified package.
-func NewFile(packageName string) *File {
+// NewFile returns new empty source file within the specified package. The
+// location is the import path of the package and may be empty if unknown.
+func NewFile(packageName, location string) *File {
 	return &File{
 		packageName:   packageName,
+		location:      location,
 		importToAlias: map[string]string{},
 		aliasToImport: map[string]string{},
 	}
@@ -30,7 +33,12 @@ func NewFile(packageName string) *File {
 
 // AddImport assures that the specified package name in the specified
 // location will be added as an import and returns the import package alias.
+// If the location is the one of the file itself, no import is added and an
+// empty alias is returned.
 func (f *File) AddImport(packageName, location string) (importAlias string) {
+	if location == f.location {
+		return ""
+	}
 	alias, locationAlreadyRegistered := f.importToAlias[location]
 	if locationAlreadyRegistered {
 		return alias
@@ -48,6 +56,26 @@ func (f *File) AddImport(packageName, location string) (importAlias string) {
 	return alias
 }
 
+// ImportAlias returns the alias of the package at the specified location, if
+// it has been imported.
+func (f *File) ImportAlias(location string) (string, bool) {
+	alias, ok := f.importToAlias[location]
+	return alias, ok
+}
+
+// QualifiedName returns an expression referring to the name declared in the
+// package imported with the specified alias. If the alias is empty, the name is
+// returned unqualified.
+func QualifiedName(importAlias, name string) ast.Expr {
+	if importAlias == "" {
+		return ast.NewIdent(name)
+	}
+	return &ast.SelectorExpr{
+		X:   ast.NewIdent(importAlias),
+		Sel: ast.NewIdent(name),
+	}
+}
+
 func (f *File) allocateUniqueAlias() string {
 	f.aliasCounter++
 	return fmt.Sprintf("alias%d", f.aliasCounter)
@@ -4,7 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/parser"
+	"go/types"
 
+	"github.com/Bo0mer/gentools/pkg/directive"
 	"github.com/Bo0mer/gentools/pkg/internal"
 	"github.com/Bo0mer/gentools/pkg/resolution"
 )
@@ -26,6 +29,27 @@ type MethodConfig struct {
 	// resolved (i.e. all selector expressions resolved against the generated
 	// stub's new namespace)
 	MethodResults []*ast.Field
+
+	// Doc is the doc comment of the method in the interface declaration, if
+	// any.
+	Doc *ast.CommentGroup
+
+	// Context is the context of the file that declares the method. It can be
+	// used to resolve references found in the doc comment.
+	Context *resolution.LocatorContext
+
+	// FailurePredicate is the expression declared with a "//gentools:fail"
+	// directive in the doc comment of the method, if any. A call fails if
+	// it returns a non-nil error or if the predicate holds. It refers to the
+	// results as result1, result2 and so on, and to the parameters as arg1,
+	// arg2 and so on, and is resolved like the parameters. The names
+	// declared by the interface are bound to them as well.
+	FailurePredicate ast.Expr
+
+	// ResultTypes specifies the types of the results, in the order of
+	// MethodResults, or is nil if the package of the interface could not be
+	// type checked.
+	ResultTypes []types.Type
 }
 
 func (s *MethodConfig) HasParams() bool {
@@ -45,6 +69,11 @@ type Generator struct {
 	Model    ModelBuilder
 	Locator  *resolution.Locator
 	Resolver *resolution.Resolver
+
+	// packages caches the type checked packages of the processed
+	// interfaces by location.
+	packages map[string]*types.Package
+	importer types.Importer
 }
 
 func (g *Generator) ProcessInterface(d resolution.TypeDiscovery) error {
@@ -53,11 +82,12 @@ func (g *Generator) ProcessInterface(d resolution.TypeDiscovery) error {
 	if !isIFace {
 		return errors.New(fmt.Sprintf("type '%s' in '%s' is not interface!", d.Spec.Name.String(), d.Location))
 	}
+	iface := g.interfaceType(d)
 	for field := range internal.EachFieldInFieldList(iFaceType.Methods) {
 		var err error
 		switch t := field.Type.(type) {
 		case *ast.FuncType:
-			err = g.processMethod(context, field.Names[0].String(), t)
+			err = g.processMethod(context, iface, field.Names[0].String(), field.Doc, t)
 		case *ast.Ident:
 			err = g.processSubInterfaceIdent(context, t)
 		case *ast.SelectorExpr:
@@ -72,7 +102,7 @@ func (g *Generator) ProcessInterface(d resolution.TypeDiscovery) error {
 	return nil
 }
 
-func (g *Generator) processMethod(context *resolution.LocatorContext, name string, funcType *ast.FuncType) error {
+func (g *Generator) processMethod(context *resolution.LocatorContext, iface *types.Interface, name string, doc *ast.CommentGroup, funcType *ast.FuncType) error {
 	normalizedParams, err := g.getNormalizedParams(context, funcType)
 	if err != nil {
 		return err
@@ -82,10 +112,19 @@ func (g *Generator) processMethod(context *resolution.LocatorContext, name strin
 		return err
 	}
 
+	failurePredicate, err := g.failurePredicate(context, name, doc, funcType, normalizedParams, normalizedResults)
+	if err != nil {
+		return err
+	}
+
 	source := &MethodConfig{
-		MethodName:    name,
-		MethodParams:  normalizedParams,
-		MethodResults: normalizedResults,
+		MethodName:       name,
+		MethodParams:     normalizedParams,
+		MethodResults:    normalizedResults,
+		Doc:              doc,
+		Context:          context,
+		FailurePredicate: failurePredicate,
+		ResultTypes:      resultTypes(iface, name, len(normalizedResults)),
 	}
 	err = g.Model.AddMethod(source)
 	if err != nil {
@@ -94,6 +133,77 @@ func (g *Generator) processMethod(context *resolution.LocatorContext, name strin
 	return nil
 }
 
+// failurePredicate parses the failure predicate declared for the method with
+// a "//gentools:fail EXPR" directive, or returns nil if there is none. The
+// predicate may refer to the parameters and results by their normalized names
+// or by the names declared in funcType, which take precedence.
+func (g *Generator) failurePredicate(context *resolution.LocatorContext, name string, doc *ast.CommentGroup, funcType *ast.FuncType, params, results []*ast.Field) (ast.Expr, error) {
+	locals := make(map[string]bool)
+	for _, field := range params {
+		locals[field.Names[0].String()] = true
+	}
+	for _, field := range results {
+		locals[field.Names[0].String()] = true
+	}
+	declared := make(map[string]string)
+	declaredNames(funcType.Params, "arg", declared)
+	declaredNames(funcType.Results, "result", declared)
+	for name := range declared {
+		locals[name] = true
+	}
+
+	var predicate ast.Expr
+	for _, d := range directive.Parse("gentools", doc) {
+		if d.Name != "fail" {
+			continue
+		}
+		if predicate != nil {
+			return nil, fmt.Errorf("method %s: more than one fail directive", name)
+		}
+		expr, err := parser.ParseExpr(d.Args)
+		if err != nil {
+			return nil, fmt.Errorf("method %s: invalid fail directive %q: %v", name, d.Args, err)
+		}
+		predicate, err = g.Resolver.ResolveExpr(context, expr, locals)
+		if err != nil {
+			return nil, fmt.Errorf("method %s: invalid fail directive %q: %v", name, d.Args, err)
+		}
+		renameIdents(predicate, declared)
+	}
+	return predicate, nil
+}
+
+// declaredNames maps the names declared in the field list to the normalized
+// names of the fields, prefix followed by their position.
+func declaredNames(fields *ast.FieldList, prefix string, names map[string]string) {
+	index := 1
+	for field := range internal.EachFieldInFieldList(fields) {
+		for i := 0; i < internal.FieldTypeReuseCount(field); i++ {
+			if i < len(field.Names) && field.Names[i].Name != "_" {
+				names[field.Names[i].Name] = fmt.Sprintf("%s%d", prefix, index)
+			}
+			index++
+		}
+	}
+}
+
+// renameIdents renames the identifiers of the expression found in names, but
+// not the selected fields and methods.
+func renameIdents(expr ast.Expr, names map[string]string) {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			renameIdents(n.X, names)
+			return false
+		case *ast.Ident:
+			if name, ok := names[n.Name]; ok {
+				n.Name = name
+			}
+		}
+		return true
+	})
+}
+
 func (g *Generator) processSubInterfaceIdent(context *resolution.LocatorContext, ident *ast.Ident) error {
 	discovery, err := g.Locator.FindIdentType(context, ident)
 	if err != nil {
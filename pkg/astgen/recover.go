@@ -0,0 +1,50 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// PanicVarName is the name of the variable that holds the recovered value in
+// the statements built by Recover.
+const PanicVarName = "_panic"
+
+// Recover builds a statement that records a panic with the statements, and
+// raises it again:
+//
+//	defer func() {
+//		if _panic := recover(); _panic != nil {
+//			m.logger.Log("method", "DoWork", "panic", _panic)
+//			panic(_panic)
+//		}
+//	}()
+//
+// The statement must precede the call of the wrapped implementation. As the
+// panic continues from the deferred call, its stack trace still includes the
+// frames of the call.
+func Recover(record ...ast.Stmt) ast.Stmt {
+	recovered := ast.NewIdent(PanicVarName)
+	body := append(record, &ast.ExprStmt{
+		X: &ast.CallExpr{Fun: ast.NewIdent("panic"), Args: []ast.Expr{recovered}},
+	})
+	return &ast.DeferStmt{
+		Call: &ast.CallExpr{
+			Fun: &ast.FuncLit{
+				Type: &ast.FuncType{Params: &ast.FieldList{}},
+				Body: &ast.BlockStmt{
+					List: []ast.Stmt{
+						&ast.IfStmt{
+							Init: &ast.AssignStmt{
+								Lhs: []ast.Expr{recovered},
+								Tok: token.DEFINE,
+								Rhs: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent("recover")}},
+							},
+							Cond: &ast.BinaryExpr{X: recovered, Op: token.NEQ, Y: ast.NewIdent("nil")},
+							Body: &ast.BlockStmt{List: body},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -25,10 +25,7 @@ func (s *Struct) AddField(name, typePackage, typeName string) {
 			Names: []*ast.Ident{
 				ast.NewIdent(name),
 			},
-			Type: &ast.SelectorExpr{
-				X:   ast.NewIdent(typePackage),
-				Sel: ast.NewIdent(typeName),
-			},
+			Type: QualifiedName(typePackage, typeName),
 		},
 	)
 }
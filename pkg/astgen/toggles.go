@@ -0,0 +1,155 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/token"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TogglePackage is the import path of the package that controls at runtime
+// which calls are instrumented by the generated wrappers.
+const TogglePackage = "github.com/Bo0mer/gentools/pkg/toggle"
+
+// TogglesParamName is the name of the constructor parameter that accepts the
+// runtime controls.
+const TogglesParamName = "toggles"
+
+// Toggles builds the parts of a wrapper that let the instrumentation of its
+// methods be disabled or sampled at runtime: a field per method that holds
+// the control of the method, the constructor parameter and field initializers
+// that set them, and the guard that bypasses the instrumentation of a call.
+type Toggles struct {
+	packageAlias  string
+	interfaceName string
+	methodNames   []string
+}
+
+// NewToggles creates the toggles of the methods of the interface, where
+// packageAlias is the alias of TogglePackage in the generated file.
+func NewToggles(packageAlias, interfaceName string) *Toggles {
+	return &Toggles{
+		packageAlias:  packageAlias,
+		interfaceName: interfaceName,
+	}
+}
+
+// Param returns the constructor parameter that accepts the controls:
+//
+//	toggles *toggle.Controls
+func (t *Toggles) Param() *ast.Field {
+	return &ast.Field{
+		Names: []*ast.Ident{ast.NewIdent(TogglesParamName)},
+		Type:  &ast.StarExpr{X: QualifiedName(t.packageAlias, "Controls")},
+	}
+}
+
+// AddMethod adds the field that holds the control of the method to the
+// struct.
+func (t *Toggles) AddMethod(strct *Struct, methodName string) {
+	t.methodNames = append(t.methodNames, methodName)
+	strct.AddFieldWithType(toggleFieldName(methodName), &ast.StarExpr{X: QualifiedName(t.packageAlias, "Method")})
+}
+
+// Init returns the struct field initializers of the controls of the methods
+// added so far:
+//
+//	doWorkToggle: toggles.Method("Service.DoWork"),
+func (t *Toggles) Init() []ast.Expr {
+	var elts []ast.Expr
+	for _, methodName := range t.methodNames {
+		elts = append(elts, &ast.KeyValueExpr{
+			Key: ast.NewIdent(toggleFieldName(methodName)),
+			Value: &ast.CallExpr{
+				Fun: &ast.SelectorExpr{X: ast.NewIdent(TogglesParamName), Sel: ast.NewIdent("Method")},
+				Args: []ast.Expr{&ast.BasicLit{
+					Kind:  token.STRING,
+					Value: `"` + t.interfaceName + "." + methodName + `"`,
+				}},
+			},
+		})
+	}
+	return elts
+}
+
+// Guard returns a builder of the method built by method, that starts with a
+// call of the wrapped implementation if the current call is not instrumented:
+//
+//	if !m.doWorkToggle.Sample() {
+//		return m.next.DoWork(arg1, arg2)
+//	}
+//
+// Methods without results have nothing to return, so their instrumented body
+// is put in the else branch of the guard instead:
+//
+//	if !m.closeToggle.Sample() {
+//		m.next.Close()
+//	} else {
+//		...
+//	}
+//
+// The receiver of the method must be named m, and the wrapped implementation
+// must be held by its next field.
+func (t *Toggles) Guard(methodConfig *MethodConfig, method DeclarationBuilder) DeclarationBuilder {
+	return toggleGuard{methodConfig: methodConfig, method: method}
+}
+
+type toggleGuard struct {
+	methodConfig *MethodConfig
+	method       DeclarationBuilder
+}
+
+func (g toggleGuard) Build() ast.Decl {
+	receiver := ast.NewIdent("m")
+	var args []ast.Expr
+	var ellipsis token.Pos
+	for _, param := range g.methodConfig.MethodParams {
+		args = append(args, ast.NewIdent(param.Names[0].String()))
+		if _, ok := param.Type.(*ast.Ellipsis); ok {
+			ellipsis = 1
+		}
+	}
+	call := &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   &ast.SelectorExpr{X: receiver, Sel: ast.NewIdent("next")},
+			Sel: ast.NewIdent(g.methodConfig.MethodName),
+		},
+		Args:     args,
+		Ellipsis: ellipsis,
+	}
+	decl := g.method.Build().(*ast.FuncDecl)
+	guard := &ast.IfStmt{
+		Cond: &ast.UnaryExpr{
+			Op: token.NOT,
+			X: &ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X:   &ast.SelectorExpr{X: receiver, Sel: ast.NewIdent(toggleFieldName(g.methodConfig.MethodName))},
+					Sel: ast.NewIdent("Sample"),
+				},
+			},
+		},
+	}
+	if g.methodConfig.HasResults() {
+		guard.Body = &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{call}}}}
+		decl.Body.List = append([]ast.Stmt{guard}, decl.Body.List...)
+		return decl
+	}
+
+	instrumented := decl.Body.List
+	if n := len(instrumented); n > 0 {
+		if ret, ok := instrumented[n-1].(*ast.ReturnStmt); ok && len(ret.Results) == 0 {
+			instrumented = instrumented[:n-1]
+		}
+	}
+	guard.Body = &ast.BlockStmt{List: []ast.Stmt{&ast.ExprStmt{X: call}}}
+	guard.Else = &ast.BlockStmt{List: instrumented}
+	decl.Body.List = []ast.Stmt{guard}
+	return decl
+}
+
+// toggleFieldName returns the name of the struct field that holds the
+// control of a method.
+func toggleFieldName(methodName string) string {
+	r, size := utf8.DecodeRuneInString(methodName)
+	return string(unicode.ToLower(r)) + methodName[size:] + "Toggle"
+}
@@ -0,0 +1,209 @@
+// Package catalog describes the metrics recorded by the monitoring
+// implementations generated by mongen, and renders Grafana dashboards and
+// Prometheus rules from the description. mongen writes catalogs with the
+// -catalog option, so that dashboards and alerts refer to the operation label
+// values it chose instead of hand-written ones.
+package catalog
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+)
+
+// metric types
+const (
+	Counter   = "counter"
+	Gauge     = "gauge"
+	Histogram = "histogram"
+)
+
+// metric roles
+const (
+	TotalOps    = "total_ops"
+	FailedOps   = "failed_ops"
+	OpsDuration = "ops_duration"
+	InFlightOps = "in_flight_ops"
+	StreamSize  = "stream_size"
+	ResultSize  = "result_size"
+)
+
+// Catalog lists the metrics, labels and operations of the monitoring
+// implementation of an interface.
+type Catalog struct {
+	// Header are the lines of the header of the generated file, which
+	// record how to regenerate it. They are written first, as the
+	// "generated" field of the JSON catalog and dashboard and as comments
+	// at the top of the rules.
+	Header []string `json:"generated,omitempty"`
+	// Generator is the tool and the version that generated the catalog.
+	Generator string `json:"generator"`
+	// Source is the interface, given as "importpath.Name".
+	Source string `json:"source"`
+	// Provider is the monitoring provider of the implementation.
+	Provider string `json:"provider"`
+	// Labels are the keys of the labels recorded by the implementation.
+	Labels Labels `json:"labels"`
+	// Metrics are the metrics recorded by the implementation.
+	Metrics []Metric `json:"metrics"`
+	// Operations are the operations of the interface, one per method.
+	Operations []Operation `json:"operations"`
+}
+
+// Labels are the keys of the labels recorded by an implementation.
+type Labels struct {
+	// Operation is the key of the label that distinguishes the operations.
+	Operation string `json:"operation"`
+	// ErrorClass is the key of the label of the class of the failures, if
+	// errors are classified.
+	ErrorClass string `json:"errorClass,omitempty"`
+	// Outcome is the key of the label of the outcome of the operations, if
+	// it is recorded.
+	Outcome string `json:"outcome,omitempty"`
+	// Extra are the keys of the labels declared with directives.
+	Extra []string `json:"extra,omitempty"`
+}
+
+// Metric describes a metric.
+type Metric struct {
+	// Name is the name of the metric, including its prefix.
+	Name string `json:"name"`
+	// Role is what the metric records: TotalOps, FailedOps, OpsDuration,
+	// InFlightOps, StreamSize or ResultSize. The durations of operations
+	// may be recorded by several metrics, one per group of buckets.
+	Role string `json:"role"`
+	// Type is Counter, Gauge or Histogram.
+	Type string `json:"type"`
+	// Help describes the metric.
+	Help string `json:"help"`
+	// Unit is the unit of the values of the metric, if any.
+	Unit string `json:"unit,omitempty"`
+	// Labels are the keys of the labels the metric is recorded with.
+	Labels []string `json:"labels"`
+	// Buckets are the upper bounds of the buckets of a histogram.
+	Buckets []float64 `json:"buckets,omitempty"`
+	// Group is the bucket group of a histogram of durations, unless it has
+	// the default buckets.
+	Group string `json:"group,omitempty"`
+}
+
+// Operation describes an operation of the interface.
+type Operation struct {
+	// Method is the name of the method.
+	Method string `json:"method"`
+	// Name is the value of the operation label.
+	Name string `json:"name"`
+	// Duration is the name of the histogram of the duration of the
+	// operation.
+	Duration string `json:"duration"`
+	// Labels are the keys of the labels declared for the operation.
+	Labels []string `json:"labels,omitempty"`
+	// ResultSize reports whether the size of the results of the operation
+	// is recorded.
+	ResultSize bool `json:"resultSize,omitempty"`
+	// Stream reports whether the operation lasts until the stream it
+	// returns ends.
+	Stream bool `json:"stream,omitempty"`
+}
+
+// Metric returns the first metric with the role, if any.
+func (c *Catalog) Metric(role string) (Metric, bool) {
+	for _, m := range c.Metrics {
+		if m.Role == role {
+			return m, true
+		}
+	}
+	return Metric{}, false
+}
+
+// metricName returns the name of the first metric with the role.
+func (c *Catalog) metricName(role string) string {
+	m, _ := c.Metric(role)
+	return m.Name
+}
+
+// durationGroup returns the bucket group of the histogram of durations, or ""
+// for the default buckets.
+func (c *Catalog) durationGroup(name string) string {
+	for _, m := range c.Metrics {
+		if m.Role == OpsDuration && m.Name == name {
+			return m.Group
+		}
+	}
+	return ""
+}
+
+// DurationGroups returns the names of the histograms of the durations of the
+// operations, and the operations recorded by each, in the order of the
+// operations.
+func (c *Catalog) DurationGroups() ([]string, map[string][]Operation) {
+	var names []string
+	groups := make(map[string][]Operation)
+	for _, op := range c.Operations {
+		if _, ok := groups[op.Duration]; !ok {
+			names = append(names, op.Duration)
+		}
+		groups[op.Duration] = append(groups[op.Duration], op)
+	}
+	return names, groups
+}
+
+// JSON returns the catalog as indented JSON.
+func (c *Catalog) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Parse parses a catalog written by JSON.
+func Parse(data []byte) (*Catalog, error) {
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// interfaceName returns the name of the interface of the catalog, without
+// its import path.
+func (c *Catalog) interfaceName() string {
+	return c.Source[strings.LastIndex(c.Source, ".")+1:]
+}
+
+// scope returns the name of the package and the name of the interface of the
+// catalog, e.g. "payments Service" for example.com/payments.Service, which
+// tell apart the rules of interfaces with the same name or metrics.
+func (c *Catalog) scope() string {
+	i := strings.LastIndex(c.Source, ".")
+	if i < 0 {
+		return c.Source
+	}
+	return path.Base(c.Source[:i]) + " " + c.Source[i+1:]
+}
+
+// selector returns the label selector that matches the operations.
+func selector(key string, ops []Operation) string {
+	names := make([]string, len(ops))
+	for i, op := range ops {
+		names[i] = regexpQuote(op.Name)
+	}
+	return key + `=~"` + strings.Join(names, "|") + `"`
+}
+
+// regexpQuote escapes the metacharacters of a RE2 expression, and the
+// backslashes for PromQL strings.
+func regexpQuote(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`\.+*?()|[]{}^$`, r) {
+			b.WriteString(`\\`)
+		}
+		if r == '"' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package catalog_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Bo0mer/gentools/pkg/catalog"
+)
+
+func parseFixture(t *testing.T) (*catalog.Catalog, []byte) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/catalog.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := catalog.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, data
+}
+
+func TestCatalogJSON(t *testing.T) {
+	c, want := parseFixture(t)
+	got, err := c.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestRules(t *testing.T) {
+	c, _ := parseFixture(t)
+	golden, err := os.ReadFile("testdata/rules.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(c.Rules(catalog.RuleOptions{
+		ErrorRatio:   0.05,
+		Latency:      0.5,
+		GroupLatency: map[string]float64{"batch": 600},
+	})); got != string(golden) {
+		t.Errorf("got %s, want %s", got, golden)
+	}
+
+	// the latency alerts are matched by their expressions, without and with
+	// the thresholds, and by their names, which tell the groups apart
+	const (
+		errorAlert       = `alert: "PaymentsServiceHighErrorRate"`
+		defaultLatency   = `payments_service_operation:payments_ops_duration_seconds:p99_5m{operation=~\"charge|refund\\\\.v2\"} >`
+		defaultAlert     = defaultLatency + ` 0.5"`
+		defaultAlertName = `alert: "PaymentsServiceHighLatency"`
+		batchLatency     = `payments_service_operation:payments_batch_ops_duration_seconds:p99_5m{operation=~\"settle\"} >`
+		batchAlert       = batchLatency + ` 600"`
+		batchAlertName   = `alert: "PaymentsServiceHighLatencyBatch"`
+	)
+	for _, tc := range []struct {
+		name    string
+		opts    catalog.RuleOptions
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "no alerts",
+			notWant: []string{"alert:"},
+		},
+		{
+			name:    "error ratio",
+			opts:    catalog.RuleOptions{ErrorRatio: 0.05},
+			want:    []string{errorAlert},
+			notWant: []string{"HighLatency"},
+		},
+		{
+			name:    "default latency only",
+			opts:    catalog.RuleOptions{Latency: 0.5},
+			want:    []string{defaultAlert, defaultAlertName},
+			notWant: []string{errorAlert, batchLatency, batchAlertName},
+		},
+		{
+			name:    "group latency only",
+			opts:    catalog.RuleOptions{GroupLatency: map[string]float64{"batch": 600}},
+			want:    []string{batchAlert, batchAlertName},
+			notWant: []string{errorAlert, defaultLatency, defaultAlertName},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := string(c.Rules(tc.opts))
+			if !strings.HasPrefix(rules, "# Code generated by mongen. DO NOT EDIT.\n# Source: example.com/payments.Service\ngroups:\n") {
+				t.Errorf("got rules without the header: %s", rules)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(rules, want) {
+					t.Errorf("got %s, want it to contain %q", rules, want)
+				}
+			}
+			for _, notWant := range tc.notWant {
+				if strings.Contains(rules, notWant) {
+					t.Errorf("got %s, want it not to contain %q", rules, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestRulesOfInterfacesWithTheSameName(t *testing.T) {
+	payments, _ := parseFixture(t)
+	billing, _ := parseFixture(t)
+	billing.Source = "example.com/billing.Service"
+	opts := catalog.RuleOptions{ErrorRatio: 0.05}
+
+	// The metrics are the same, but the names of the rules are not.
+	for _, want := range []string{
+		`record: "billing_service_operation:payments_total_ops:rate5m"`,
+		`alert: "BillingServiceHighErrorRate"`,
+	} {
+		if rules := string(billing.Rules(opts)); !strings.Contains(rules, want) {
+			t.Errorf("got %s, want it to contain %q", rules, want)
+		}
+		if rules := string(payments.Rules(opts)); strings.Contains(rules, want) {
+			t.Errorf("got %s, want it not to contain %q", rules, want)
+		}
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	c, _ := parseFixture(t)
+	golden, err := os.ReadFile("testdata/dashboard.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Dashboard()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(golden) {
+		t.Errorf("got %s, want %s", got, golden)
+	}
+}
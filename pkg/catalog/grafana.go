@@ -0,0 +1,204 @@
+package catalog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// grafana dashboard layout
+const (
+	panelWidth  = 12
+	panelHeight = 8
+)
+
+type dashboard struct {
+	Generated     []string   `json:"generated,omitempty"`
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Tags          []string   `json:"tags"`
+	Timezone      string     `json:"timezone"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Time          timeRange  `json:"time"`
+	Refresh       string     `json:"refresh"`
+	Templating    templating `json:"templating"`
+	Panels        []panel    `json:"panels"`
+}
+
+type timeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type templating struct {
+	List []variable `json:"list"`
+}
+
+type variable struct {
+	Name       string   `json:"name"`
+	Label      string   `json:"label"`
+	Type       string   `json:"type"`
+	Query      string   `json:"query"`
+	Multi      bool     `json:"multi,omitempty"`
+	IncludeAll bool     `json:"includeAll,omitempty"`
+	AllValue   string   `json:"allValue,omitempty"`
+	Current    *current `json:"current,omitempty"`
+}
+
+type current struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+type panel struct {
+	ID          int         `json:"id"`
+	Title       string      `json:"title"`
+	Type        string      `json:"type"`
+	Datasource  datasource  `json:"datasource"`
+	GridPos     gridPos     `json:"gridPos"`
+	FieldConfig fieldConfig `json:"fieldConfig"`
+	Targets     []target    `json:"targets"`
+}
+
+type datasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type gridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type fieldConfig struct {
+	Defaults fieldDefaults `json:"defaults"`
+}
+
+type fieldDefaults struct {
+	Unit string `json:"unit"`
+}
+
+type target struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+}
+
+// Dashboard renders a Grafana dashboard in JSON, with a panel per metric of
+// the catalog: the rates of the operations and their failures, the ratio of
+// the failed operations, the median and 99th percentile of their durations,
+// and the operations in progress and the sizes of their results and streams,
+// if recorded. The dashboard has a variable for the Prometheus data source,
+// and a variable that selects the operations shown.
+//
+// The uid of the dashboard is derived from the source of the catalog, so that
+// regenerated dashboards replace the ones imported before.
+func (c *Catalog) Dashboard() ([]byte, error) {
+	op := c.Labels.Operation
+	var names []string
+	for _, o := range c.Operations {
+		names = append(names, regexpQuote(o.Name))
+	}
+	sel := op + `=~"$operation"`
+	rate := func(metric string, by ...string) string {
+		groups := strings.Join(append([]string{op}, by...), ", ")
+		return fmt.Sprintf("sum by (%s) (rate(%s{%s}[$__rate_interval]))", groups, metric, sel)
+	}
+	legend := "{{" + op + "}}"
+
+	var panels []panel
+	addPanel := func(title, unit string, targets ...target) {
+		for i := range targets {
+			targets[i].RefID = string(rune('A' + i))
+		}
+		n := len(panels)
+		panels = append(panels, panel{
+			ID:          n + 1,
+			Title:       title,
+			Type:        "timeseries",
+			Datasource:  datasource{Type: "prometheus", UID: "${datasource}"},
+			GridPos:     gridPos{H: panelHeight, W: panelWidth, X: n % 2 * panelWidth, Y: n / 2 * panelHeight},
+			FieldConfig: fieldConfig{Defaults: fieldDefaults{Unit: unit}},
+			Targets:     targets,
+		})
+	}
+
+	totalOps := c.metricName(TotalOps)
+	failedOps := c.metricName(FailedOps)
+	addPanel("Operations", "ops",
+		target{Expr: rate(totalOps), LegendFormat: legend})
+	addPanel("Failed operations", "ops",
+		target{Expr: rate(failedOps), LegendFormat: legend})
+	addPanel("Error ratio", "percentunit",
+		target{Expr: rate(failedOps) + " / " + rate(totalOps), LegendFormat: legend})
+	durations, _ := c.DurationGroups()
+	for _, duration := range durations {
+		quantile := func(q float64) target {
+			return target{
+				Expr:         fmt.Sprintf("histogram_quantile(%s, %s)", formatFloat(q), rate(duration+"_bucket", "le")),
+				LegendFormat: legend + " p" + formatFloat(q*100),
+			}
+		}
+		addPanel("Duration ("+duration+")", "s", quantile(0.5), quantile(0.99))
+	}
+	if m, ok := c.Metric(InFlightOps); ok {
+		addPanel("Operations in progress", "short",
+			target{Expr: fmt.Sprintf("sum by (%s) (%s{%s})", op, m.Name, sel), LegendFormat: legend})
+	}
+	for _, size := range []struct{ role, title string }{{ResultSize, "Result size"}, {StreamSize, "Stream size"}} {
+		if m, ok := c.Metric(size.role); ok {
+			addPanel(size.title, "short",
+				target{Expr: fmt.Sprintf("histogram_quantile(0.99, %s)", rate(m.Name+"_bucket", "le")), LegendFormat: legend + " p99"})
+		}
+	}
+
+	sum := sha256.Sum256([]byte(c.Source))
+	d := dashboard{
+		Generated:     c.Header,
+		UID:           "mongen-" + hex.EncodeToString(sum[:])[:12],
+		Title:         c.interfaceName(),
+		Tags:          []string{"mongen"},
+		Timezone:      "browser",
+		SchemaVersion: 39,
+		Time:          timeRange{From: "now-6h", To: "now"},
+		Refresh:       "1m",
+		Templating: templating{List: []variable{
+			{
+				Name:  "datasource",
+				Label: "Data source",
+				Type:  "datasource",
+				Query: "prometheus",
+			},
+			{
+				Name:       "operation",
+				Label:      "Operation",
+				Type:       "custom",
+				Query:      strings.Join(operationNames(c.Operations), ","),
+				Multi:      true,
+				IncludeAll: true,
+				AllValue:   strings.Join(names, "|"),
+				Current:    &current{Text: "All", Value: "$__all"},
+			},
+		}},
+		Panels: panels,
+	}
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// operationNames returns the names of the operations, with the commas that
+// separate the values of custom Grafana variables escaped.
+func operationNames(ops []Operation) []string {
+	names := make([]string, len(ops))
+	for i, op := range ops {
+		names[i] = strings.ReplaceAll(op.Name, ",", `\,`)
+	}
+	return names
+}
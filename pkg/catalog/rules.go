@@ -0,0 +1,144 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Bo0mer/gentools/pkg/transformation"
+)
+
+// RuleOptions are the thresholds of the alerts rendered by Rules.
+type RuleOptions struct {
+	// ErrorRatio is the ratio of failed to total operations above which an
+	// operation fails too often. There is no error rate alert if it is 0.
+	ErrorRatio float64
+	// Latency is the 99th percentile of the duration of an operation with
+	// the default buckets, in seconds, above which the operation is too
+	// slow. There is no latency alert if it is 0.
+	Latency float64
+	// GroupLatency are the latency thresholds of the operations of bucket
+	// groups, by group. The operations of a group without a threshold have
+	// no latency alert, as their durations are on another scale.
+	GroupLatency map[string]float64
+}
+
+// latency returns the latency threshold of the operations of the bucket
+// group, or 0 if there is none.
+func (o RuleOptions) latency(group string) float64 {
+	if group == "" {
+		return o.Latency
+	}
+	return o.GroupLatency[group]
+}
+
+// rateWindow is the window of the rates of the recording rules.
+const rateWindow = "5m"
+
+// Rules renders Prometheus recording and alerting rules in YAML. The rules
+// record the rates of the operations and their failures, and the 99th
+// percentile of their durations, per operation. The alerts fire when an
+// operation fails or is slow for ten minutes, according to opts. Operations
+// of bucket groups are slow according to the threshold of their group, and
+// their latency alerts are named after the group, e.g.
+// PaymentsServiceHighLatencyBatch.
+//
+// The names of the rules are scoped by the package and the name of the
+// interface, e.g. payments_service_operation:payments_total_ops:rate5m and
+// PaymentsServiceHighErrorRate for example.com/payments.Service, so that the
+// rules of several interfaces do not clash, even if their metrics are not
+// prefixed.
+//
+// The metrics are queried by their names in the catalog, as exported by a
+// Prometheus registry, and only the series of the operations of the catalog
+// are selected.
+func (c *Catalog) Rules(opts RuleOptions) []byte {
+	op := c.Labels.Operation
+	all := selector(op, c.Operations)
+	level := transformation.SnakeCase.Apply(c.scope()) + "_" + op
+	record := func(metric, aggregation string) string {
+		return level + ":" + metric + ":" + aggregation
+	}
+	rate := func(metric, sel string, by ...string) string {
+		groups := op
+		for _, label := range by {
+			groups += ", " + label
+		}
+		return fmt.Sprintf("sum by (%s) (rate(%s{%s}[%s]))", groups, metric, sel, rateWindow)
+	}
+
+	var b bytes.Buffer
+	for _, line := range c.Header {
+		fmt.Fprintf(&b, "# %s\n", line)
+	}
+	b.WriteString("groups:\n")
+	fmt.Fprintf(&b, "  - name: %s\n", quote(c.Source))
+	b.WriteString("    rules:\n")
+	writeRule := func(kind, name, expr string) {
+		fmt.Fprintf(&b, "      - %s: %s\n", kind, quote(name))
+		fmt.Fprintf(&b, "        expr: %s\n", quote(expr))
+	}
+
+	totalOps := c.metricName(TotalOps)
+	failedOps := c.metricName(FailedOps)
+	writeRule("record", record(totalOps, "rate"+rateWindow), rate(totalOps, all))
+	writeRule("record", record(failedOps, "rate"+rateWindow), rate(failedOps, all))
+	durations, groups := c.DurationGroups()
+	for _, duration := range durations {
+		writeRule("record", record(duration, "p99_"+rateWindow),
+			fmt.Sprintf("histogram_quantile(0.99, %s)", rate(duration+"_bucket", selector(op, groups[duration]), "le")))
+	}
+
+	writeAlert := func(name, expr, summary string) {
+		writeRule("alert", alertSuffix(c.scope())+name, expr)
+		b.WriteString("        for: 10m\n")
+		b.WriteString("        labels:\n")
+		b.WriteString("          severity: warning\n")
+		b.WriteString("        annotations:\n")
+		fmt.Fprintf(&b, "          summary: %s\n", quote(summary))
+	}
+	if opts.ErrorRatio > 0 {
+		writeAlert("HighErrorRate",
+			fmt.Sprintf("%s{%s} / %s{%s} > %s",
+				record(failedOps, "rate"+rateWindow), all, record(totalOps, "rate"+rateWindow), all, formatFloat(opts.ErrorRatio)),
+			fmt.Sprintf("More than %s%% of the {{ $labels.%s }} operations of %s fail", formatFloat(opts.ErrorRatio*100), op, c.interfaceName()))
+	}
+	for _, duration := range durations {
+		latency := opts.latency(c.durationGroup(duration))
+		if latency <= 0 {
+			continue
+		}
+		writeAlert("HighLatency"+alertSuffix(c.durationGroup(duration)),
+			fmt.Sprintf("%s{%s} > %s", record(duration, "p99_"+rateWindow), selector(op, groups[duration]), formatFloat(latency)),
+			fmt.Sprintf("The 99th percentile of the duration of the {{ $labels.%s }} operations of %s is above %ss", op, c.interfaceName(), formatFloat(latency)))
+	}
+	return b.Bytes()
+}
+
+// alertSuffix returns the part of the names of the alerts that stands for the
+// name, in camel case with an uppercase first letter, e.g. SlowIo for the
+// bucket group slow_io.
+func alertSuffix(group string) string {
+	name := transformation.CamelCase.Apply(group)
+	r, size := utf8.DecodeRuneInString(name)
+	if size == 0 {
+		return ""
+	}
+	return string(unicode.ToUpper(r)) + name[size:]
+}
+
+// quote returns s as a double-quoted YAML scalar.
+func quote(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return string(bytes.TrimSuffix(b.Bytes(), []byte("\n")))
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
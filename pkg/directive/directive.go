@@ -0,0 +1,47 @@
+// Package directive parses the generator directives found in doc comments.
+package directive
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// Directive is a comment of the form "//tool:name arguments" that configures
+// the code generated by a tool. There must be no space after the slashes.
+type Directive struct {
+	// Name is the name of the directive, e.g. "label".
+	Name string
+	// Args is the rest of the comment, with surrounding space trimmed.
+	Args string
+	// Pos is the position of the comment.
+	Pos token.Pos
+}
+
+// Fields returns the space separated arguments of the directive.
+func (d Directive) Fields() []string {
+	return strings.Fields(d.Args)
+}
+
+// Parse returns the directives for the named tool in doc, in the order they
+// appear. The doc may be nil.
+func Parse(tool string, doc *ast.CommentGroup) []Directive {
+	if doc == nil {
+		return nil
+	}
+	prefix := "//" + tool + ":"
+	var directives []Directive
+	for _, c := range doc.List {
+		if !strings.HasPrefix(c.Text, prefix) {
+			continue
+		}
+		text := strings.TrimPrefix(c.Text, prefix)
+		name := text
+		args := ""
+		if i := strings.IndexAny(text, " \t"); i >= 0 {
+			name, args = text[:i], strings.TrimSpace(text[i:])
+		}
+		directives = append(directives, Directive{Name: name, Args: args, Pos: c.Slash})
+	}
+	return directives
+}
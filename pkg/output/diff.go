@@ -0,0 +1,209 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+type edit struct {
+	op   byte // ' ' for unchanged, '-' for deleted and '+' for inserted lines
+	line string
+}
+
+// UnifiedDiff returns the line based difference between old and new in
+// unified diff format. It returns an empty string if both are equal.
+func UnifiedDiff(oldName, newName string, old, new []byte) string {
+	if bytes.Equal(old, new) {
+		return ""
+	}
+	edits := diffLines(splitLines(old), splitLines(new))
+
+	// oldLine and newLine hold the 0-based line numbers at which each edit
+	// starts in the old and the new content respectively.
+	oldLine := make([]int, len(edits)+1)
+	newLine := make([]int, len(edits)+1)
+	for i, e := range edits {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if e.op != '+' {
+			oldLine[i+1]++
+		}
+		if e.op != '-' {
+			newLine[i+1]++
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for i := 0; i < len(edits); {
+		for i < len(edits) && edits[i].op == ' ' {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		start := max(i-diffContext, 0)
+		end := i
+		for {
+			for end < len(edits) && edits[end].op != ' ' {
+				end++
+			}
+			next := end
+			for next < len(edits) && edits[next].op == ' ' {
+				next++
+			}
+			if next == len(edits) || next-end > 2*diffContext {
+				break
+			}
+			end = next
+		}
+		stop := min(end+diffContext, len(edits))
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(oldLine[start], oldLine[stop]-oldLine[start]),
+			hunkRange(newLine[start], newLine[stop]-newLine[start]))
+		for _, e := range edits[start:stop] {
+			out.WriteByte(e.op)
+			out.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = stop
+	}
+	return out.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(b []byte) []string {
+	if len(b) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(b), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes the shortest edit script that transforms a into b using
+// the linear space refinement of the Myers' difference algorithm, which
+// splits the script at the middle snake of an optimal path and diffs the
+// halves recursively.
+func diffLines(a, b []string) []edit {
+	// The furthest reaching paths of the forward and the backward searches
+	// are stored in vectors reused by every split.
+	size := 2*(len(a)+len(b)) + 3
+	d := differ{a: a, b: b, vf: make([]int, size), vb: make([]int, size)}
+	d.diff(0, len(a), 0, len(b))
+	return d.edits
+}
+
+type differ struct {
+	a, b   []string
+	vf, vb []int
+	edits  []edit
+}
+
+// diff appends the edits that transform a[aLo:aHi] into b[bLo:bHi].
+func (d *differ) diff(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.edits = append(d.edits, edit{op: ' ', line: d.a[aLo]})
+		aLo++
+		bLo++
+	}
+	suffix := aHi
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+	}
+
+	switch {
+	case aLo == aHi:
+		for _, line := range d.b[bLo:bHi] {
+			d.edits = append(d.edits, edit{op: '+', line: line})
+		}
+	case bLo == bHi:
+		for _, line := range d.a[aLo:aHi] {
+			d.edits = append(d.edits, edit{op: '-', line: line})
+		}
+	default:
+		x, y := d.split(aLo, aHi, bLo, bHi)
+		d.diff(aLo, x, bLo, y)
+		d.diff(x, aHi, y, bHi)
+	}
+
+	for _, line := range d.a[aHi:suffix] {
+		d.edits = append(d.edits, edit{op: ' ', line: line})
+	}
+}
+
+// split returns the point at which an optimal path from (aLo, bLo) to
+// (aHi, bHi) is split, where the forward and the backward searches meet. The
+// ranges must be non-empty and differ in their first and last lines, so that
+// the point is neither end of the path.
+func (d *differ) split(aLo, aHi, bLo, bHi int) (int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	// vf[offset+k] holds the furthest x reached on the diagonal k = x-y,
+	// and vb[offset+k] the furthest distance from the end reached on the
+	// diagonal k of the reversed ranges, both relative to the ranges.
+	offset := (n+m+1)/2 + 1
+	vf, vb := d.vf[:2*offset+1], d.vb[:2*offset+1]
+	vf[offset+1], vb[offset+1] = 0, 0
+
+	for depth := 0; depth < offset; depth++ {
+		for k := -depth; k <= depth; k += 2 {
+			var x int
+			if k == -depth || (k != depth && vf[offset+k-1] < vf[offset+k+1]) {
+				x = vf[offset+k+1]
+			} else {
+				x = vf[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			vf[offset+k] = x
+			// the backward searches of depth-1 are on the diagonals
+			// delta-depth+1 to delta+depth-1
+			if c := delta - k; odd && c >= -depth+1 && c <= depth-1 && x >= n-vb[offset+c] {
+				return aLo + x, bLo + y
+			}
+		}
+		for c := -depth; c <= depth; c += 2 {
+			var u int
+			if c == -depth || (c != depth && vb[offset+c-1] < vb[offset+c+1]) {
+				u = vb[offset+c+1]
+			} else {
+				u = vb[offset+c-1] + 1
+			}
+			v := u - c
+			for u < n && v < m && d.a[aHi-u-1] == d.b[bHi-v-1] {
+				u++
+				v++
+			}
+			vb[offset+c] = u
+			if k := delta - c; !odd && k >= -depth && k <= depth && vf[offset+k] >= n-u {
+				x := vf[offset+k]
+				return aLo + x, bLo + x - k
+			}
+		}
+	}
+	panic("output: no middle snake found")
+}
@@ -0,0 +1,95 @@
+package output_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Bo0mer/gentools/pkg/output"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	const header = "--- old\n+++ new\n"
+	for _, tc := range []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			name: "equal",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "",
+		},
+		{
+			name: "both empty",
+			want: "",
+		},
+		{
+			name: "insert",
+			old:  "a\nb\nc\n",
+			new:  "a\nb\nx\nc\n",
+			want: header + "@@ -1,3 +1,4 @@\n a\n b\n+x\n c\n",
+		},
+		{
+			name: "delete",
+			old:  "a\nb\nc\n",
+			new:  "a\nc\n",
+			want: header + "@@ -1,3 +1,2 @@\n a\n-b\n c\n",
+		},
+		{
+			name: "empty old",
+			new:  "a\nb\n",
+			want: header + "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "empty new",
+			old:  "a\nb\n",
+			want: header + "@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new:  "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want: header +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			name: "no newline at end of file",
+			old:  "a\n",
+			new:  "a",
+			want: header + "@@ -1 +1 @@\n-a\n+a\n\\ No newline at end of file\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := output.UnifiedDiff("old", "new", []byte(tc.old), []byte(tc.new))
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffLargeDifferentInputs(t *testing.T) {
+	// A quadratic search would need gigabytes to diff these.
+	const lines = 10000
+	var old, new strings.Builder
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&old, "old %d\n", i)
+		fmt.Fprintf(&new, "new %d\n", i)
+	}
+
+	got := output.UnifiedDiff("old", "new", []byte(old.String()), []byte(new.String()))
+
+	wantHeader := fmt.Sprintf("--- old\n+++ new\n@@ -1,%d +1,%d @@\n", lines, lines)
+	if !strings.HasPrefix(got, wantHeader) {
+		t.Fatalf("got diff starting with %q, want %q", got[:min(len(got), len(wantHeader))], wantHeader)
+	}
+	if got, want := strings.Count(got, "\n-old "), lines; got != want {
+		t.Errorf("got %d deleted lines, want %d", got, want)
+	}
+	if got, want := strings.Count(got, "\n+new "), lines; got != want {
+		t.Errorf("got %d inserted lines, want %d", got, want)
+	}
+}
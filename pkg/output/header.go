@@ -0,0 +1,304 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// modulePath is the path of the module of the generators.
+const modulePath = "github.com/Bo0mer/gentools"
+
+// defaultVersion is the version of the generators when the build records no
+// version of their module, e.g. when they are built from a checkout of the
+// repository. Release builds may set it with
+//
+//	-ldflags "-X github.com/Bo0mer/gentools/pkg/output.defaultVersion=v2.2.0"
+var defaultVersion = "v2.1.0"
+
+// Version is the version of the generators recorded in generated files. It
+// is the version of their module reported by runtime/debug.ReadBuildInfo,
+// e.g. when they are installed with go install, or defaultVersion.
+var Version = moduleVersion()
+
+// moduleVersion returns the version of the module of the generators, whether
+// it is the main module of the build or a dependency of it.
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return defaultVersion
+	}
+	module := &info.Main
+	if module.Path != modulePath {
+		module = nil
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				module = dep
+				if dep.Replace != nil {
+					module = dep.Replace
+				}
+				break
+			}
+		}
+	}
+	if module == nil || module.Version == "" || module.Version == "(devel)" {
+		return defaultVersion
+	}
+	return strings.TrimSuffix(module.Version, "+incompatible")
+}
+
+// Generators lists the tools whose output is recognized by this package.
+var Generators = []string{"mongen", "logen", "tracegen"}
+
+const (
+	sourcePrefix     = "Source: "
+	sourceHashPrefix = "Source-Hash: "
+	generatorPrefix  = "Generator: "
+	argsPrefix       = "Args: "
+)
+
+var generatedByRegexp = regexp.MustCompile(`^Code generated by (\w+)\. DO NOT EDIT\.$`)
+
+// HeaderField is the field of the generated JSON files that records their
+// header, as an array of its lines.
+const HeaderField = "generated"
+
+// generatedExtensions are the extensions of the files the generators write:
+// Go source, JSON catalogs and dashboards, and YAML rules.
+var generatedExtensions = []string{".go", ".json", ".yaml"}
+
+// IsGeneratedFileName reports whether a file with the name may have been
+// written by one of the generators, judging by its extension.
+func IsGeneratedFileName(name string) bool {
+	ext := filepath.Ext(name)
+	for _, e := range generatedExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Header describes how a file was generated. It is recorded as a comment at
+// the top of every generated Go or YAML file, and in the HeaderField of every
+// generated JSON file.
+type Header struct {
+	// Generator is the name of the tool that generated the file.
+	Generator string
+	// Version is the version of the tool that generated the file.
+	Version string
+	// Source is the interface the file was generated for, given as
+	// "importpath.Name".
+	Source string
+	// SourceHash is a hash of the source interface declaration.
+	SourceHash string
+	// Args are the arguments that reproduce the file when the generator is
+	// run in the directory of the file.
+	Args []string
+}
+
+// Lines returns the lines of the header, without comment markers.
+func (h Header) Lines() []string {
+	lines := []string{
+		fmt.Sprintf("Code generated by %s. DO NOT EDIT.", h.Generator),
+		sourcePrefix + h.Source,
+	}
+	if h.SourceHash != "" {
+		lines = append(lines, sourceHashPrefix+h.SourceHash)
+	}
+	if h.Version != "" {
+		lines = append(lines, fmt.Sprintf("%s%s %s", generatorPrefix, h.Generator, h.Version))
+	}
+	if len(h.Args) > 0 {
+		lines = append(lines, argsPrefix+joinArgs(h.Args))
+	}
+	return lines
+}
+
+// String returns the header comment of Go source.
+func (h Header) String() string {
+	return h.Comment("//")
+}
+
+// Comment returns the header as a comment, with every line starting with the
+// comment marker, e.g. "#" for YAML.
+func (h Header) Comment(marker string) string {
+	var b strings.Builder
+	for _, line := range h.Lines() {
+		fmt.Fprintf(&b, "%s %s\n", marker, line)
+	}
+	return b.String()
+}
+
+// ParseHeader returns the header of a generated file: the comment at the top
+// of Go or YAML source, or the HeaderField of a JSON object. It reports false
+// if the file was not generated by any of the known generators or does not
+// record its source.
+func ParseHeader(src []byte) (Header, bool) {
+	if trimmed := bytes.TrimSpace(src); len(trimmed) > 0 && trimmed[0] == '{' {
+		var fields map[string]json.RawMessage
+		var lines []string
+		if json.Unmarshal(trimmed, &fields) != nil || json.Unmarshal(fields[HeaderField], &lines) != nil {
+			return Header{}, false
+		}
+		return parseHeaderLines(lines)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for scanner.Scan() {
+		line := scanner.Text()
+		var marker string
+		switch {
+		case strings.HasPrefix(line, "//"):
+			marker = "//"
+		case strings.HasPrefix(line, "#"):
+			marker = "#"
+		default:
+			return parseHeaderLines(lines)
+		}
+		lines = append(lines, strings.TrimPrefix(strings.TrimPrefix(line, marker), " "))
+	}
+	return parseHeaderLines(lines)
+}
+
+func parseHeaderLines(lines []string) (Header, bool) {
+	var h Header
+	for _, line := range lines {
+		switch {
+		case generatedByRegexp.MatchString(line):
+			if name := generatedByRegexp.FindStringSubmatch(line)[1]; isKnownGenerator(name) {
+				h.Generator = name
+			}
+		case strings.HasPrefix(line, sourcePrefix):
+			h.Source = strings.TrimPrefix(line, sourcePrefix)
+		case strings.HasPrefix(line, sourceHashPrefix):
+			h.SourceHash = strings.TrimPrefix(line, sourceHashPrefix)
+		case strings.HasPrefix(line, generatorPrefix):
+			fields := strings.Fields(strings.TrimPrefix(line, generatorPrefix))
+			if len(fields) == 2 {
+				h.Version = fields[1]
+			}
+		case strings.HasPrefix(line, argsPrefix):
+			args, err := splitArgs(strings.TrimPrefix(line, argsPrefix))
+			if err == nil {
+				h.Args = args
+			}
+		}
+	}
+	return h, h.Generator != "" && h.Source != ""
+}
+
+// SplitSource splits a source interface reference into the import path of
+// its package and its name.
+func SplitSource(source string) (pkgPath, name string) {
+	i := strings.LastIndex(source, ".")
+	if i < 0 {
+		return "", source
+	}
+	return source[:i], source[i+1:]
+}
+
+// outputFlags are the flags that only control where and how the generated
+// source is emitted. They are not recorded in the header.
+var outputFlags = map[string]bool{
+	"check":      true,
+	"diff":       true,
+	"prune":      true,
+	"typecheck":  true,
+	"output-dir": true,
+	"o":          true,
+}
+
+// Args returns the arguments that reproduce the generated file when the
+// generator is run in the target directory. It records all flags that were
+// set in fs and affect the generated code, followed by the source directory
+// and the remaining positional arguments.
+func Args(fs *flag.FlagSet, t Target, sourceDir string, positional ...string) []string {
+	var args []string
+	fs.Visit(func(f *flag.Flag) {
+		if !outputFlags[f.Name] {
+			args = append(args, fmt.Sprintf("-%s=%s", f.Name, f.Value.String()))
+		}
+	})
+
+	args = append(args, "-output-dir", ".")
+	if t.File != Stdout {
+		args = append(args, "-o", filepath.Base(t.File))
+	}
+
+	dir, err := filepath.Abs(t.Dir)
+	if err == nil {
+		dir, err = filepath.Rel(dir, sourceDir)
+	}
+	if err != nil {
+		dir = sourceDir
+	}
+	args = append(args, filepath.ToSlash(dir))
+	return append(args, positional...)
+}
+
+func isKnownGenerator(name string) bool {
+	for _, g := range Generators {
+		if g == name {
+			return true
+		}
+	}
+	return false
+}
+
+// joinArgs joins the arguments into a single line, quoting the ones that
+// splitArgs could not split back as they are: empty ones, and ones with
+// anything but printable characters other than spaces, quotes and
+// backslashes, e.g. newlines.
+func joinArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.IndexFunc(arg, isUnsafeArgRune) >= 0 {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+func isUnsafeArgRune(r rune) bool {
+	return r == ' ' || r == '"' || r == '\\' || !unicode.IsPrint(r)
+}
+
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return args, nil
+		}
+		if s[0] != '"' {
+			end := strings.IndexAny(s, " \t")
+			if end < 0 {
+				end = len(s)
+			}
+			args = append(args, s[:end])
+			s = s[end:]
+			continue
+		}
+		quoted, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return nil, err
+		}
+		arg, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		s = s[len(quoted):]
+	}
+}
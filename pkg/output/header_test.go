@@ -0,0 +1,153 @@
+package output_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/gentools/pkg/output"
+)
+
+func TestHeaderArgs(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []string
+		line string
+	}{
+		{
+			name: "plain",
+			args: []string{"-toggles", ".", "Service", "prometheus"},
+			line: "Args: -toggles . Service prometheus",
+		},
+		{
+			name: "spaces",
+			args: []string{"-name-template", "{{.Interface}} {{.Name}}", ".", "Service"},
+			line: `Args: -name-template "{{.Interface}} {{.Name}}" . Service`,
+		},
+		{
+			name: "quotes and backslashes",
+			args: []string{"-labels", `a="b"`, `dir\with\backslashes`},
+			line: `Args: -labels "a=\"b\"" "dir\\with\\backslashes"`,
+		},
+		{
+			name: "tabs",
+			args: []string{"a\tb"},
+			line: `Args: "a\tb"`,
+		},
+		{
+			name: "newlines",
+			args: []string{"-name-template", "{{.Name}}\n// injected", "."},
+			line: `Args: -name-template "{{.Name}}\n// injected" .`,
+		},
+		{
+			name: "control characters",
+			args: []string{"a\rb", "c\x00d", "e\u00a0f"},
+			line: `Args: "a\rb" "c\x00d" "e\u00a0f"`,
+		},
+		{
+			name: "unicode",
+			args: []string{"-pkg", "café"},
+			line: "Args: -pkg café",
+		},
+		{
+			name: "empty",
+			args: []string{"-pkg", "", "."},
+			line: `Args: -pkg "" .`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := output.Header{
+				Generator: "mongen",
+				Version:   "v2.1.0",
+				Source:    "example.com/service.Service",
+				Args:      tc.args,
+			}
+
+			lines := h.Lines()
+			if got := lines[len(lines)-1]; got != tc.line {
+				t.Errorf("got %q, want %q", got, tc.line)
+			}
+
+			jsonHeader, err := json.Marshal(map[string][]string{output.HeaderField: lines})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, src := range []string{
+				h.String() + "\npackage service\n",
+				h.Comment("#") + "groups: []\n",
+				string(jsonHeader),
+			} {
+				got, ok := output.ParseHeader([]byte(src))
+				if !ok {
+					t.Fatalf("no header parsed from %q", src)
+				}
+				if !reflect.DeepEqual(got.Args, tc.args) {
+					t.Errorf("got %q, want %q", got.Args, tc.args)
+				}
+			}
+		})
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		src  string
+		want output.Header
+		ok   bool
+	}{
+		{
+			name: "go",
+			src: "// Code generated by tracegen. DO NOT EDIT.\n" +
+				"// Source: example.com/service.Service\n" +
+				"// Source-Hash: abc\n" +
+				"// Generator: tracegen v2.1.0\n" +
+				"\npackage servicemws\n",
+			want: output.Header{Generator: "tracegen", Version: "v2.1.0", Source: "example.com/service.Service", SourceHash: "abc"},
+			ok:   true,
+		},
+		{
+			name: "yaml",
+			src: "# Code generated by mongen. DO NOT EDIT.\n" +
+				"# Source: example.com/service.Service\n" +
+				"groups: []\n",
+			want: output.Header{Generator: "mongen", Source: "example.com/service.Service"},
+			ok:   true,
+		},
+		{
+			name: "json",
+			src:  `{"generated": ["Code generated by mongen. DO NOT EDIT.", "Source: example.com/service.Service"], "metrics": []}`,
+			want: output.Header{Generator: "mongen", Source: "example.com/service.Service"},
+			ok:   true,
+		},
+		{
+			name: "unknown generator",
+			src: "// Code generated by stringer. DO NOT EDIT.\n" +
+				"// Source: example.com/service.Service\n",
+		},
+		{
+			name: "no source",
+			src:  "// Code generated by mongen. DO NOT EDIT.\n",
+		},
+		{
+			name: "json without header",
+			src:  `{"metrics": []}`,
+		},
+		{
+			name: "header after code",
+			src: "package servicemws\n" +
+				"// Code generated by mongen. DO NOT EDIT.\n" +
+				"// Source: example.com/service.Service\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := output.ParseHeader([]byte(tc.src))
+			if ok != tc.ok {
+				t.Fatalf("got %v, want %v", ok, tc.ok)
+			}
+			if ok && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+// Package output takes care of placing generated source files on disk.
+package output
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/build"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Stdout is the output file name that denotes the standard output.
+const Stdout = "-"
+
+// Options control what happens with generated source.
+type Options struct {
+	// Check makes Emit compare the generated source with the existing file,
+	// print the difference and fail if they differ. Nothing is written.
+	Check bool
+	// Diff makes Emit print the difference between the generated source and
+	// the existing file without failing. Nothing is written.
+	Diff bool
+
+	// OutputDir overrides the directory of the generated package.
+	OutputDir string
+	// OutputFile overrides the name of the generated file. Relative paths
+	// are relative to the output directory.
+	OutputFile string
+	// PackageName overrides the name of the generated package.
+	PackageName string
+	// TypeName overrides the name of the generated wrapper type.
+	TypeName string
+	// ConstructorName overrides the name of the generated constructor.
+	ConstructorName string
+
+	// TypeCheck enables type checking of the generated source before it is
+	// emitted.
+	TypeCheck bool
+
+	// Prune enables removal of files in the output directory that were
+	// generated for interfaces that no longer exist.
+	Prune bool
+
+	// Stdout is where differences are printed. Defaults to os.Stdout.
+	Stdout io.Writer
+}
+
+// RegisterFlags registers the command line flags that populate o.
+func (o *Options) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.Check, "check", false, "Fail with a diff if the output file is not up to date")
+	fs.BoolVar(&o.Diff, "diff", false, "Print a diff against the output file instead of writing it")
+	fs.StringVar(&o.OutputDir, "output-dir", "", "Directory of the generated package")
+	fs.StringVar(&o.OutputFile, "o", "", "Output file, relative to the output directory, or - for stdout")
+	fs.StringVar(&o.PackageName, "pkg", "", "Name of the generated package")
+	fs.StringVar(&o.TypeName, "type", "", "Name of the generated wrapper type")
+	fs.StringVar(&o.ConstructorName, "constructor", "", "Name of the generated constructor")
+	fs.BoolVar(&o.Prune, "prune", false, "Remove files generated for interfaces that no longer exist")
+	fs.BoolVar(&o.TypeCheck, "typecheck", true, "Type-check the generated source before writing it")
+}
+
+// Validate reports an error if a name given by the options is not a valid Go
+// identifier.
+func (o Options) Validate() error {
+	names := []struct{ flag, value string }{
+		{"pkg", o.PackageName},
+		{"type", o.TypeName},
+		{"constructor", o.ConstructorName},
+	}
+	for _, n := range names {
+		if n.value != "" && !token.IsIdentifier(n.value) {
+			return fmt.Errorf("invalid -%s: %q is not a Go identifier", n.flag, n.value)
+		}
+	}
+	return nil
+}
+
+// Target describes where generated code is placed.
+type Target struct {
+	// Dir is the directory of the target package.
+	Dir string
+	// File is the path of the output file or Stdout.
+	File string
+	// Name is the base name of the output file. If File is Stdout, it is
+	// the name of the file that would be written by default, which the
+	// generated source replaces when it is type-checked.
+	Name string
+	// ImportPath is the import path of the target package. It is empty if
+	// the directory is outside of any known source root.
+	ImportPath string
+	// Package is the name of the target package.
+	Package string
+}
+
+// Target applies the options to the default output directory and file name
+// and returns the resulting target. The directory does not need to exist.
+func (o Options) Target(defaultDir, defaultFile string) Target {
+	t := Target{Dir: defaultDir}
+	if o.OutputDir != "" {
+		t.Dir = o.OutputDir
+	}
+
+	t.File = filepath.Join(t.Dir, defaultFile)
+	switch {
+	case o.OutputFile == Stdout:
+		t.File = Stdout
+	case filepath.IsAbs(o.OutputFile):
+		t.File = o.OutputFile
+		t.Dir = filepath.Dir(o.OutputFile)
+	case o.OutputFile != "":
+		t.File = filepath.Join(t.Dir, o.OutputFile)
+		t.Dir = filepath.Dir(t.File)
+	}
+
+	t.Name = filepath.Base(t.File)
+	if t.File == Stdout {
+		t.Name = defaultFile
+	}
+
+	t.ImportPath, t.Package = packageInDir(t.Dir)
+	if o.PackageName != "" {
+		t.Package = o.PackageName
+	}
+	return t
+}
+
+// packageInDir returns the import path and name of the package in the
+// specified directory. If there is no package, its name is derived from
+// the directory.
+func packageInDir(dir string) (importPath, name string) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	pkg, err := build.ImportDir(abs, 0)
+	if pkg.ImportPath != "." {
+		importPath = pkg.ImportPath
+	}
+	if err == nil {
+		return importPath, pkg.Name
+	}
+	if importPath != "" {
+		return importPath, path.Base(importPath)
+	}
+	return "", filepath.Base(abs)
+}
+
+// DryRun returns whether the options forbid writing anything.
+func (o Options) DryRun() bool {
+	return o.Check || o.Diff
+}
+
+// OutdatedError is returned by Emit in check mode when the existing file
+// does not match the generated source.
+type OutdatedError struct {
+	Path string
+}
+
+func (e *OutdatedError) Error() string {
+	return fmt.Sprintf("%s is not up to date", e.Path)
+}
+
+// Emit places src at path according to opts. It reports whether the content
+// at path differs from src.
+func Emit(path string, src []byte, opts Options) (changed bool, err error) {
+	if path == Stdout {
+		if opts.DryRun() {
+			return false, fmt.Errorf("cannot compare generated source with standard output")
+		}
+		_, err := os.Stdout.Write(src)
+		return true, err
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	changed = err != nil || !bytes.Equal(current, src)
+
+	if opts.DryRun() {
+		if !changed {
+			return false, nil
+		}
+		stdout := opts.Stdout
+		if stdout == nil {
+			stdout = os.Stdout
+		}
+		fmt.Fprint(stdout, UnifiedDiff(path+".orig", path, current, src))
+		if opts.Check {
+			return true, &OutdatedError{Path: path}
+		}
+		return true, nil
+	}
+
+	if !changed {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return false, fmt.Errorf("error creating target package directory: %v", err)
+	}
+	if err := writeFileAtomic(path, src); err != nil {
+		return false, fmt.Errorf("error writing output source file: %v", err)
+	}
+	return true, nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// to path, so that readers never observe a partially written file.
+func writeFileAtomic(path string, data []byte) (err error) {
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Save emits the generated implementation of the source interface to the
+// target and prunes stale files if requested. It reports the outcome on
+// standard output, describing the implementation with kind (e.g.
+// "monitoring").
+func (o Options) Save(t Target, src []byte, kind, source string) error {
+	return o.SaveFile(t, src, fmt.Sprintf("%s implementation of %q", kind, source))
+}
+
+// SaveFile emits data to the target and prunes stale files if requested. It
+// reports the outcome on standard output, describing data with description
+// (e.g. "metric catalog of \"example.Service\"").
+func (o Options) SaveFile(t Target, data []byte, description string) error {
+	file := t.File
+	if file != Stdout {
+		file = relativeToWorkDir(file)
+	}
+
+	changed, err := Emit(file, data, o)
+	if err != nil {
+		return err
+	}
+	switch {
+	case file == Stdout:
+	case !changed:
+		fmt.Printf("%s in %q is up to date\n", capitalize(description), file)
+	case !o.DryRun():
+		fmt.Printf("Wrote %s to %q\n", description, file)
+	}
+
+	if !o.Prune {
+		return nil
+	}
+	stale, err := Prune(relativeToWorkDir(t.Dir), file, o)
+	for _, f := range stale {
+		if o.DryRun() {
+			fmt.Printf("Stale generated file %q\n", f)
+		} else {
+			fmt.Printf("Removed stale generated file %q\n", f)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if o.Check && len(stale) > 0 {
+		return fmt.Errorf("found %d stale generated file(s)", len(stale))
+	}
+	return nil
+}
+
+func relativeToWorkDir(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"go/ast"
+	"os"
+	"path/filepath"
+
+	"github.com/Bo0mer/gentools/pkg/resolution"
+)
+
+// Prune finds the files in dir that were generated for interfaces that no
+// longer exist and removes them, unless the options forbid writing. The
+// file at keep is never removed. It returns the stale files.
+func Prune(dir, keep string, opts Options) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	keepAbs, _ := filepath.Abs(keep)
+	locator := resolution.NewLocator()
+
+	var stale []string
+	for _, entry := range entries {
+		if entry.IsDir() || !IsGeneratedFileName(entry.Name()) {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		if abs, _ := filepath.Abs(file); abs == keepAbs {
+			continue
+		}
+
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return stale, err
+		}
+		header, ok := ParseHeader(src)
+		if !ok {
+			continue
+		}
+		exists, err := interfaceExists(locator, header.Source)
+		if err != nil {
+			return stale, err
+		}
+		if exists {
+			continue
+		}
+
+		if !opts.DryRun() {
+			if err := os.Remove(file); err != nil {
+				return stale, err
+			}
+		}
+		stale = append(stale, file)
+	}
+	return stale, nil
+}
+
+func interfaceExists(locator *resolution.Locator, source string) (bool, error) {
+	pkgPath, name := SplitSource(source)
+	context := resolution.NewSingleLocationContext(pkgPath)
+	d, err := locator.FindIdentType(context, ast.NewIdent(name))
+	// A deleted or moved package no longer declares the interface. Other
+	// errors, e.g. of parsing, are returned.
+	switch err.(type) {
+	case *resolution.TypeNotFoundError, *resolution.PackageNotFoundError:
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	_, isIFace := d.Spec.Type.(*ast.InterfaceType)
+	return isIFace, nil
+}
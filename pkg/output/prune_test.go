@@ -0,0 +1,122 @@
+package output_test
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/gentools/pkg/output"
+)
+
+// gopath makes the packages of a temporary GOPATH the only resolvable
+// packages until the test ends, and returns the GOPATH.
+func gopath(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("GO111MODULE", "off")
+	old := build.Default.GOPATH
+	build.Default.GOPATH = dir
+	t.Cleanup(func() { build.Default.GOPATH = old })
+	return dir
+}
+
+func writeFile(t *testing.T, name, src string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func generated(source string) string {
+	return output.Header{Generator: "mongen", Source: source}.String() + "package servicemws\n"
+}
+
+func TestPrune(t *testing.T) {
+	src := filepath.Join(gopath(t), "src")
+	writeFile(t, filepath.Join(src, "example.com/service/service.go"), "package service\n\ntype Service interface{}\n\ntype Config struct{}\n")
+	writeFile(t, filepath.Join(src, "example.com/empty/doc.go"), "package empty\n")
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"kept.go":       "example.com/service.Service",
+		"keep.go":       "example.com/deleted.Service",
+		"renamed.go":    "example.com/service.Renamed",
+		"struct.go":     "example.com/service.Config",
+		"emptied.go":    "example.com/empty.Service",
+		"deleted.go":    "example.com/deleted.Service",
+		"unrelated.txt": "example.com/deleted.Service",
+	}
+	for name, source := range files {
+		writeFile(t, filepath.Join(dir, name), generated(source))
+	}
+	writeFile(t, filepath.Join(dir, "handwritten.go"), "package servicemws\n")
+
+	stale, err := output.Prune(dir, filepath.Join(dir, "keep.go"), output.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "deleted.go"),
+		filepath.Join(dir, "emptied.go"),
+		filepath.Join(dir, "renamed.go"),
+		filepath.Join(dir, "struct.go"),
+	}
+	if !reflect.DeepEqual(stale, want) {
+		t.Errorf("got stale files %q, want %q", stale, want)
+	}
+	for name := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		removed := os.IsNotExist(err)
+		if wantRemoved := contains(want, filepath.Join(dir, name)); removed != wantRemoved {
+			t.Errorf("%s removed: %t, want %t", name, removed, wantRemoved)
+		}
+	}
+}
+
+func TestPruneDryRun(t *testing.T) {
+	gopath(t)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "deleted.go")
+	writeFile(t, file, generated("example.com/deleted.Service"))
+
+	stale, err := output.Prune(dir, "", output.Options{Check: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{file}; !reflect.DeepEqual(stale, want) {
+		t.Errorf("got stale files %q, want %q", stale, want)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("got %v, want the file kept", err)
+	}
+}
+
+func TestPruneFailsOnUnparsableSource(t *testing.T) {
+	src := filepath.Join(gopath(t), "src")
+	writeFile(t, filepath.Join(src, "example.com/broken/broken.go"), "package broken\n\ntype Service interface {\n")
+	dir := t.TempDir()
+	file := filepath.Join(dir, "broken.go")
+	writeFile(t, file, generated("example.com/broken.Service"))
+
+	if _, err := output.Prune(dir, "", output.Options{}); err == nil {
+		t.Error("got no error, want the parse error")
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("got %v, want the file kept", err)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
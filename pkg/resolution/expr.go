@@ -0,0 +1,123 @@
+package resolution
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+)
+
+// ResolveExpr resolves the package references in an expression written in
+// the specified context against the namespace of the generated code, the way
+// ResolveType does for types. Identifiers listed in locals, such as method
+// parameters, are kept as they are, and so are predeclared identifiers.
+// Other exported identifiers are taken to be declared in the package of the
+// context.
+func ResolveExpr(importer Importer, context *LocatorContext, expr ast.Expr, locals map[string]bool) (ast.Expr, error) {
+	r := exprResolver{importer: importer, context: context, locals: locals}
+	return r.resolve(expr)
+}
+
+type exprResolver struct {
+	importer Importer
+	context  *LocatorContext
+	locals   map[string]bool
+}
+
+func (r exprResolver) resolve(expr ast.Expr) (ast.Expr, error) {
+	var err error
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return r.resolveIdent(e)
+	case *ast.SelectorExpr:
+		return r.resolveSelectorExpr(e)
+	case *ast.CallExpr:
+		if e.Fun, err = r.resolve(e.Fun); err != nil {
+			return nil, err
+		}
+		err = r.resolveList(e.Args)
+	case *ast.ParenExpr:
+		e.X, err = r.resolve(e.X)
+	case *ast.StarExpr:
+		e.X, err = r.resolve(e.X)
+	case *ast.UnaryExpr:
+		e.X, err = r.resolve(e.X)
+	case *ast.BinaryExpr:
+		if e.X, err = r.resolve(e.X); err != nil {
+			return nil, err
+		}
+		e.Y, err = r.resolve(e.Y)
+	case *ast.IndexExpr:
+		if e.X, err = r.resolve(e.X); err != nil {
+			return nil, err
+		}
+		e.Index, err = r.resolve(e.Index)
+	case *ast.SliceExpr:
+		if e.X, err = r.resolve(e.X); err != nil {
+			return nil, err
+		}
+		err = r.resolveList([]ast.Expr{e.Low, e.High, e.Max})
+	case *ast.TypeAssertExpr:
+		if e.X, err = r.resolve(e.X); err != nil {
+			return nil, err
+		}
+		if e.Type != nil {
+			e.Type, err = r.resolve(e.Type)
+		}
+	case *ast.BasicLit, nil:
+	default:
+		return nil, fmt.Errorf("unsupported expression of type %T", expr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+func (r exprResolver) resolveList(exprs []ast.Expr) error {
+	for i, expr := range exprs {
+		if expr == nil {
+			continue
+		}
+		resolved, err := r.resolve(expr)
+		if err != nil {
+			return err
+		}
+		exprs[i] = resolved
+	}
+	return nil
+}
+
+func (r exprResolver) resolveIdent(ident *ast.Ident) (ast.Expr, error) {
+	if r.locals[ident.Name] || !ident.IsExported() || types.Universe.Lookup(ident.Name) != nil {
+		return ident, nil
+	}
+	locations := r.context.LocalLocations()
+	if len(locations) == 0 {
+		return ident, nil
+	}
+	return qualify(r.importer.AddImport("", locations[0]), ident), nil
+}
+
+func (r exprResolver) resolveSelectorExpr(expr *ast.SelectorExpr) (ast.Expr, error) {
+	pkg, ok := expr.X.(*ast.Ident)
+	if !ok || r.locals[pkg.Name] {
+		var err error
+		expr.X, err = r.resolve(expr.X)
+		return expr, err
+	}
+	locations := r.context.CandidateLocations(pkg.Name)
+	if len(locations) == 0 {
+		return nil, fmt.Errorf("unknown package or parameter %q", pkg.Name)
+	}
+	return qualify(r.importer.AddImport("", locations[0]), expr.Sel), nil
+}
+
+func qualify(alias string, ident *ast.Ident) ast.Expr {
+	if alias == "" {
+		return ident
+	}
+	return &ast.SelectorExpr{
+		X:   ast.NewIdent(alias),
+		Sel: ident,
+	}
+}
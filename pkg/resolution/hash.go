@@ -0,0 +1,71 @@
+package resolution
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"go/ast"
+	"io"
+	"os"
+
+	"github.com/Bo0mer/gentools/pkg/internal"
+)
+
+// DeclarationHash returns a hash of the source of the discovered type
+// declaration, including its doc comment. For interfaces, the declarations of
+// all embedded interfaces are included as well.
+func (l *Locator) DeclarationHash(d TypeDiscovery) (string, error) {
+	h := sha256.New()
+	if err := l.hashDeclaration(h, d, make(map[*ast.TypeSpec]bool)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
+
+func (l *Locator) hashDeclaration(w io.Writer, d TypeDiscovery, seen map[*ast.TypeSpec]bool) error {
+	if seen[d.Spec] {
+		return nil
+	}
+	seen[d.Spec] = true
+
+	start := l.fset.Position(d.Spec.Pos())
+	end := l.fset.Position(d.Spec.End())
+	src, err := os.ReadFile(start.Filename)
+	if err != nil {
+		return err
+	}
+	if end.Offset > len(src) {
+		return fmt.Errorf("declaration of %s is outside of %s", d.Spec.Name, start.Filename)
+	}
+	fmt.Fprintf(w, "%s\n", d.Location)
+	if d.Doc != nil {
+		docStart := l.fset.Position(d.Doc.Pos())
+		docEnd := l.fset.Position(d.Doc.End())
+		w.Write(src[docStart.Offset:docEnd.Offset])
+		fmt.Fprintln(w)
+	}
+	w.Write(src[start.Offset:end.Offset])
+
+	iFaceType, isIFace := d.Spec.Type.(*ast.InterfaceType)
+	if !isIFace {
+		return nil
+	}
+	context := NewASTFileLocatorContext(d.File, d.Location)
+	for field := range internal.EachFieldInFieldList(iFaceType.Methods) {
+		var embedded TypeDiscovery
+		switch t := field.Type.(type) {
+		case *ast.Ident:
+			embedded, err = l.FindIdentType(context, t)
+		case *ast.SelectorExpr:
+			embedded, err = l.FindSelectorType(context, t)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := l.hashDeclaration(w, embedded, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
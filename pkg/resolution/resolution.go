@@ -5,6 +5,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"os"
 	"strings"
 
 	"github.com/Bo0mer/gentools/pkg/internal"
@@ -12,11 +13,13 @@ import (
 
 func NewLocator() *Locator {
 	return &Locator{
+		fset:  token.NewFileSet(),
 		cache: make(map[string][]TypeDiscovery),
 	}
 }
 
 type Locator struct {
+	fset  *token.FileSet
 	cache map[string][]TypeDiscovery
 }
 
@@ -24,6 +27,8 @@ type TypeDiscovery struct {
 	Location string
 	File     *ast.File
 	Spec     *ast.TypeSpec
+	// Doc is the doc comment of the type declaration, if any.
+	Doc *ast.CommentGroup
 }
 
 func (l *Locator) FindIdentType(context *LocatorContext, ref *ast.Ident) (TypeDiscovery, error) {
@@ -74,10 +79,13 @@ func (l *Locator) discoverTypes(location string) ([]TypeDiscovery, error) {
 
 	sourcePath, err := internal.ImportToDir(location)
 	if err != nil {
-		return nil, err
+		return nil, &PackageNotFoundError{Path: location, Err: err}
 	}
 
-	pkgs, err := parser.ParseDir(token.NewFileSet(), sourcePath, nil, parser.AllErrors)
+	pkgs, err := parser.ParseDir(l.fset, sourcePath, nil, parser.AllErrors|parser.ParseComments)
+	if os.IsNotExist(err) {
+		return nil, &PackageNotFoundError{Path: location, Err: err}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -85,12 +93,19 @@ func (l *Locator) discoverTypes(location string) ([]TypeDiscovery, error) {
 	discoveries = make([]TypeDiscovery, 0)
 	for _, pkg := range pkgs {
 		for _, file := range pkg.Files {
-			for spec := range internal.EachTypeSpecificationInFile(file) {
-				discoveries = append(discoveries, TypeDiscovery{
-					Location: location,
-					File:     file,
-					Spec:     spec,
-				})
+			for decl := range internal.EachGenericDeclarationInFile(file) {
+				for spec := range internal.EachTypeSpecificationInGenericDeclaration(decl) {
+					doc := spec.Doc
+					if doc == nil && len(decl.Specs) == 1 {
+						doc = decl.Doc
+					}
+					discoveries = append(discoveries, TypeDiscovery{
+						Location: location,
+						File:     file,
+						Spec:     spec,
+						Doc:      doc,
+					})
+				}
 			}
 		}
 	}
@@ -106,6 +121,21 @@ func (e *TypeNotFoundError) Error() string {
 	return fmt.Sprintf("Could not find '%s' type.", e.Name)
 }
 
+// PackageNotFoundError is returned if the package of a location cannot be
+// found, e.g. because it was deleted or moved.
+type PackageNotFoundError struct {
+	Path string
+	Err  error
+}
+
+func (e *PackageNotFoundError) Error() string {
+	return fmt.Sprintf("Could not find '%s' package: %v", e.Path, e.Err)
+}
+
+func (e *PackageNotFoundError) Unwrap() error {
+	return e.Err
+}
+
 func NewSingleLocationContext(location string) *LocatorContext {
 	return &LocatorContext{
 		imports: []importEntry{
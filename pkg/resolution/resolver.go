@@ -7,6 +7,9 @@ import (
 )
 
 type Importer interface {
+	// AddImport should import the package at the specified location and
+	// return its alias. An empty alias means that the location is the package
+	// the generated code resides in and no qualifier is needed.
 	AddImport(pkgName, location string) string
 }
 
@@ -44,10 +47,21 @@ func (r *Resolver) ResolveType(context *LocatorContext, astType ast.Expr) (ast.E
 		return r.resolveInterfaceType(context, t)
 	case *ast.Ellipsis:
 		return r.resolveEllipsisType(context, t)
+	case *ast.IndexExpr:
+		return r.resolveIndexType(context, t)
+	case *ast.IndexListExpr:
+		return r.resolveIndexListType(context, t)
 	}
 	return astType, nil
 }
 
+// ResolveExpr resolves an expression written in the specified context, such
+// as one declared in a directive, with the importer of the resolver. See
+// the ResolveExpr function.
+func (r *Resolver) ResolveExpr(context *LocatorContext, expr ast.Expr, locals map[string]bool) (ast.Expr, error) {
+	return ResolveExpr(r.importer, context, expr, locals)
+}
+
 func (r *Resolver) resolveIdent(context *LocatorContext, ident *ast.Ident) (ast.Expr, error) {
 	if r.isBuiltIn(ident.String()) {
 		return ident, nil
@@ -57,6 +71,9 @@ func (r *Resolver) resolveIdent(context *LocatorContext, ident *ast.Ident) (ast.
 		return nil, err
 	}
 	al := r.importer.AddImport("", discovery.Location)
+	if al == "" {
+		return ident, nil
+	}
 	return &ast.SelectorExpr{
 		X:   ast.NewIdent(al),
 		Sel: ast.NewIdent(ident.String()),
@@ -69,6 +86,9 @@ func (r *Resolver) resolveSelectorExpr(context *LocatorContext, expr *ast.Select
 		return nil, err
 	}
 	al := r.importer.AddImport("", discovery.Location)
+	if al == "" {
+		return expr.Sel, nil
+	}
 	return &ast.SelectorExpr{
 		X:   ast.NewIdent(al),
 		Sel: expr.Sel,
@@ -151,10 +171,41 @@ func (r *Resolver) resolveEllipsisType(context *LocatorContext, astType *ast.Ell
 	return astType, err
 }
 
+// resolveIndexType resolves an instantiation of a generic type with a single
+// type argument, e.g. iter.Seq[T].
+func (r *Resolver) resolveIndexType(context *LocatorContext, astType *ast.IndexExpr) (ast.Expr, error) {
+	var err error
+	astType.X, err = r.ResolveType(context, astType.X)
+	if err != nil {
+		return nil, err
+	}
+	astType.Index, err = r.ResolveType(context, astType.Index)
+	return astType, err
+}
+
+// resolveIndexListType resolves an instantiation of a generic type with
+// several type arguments, e.g. iter.Seq2[K, V].
+func (r *Resolver) resolveIndexListType(context *LocatorContext, astType *ast.IndexListExpr) (ast.Expr, error) {
+	var err error
+	astType.X, err = r.ResolveType(context, astType.X)
+	if err != nil {
+		return nil, err
+	}
+	for i, index := range astType.Indices {
+		astType.Indices[i], err = r.ResolveType(context, index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return astType, nil
+}
+
 // isBuiltIn should return whether a type, specified by its name,
 // is native to the language or not.
 func (r *Resolver) isBuiltIn(name string) bool {
 	switch name {
+	case "any":
+		return true
 	case "bool":
 		return true
 	case "byte":
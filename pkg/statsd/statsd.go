@@ -0,0 +1,141 @@
+// Package statsd emits metrics to a StatsD agent, with DogStatsD style tags.
+// The monitoring implementations generated by mongen with the statsd provider
+// record their metrics with an Emitter.
+package statsd
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Emitter emits metrics. Tags are in the form "key:value". Implementations
+// must be safe for concurrent use and must not retain the tags after
+// returning.
+type Emitter interface {
+	// Count adds value to the counter with the name.
+	Count(name string, value int64, tags ...string)
+	// Timing records a duration in the timer with the name.
+	Timing(name string, value time.Duration, tags ...string)
+	// Histogram records a value in the histogram with the name.
+	Histogram(name string, value float64, tags ...string)
+}
+
+// Tags returns a new slice with the tags followed by a "key:value" tag for
+// every key and value pair in keyvals. Pairs with an empty value are skipped,
+// as a tag without a value is not told apart from the key. The tags are not
+// modified, so they can be shared by concurrent calls.
+func Tags(tags []string, keyvals ...string) []string {
+	t := make([]string, len(tags), len(tags)+len(keyvals)/2)
+	copy(t, tags)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if keyvals[i+1] != "" {
+			t = append(t, keyvals[i]+":"+keyvals[i+1])
+		}
+	}
+	return t
+}
+
+// UDPEmitter sends every metric to a StatsD agent in a separate UDP packet.
+// Errors are ignored, as UDP does not guarantee delivery anyway.
+type UDPEmitter struct {
+	conn   net.Conn
+	prefix string
+	tags   []string
+	bufs   sync.Pool
+}
+
+// DialUDP creates an emitter that sends metrics to the agent listening on
+// addr, e.g. "127.0.0.1:8125". The prefix is prepended to the names of the
+// metrics and the tags are added to every metric.
+func DialUDP(addr, prefix string, tags ...string) (*UDPEmitter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &UDPEmitter{
+		conn:   conn,
+		prefix: prefix,
+		tags:   tags,
+		bufs: sync.Pool{
+			New: func() interface{} { return new([]byte) },
+		},
+	}, nil
+}
+
+// Count sends a "c" metric.
+func (e *UDPEmitter) Count(name string, value int64, tags ...string) {
+	buf, b := e.start(name)
+	b = strconv.AppendInt(b, value, 10)
+	e.send(buf, b, "c", tags)
+}
+
+// Timing sends a "ms" metric.
+func (e *UDPEmitter) Timing(name string, value time.Duration, tags ...string) {
+	buf, b := e.start(name)
+	b = strconv.AppendFloat(b, value.Seconds()*1000, 'f', -1, 64)
+	e.send(buf, b, "ms", tags)
+}
+
+// Histogram sends a "h" metric.
+func (e *UDPEmitter) Histogram(name string, value float64, tags ...string) {
+	buf, b := e.start(name)
+	b = strconv.AppendFloat(b, value, 'f', -1, 64)
+	e.send(buf, b, "h", tags)
+}
+
+// Close closes the connection to the agent.
+func (e *UDPEmitter) Close() error {
+	return e.conn.Close()
+}
+
+// start starts a metric in the form "prefix.name:value|type|#tag1,tag2" in
+// a pooled buffer, up to the value.
+func (e *UDPEmitter) start(name string) (*[]byte, []byte) {
+	buf := e.bufs.Get().(*[]byte)
+	b := append((*buf)[:0], e.prefix...)
+	b = append(b, name...)
+	return buf, append(b, ':')
+}
+
+// send completes the metric, sends it and returns the buffer to the pool.
+func (e *UDPEmitter) send(buf *[]byte, b []byte, metricType string, tags []string) {
+	b = append(b, '|')
+	b = append(b, metricType...)
+	if len(e.tags)+len(tags) > 0 {
+		b = append(b, "|#"...)
+		b = appendTags(b, e.tags, false)
+		b = appendTags(b, tags, len(e.tags) > 0)
+	}
+	e.conn.Write(b)
+
+	*buf = b
+	e.bufs.Put(buf)
+}
+
+func appendTags(b []byte, tags []string, comma bool) []byte {
+	for _, tag := range tags {
+		if comma {
+			b = append(b, ',')
+		}
+		b = append(b, sanitize(tag)...)
+		comma = true
+	}
+	return b
+}
+
+// sanitize replaces the characters that separate the parts of a metric.
+func sanitize(tag string) string {
+	if !strings.ContainsAny(tag, "|,#\n") {
+		return tag
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '|', ',', '#', '\n':
+			return '_'
+		}
+		return r
+	}, tag)
+}
@@ -0,0 +1,70 @@
+package statsd_test
+
+import (
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Bo0mer/gentools/pkg/statsd"
+)
+
+func TestUDPEmitter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	emitter, err := statsd.DialUDP(conn.LocalAddr().String(), "app.", "env:test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emitter.Close()
+
+	emitter.Count("total_ops", 1, "operation:do_work")
+	emitter.Timing("ops_duration", 1500*time.Microsecond, "operation:do_work", "error_class:a|b")
+	emitter.Count("failed_ops", 2)
+	emitter.Histogram("result_size", 42, "operation:list")
+
+	for _, want := range []string{
+		"app.total_ops:1|c|#env:test,operation:do_work",
+		"app.ops_duration:1.5|ms|#env:test,operation:do_work,error_class:a_b",
+		"app.failed_ops:2|c|#env:test",
+		"app.result_size:42|h|#env:test,operation:list",
+	} {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		buf := make([]byte, 512)
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:n]); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	tags := make([]string, 1, 4)
+	tags[0] = "operation:do_work"
+	for _, tc := range []struct {
+		name    string
+		keyvals []string
+		want    []string
+	}{
+		{name: "no pairs", want: []string{"operation:do_work"}},
+		{name: "pairs", keyvals: []string{"tenant", "acme", "error_class", "timeout"}, want: []string{"operation:do_work", "tenant:acme", "error_class:timeout"}},
+		{name: "empty value", keyvals: []string{"tenant", "", "error_class", "timeout"}, want: []string{"operation:do_work", "error_class:timeout"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := statsd.Tags(tags, tc.keyvals...)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+			if got := tags[:cap(tags)]; got[1] != "" {
+				t.Errorf("got %q, want the tags not modified", got)
+			}
+		})
+	}
+}
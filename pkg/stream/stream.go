@@ -0,0 +1,132 @@
+// Package stream measures the streams returned by methods over their
+// lifetime. The monitoring implementations generated by mongen with the
+// -streams option wrap the channels, iterators and readers returned by the
+// methods they monitor, so that the operation ends when its stream ends,
+// instead of when the method returns.
+package stream
+
+import (
+	"io"
+	"iter"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Done is called once a stream ends, with the number of items or bytes that
+// were received from it and the error that ended it, if any.
+type Done func(size int64, err error)
+
+// Chan returns a channel with the same capacity as c, that receives the
+// values received from c. Done is called once c is closed and all its values
+// are forwarded to the returned channel, before it is closed. As the returned
+// channel is buffered, the receiver may not have received the last values
+// yet when done is called.
+//
+// The values are forwarded by a goroutine, which exits once c is closed. If
+// the receiver stops receiving before that, the goroutine leaks, just like
+// the sender of c would.
+func Chan[T any](c <-chan T, done Done) <-chan T {
+	out := make(chan T, cap(c))
+	go func() {
+		var size int64
+		for v := range c {
+			out <- v
+			size++
+		}
+		done(size, nil)
+		close(out)
+	}()
+	return out
+}
+
+// Seq returns a sequence that yields the values of seq. Done is called once
+// the first iteration over the sequence ends, either because seq is
+// exhausted or because the loop stopped. Later iterations are not measured.
+//
+// Done is not called if the sequence is never ranged over, so the stream of
+// a sequence that is discarded never ends.
+func Seq[T any](seq iter.Seq[T], done Done) iter.Seq[T] {
+	var started atomic.Bool
+	return func(yield func(T) bool) {
+		if !started.CompareAndSwap(false, true) {
+			seq(yield)
+			return
+		}
+		var size int64
+		defer func() { done(size, nil) }()
+		seq(func(v T) bool {
+			size++
+			return yield(v)
+		})
+	}
+}
+
+// Seq2 returns a sequence that yields the pairs of seq. Done is called once
+// the first iteration over the sequence ends, like with Seq. If the second
+// values of seq are errors, the stream fails if any pair carried a non-nil
+// error, even if later pairs did not, and done is called with the last such
+// error; the pairs that carry an error are not counted. Like with Seq, done
+// is not called if the sequence is never ranged over.
+func Seq2[K, V any](seq iter.Seq2[K, V], done Done) iter.Seq2[K, V] {
+	errs := reflect.TypeFor[V]().Implements(reflect.TypeFor[error]())
+	var started atomic.Bool
+	return func(yield func(K, V) bool) {
+		if !started.CompareAndSwap(false, true) {
+			seq(yield)
+			return
+		}
+		var size int64
+		var err error
+		defer func() { done(size, err) }()
+		seq(func(k K, v V) bool {
+			if errs {
+				if e, _ := any(v).(error); e != nil {
+					err = e
+					return yield(k, v)
+				}
+			}
+			size++
+			return yield(k, v)
+		})
+	}
+}
+
+// ReadCloser returns a reader that reads from r. Done is called with the
+// number of bytes read once r returns an error from Read, or is closed,
+// whichever happens first. The error is nil if the reader reached io.EOF.
+//
+// The returned reader implements only io.ReadCloser, even if r implements
+// other interfaces, such as io.WriterTo.
+func ReadCloser(r io.ReadCloser, done Done) io.ReadCloser {
+	return &readCloser{ReadCloser: r, done: done}
+}
+
+type readCloser struct {
+	io.ReadCloser
+	done Done
+	size atomic.Int64
+	once sync.Once
+}
+
+func (r *readCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.size.Add(int64(n))
+	if err != nil {
+		r.end(err)
+	}
+	return n, err
+}
+
+func (r *readCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.end(err)
+	return err
+}
+
+func (r *readCloser) end(err error) {
+	if err == io.EOF {
+		err = nil
+	}
+	r.once.Do(func() { r.done(r.size.Load(), err) })
+}
@@ -0,0 +1,183 @@
+package stream_test
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Bo0mer/gentools/pkg/stream"
+)
+
+// recorder records the calls of a Done.
+type recorder struct {
+	calls int
+	size  int64
+	err   error
+}
+
+func (r *recorder) done(size int64, err error) {
+	r.calls++
+	r.size = size
+	r.err = err
+}
+
+func (r *recorder) check(t *testing.T, size int64, err error) {
+	t.Helper()
+	if r.calls != 1 {
+		t.Errorf("got %d calls, want 1", r.calls)
+	}
+	if r.size != size {
+		t.Errorf("got size %d, want %d", r.size, size)
+	}
+	if !errors.Is(r.err, err) {
+		t.Errorf("got error %v, want %v", r.err, err)
+	}
+}
+
+func TestChan(t *testing.T) {
+	c := make(chan int, 3)
+	c <- 1
+	c <- 2
+	c <- 3
+	close(c)
+
+	var r recorder
+	var got []int
+	for v := range stream.Chan(c, r.done) {
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("got %v, want %v", got, []int{1, 2, 3})
+	}
+	r.check(t, 3, nil)
+}
+
+func TestSeq(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		stop  int
+		loops int
+		size  int64
+	}{
+		{name: "exhausted", stop: -1, loops: 1, size: 3},
+		{name: "stopped", stop: 2, loops: 1, size: 2},
+		{name: "iterated again", stop: -1, loops: 3, size: 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var r recorder
+			seq := stream.Seq(slices.Values([]string{"a", "b", "c"}), r.done)
+			for range tc.loops {
+				n := 0
+				for range seq {
+					n++
+					if n == tc.stop {
+						break
+					}
+				}
+			}
+			r.check(t, tc.size, nil)
+		})
+	}
+}
+
+func TestSeqNeverRanged(t *testing.T) {
+	var r recorder
+	stream.Seq(slices.Values([]string{"a", "b"}), r.done)
+	stream.Seq2(errorSeq([]error{nil}), r.done)
+	if r.calls != 0 {
+		t.Errorf("got %d calls, want none for sequences that are never ranged over", r.calls)
+	}
+}
+
+func TestSeq2(t *testing.T) {
+	errBroken := errors.New("broken")
+	for _, tc := range []struct {
+		name string
+		errs []error
+		size int64
+		err  error
+	}{
+		{name: "no errors", errs: []error{nil, nil, nil}, size: 3},
+		{name: "error", errs: []error{nil, errBroken, nil}, size: 2, err: errBroken},
+		{name: "last error", errs: []error{errors.New("first"), nil, errBroken}, size: 1, err: errBroken},
+		{name: "empty"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var r recorder
+			seq := stream.Seq2(errorSeq(tc.errs), r.done)
+			for range seq {
+			}
+			for range seq {
+			}
+			r.check(t, tc.size, tc.err)
+		})
+	}
+}
+
+func TestSeq2WithoutErrors(t *testing.T) {
+	var r recorder
+	seq := stream.Seq2(slices.All([]string{"a", "b"}), r.done)
+	for range seq {
+	}
+	r.check(t, 2, nil)
+}
+
+func TestReadCloser(t *testing.T) {
+	errBroken := errors.New("broken")
+	for _, tc := range []struct {
+		name  string
+		r     io.Reader
+		read  bool
+		size  int64
+		err   error
+		close error
+	}{
+		{name: "read to end", r: strings.NewReader("hello"), read: true, size: 5},
+		{name: "closed early", r: strings.NewReader("hello")},
+		{name: "read error", r: io.MultiReader(strings.NewReader("he"), &errReader{errBroken}), read: true, size: 2, err: errBroken},
+		{name: "close error", r: strings.NewReader("hello"), close: errBroken, err: errBroken},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var r recorder
+			rc := stream.ReadCloser(&closer{Reader: tc.r, err: tc.close}, r.done)
+			if tc.read {
+				io.ReadAll(rc)
+			}
+			if err := rc.Close(); !errors.Is(err, tc.close) {
+				t.Errorf("got close error %v, want %v", err, tc.close)
+			}
+			r.check(t, tc.size, tc.err)
+		})
+	}
+}
+
+// errorSeq yields the errors with their indexes.
+func errorSeq(errs []error) iter.Seq2[int, error] {
+	return func(yield func(int, error) bool) {
+		for i, err := range errs {
+			if !yield(i, err) {
+				return
+			}
+		}
+	}
+}
+
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+type closer struct {
+	io.Reader
+	err error
+}
+
+func (c *closer) Close() error {
+	return c.err
+}
@@ -0,0 +1,140 @@
+// Package toggle controls at runtime which calls are instrumented by the
+// wrappers generated by mongen, logen and tracegen with the -toggles option.
+// Instrumentation can be disabled or sampled per method, e.g. from an admin
+// endpoint, without changing the wiring of the wrappers. The calls that are
+// not instrumented go directly to the wrapped implementation.
+package toggle
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Controls holds the controls of methods by name. The generated wrappers name
+// their methods "Interface.Method", e.g. "Service.DoWork", so that a single
+// Controls can be shared by the wrappers of an interface. The names do not
+// include the package of the interface, so interfaces with the same name in
+// different packages need separate Controls to be toggled independently.
+//
+// The zero value is ready for use, and instruments every call. A nil
+// *Controls instruments every call as well, and cannot be changed: Enable,
+// Disable and SetRate do nothing. Controls is safe for concurrent use.
+type Controls struct {
+	mu      sync.Mutex
+	methods map[string]*Method
+}
+
+// Method returns the control of the method with the name, creating it if
+// needed. A new control instruments every call. Method returns nil if c is
+// nil.
+func (c *Controls) Method(name string) *Method {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if m, ok := c.methods[name]; ok {
+		return m
+	}
+	if c.methods == nil {
+		c.methods = make(map[string]*Method)
+	}
+	m := &Method{}
+	m.SetRate(1)
+	c.methods[name] = m
+	return m
+}
+
+// Enable makes every call of the method with the name instrumented.
+func (c *Controls) Enable(name string) {
+	c.SetRate(name, 1)
+}
+
+// Disable makes no call of the method with the name instrumented.
+func (c *Controls) Disable(name string) {
+	c.SetRate(name, 0)
+}
+
+// SetRate makes the given fraction of the calls of the method with the name
+// instrumented, as by Method.SetRate.
+func (c *Controls) SetRate(name string, rate float64) {
+	if c == nil {
+		return
+	}
+	c.Method(name).SetRate(rate)
+}
+
+// Names returns the sorted names of the methods with controls.
+func (c *Controls) Names() []string {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, 0, len(c.methods))
+	for name := range c.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Method controls which calls of a method are instrumented. A nil *Method
+// instruments every call.
+type Method struct {
+	// threshold is the rate of the instrumented calls scaled to a uint32,
+	// such that 0 disables and math.MaxUint32 enables the instrumentation
+	// of every call.
+	threshold atomic.Uint32
+}
+
+// SetRate makes the given fraction of the calls instrumented, picked at
+// random. A rate of 0 or less disables the instrumentation, while a rate of
+// 1 or more enables it for every call. The rate of a nil *Method cannot be
+// changed.
+//
+// The calls that are not picked are not recorded at all, so the counters of
+// a sampled method count only the picked calls: divide them by the rate to
+// estimate the number of calls.
+func (m *Method) SetRate(rate float64) {
+	if m == nil {
+		return
+	}
+	switch {
+	case rate <= 0 || math.IsNaN(rate):
+		m.threshold.Store(0)
+	case rate >= 1:
+		m.threshold.Store(math.MaxUint32)
+	default:
+		m.threshold.Store(uint32(rate * math.MaxUint32))
+	}
+}
+
+// Rate returns the fraction of the calls that are instrumented.
+func (m *Method) Rate() float64 {
+	if m == nil {
+		return 1
+	}
+	return float64(m.threshold.Load()) / math.MaxUint32
+}
+
+// Sample reports whether the current call should be instrumented. If the
+// instrumentation is enabled or disabled for every call, it costs a single
+// atomic load. Otherwise, the call is picked with the top-level source of
+// math/rand, which is safe for concurrent use.
+func (m *Method) Sample() bool {
+	if m == nil {
+		return true
+	}
+	switch t := m.threshold.Load(); t {
+	case 0:
+		return false
+	case math.MaxUint32:
+		return true
+	default:
+		return rand.Uint32() < t
+	}
+}
@@ -0,0 +1,95 @@
+package toggle_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/Bo0mer/gentools/pkg/toggle"
+)
+
+func TestMethodSample(t *testing.T) {
+	const calls = 100000
+	for _, tc := range []struct {
+		name     string
+		rate     float64
+		wantRate float64
+		min, max int
+	}{
+		{name: "disabled", rate: 0, wantRate: 0, min: 0, max: 0},
+		{name: "negative", rate: -1, wantRate: 0, min: 0, max: 0},
+		{name: "NaN", rate: math.NaN(), wantRate: 0, min: 0, max: 0},
+		{name: "enabled", rate: 1, wantRate: 1, min: calls, max: calls},
+		{name: "above one", rate: 2, wantRate: 1, min: calls, max: calls},
+		{name: "partial", rate: 0.25, wantRate: 0.25, min: calls * 23 / 100, max: calls * 27 / 100},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var m toggle.Method
+			m.SetRate(tc.rate)
+			if got := m.Rate(); math.Abs(got-tc.wantRate) > 1e-9 {
+				t.Errorf("got rate %v, want %v", got, tc.wantRate)
+			}
+			sampled := 0
+			for range calls {
+				if m.Sample() {
+					sampled++
+				}
+			}
+			if sampled < tc.min || sampled > tc.max {
+				t.Errorf("got %d sampled calls, want between %d and %d", sampled, tc.min, tc.max)
+			}
+		})
+	}
+}
+
+func TestNilMethod(t *testing.T) {
+	var m *toggle.Method
+	m.SetRate(0)
+	if !m.Sample() {
+		t.Errorf("got a nil method not sampled")
+	}
+	if got := m.Rate(); got != 1 {
+		t.Errorf("got rate %v, want 1", got)
+	}
+}
+
+func TestControls(t *testing.T) {
+	var c toggle.Controls
+	doWork := c.Method("Service.DoWork")
+	if doWork != c.Method("Service.DoWork") {
+		t.Errorf("got a new control for the same method")
+	}
+	if got := doWork.Rate(); got != 1 {
+		t.Errorf("got rate %v, want 1", got)
+	}
+
+	c.Disable("Service.DoWork")
+	if doWork.Sample() {
+		t.Errorf("got a disabled method sampled")
+	}
+	c.Enable("Service.DoWork")
+	if !doWork.Sample() {
+		t.Errorf("got an enabled method not sampled")
+	}
+	c.SetRate("Service.Ping", 0.5)
+	if got := c.Method("Service.Ping").Rate(); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("got rate %v, want 0.5", got)
+	}
+
+	if got, want := c.Names(), []string{"Service.DoWork", "Service.Ping"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNilControls(t *testing.T) {
+	var c *toggle.Controls
+	if m := c.Method("Service.DoWork"); !m.Sample() {
+		t.Errorf("got a method of nil controls not sampled")
+	}
+	c.Disable("Service.DoWork")
+	c.SetRate("Service.DoWork", 0.5)
+	c.Enable("Service.DoWork")
+	if got := c.Names(); got != nil {
+		t.Errorf("got %q, want no names", got)
+	}
+}
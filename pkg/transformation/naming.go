@@ -0,0 +1,164 @@
+package transformation
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// Case is a convention for joining the words of a name.
+type Case string
+
+// supported cases
+const (
+	// AsIs keeps names as they are.
+	AsIs Case = ""
+	// SnakeCase joins lower case words with underscores: do_work.
+	SnakeCase Case = "snake"
+	// KebabCase joins lower case words with dashes: do-work.
+	KebabCase Case = "kebab"
+	// CamelCase joins capitalized words, except for the first one: doWork.
+	CamelCase Case = "camel"
+	// DottedCase joins lower case words with dots: do.work.
+	DottedCase Case = "dotted"
+)
+
+// Cases lists the supported cases, except AsIs.
+var Cases = []Case{SnakeCase, KebabCase, CamelCase, DottedCase}
+
+// Words splits a name into lower case words at underscores, dashes, dots and
+// spaces and at the word boundaries recognized by ToSnakeCase.
+func Words(in string) []string {
+	return strings.FieldsFunc(ToSnakeCase(in), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// Apply returns the name in the case.
+func (c Case) Apply(name string) string {
+	words := Words(name)
+	switch c {
+	case SnakeCase:
+		return strings.Join(words, "_")
+	case KebabCase:
+		return strings.Join(words, "-")
+	case DottedCase:
+		return strings.Join(words, ".")
+	case CamelCase:
+		for i := 1; i < len(words); i++ {
+			runes := []rune(words[i])
+			runes[0] = unicode.ToUpper(runes[0])
+			words[i] = string(runes)
+		}
+		return strings.Join(words, "")
+	}
+	return name
+}
+
+// String returns the name of the case.
+func (c Case) String() string {
+	return string(c)
+}
+
+// Set sets the case by its name, so that a Case can be used as a flag.
+func (c *Case) Set(name string) error {
+	for _, known := range append(Cases, AsIs) {
+		if Case(name) == known {
+			*c = known
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown case %q, expected one of snake, kebab, camel or dotted", name)
+}
+
+// Operation describes a method that is named by a Naming.
+type Operation struct {
+	// Package is the import path of the package declaring the interface.
+	Package string
+	// PackageName is the last element of Package.
+	PackageName string
+	// Interface is the name of the interface.
+	Interface string
+	// Method is the name of the method.
+	Method string
+	// Name is the name of the method in the case of the naming policy.
+	Name string
+}
+
+// Naming is a policy for the names recorded by generated code: the names of
+// operations and spans and the keys of labels and log fields.
+type Naming struct {
+	// Case is the case of operation names.
+	Case Case
+	// Template, if not empty, is a text/template that builds operation names
+	// from an Operation. The functions snake, kebab, camel and dotted convert
+	// their argument to the respective case. Defaults to "{{.Name}}".
+	Template string
+	// KeyCase is the case of label keys and log field names.
+	KeyCase Case
+
+	// tmpl is the parsed Template.
+	tmpl *template.Template
+}
+
+// RegisterFlags registers the command line flags that populate n. The
+// current values of n are the defaults.
+func (n *Naming) RegisterFlags(fs *flag.FlagSet) {
+	fs.Var(&n.Case, "name-case", "Case of operation names: snake, kebab, camel or dotted")
+	fs.StringVar(&n.Template, "name-template", n.Template, "Go template of operation names")
+	fs.Var(&n.KeyCase, "key-case", "Case of label keys and log field names: snake, kebab, camel or dotted")
+}
+
+// Parse parses the template of the policy, so that it is not parsed again for
+// every operation. It is called once the fields are set, before the policy is
+// copied.
+func (n *Naming) Parse() error {
+	n.tmpl = nil
+	if n.Template == "" {
+		return nil
+	}
+	tmpl, err := template.New("name").Funcs(caseFuncs).Parse(n.Template)
+	if err != nil {
+		return fmt.Errorf("invalid name template: %v", err)
+	}
+	n.tmpl = tmpl
+	return nil
+}
+
+// Operation returns the name of the method described by op. The Name and
+// PackageName fields of op are filled in by the policy. The template is
+// parsed by the call if the policy was not parsed before.
+func (n Naming) Operation(op Operation) (string, error) {
+	op.Name = n.Case.Apply(op.Method)
+	op.PackageName = path.Base(op.Package)
+	if n.Template == "" {
+		return op.Name, nil
+	}
+
+	if n.tmpl == nil {
+		if err := n.Parse(); err != nil {
+			return "", err
+		}
+	}
+	var b bytes.Buffer
+	if err := n.tmpl.Execute(&b, op); err != nil {
+		return "", fmt.Errorf("invalid name template: %v", err)
+	}
+	return b.String(), nil
+}
+
+// Key returns the label key or log field name in the key case of the policy.
+func (n Naming) Key(key string) string {
+	return n.KeyCase.Apply(key)
+}
+
+var caseFuncs = template.FuncMap{
+	"snake":  SnakeCase.Apply,
+	"kebab":  KebabCase.Apply,
+	"camel":  CamelCase.Apply,
+	"dotted": DottedCase.Apply,
+}
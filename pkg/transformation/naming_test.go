@@ -0,0 +1,130 @@
+package transformation_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Bo0mer/gentools/pkg/transformation"
+)
+
+func TestCaseApply(t *testing.T) {
+	for _, tc := range []struct {
+		c    transformation.Case
+		name string
+		want string
+	}{
+		{transformation.AsIs, "DoWork", "DoWork"},
+		{transformation.SnakeCase, "DoWork", "do_work"},
+		{transformation.KebabCase, "DoWork", "do-work"},
+		{transformation.CamelCase, "DoWork", "doWork"},
+		{transformation.DottedCase, "DoWork", "do.work"},
+		{transformation.SnakeCase, "GetHTTPStatus", "get_http_status"},
+		{transformation.CamelCase, "error_class", "errorClass"},
+		{transformation.KebabCase, "ops.duration seconds", "ops-duration-seconds"},
+	} {
+		if got := tc.c.Apply(tc.name); got != tc.want {
+			t.Errorf("%q in %q case: got %q, want %q", tc.name, tc.c, got, tc.want)
+		}
+	}
+}
+
+func TestCaseSet(t *testing.T) {
+	var c transformation.Case
+	if err := c.Set("kebab"); err != nil {
+		t.Fatal(err)
+	}
+	if c != transformation.KebabCase {
+		t.Errorf("got %q, want %q", c, transformation.KebabCase)
+	}
+	if err := c.Set("pascal"); err == nil {
+		t.Errorf("got no error for an unknown case")
+	}
+}
+
+func TestNamingOperation(t *testing.T) {
+	op := transformation.Operation{
+		Package:   "example.com/payments",
+		Interface: "Service",
+		Method:    "DoWork",
+	}
+	for _, tc := range []struct {
+		name   string
+		naming transformation.Naming
+		want   string
+	}{
+		{
+			name:   "method",
+			naming: transformation.Naming{},
+			want:   "DoWork",
+		},
+		{
+			name:   "case",
+			naming: transformation.Naming{Case: transformation.SnakeCase},
+			want:   "do_work",
+		},
+		{
+			name:   "template",
+			naming: transformation.Naming{Case: transformation.SnakeCase, Template: "{{.PackageName}}.{{.Interface}}.{{.Name}}"},
+			want:   "payments.Service.do_work",
+		},
+		{
+			name:   "template funcs",
+			naming: transformation.Naming{Template: "{{kebab .Interface}}/{{dotted .Method}}"},
+			want:   "service/do.work",
+		},
+		{
+			name:   "import path",
+			naming: transformation.Naming{Template: "{{.Package}}.{{.Method}}"},
+			want:   "example.com/payments.DoWork",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.naming.Parse(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := tc.naming.Operation(op)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNamingTemplateErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		template string
+		parse    bool
+	}{
+		{name: "syntax", template: "{{.Name", parse: true},
+		{name: "unknown function", template: "{{pascal .Name}}", parse: true},
+		{name: "unknown field", template: "{{.Receiver}}"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n := transformation.Naming{Template: tc.template}
+			err := n.Parse()
+			if tc.parse {
+				if err == nil || !strings.Contains(err.Error(), "invalid name template") {
+					t.Errorf("got %v, want an invalid name template error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := n.Operation(transformation.Operation{Method: "DoWork"}); err == nil {
+				t.Errorf("got no error executing %q", tc.template)
+			}
+		})
+	}
+}
+
+func TestNamingKey(t *testing.T) {
+	n := transformation.Naming{KeyCase: transformation.CamelCase}
+	if got, want := n.Key("error_class"), "errorClass"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
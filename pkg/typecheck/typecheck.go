@@ -0,0 +1,174 @@
+// Package typecheck verifies that generated source compiles.
+package typecheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+)
+
+// Error describes a type error in generated source.
+type Error struct {
+	// Pos is the position of the error in the generated source.
+	Pos token.Position
+	// Decl names the generated declaration that contains the error, e.g.
+	// "method DoWork".
+	Decl string
+	// Msg is the error message reported by the type checker.
+	Msg string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Decl, e.Pos, e.Msg)
+}
+
+// Errors is returned by Check when the generated source does not compile.
+type Errors struct {
+	// Generator is the name of the tool that generated the source.
+	Generator string
+	List      []Error
+}
+
+func (e *Errors) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s generated code that does not compile:", e.Generator)
+	for _, err := range e.List {
+		fmt.Fprintf(&b, "\n\t%s", err)
+	}
+	return b.String()
+}
+
+// Check type-checks the source that generator produced for the named file in
+// dir. Other Go files of the same package in dir are checked along with it,
+// and imports, including the source package, are resolved from their
+// sources. Only errors within the generated source are reported, including
+// those the type checker reports in another file, such as redeclarations,
+// which are reported at the generated declaration they conflict with.
+func Check(generator, dir, filename string, src []byte) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		errs := &Errors{Generator: generator}
+		if list, ok := err.(scanner.ErrorList); ok {
+			for _, e := range list {
+				errs.List = append(errs.List, Error{Pos: e.Pos, Decl: "file", Msg: e.Msg})
+			}
+		} else {
+			errs.List = append(errs.List, Error{Decl: "file", Msg: err.Error()})
+		}
+		return errs
+	}
+
+	files := []*ast.File{file}
+	files = append(files, packageFiles(fset, dir, filename, file.Name.Name)...)
+
+	var errs []Error
+	// The type checker reports some errors, such as redeclarations, as a
+	// primary error followed by continuations whose messages start with a
+	// tab. Either part may be in another file of the package.
+	var (
+		outside *types.Error // last primary error outside the generated file
+		kept    bool         // whether the last primary error was kept
+	)
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			typeErr, ok := err.(types.Error)
+			if !ok {
+				return
+			}
+			pos := fset.Position(typeErr.Pos)
+			inFile := pos.Filename == filename
+			if !strings.HasPrefix(typeErr.Msg, "\t") {
+				outside, kept = nil, inFile
+				if !inFile {
+					outside = &typeErr
+					return
+				}
+				errs = append(errs, Error{
+					Pos:  pos,
+					Decl: enclosingDecl(file, typeErr.Pos),
+					Msg:  typeErr.Msg,
+				})
+				return
+			}
+
+			// A continuation adds its position to a kept error, and makes
+			// a primary error outside the generated file an error of the
+			// generated declaration it points to.
+			msg := strings.TrimSpace(typeErr.Msg)
+			switch {
+			case kept:
+				last := &errs[len(errs)-1]
+				last.Msg += fmt.Sprintf("; %s at %s", msg, pos)
+			case outside != nil && inFile:
+				errs = append(errs, Error{
+					Pos:  pos,
+					Decl: enclosingDecl(file, typeErr.Pos),
+					Msg:  fmt.Sprintf("%s at %s; %s", outside.Msg, fset.Position(outside.Pos), msg),
+				})
+				outside, kept = nil, true
+			}
+		},
+	}
+	conf.Check(file.Name.Name, fset, files, nil)
+
+	if len(errs) > 0 {
+		return &Errors{Generator: generator, List: errs}
+	}
+	return nil
+}
+
+// packageFiles parses the Go files of the named package in dir, except for
+// the one named skip.
+func packageFiles(fset *token.FileSet, dir, skip, name string) []*ast.File {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil
+	}
+	var files []*ast.File
+	for _, f := range pkg.GoFiles {
+		if f == skip {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, f), nil, 0)
+		if err != nil || file.Name.Name != name {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// enclosingDecl describes the top level declaration of file that contains pos.
+func enclosingDecl(file *ast.File, pos token.Pos) string {
+	for _, decl := range file.Decls {
+		if pos < decl.Pos() || pos >= decl.End() {
+			continue
+		}
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil {
+				return fmt.Sprintf("method %s", d.Name.Name)
+			}
+			return fmt.Sprintf("func %s", d.Name.Name)
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				return "imports"
+			}
+			for _, spec := range d.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					return fmt.Sprintf("type %s", ts.Name.Name)
+				}
+			}
+		}
+	}
+	return "file"
+}
@@ -0,0 +1,108 @@
+package typecheck_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bo0mer/gentools/pkg/output"
+	"github.com/Bo0mer/gentools/pkg/typecheck"
+)
+
+const (
+	serviceSource = `package servicemws
+
+const name = "service"
+
+type Service interface {
+	DoWork() error
+}
+`
+	generatedSource = `package servicemws
+
+type monitoringService struct {
+	next Service
+}
+
+func (m *monitoringService) DoWork() error {
+	return m.next.DoWork()
+}
+`
+)
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	for name, src := range map[string]string{
+		"service.go":            serviceSource,
+		"monitoring_service.go": generatedSource,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		options output.Options
+		src     string
+		want    []string
+	}{
+		{
+			name: "default file",
+			src:  generatedSource,
+		},
+		{
+			name:    "stdout replaces the default file",
+			options: output.Options{OutputFile: output.Stdout},
+			src:     generatedSource,
+		},
+		{
+			name:    "other file",
+			options: output.Options{OutputFile: "other.go"},
+			src:     strings.Replace(generatedSource, "monitoringService", "otherService", -1),
+		},
+		{
+			name:    "redeclaration",
+			options: output.Options{OutputFile: "other.go"},
+			src:     generatedSource,
+			want: []string{
+				"type monitoringService: other.go:3:6: monitoringService redeclared in this block at " +
+					filepath.Join(dir, "monitoring_service.go") + ":3:6; other declaration of monitoringService",
+			},
+		},
+		{
+			name:    "redeclaration on stdout",
+			options: output.Options{OutputFile: output.Stdout},
+			src:     generatedSource + "\nconst name = \"monitoring\"\n",
+			want: []string{
+				"file: monitoring_service.go:11:7: name redeclared in this block at " +
+					filepath.Join(dir, "service.go") + ":3:7; other declaration of name",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			target := tc.options.Target(dir, "monitoring_service.go")
+			err := typecheck.Check("mongen", target.Dir, target.Name, []byte(tc.src))
+			if tc.want == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var errs *typecheck.Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("got %v, want type errors", err)
+			}
+			var got []string
+			for _, e := range errs.List {
+				got = append(got, e.Error())
+			}
+			if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}